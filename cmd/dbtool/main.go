@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/mailer"
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+	"github.com/ZotovSergey/authenticationservice/internal/profilesTransfer"
+	"github.com/ZotovSergey/authenticationservice/internal/rest/handlers"
+)
+
+// Использование утилиты (запускается из каталога cmd/dbtool, как и сервис - из cmd/app, чтобы конфиги читались по тем же путям)
+const usage = `usage: dbtool <command> [options]
+
+commands:
+  generate-key  print new random master key (base64) to add to database encryption keys as "version:key"
+  reencrypt     reencrypt database dump with the current (highest) version of master key; service must be stopped
+  import        import profiles from csv or jsonl file and print import report; service must be stopped
+                  dbtool import [-tenant name] [-format csv|jsonl] [-mode create|upsert] [-dry-run] <file>
+  export        export profiles to csv or jsonl file (standard output if file is not set)
+                  dbtool export [-tenant name] [-format csv|jsonl] [-password-hashes] [file]`
+
+/*
+Утилита обслуживания файла базы данных
+*/
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	// Генерация нового мастер-ключа
+	case "generate-key":
+		key, err := myProfilesDB.GenerateEncryptionKey()
+		if err != nil {
+			log.Fatalf("fatal error: %s", err.Error())
+		}
+		fmt.Println(key)
+
+	// Перешифровка файла базы данных ключом текущей версии
+	case "reencrypt":
+		fromVersion, toVersion, err := myProfilesDB.ReencryptDump()
+		if err != nil {
+			log.Fatalf("fatal error: %s", err.Error())
+		}
+		if fromVersion == 0 {
+			log.Printf("unencrypted database dump is encrypted with key version %d", toVersion)
+		} else {
+			log.Printf("database dump is reencrypted from key version %d to key version %d", fromVersion, toVersion)
+		}
+
+	// Импорт профилей из файла
+	case "import":
+		err := importProfiles(os.Args[2:])
+		if err != nil {
+			log.Fatalf("fatal error: %s", err.Error())
+		}
+
+	// Экспорт профилей в файл
+	case "export":
+		err := exportProfiles(os.Args[2:])
+		if err != nil {
+			log.Fatalf("fatal error: %s", err.Error())
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+/*
+Поднятие базы данных и получение тенанта (пустое название - тенант платформы)
+
+:param tenantName string: название тенанта
+
+:return: тенант или ошибка, если базу данных не удалось поднять или тенанта нет
+*/
+func openTenant(tenantName string) (*myProfilesDB.Tenant, error) {
+	err := myProfilesDB.RaiseMyProfilesDB()
+	if err != nil {
+		return nil, err
+	}
+	if tenantName == "" {
+		tenantName = myProfilesDB.DB.PlatformTenant()
+	}
+	return myProfilesDB.DB.GetTenant(tenantName)
+}
+
+/*
+Импорт профилей из файла (пароли проверяются по той же политике паролей, что и в API); отчет об импорте выводится на стандартный вывод
+
+:param args []string: аргументы команды
+
+:return: ошибка, если аргументы некорректны, файл или базу данных не удалось прочитать или сохранить
+*/
+func importProfiles(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	tenantName := flags.String("tenant", "", "tenant name (platform tenant by default)")
+	format := flags.String("format", models.TransferFormatCSV, "file format: csv or jsonl")
+	mode := flags.String("mode", models.ImportModeCreate, "import mode: create or upsert")
+	dryRun := flags.Bool("dry-run", false, "only validate records")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("import file is required\n%s", usage)
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	err = handlers.LoadPasswordPolicy()
+	if err != nil {
+		return err
+	}
+	err = mailer.Configure()
+	if err != nil {
+		return err
+	}
+	t, err := openTenant(*tenantName)
+	if err != nil {
+		return err
+	}
+	report, err := profilesTransfer.Import(t, file, *format, *mode, *dryRun, handlers.PasswordPolicyError, "")
+	if err != nil {
+		return err
+	}
+	mailer.Wait()
+
+	reportJSON, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(reportJSON))
+	return nil
+}
+
+/*
+Экспорт профилей в файл или на стандартный вывод
+
+:param args []string: аргументы команды
+
+:return: ошибка, если аргументы некорректны, базу данных не удалось прочитать или файл не удалось записать
+*/
+func exportProfiles(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	tenantName := flags.String("tenant", "", "tenant name (platform tenant by default)")
+	format := flags.String("format", models.TransferFormatCSV, "file format: csv or jsonl")
+	withPasswordHashes := flags.Bool("password-hashes", false, "export password hashes")
+	flags.Parse(args)
+	if flags.NArg() > 1 {
+		return fmt.Errorf("too many arguments\n%s", usage)
+	}
+
+	t, err := openTenant(*tenantName)
+	if err != nil {
+		return err
+	}
+	var w io.Writer = os.Stdout
+	if flags.NArg() == 1 {
+		file, err := os.OpenFile(flags.Arg(0), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	return profilesTransfer.Export(t, w, *format, *withPasswordHashes)
+}
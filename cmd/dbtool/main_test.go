@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+	"github.com/ZotovSergey/authenticationservice/internal/testutil"
+)
+
+// Путь к файлу базы данных из конфига "../../configs/dbConfig.json" относительно рабочего каталога тестов
+const testDumpPath = "../../databaseDumps/db.json"
+
+// Тесты выполняются из временного каталога с конфигами репозитория, файл базы данных создается в его каталоге databaseDumps
+func TestMain(m *testing.M) {
+	os.Exit(testutil.RunWithConfigs(m, "../../configs", nil, func() error {
+		return os.MkdirAll(filepath.Dir(testDumpPath), 0700)
+	}))
+}
+
+/*
+Запуск команды утилиты на новой БД с перехватом стандартного вывода
+
+:param t *testing.T: тест
+:param command func([]string) error: команда утилиты
+:param args ...string: аргументы команды
+
+:return: стандартный вывод команды
+*/
+func runTestCommand(t *testing.T, command func([]string) error, args ...string) string {
+	t.Helper()
+	output, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+	stdout := os.Stdout
+	os.Stdout = output
+	err = command(args)
+	os.Stdout = stdout
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(output.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+/*
+Импорт файла профилей утилитой
+
+:param t *testing.T: тест
+:param file string: содержимое файла
+:param args ...string: флаги команды import (путь к файлу добавляется в конец)
+
+:return: отчет об импорте
+*/
+func runTestImport(t *testing.T, file string, args ...string) models.ImportReport {
+	t.Helper()
+	filePath := filepath.Join(t.TempDir(), "profiles")
+	err := ioutil.WriteFile(filePath, []byte(file), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report models.ImportReport
+	output := runTestCommand(t, importProfiles, append(args, filePath)...)
+	err = json.Unmarshal([]byte(output), &report)
+	if err != nil {
+		t.Fatalf("import report is not printed: %s", output)
+	}
+	return report
+}
+
+// Импорт и экспорт утилитой: пробный импорт не меняет файл базы данных, пароли проверяются по политике паролей API, зашифрованные пароли экспортируются только по флагу
+func TestImportExport(t *testing.T) {
+	os.Remove(testDumpPath)
+	runTestCommand(t, exportProfiles, filepath.Join(t.TempDir(), "init.csv"))
+	dumpBefore, err := ioutil.ReadFile(testDumpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := `{"login":"alice","firstName":"Alice","email":"alice@example.com","status":"active","password":"Passw0rd1"}
+{"login":"bob","password":"password"}
+{"login":"admin","firstName":"Root"}
+`
+	report := runTestImport(t, file, "-format", "jsonl", "-dry-run")
+	if !report.DryRun || report.Created != 1 || report.Failed != 2 || len(report.Errors) != 2 || report.Errors[0].Login != "bob" ||
+		!strings.Contains(report.Errors[0].Error, "digit") || report.Errors[1].Login != "admin" {
+		t.Fatalf("unexpected dry run report: %+v", report)
+	}
+	dumpAfter, err := ioutil.ReadFile(testDumpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(dumpAfter) != string(dumpBefore) {
+		t.Fatal("database dump is changed by dry run")
+	}
+
+	report = runTestImport(t, file, "-format", "jsonl", "-mode", "upsert")
+	if report.DryRun || report.Created != 1 || report.Updated != 1 || report.Failed != 1 {
+		t.Fatalf("unexpected import report: %+v", report)
+	}
+
+	// Экспорт в файл и на стандартный вывод
+	exportPath := filepath.Join(t.TempDir(), "profiles.csv")
+	runTestCommand(t, exportProfiles, exportPath)
+	exported, err := ioutil.ReadFile(exportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "id,login,firstName,lastName,email,status,attributes\n"
+	if !strings.HasPrefix(string(exported), expected) || !strings.Contains(string(exported), ",admin,Root,admin,,active,\n") ||
+		!strings.Contains(string(exported), ",alice,Alice,,alice@example.com,active,\n") || strings.Contains(string(exported), "$2a$") {
+		t.Fatalf("unexpected export: %s", exported)
+	}
+	output := runTestCommand(t, exportProfiles, "-format", "jsonl", "-password-hashes")
+	if strings.Count(output, "\n") != 2 || strings.Count(output, `"passwordHash":"$2a$`) != 2 {
+		t.Fatalf("unexpected export with password hashes: %s", output)
+	}
+}
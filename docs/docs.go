@@ -91,48 +91,102 @@ const docTemplate = `{
                 }
             }
         },
-        "/logins": {
-            "get": {
+        "/admin/group": {
+            "post": {
                 "security": [
                     {
                         "BasicAuth": []
                     }
                 ],
-                "description": "Запрос на вывод списка логинов всех профилей, доступно всем пользователям",
-                "produces": [
+                "description": "Запрос на добавление группы в список групп администраторов (все участники группы и вложенных групп получают права администратора), доступно только администраторам",
+                "consumes": [
                     "application/json"
                 ],
-                "summary": "Get all logins",
+                "summary": "Add admin group",
+                "parameters": [
+                    {
+                        "description": "название группы, добавляемой к списку групп администраторов",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupNameData"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "logins",
+                        "description": "request completed",
                         "schema": {
-                            "type": "json"
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such group",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на удаление группы из списка групп администраторов, доступно только администраторам, нельзя удалять группу, через которую пользователь получил права администратора",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Drop admin group",
+                "parameters": [
+                    {
+                        "description": "название группы, удаляемой из списка групп администраторов",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupNameData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such group",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
             }
         },
-        "/password": {
-            "patch": {
+        "/email/verification": {
+            "post": {
                 "security": [
                     {
                         "BasicAuth": []
                     }
                 ],
-                "description": "Запрос на изменение пароля пользователя, доступно всем пользователям при редактировании своих профилей и доступно редактирование всех профилей администраторам",
+                "description": "Запрос на повторную отправку токена подтверждения на ожидающий подтверждения адрес электронной почты профиля или на неподтвержденный текущий адрес, доступно всем пользователям для своих профилей и администраторам для всех профилей",
                 "consumes": [
                     "application/json"
                 ],
-                "summary": "Change password",
+                "summary": "Resend email verification",
                 "parameters": [
                     {
-                        "description": "логин профиля, для каторого меняется пароль и новый пароль",
+                        "description": "логин или идентификатор профиля",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.NewPasswordForProfile"
+                            "$ref": "#/definitions/models.LoginData"
                         }
                     }
                 ],
@@ -144,7 +198,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "no such profile",
+                        "description": "email is already verified",
                         "schema": {
                             "type": "string"
                         }
@@ -152,29 +206,58 @@ const docTemplate = `{
                 }
             }
         },
-        "/profile": {
+        "/email/verify/{token}": {
+            "post": {
+                "description": "Запрос на подтверждение адреса электронной почты по одноразовому токену из письма (без авторизации): новый адрес, ожидающий подтверждения, заменяет текущий (на старый адрес отправляется уведомление), в профиле записывается время подтверждения; если заявка на регистрацию не требует одобрения администратора, учетная запись становится активной",
+                "summary": "Verify email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "токен подтверждения",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such email verification or verification expired",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/group": {
             "get": {
                 "security": [
                     {
                         "BasicAuth": []
                     }
                 ],
-                "description": "Запрос на вывод данных о профиле по логину, доступно всем пользователям (запрос не работает со страницы swagger из браузера, но работает через postman или insomnia)",
+                "description": "Запрос на вывод участников и вложенных групп группы по названию, доступно всем пользователям (запрос не работает со страницы swagger из браузера, но работает через postman или insomnia)",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get profile data",
+                "summary": "Get group data",
                 "parameters": [
                     {
-                        "description": "логин получаемого профиля",
+                        "description": "название получаемой группы",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.LoginData"
+                            "$ref": "#/definitions/models.GroupNameData"
                         }
                     }
                 ],
@@ -186,7 +269,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "no such profile",
+                        "description": "no such group",
                         "schema": {
                             "type": "string"
                         }
@@ -199,19 +282,19 @@ const docTemplate = `{
                         "BasicAuth": []
                     }
                 ],
-                "description": "Запрос на регистрацию нового пользователя, доступно только администраторам",
+                "description": "Запрос на создание новой пустой группы, доступно только администраторам",
                 "consumes": [
                     "application/json"
                 ],
-                "summary": "Add profile",
+                "summary": "Add group",
                 "parameters": [
                     {
-                        "description": "данные нового профиля, включающие логин нового профиля, данные для хранения и пароль",
+                        "description": "название новой группы",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.FullProfileData"
+                            "$ref": "#/definitions/models.GroupNameData"
                         }
                     }
                 ],
@@ -223,7 +306,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "no such profile",
+                        "description": "such group is already exists",
                         "schema": {
                             "type": "string"
                         }
@@ -236,19 +319,19 @@ const docTemplate = `{
                         "BasicAuth": []
                     }
                 ],
-                "description": "Запрос удаление профиля, доступно только администраторам, нельзя удалять свой профиль",
+                "description": "Запрос на удаление группы (вложенные группы и профили не удаляются), доступно только администраторам, нельзя удалять группу, через которую пользователь получил права администратора",
                 "consumes": [
                     "application/json"
                 ],
-                "summary": "Remove profile",
+                "summary": "Remove group",
                 "parameters": [
                     {
-                        "description": "логин удаляемого профиля",
+                        "description": "название удаляемой группы",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.LoginData"
+                            "$ref": "#/definitions/models.GroupNameData"
                         }
                     }
                 ],
@@ -260,7 +343,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "no such profile",
+                        "description": "no such group",
                         "schema": {
                             "type": "string"
                         }
@@ -273,19 +356,19 @@ const docTemplate = `{
                         "BasicAuth": []
                     }
                 ],
-                "description": "Запрос на редактирование данных профиля, доступно всем пользователям при редактировании своих профилей и доступно редактирование всех профилей администраторам",
+                "description": "Запрос на переименование группы, доступно только администраторам",
                 "consumes": [
                     "application/json"
                 ],
-                "summary": "Edit profile",
+                "summary": "Rename group",
                 "parameters": [
                     {
-                        "description": "логин редактируемого профиля и новые данные для редактирования (если данные не добавлениы, то они не меняются)",
+                        "description": "текущее и новое название группы",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ProfileData"
+                            "$ref": "#/definitions/models.GroupRenameData"
                         }
                     }
                 ],
@@ -297,52 +380,2859 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "no such profile",
+                        "description": "no such group",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.FullProfileData": {
-            "type": "object",
-            "properties": {
-                "firstName": {
-                    "description": "имя пользователя",
-                    "type": "string"
-                },
-                "lastName": {
-                    "description": "фамилия пользователя",
-                    "type": "string"
-                },
-                "login": {
-                    "description": "логин профиля, используется при авторизации, является первичным ключом для БД, должен быть уникальным",
-                    "type": "string"
-                },
-                "password": {
-                    "description": "пароль для входа нового пользователя (должен быть не длиннее 72 символов)",
-                    "type": "string"
+        },
+        "/group/member": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на добавление профиля в группу, доступно только администраторам",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add group member",
+                "parameters": [
+                    {
+                        "description": "название группы и логин добавляемого профиля",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupMemberData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such group",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на удаление профиля из группы, доступно только администраторам",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Remove group member",
+                "parameters": [
+                    {
+                        "description": "название группы и логин удаляемого из нее профиля",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupMemberData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such group",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
                 }
             }
         },
-        "models.LoginData": {
-            "type": "object",
-            "properties": {
-                "login": {
-                    "description": "логин профиля, используется при авторизации, является первичным ключом для БД, должен быть уникальным",
-                    "type": "string"
+        "/group/subgroup": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на вложение группы в другую группу, доступно только администраторам, вложение не должно образовывать цикл",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add subgroup",
+                "parameters": [
+                    {
+                        "description": "название родительской группы и вкладываемой группы",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SubgroupData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "nesting groups would create a cycle",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на удаление вложенной группы из родительской группы, доступно только администраторам",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Remove subgroup",
+                "parameters": [
+                    {
+                        "description": "название родительской группы и вложенной группы",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SubgroupData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such group",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
                 }
             }
         },
-        "models.NewPasswordForProfile": {
-            "type": "object",
-            "properties": {
-                "login": {
-                    "description": "логин профиля, используется при авторизации, является первичным ключом для БД, должен быть уникальным",
-                    "type": "string"
+        "/groups": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на вывод списка названий всех групп, доступно всем пользователям",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get all groups",
+                "responses": {
+                    "200": {
+                        "description": "groups",
+                        "schema": {
+                            "type": "json"
+                        }
+                    }
+                }
+            }
+        },
+        "/invitation": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на создание приглашения: создается профиль, ожидающий активации, без пароля с правами администратора и членством в группах из приглашения, в ответе возвращается одноразовый токен, по которому приглашенный пользователь задает пароль (токен выдается только один раз), доступно только администраторам",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create invitation",
+                "parameters": [
+                    {
+                        "description": "логин, адрес электронной почты, права администратора, группы и время истечения приглашения",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.InvitationData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        }
+                    },
+                    "404": {
+                        "description": "profile with such login already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на отзыв приглашения: токен приглашения перестает действовать, профиль приглашенного пользователя, если он еще не активирован, удаляется, доступно только администраторам",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Revoke invitation",
+                "parameters": [
+                    {
+                        "description": "идентификатор приглашения",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.InvitationIDData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such invitation or invitation expired",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/invitations": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на вывод приглашений тенанта, которые еще не приняты (токены не выводятся), доступно только администраторам",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get invitations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        }
+                    }
+                }
+            }
+        },
+        "/invitations/{token}/accept": {
+            "post": {
+                "description": "Запрос на принятие приглашения по одноразовому токену (без авторизации): приглашенный пользователь задает пароль, соответствующий политике паролей, и, при необходимости, имя и фамилию, учетная запись становится активной, токен перестает действовать",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Accept invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "токен приглашения",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "пароль, имя и фамилия",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AcceptInvitationData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "password does not satisfy password policy",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such invitation or invitation expired",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/logins": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на вывод списка логинов всех профилей, доступно всем пользователям",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get all logins",
+                "responses": {
+                    "200": {
+                        "description": "logins",
+                        "schema": {
+                            "type": "json"
+                        }
+                    }
+                }
+            }
+        },
+        "/membership": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на вывод всех групп, в которые профиль входит непосредственно или через вложенные группы, доступно всем пользователям (запрос не работает со страницы swagger из браузера, но работает через postman или insomnia)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get effective groups",
+                "parameters": [
+                    {
+                        "description": "логин профиля",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "groups",
+                        "schema": {
+                            "type": "json"
+                        }
+                    },
+                    "404": {
+                        "description": "no such profile",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/client": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на регистрацию нового клиента OAuth 2.0, доступно только администраторам платформы",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add oauth client",
+                "parameters": [
+                    {
+                        "description": "идентификатор, секрет и области доступа нового клиента",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.FullOAuthClientData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "oauth client id must not be empty",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на удаление клиента OAuth 2.0 вместе с выданными ему токенами, доступно только администраторам платформы",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Remove oauth client",
+                "parameters": [
+                    {
+                        "description": "идентификатор удаляемого клиента",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OAuthClientIDData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such oauth client",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/introspect": {
+            "post": {
+                "description": "Запрос на интроспекцию токена доступа (RFC 7662), доступно зарегистрированным клиентам OAuth 2.0",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Introspect access token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "проверяемый токен",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "тип токена, поддерживается только access_token",
+                        "name": "token_type_hint",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OAuthIntrospectionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.OAuthErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.OAuthErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/revoke": {
+            "post": {
+                "description": "Запрос на отзыв токена доступа (RFC 7009), клиент может отозвать только выданные ему токены",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Revoke access token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "отзываемый токен",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "тип токена, поддерживается только access_token",
+                        "name": "token_type_hint",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.OAuthErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.OAuthErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/token": {
+            "post": {
+                "description": "Запрос на выдачу токена доступа по client_credentials grant (RFC 6749, раздел 4.4), клиент авторизуется через basic auth или параметрами client_id и client_secret",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Issue access token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "тип гранта, поддерживается только client_credentials",
+                        "name": "grant_type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "запрашиваемые области доступа, разделенные пробелами (по умолчанию - все области клиента)",
+                        "name": "scope",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OAuthTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.OAuthErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.OAuthErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/password": {
+            "patch": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на изменение пароля пользователя, доступно всем пользователям при редактировании своих профилей и доступно редактирование всех профилей администраторам",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Change password",
+                "parameters": [
+                    {
+                        "description": "логин или идентификатор профиля, для каторого меняется пароль, и новый пароль",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NewPasswordForProfile"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such profile",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/platformAdmin": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на добавление профиля тенанта платформы в список администраторов платформы, доступно только администраторам платформы",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add platform admin",
+                "parameters": [
+                    {
+                        "description": "логин профиля тенанта платформы",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such profile",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на удаление профиля из списка администраторов платформы, доступно только администраторам платформы, нельзя удалять из списка свой профиль",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Drop platform admin",
+                "parameters": [
+                    {
+                        "description": "логин профиля тенанта платформы",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such profile",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на вывод данных о профиле по логину, доступно всем пользователям; поля, скрытые от пользователя настройками видимости тенанта, не выводятся (запрос не работает со страницы swagger из браузера, но работает через postman или insomnia)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get profile data",
+                "parameters": [
+                    {
+                        "description": "логин или идентификатор получаемого профиля",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "версия профиля"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "no such profile",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на регистрацию нового пользователя, доступно только администраторам; учетная запись создается активной или ожидающей активации",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add profile",
+                "parameters": [
+                    {
+                        "description": "данные нового профиля, включающие логин нового профиля, данные для хранения, пароль и начальный статус учетной записи (pending или active)",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.FullProfileData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such profile",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос удаление профиля, доступно только администраторам, нельзя удалять свой профиль; удаленный профиль переносится в корзину, откуда его можно восстановить до истечения времени хранения, с параметром hard=true профиль удаляется окончательно",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Remove profile",
+                "parameters": [
+                    {
+                        "description": "логин или идентификатор удаляемого профиля",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginData"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true - профиль удаляется окончательно вместе с паролем, минуя корзину",
+                        "name": "hard",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag профиля, полученный запросом /profile [get]: профиль удаляется, только если он не был изменен",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such profile",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "412": {
+                        "description": "profile version does not match",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на редактирование данных профиля, доступно всем пользователям при редактировании своих профилей и доступно редактирование всех профилей администраторам.\nФормат изменений определяется заголовком Content-Type: application/json - новые данные профиля (пустые поля не меняются, атрибуты со значением null удаляются),\napplication/merge-patch+json - JSON Merge Patch (RFC 7396, поля со значением null очищаются), application/json-patch+json - JSON Patch (RFC 6902).\nДанные профиля после применения изменений проверяются, логин профиля изменять нельзя.\nИзменения применяются к данным, видимым пользователю: изменения и ссылки на скрытые от пользователя поля отклоняются (422), скрытые поля не меняются",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "summary": "Edit profile",
+                "parameters": [
+                    {
+                        "description": "логин редактируемого профиля и новые данные для редактирования (если данные не добавлениы, то они не меняются, атрибуты со значением null удаляются) или патч",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProfileData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "логин редактируемого профиля (для JSON Patch обязателен логин или идентификатор)",
+                        "name": "login",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "идентификатор редактируемого профиля",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag профиля, полученный запросом /profile [get]: профиль редактируется, только если он не был изменен",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "новая версия профиля"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "no such profile",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "412": {
+                        "description": "profile version does not match",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "unsupported media type",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "patched profile is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/login": {
+            "patch": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на изменение логина профиля, доступно всем пользователям для своих профилей и администраторам для всех профилей. Права администратора и членство в группах переносятся на новый логин, старый логин остается псевдонимом нового на время, заданное в конфиге",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Rename profile",
+                "parameters": [
+                    {
+                        "description": "текущий и новый логины профиля",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginRenameData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such profile",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ready": {
+            "get": {
+                "description": "Запрос на проверку готовности экземпляра обслуживать запросы (без авторизации): роль экземпляра в репликации и позиция в журнале изменений БД,\nдля ведомого экземпляра - отставание от ведущего; ведомый готов, если загрузил снимок ведущего и отстает не больше допустимого в конфиге.\nДля узла кластера возвращается состояние узла (models.ClusterStatus); узел готов, если получил данные БД из журнала Raft и лидер кластера известен",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Readiness",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReplicationStatus"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReplicationStatus"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Запрос на самостоятельную регистрацию пользователя (без авторизации, если разрешена в конфиге регистрации): создается профиль, ожидающий подтверждения адреса электронной почты и (или) одобрения администратора; токен подтверждения отправляется на адрес электронной почты. Число запросов с одного IP-адреса ограничено",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Register",
+                "parameters": [
+                    {
+                        "description": "логин, пароль, имя, фамилия, адрес электронной почты и атрибуты",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RegistrationData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        }
+                    },
+                    "400": {
+                        "description": "password does not satisfy password policy",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "self-registration is disabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "too many registrations from this address, try again later",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/registration/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на одобрение заявки на регистрацию по идентификатору профиля: учетная запись становится активной (адрес электронной почты должен быть подтвержден, если это требуется), доступно только администраторам",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Approve registration",
+                "parameters": [
+                    {
+                        "description": "идентификатор профиля зарегистрировавшегося пользователя",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProfileIDData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such registration",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/registration/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на отклонение заявки на регистрацию по идентификатору профиля: заявка удаляется из очереди, профиль, ожидающий активации, удаляется окончательно, доступно только администраторам",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Reject registration",
+                "parameters": [
+                    {
+                        "description": "идентификатор профиля зарегистрировавшегося пользователя",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProfileIDData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such registration",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/registrations": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на вывод очереди заявок на регистрацию, ожидающих подтверждения адреса электронной почты или одобрения администратора, доступно только администраторам",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get registrations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        }
+                    }
+                }
+            }
+        },
+        "/schema": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на вывод схемы дополнительных атрибутов профилей тенанта, доступно всем пользователям",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get attributes schema",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        }
+                    }
+                }
+            }
+        },
+        "/schema/attribute": {
+            "put": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на добавление или замену описания дополнительного атрибута профилей в схеме атрибутов тенанта, доступно только администраторам, новое описание должно выполняться для всех существующих профилей",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Set attribute definition",
+                "parameters": [
+                    {
+                        "description": "описание атрибута: название, тип (string, int, bool, date, enum, list), обязательность, уникальность, регулярное выражение, допустимые значения enum и видимость (public, self, admin)",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AttributeDefinition"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "invalid attribute definition",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на удаление описания дополнительного атрибута из схемы атрибутов тенанта вместе со значениями атрибута во всех профилях, доступно только администраторам",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Remove attribute definition",
+                "parameters": [
+                    {
+                        "description": "название удаляемого атрибута",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AttributeNameData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "unknown attribute",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/schema/visibility": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на вывод настроек видимости основных полей и дополнительных атрибутов профилей тенанта, доступно всем пользователям",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get fields visibility",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на изменение видимости основного поля (firstName, lastName, email) или дополнительного атрибута профилей тенанта, доступно только администраторам",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Set field visibility",
+                "parameters": [
+                    {
+                        "description": "название поля, уровень видимости (public, self, admin) и группы, участникам которых поле видно всегда",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.FieldVisibility"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "unknown visibility",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tenant": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на создание нового пустого тенанта, доступно только администраторам платформы (профили и администраторы тенанта добавляются запросами к тенанту)",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add tenant",
+                "parameters": [
+                    {
+                        "description": "название нового тенанта",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TenantData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "such tenant is already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на удаление тенанта со всеми его профилями и группами, доступно только администраторам платформы, нельзя удалять тенант платформы",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Remove tenant",
+                "parameters": [
+                    {
+                        "description": "название удаляемого тенанта",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TenantData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such tenant",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tenants": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на вывод списка названий всех тенантов, доступно только администраторам платформы",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get all tenants",
+                "responses": {
+                    "200": {
+                        "description": "tenants",
+                        "schema": {
+                            "type": "json"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на вывод профилей в корзине тенанта (удаленных, но еще не удаленных окончательно) с временем удаления, доступно только администраторам",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get deleted profiles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на окончательное удаление профиля из корзины вместе с паролем, не дожидаясь истечения времени хранения, доступно только администраторам",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Purge deleted profile",
+                "parameters": [
+                    {
+                        "description": "идентификатор удаленного профиля",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProfileIDData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such deleted profile",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на восстановление профиля из корзины вместе с паролем, правами администратора и членством в группах, доступно только администраторам; логин профиля не должен быть занят",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Restore deleted profile",
+                "parameters": [
+                    {
+                        "description": "идентификатор удаленного профиля",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProfileIDData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no such deleted profile",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/backup": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на получение резервной копии БД без остановки сервиса: согласованный снимок всех тенантов, администраторов платформы, клиентов и токенов OAuth 2.0 в формате файла базы данных (если заданы мастер-ключи, копия зашифрована), доступно только администраторам платформы",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "summary": "Backup database",
+                "responses": {
+                    "200": {
+                        "description": "резервная копия БД",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "user is not platform admin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на восстановление БД из резервной копии без остановки сервиса: копия проверяется (целостность, ключ шифрования, наличие тенанта и администраторов платформы), после чего все данные БД атомарно заменяются данными копии (если БД не удалось сохранить, данные не меняются); журналы изменений профилей очищаются, номера событий не уменьшаются, доступно только администраторам платформы",
+                "consumes": [
+                    "application/octet-stream"
+                ],
+                "summary": "Restore database",
+                "parameters": [
+                    {
+                        "description": "резервная копия БД, полученная запросом /v1/admin/backup [get]",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid backup",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "user is not platform admin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/batch": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на выполнение пакета операций над профилями тенанта по принципу \"все или ничего\": операции (createProfile, editProfile, setPassword, removeProfile, addAdmin, dropAdmin, addGroupMember, removeGroupMember, activateAccount, suspendAccount) выполняются по порядку, данные сохраняются один раз; если одна из операций не выполнена, все операции отменяются. Доступно только администраторам; пароли проверяются по политике паролей, нельзя удалять и приостанавливать свой профиль и лишать себя прав администратора",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Batch operations",
+                "parameters": [
+                    {
+                        "description": "операции пакета",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        }
+                    },
+                    "400": {
+                        "description": "password does not satisfy password policy",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "json"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/cluster": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на получение состояния узла кластера: состояние узла в Raft, лидер кластера и адрес его API, позиция в журнале Raft и узлы кластера, доступно только администраторам платформы",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Cluster status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ClusterStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "user is not platform admin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "cluster is disabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/events": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на получение потока событий изменений профилей тенанта (Server-Sent Events): создание, изменение и удаление профилей, выдача и отзыв прав администратора, смена пароля (без пароля и хэша), доступно только администраторам.\nСначала выводятся хранимые события с номерами больше since (или Last-Event-ID), затем новые события по мере фиксации изменений; без номера выводятся только новые события.\nЕсли события после заданного номера уже не хранятся, возвращается 410 - клиенту нужно заново прочитать профили и подключиться без номера",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "summary": "Watch profile changes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "номер последнего полученного события",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "номер последнего полученного события (используется, если since не задан)",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "поток событий",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "event sequence number must be a non-negative integer",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "change events are no longer retained",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/profiles": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на вывод учетных записей тенанта со статусами (pending, active, suspended, expired), причинами приостановки и сроками действия, доступно только администраторам",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get accounts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "статус, по которому отбираются учетные записи",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        }
+                    },
+                    "404": {
+                        "description": "unknown account status",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/profiles/export": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на экспорт профилей тенанта в CSV или JSON Lines (файл выводится потоком по мере форматирования), доступно только администраторам; зашифрованные пароли выводятся только по запросу",
+                "produces": [
+                    "text/plain"
+                ],
+                "summary": "Export profiles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "формат файла: csv или jsonl (по-умолчанию)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "выводить зашифрованные пароли (хэши bcrypt)",
+                        "name": "passwordHashes",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "файл профилей",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "unknown profiles file format",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/profiles/import": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на импорт профилей из CSV (с заголовком, колонка login обязательна, атрибуты - json-объект в колонке attributes) или JSON Lines, доступно только администраторам. В режиме create создаются только новые профили, в режиме upsert данные существующих профилей заменяются. Пароли проверяются по политике паролей, вместо пароля можно передать хэш bcrypt (passwordHash). Записи с ошибками пропускаются и попадают в отчет, при проверке (dryRun) профили не изменяются",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Import profiles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "формат файла: csv или jsonl (по-умолчанию определяется по Content-Type: text/csv или application/x-ndjson)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "режим импорта: create (по-умолчанию) или upsert",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "только проверить записи",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "файл профилей",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid profiles file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/profiles/{login}/activate": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на активацию ожидающей активации, приостановленной или истекшей учетной записи (истекший срок действия снимается), доступно только администраторам",
+                "summary": "Activate account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "логин профиля",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "account status transition is not allowed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/profiles/{login}/expiry": {
+            "put": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на изменение срока действия учетной записи: по истечении срока активная учетная запись автоматически становится истекшей, срок должен быть 0 или в будущем, доступно только администраторам, нельзя менять срок действия своей учетной записи",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Set account expiry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "логин профиля",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "время истечения срока действия (unix-время в секундах, 0 - срок не ограничен)",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ExpiryData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "account expiry time must be 0 or in the future",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/profiles/{login}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на вывод истории изменений профиля: версия, время, автор, действие и изменения полей относительно предыдущей записи, доступно всем пользователям; изменения полей, скрытых от пользователя настройками видимости тенанта, не выводятся",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get profile history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "логин профиля",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        }
+                    },
+                    "404": {
+                        "description": "no such profile",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/profiles/{login}/history/as-of": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на вывод данных профиля на момент одной из предыдущих версий или на заданное время, доступно всем пользователям; поля, скрытые от пользователя настройками видимости тенанта, не выводятся",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get profile as of version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "логин профиля",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "версия профиля из истории изменений",
+                        "name": "version",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "время (unix-время в секундах), используется, если версия не задана",
+                        "name": "timestamp",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        }
+                    },
+                    "404": {
+                        "description": "no such version in profile history",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/profiles/{login}/revert": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на возврат данных профиля (имени, фамилии, адреса электронной почты и атрибутов) к одной из предыдущих версий или к данным на заданное время, доступно всем пользователям для своих профилей и администраторам для всех профилей; логин профиля не меняется, возврат записывается в историю как новое изменение",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Revert profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "логин профиля",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "версия профиля или время (unix-время в секундах)",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProfileRevertData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag профиля, полученный запросом /profile [get]: профиль изменяется, только если он не был изменен",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "новая версия профиля"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "no such version in profile history",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "412": {
+                        "description": "profile version does not match",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/profiles/{login}/suspend": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на приостановку активной учетной записи с указанием причины (данные профиля сохраняются, авторизация запрещается до активации), доступно только администраторам, нельзя приостанавливать свою учетную запись",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Suspend account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "логин профиля",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "причина приостановки",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SuspensionData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "account status transition is not allowed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/replication/log": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на получение записей журнала изменений БД после заданного номера для ведомого экземпляра, доступно только администраторам платформы ведущего экземпляра.\nЕсли новых записей нет, запрос ждет их не дольше wait секунд (не больше 60). Если эпоха журнала сменилась (ведущий перезапущен) или записи после since уже не хранятся, возвращается 410 - ведомому нужно заново загрузить снимок",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Replication log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "эпоха журнала из снимка или предыдущего ответа",
+                        "name": "epoch",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "номер последней примененной записи",
+                        "name": "since",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "время ожидания новых записей в секундах",
+                        "name": "wait",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReplicationLog"
+                        }
+                    },
+                    "400": {
+                        "description": "replication log position must be a non-negative integer",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "user is not platform admin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "replication log entries are no longer retained, snapshot is required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "replication data is served only by the leader",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/replication/snapshot": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на получение снимка БД для начальной загрузки ведомого экземпляра: все данные БД в формате файла базы данных (без шифрования) с эпохой и номером последней записи журнала изменений БД, доступно только администраторам платформы ведущего экземпляра",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Replication snapshot",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReplicationSnapshot"
+                        }
+                    },
+                    "404": {
+                        "description": "user is not platform admin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "replication data is served only by the leader",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на вывод вебхуков тенанта (секреты не выводятся), доступно только администраторам",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на регистрацию вебхука: на адрес отправляются события изменений профилей тенанта заданных типов (пустой список - все события) запросами POST, подписанными HMAC-SHA256 секретом вебхука (заголовок X-Webhook-Signature: \"sha256=\u003chex\u003e\" от строки \"\u003cX-Webhook-Timestamp\u003e.\u003cтело запроса\u003e\"); в ответе возвращается секрет (выдается только один раз), доступно только администраторам",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Add webhook",
+                "parameters": [
+                    {
+                        "description": "адрес и типы событий вебхука",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        }
+                    },
+                    "500": {
+                        "description": "webhook url must be an absolute http or https url",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/dead-letters": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на вывод недоставленных событий тенанта - доставок, все попытки которых завершились ошибкой, доступно только администраторам",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get undelivered webhook events",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/dead-letters/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на повторную доставку недоставленного события: доставка убирается из списка недоставленных событий и снова выполняется с полным набором попыток, доступно только администраторам",
+                "summary": "Retry undelivered webhook event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "идентификатор доставки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "no such undelivered webhook event",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на удаление вебхука вместе с историей доставок и недоставленными событиями вебхука (ожидающие доставки отменяются), доступно только администраторам",
+                "summary": "Remove webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "идентификатор вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "request completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "no such webhook",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на вывод истории доставок событий вебхука: ожидающие доставки и последние завершенные доставки с количеством попыток, статусом ответа и ошибкой последней попытки, доступно только администраторам",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "идентификатор вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        }
+                    },
+                    "500": {
+                        "description": "no such webhook",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{id}/test": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Запрос на отправку тестового события (тип webhook.test) на адрес вебхука: выполняется одна попытка доставки без повторов, результат записывается в историю доставок и возвращается в ответе, доступно только администраторам",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Test webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "идентификатор вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "json"
+                        }
+                    },
+                    "500": {
+                        "description": "no such webhook",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AcceptInvitationData": {
+            "type": "object",
+            "properties": {
+                "firstName": {
+                    "description": "имя пользователя",
+                    "type": "string"
+                },
+                "lastName": {
+                    "description": "фамилия пользователя",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "пароль, задаваемый приглашенным пользователем (должен соответствовать политике паролей)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.AttributeDefinition": {
+            "type": "object",
+            "properties": {
+                "enumValues": {
+                    "description": "допустимые значения атрибута типа enum",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "название атрибута, является первичным ключом схемы атрибутов, должно быть уникальным",
+                    "type": "string"
+                },
+                "regex": {
+                    "description": "регулярное выражение для значений типов string и list",
+                    "type": "string"
+                },
+                "required": {
+                    "description": "true, если атрибут обязателен для всех профилей",
+                    "type": "boolean"
+                },
+                "type": {
+                    "description": "тип атрибута: string, int, bool, date, enum или list",
+                    "type": "string"
+                },
+                "unique": {
+                    "description": "true, если значения атрибута не должны повторяться у разных профилей (не поддерживается для bool и list)",
+                    "type": "boolean"
+                },
+                "visibility": {
+                    "description": "видимость атрибута: public, self или admin (по умолчанию self)",
+                    "type": "string"
+                },
+                "visibleToGroups": {
+                    "description": "группы (роли), участники которых видят атрибут независимо от уровня видимости",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.AttributeNameData": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "название атрибута",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BatchData": {
+            "type": "object",
+            "properties": {
+                "operations": {
+                    "description": "операции, выполняемые по порядку",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchOperation"
+                    }
+                }
+            }
+        },
+        "models.BatchOperation": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "description": "дополнительные атрибуты профиля",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "email": {
+                    "description": "адрес электронной почты пользователя",
+                    "type": "string"
+                },
+                "firstName": {
+                    "description": "имя пользователя",
+                    "type": "string"
+                },
+                "group": {
+                    "description": "название группы",
+                    "type": "string"
+                },
+                "hard": {
+                    "description": "профиль удаляется окончательно, минуя корзину",
+                    "type": "boolean"
+                },
+                "lastName": {
+                    "description": "фамилия пользователя",
+                    "type": "string"
+                },
+                "login": {
+                    "description": "логин профиля",
+                    "type": "string"
+                },
+                "op": {
+                    "description": "операция",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "пароль (проверяется по политике паролей)",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "причина приостановки учетной записи",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "начальный статус учетной записи: pending или active (по-умолчанию)",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "ожидаемая текущая версия профиля (0 - версия не проверяется)",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ClusterServer": {
+            "type": "object",
+            "properties": {
+                "apiUrl": {
+                    "description": "адрес API узла (из конфига кластера)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "идентификатор узла",
+                    "type": "string"
+                },
+                "raftAddr": {
+                    "description": "адрес транспорта Raft узла",
+                    "type": "string"
+                },
+                "voter": {
+                    "description": "true - узел участвует в голосовании",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.ClusterStatus": {
+            "type": "object",
+            "properties": {
+                "appliedIndex": {
+                    "description": "номер последней примененной к БД команды",
+                    "type": "integer"
+                },
+                "lastIndex": {
+                    "description": "номер последней команды в журнале Raft узла",
+                    "type": "integer"
+                },
+                "leader": {
+                    "description": "идентификатор лидера кластера",
+                    "type": "string"
+                },
+                "leaderApiUrl": {
+                    "description": "адрес API лидера кластера, которому перенаправляются изменяющие запросы",
+                    "type": "string"
+                },
+                "nodeId": {
+                    "description": "идентификатор узла",
+                    "type": "string"
+                },
+                "ready": {
+                    "description": "узел получил данные БД из журнала Raft и лидер кластера известен",
+                    "type": "boolean"
+                },
+                "servers": {
+                    "description": "узлы кластера в конфигурации Raft",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ClusterServer"
+                    }
+                },
+                "state": {
+                    "description": "состояние узла в Raft: Leader, Follower, Candidate или Shutdown",
+                    "type": "string"
+                },
+                "term": {
+                    "description": "текущий срок Raft",
+                    "type": "integer"
+                },
+                "writable": {
+                    "description": "узел является лидером и принимает изменения",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.ExpiryData": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "description": "время истечения срока действия учетной записи (unix-время в секундах, 0 - срок не ограничен)",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.FieldVisibility": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "название основного поля (firstName, lastName, email) или дополнительного атрибута",
+                    "type": "string"
+                },
+                "visibility": {
+                    "description": "видимость поля: public, self или admin (по умолчанию имя и фамилия - public, адрес электронной почты - self)",
+                    "type": "string"
+                },
+                "visibleToGroups": {
+                    "description": "группы (роли), участники которых видят поле независимо от уровня видимости",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.FullOAuthClientData": {
+            "type": "object",
+            "properties": {
+                "clientId": {
+                    "description": "идентификатор клиента, является первичным ключом для таблицы клиентов, должен быть уникальным",
+                    "type": "string"
+                },
+                "clientSecret": {
+                    "description": "секрет клиента (должен быть не длиннее 72 символов)",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "список областей доступа (scopes), которые могут быть выданы клиенту",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.FullProfileData": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "description": "дополнительные атрибуты профиля, описанные в схеме атрибутов тенанта",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "email": {
+                    "description": "адрес электронной почты пользователя",
+                    "type": "string"
+                },
+                "firstName": {
+                    "description": "имя пользователя",
+                    "type": "string"
+                },
+                "lastName": {
+                    "description": "фамилия пользователя",
+                    "type": "string"
+                },
+                "login": {
+                    "description": "логин профиля, используется при авторизации, является первичным ключом для БД, должен быть уникальным",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "пароль для входа нового пользователя (должен быть не длиннее 72 символов)",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "начальный статус учетной записи: pending (ожидает активации) или active (по-умолчанию)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.GroupMemberData": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "description": "логин профиля, добавляемого в группу или удаляемого из нее",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "название группы",
+                    "type": "string"
+                }
+            }
+        },
+        "models.GroupNameData": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "название группы",
+                    "type": "string"
+                }
+            }
+        },
+        "models.GroupRenameData": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "текущее название группы",
+                    "type": "string"
+                },
+                "newName": {
+                    "description": "новое название группы",
+                    "type": "string"
+                }
+            }
+        },
+        "models.InvitationData": {
+            "type": "object",
+            "properties": {
+                "admin": {
+                    "description": "приглашенный пользователь становится администратором тенанта",
+                    "type": "boolean"
+                },
+                "email": {
+                    "description": "адрес электронной почты приглашенного пользователя",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "время истечения приглашения (unix-время в секундах, 0 - время жизни приглашения из конфига)",
+                    "type": "integer"
+                },
+                "groups": {
+                    "description": "группы, в которые входит профиль приглашенного пользователя",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "login": {
+                    "description": "логин профиля приглашенного пользователя (если не задан, логином становится адрес электронной почты)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.InvitationIDData": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "идентификатор приглашения",
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoginData": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "идентификатор профиля (если задан, профиль ищется по идентификатору, а не по логину)",
+                    "type": "string"
+                },
+                "login": {
+                    "description": "логин профиля, используется при авторизации, должен быть уникальным в пределах тенанта",
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoginRenameData": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "description": "текущий логин профиля",
+                    "type": "string"
+                },
+                "newLogin": {
+                    "description": "новый логин профиля (не должен совпадать с логинами и псевдонимами других профилей)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.NewPasswordForProfile": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "идентификатор профиля (если задан, профиль ищется по идентификатору, а не по логину)",
+                    "type": "string"
+                },
+                "login": {
+                    "description": "логин профиля, используется при авторизации, является первичным ключом для БД, должен быть уникальным",
+                    "type": "string"
                 },
                 "newPassword": {
                     "description": "новый пароль для входа пользователя (должен быть не длиннее 72 символов)",
@@ -350,9 +3240,187 @@ const docTemplate = `{
                 }
             }
         },
+        "models.OAuthClientData": {
+            "type": "object",
+            "properties": {
+                "clientId": {
+                    "description": "идентификатор клиента, является первичным ключом для таблицы клиентов, должен быть уникальным",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "список областей доступа (scopes), которые могут быть выданы клиенту",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.OAuthClientIDData": {
+            "type": "object",
+            "properties": {
+                "clientId": {
+                    "description": "идентификатор клиента",
+                    "type": "string"
+                }
+            }
+        },
+        "models.OAuthErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "код ошибки",
+                    "type": "string"
+                },
+                "error_description": {
+                    "description": "описание ошибки",
+                    "type": "string"
+                }
+            }
+        },
+        "models.OAuthIntrospectionResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "true, если токен действителен",
+                    "type": "boolean"
+                },
+                "client_id": {
+                    "description": "идентификатор клиента, которому выдан токен",
+                    "type": "string"
+                },
+                "exp": {
+                    "description": "время истечения срока действия токена (unix time)",
+                    "type": "integer"
+                },
+                "iat": {
+                    "description": "время выдачи токена (unix time)",
+                    "type": "integer"
+                },
+                "scope": {
+                    "description": "области доступа токена, разделенные пробелами",
+                    "type": "string"
+                },
+                "token_type": {
+                    "description": "тип токена",
+                    "type": "string"
+                }
+            }
+        },
+        "models.OAuthTokenData": {
+            "type": "object",
+            "properties": {
+                "clientId": {
+                    "description": "идентификатор клиента, которому выдан токен",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "время истечения срока действия токена (unix time)",
+                    "type": "integer"
+                },
+                "issuedAt": {
+                    "description": "время выдачи токена (unix time)",
+                    "type": "integer"
+                },
+                "scope": {
+                    "description": "области доступа токена, разделенные пробелами",
+                    "type": "string"
+                }
+            }
+        },
+        "models.OAuthTokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "description": "токен доступа",
+                    "type": "string"
+                },
+                "expires_in": {
+                    "description": "время жизни токена в секундах",
+                    "type": "integer"
+                },
+                "scope": {
+                    "description": "области доступа токена, разделенные пробелами",
+                    "type": "string"
+                },
+                "token_type": {
+                    "description": "тип токена, всегда \"Bearer\"",
+                    "type": "string"
+                }
+            }
+        },
         "models.ProfileData": {
             "type": "object",
             "properties": {
+                "attributes": {
+                    "description": "дополнительные атрибуты профиля, описанные в схеме атрибутов тенанта",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "email": {
+                    "description": "адрес электронной почты пользователя (не выводится, если скрыто настройками видимости)",
+                    "type": "string"
+                },
+                "firstName": {
+                    "description": "имя пользователя (не выводится, если скрыто настройками видимости)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "неизменяемый идентификатор профиля, генерируется при создании профиля, является первичным ключом для БД",
+                    "type": "string"
+                },
+                "lastName": {
+                    "description": "фамилия пользователя (не выводится, если скрыто настройками видимости)",
+                    "type": "string"
+                },
+                "login": {
+                    "description": "логин профиля, используется при авторизации, должен быть уникальным в пределах тенанта, может быть изменен",
+                    "type": "string"
+                },
+                "pendingEmail": {
+                    "description": "новый адрес электронной почты, ожидающий подтверждения (выводится только владельцу профиля и администраторам), задается только БД",
+                    "type": "string"
+                },
+                "verifiedAt": {
+                    "description": "время подтверждения адреса электронной почты (unix-время в секундах, 0 - адрес не подтвержден), задается только БД",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProfileIDData": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "идентификатор профиля",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProfileRevertData": {
+            "type": "object",
+            "properties": {
+                "timestamp": {
+                    "description": "время (unix-время в секундах), используется, если версия не задана",
+                    "type": "integer"
+                },
+                "version": {
+                    "description": "версия профиля из истории изменений",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RegistrationData": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "description": "дополнительные атрибуты профиля, описанные в схеме атрибутов тенанта",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "email": {
+                    "description": "адрес электронной почты пользователя (обязателен, если требуется подтверждение адреса)",
+                    "type": "string"
+                },
                 "firstName": {
                     "description": "имя пользователя",
                     "type": "string"
@@ -362,7 +3430,181 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "login": {
-                    "description": "логин профиля, используется при авторизации, является первичным ключом для БД, должен быть уникальным",
+                    "description": "логин нового профиля (если не задан, логином становится адрес электронной почты)",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "пароль нового пользователя (должен соответствовать политике паролей)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReplicationEntry": {
+            "type": "object",
+            "properties": {
+                "clientsDataTab": {
+                    "description": "таблица данных клиентов OAuth 2.0",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.OAuthClientData"
+                    }
+                },
+                "clientsSecretsTab": {
+                    "description": "таблица зашифрованных секретов клиентов OAuth 2.0",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "full": {
+                    "description": "true - в записи все тенанты БД (тенанты, которых нет в записи, удалены)",
+                    "type": "boolean"
+                },
+                "platformAdminsTab": {
+                    "description": "таблица админов платформы",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "schemaVersion": {
+                    "description": "версия схемы данных тенантов",
+                    "type": "integer"
+                },
+                "seq": {
+                    "description": "номер записи (номера идут подряд в пределах эпохи)",
+                    "type": "integer"
+                },
+                "tenants": {
+                    "description": "данные измененных тенантов в формате файла базы данных (null - тенант удален)",
+                    "type": "object"
+                },
+                "tokensTab": {
+                    "description": "таблица выданных токенов доступа",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.OAuthTokenData"
+                    }
+                }
+            }
+        },
+        "models.ReplicationLog": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "description": "записи журнала с номерами больше запрошенного",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReplicationEntry"
+                    }
+                },
+                "epoch": {
+                    "description": "эпоха журнала изменений БД",
+                    "type": "string"
+                },
+                "lastSeq": {
+                    "description": "номер последней записи журнала ведущего экземпляра",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReplicationSnapshot": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "данные БД в формате файла базы данных (без шифрования)",
+                    "type": "object"
+                },
+                "epoch": {
+                    "description": "эпоха журнала изменений БД (меняется при каждом запуске ведущего экземпляра)",
+                    "type": "string"
+                },
+                "seq": {
+                    "description": "номер последней записи журнала, вошедшей в снимок",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReplicationStatus": {
+            "type": "object",
+            "properties": {
+                "epoch": {
+                    "description": "эпоха журнала изменений БД",
+                    "type": "string"
+                },
+                "lagEntries": {
+                    "description": "отставание ведомого экземпляра в записях журнала",
+                    "type": "integer"
+                },
+                "lagSeconds": {
+                    "description": "отставание ведомого экземпляра в секундах (время с момента, когда ведомый в последний раз догнал ведущего)",
+                    "type": "integer"
+                },
+                "lastError": {
+                    "description": "ошибка последнего обращения к ведущему экземпляру",
+                    "type": "string"
+                },
+                "leaderSeq": {
+                    "description": "номер последней записи журнала ведущего экземпляра по последнему ответу ведущего",
+                    "type": "integer"
+                },
+                "ready": {
+                    "description": "экземпляр готов обслуживать запросы (ведомый загрузил снимок и отстает от ведущего не больше допустимого)",
+                    "type": "boolean"
+                },
+                "role": {
+                    "description": "роль экземпляра: leader или follower",
+                    "type": "string"
+                },
+                "seq": {
+                    "description": "номер последней записи журнала (для ведомого - последней примененной записи)",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SubgroupData": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "название родительской группы",
+                    "type": "string"
+                },
+                "subgroup": {
+                    "description": "название вложенной группы",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SuspensionData": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "причина приостановки учетной записи (обязательна)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.TenantData": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "название тенанта (латинские строчные буквы, цифры и дефисы), является первичным ключом для таблицы тенантов, должно быть уникальным",
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookData": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "description": "типы событий, отправляемых на адрес (пустой список - все события)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "description": "адрес, на который отправляются события (http или https)",
                     "type": "string"
                 }
             }
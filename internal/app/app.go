@@ -3,21 +3,87 @@ package app
 import (
 	"log"
 
+	"github.com/ZotovSergey/authenticationservice/internal/cluster"
 	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/mailer"
+	"github.com/ZotovSergey/authenticationservice/internal/replication"
 	"github.com/ZotovSergey/authenticationservice/internal/rest/httprouter"
+	"github.com/ZotovSergey/authenticationservice/internal/webhooks"
 	// "github.com/ZotovSergey/authenticationservice/internal/models"
 )
 
 func Run() {
-	// Поднятие БД
+	// Чтение конфигурации репликации
+	err := replication.Configure()
+	if err != nil {
+		log.Printf("fatal error: %s", err.Error())
+		return
+	}
+
+	// Чтение конфигурации кластера (узел кластера не может быть ведомым экземпляром: данные узлов выравнивает журнал Raft)
+	err = cluster.Configure()
+	if err != nil {
+		log.Printf("fatal error: %s", err.Error())
+		return
+	}
+	if cluster.Enabled() && replication.IsFollower() {
+		log.Println("fatal error: cluster node can not be a replication follower")
+		return
+	}
+
+	// Поднятие БД (БД ведомого экземпляра загружается с ведущего экземпляра)
 	log.Println("in memory database is raising...")
-	err := myProfilesDB.RaiseMyProfilesDB()
+	if replication.IsFollower() {
+		err = myProfilesDB.RaiseFollowerDB()
+	} else {
+		err = myProfilesDB.RaiseMyProfilesDB()
+	}
 	if err != nil {
 		log.Printf("fatal error: %s", err.Error())
 		return
 	}
 	log.Println("in memory database raised")
 
+	// Запуск узла кластера: изменения БД фиксируются в журнале Raft, данные узла заменяются данными лидера
+	if cluster.Enabled() {
+		err = cluster.Start(myProfilesDB.DB)
+		if err != nil {
+			log.Printf("fatal error: %s", err.Error())
+			return
+		}
+		log.Println("cluster node started")
+	}
+
+	// Выбор отправителя писем
+	err = mailer.Configure()
+	if err != nil {
+		log.Printf("fatal error: %s", err.Error())
+		return
+	}
+
+	// Чтение конфигурации доставки событий вебхуков
+	err = webhooks.Configure()
+	if err != nil {
+		log.Printf("fatal error: %s", err.Error())
+		return
+	}
+
+	if replication.IsFollower() {
+		// Запуск репликации данных ведущего экземпляра (фоновые задачи, изменяющие данные, выполняет ведущий экземпляр)
+		go replication.Run()
+	} else {
+		// Фоновые задачи, изменяющие данные, запускаются на всех узлах кластера, но выполняются только лидером
+
+		// Запуск фоновой доставки событий на адреса вебхуков
+		go myProfilesDB.DB.RunWebhookJob()
+
+		// Запуск фоновой очистки корзины удаленных профилей
+		go myProfilesDB.DB.RunPurgeJob()
+
+		// Запуск резервного копирования по расписанию
+		go myProfilesDB.DB.RunBackupJob()
+	}
+
 	// Развертывание API
 	log.Println("api deployment...")
 	err = httprouter.StartAPI()
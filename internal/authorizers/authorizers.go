@@ -6,20 +6,28 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/models"
 )
 
 /*
-Авторизация любого пользователя по логину и паролю
+Авторизация любого пользователя тенанта по логину и паролю
 
+:param tenantName string: название тенанта, в котором авторизуется пользователь
 :param login string: логин для авторизации пользователя
 :param password string: пароль для авторизации пользователя
 
-:return: true - если логин и пароль есть в БД, иначе - false
+:return: true - если логин и пароль есть в тенанте БД и учетная запись профиля активна, иначе - false
 */
-func CommonUsersAuthorizer(login, password string) bool {
-	log.Println("attempt to authorize...")
+func CommonUsersAuthorizer(tenantName, login, password string) bool {
+	log.Printf("attempt to authorize in tenant \"%s\"...", tenantName)
+	// Получение тенанта из БД
+	tenant, err := myProfilesDB.DB.GetTenant(tenantName)
+	if err != nil {
+		log.Println("access denied: no such tenant")
+		return false
+	}
 	// Получение пароля из БД
-	passwordHashSalt, err := myProfilesDB.DB.GetPasswordHashSalt(login)
+	passwordHashSalt, err := tenant.GetPasswordHashSalt(login)
 	if err != nil {
 		log.Println("access denied: no such profile")
 		return false
@@ -30,6 +38,57 @@ func CommonUsersAuthorizer(login, password string) bool {
 		log.Println("access denied: wrong password")
 		return false
 	}
+	// Проверка статуса учетной записи (ожидающие активации, приостановленные и истекшие учетные записи не авторизуются)
+	accountStatus, err := tenant.GetAccountStatus(login)
+	if err != nil {
+		log.Println("access denied: no such profile")
+		return false
+	}
+	if accountStatus.Status != models.AccountStatusActive {
+		log.Printf("access denied: account is %s", accountStatus.Status)
+		return false
+	}
 	log.Printf("access granted to user \"%s\"", login)
 	return true
 }
+
+/*
+Авторизация администратора платформы по логину и паролю профиля тенанта платформы
+
+:param login string: логин для авторизации администратора платформы
+:param password string: пароль для авторизации администратора платформы
+
+:return: true - если логин и пароль есть в тенанте платформы и профиль является администратором платформы, иначе - false
+*/
+func PlatformAdminsAuthorizer(login, password string) bool {
+	if !myProfilesDB.DB.IsPlatformAdmin(login) {
+		return false
+	}
+	return CommonUsersAuthorizer(myProfilesDB.DB.PlatformTenant(), login, password)
+}
+
+/*
+Авторизация клиента OAuth 2.0 по идентификатору и секрету
+
+:param clientID string: идентификатор для авторизации клиента
+:param secret string: секрет для авторизации клиента
+
+:return: true - если идентификатор и секрет есть в БД, иначе - false
+*/
+func ClientsAuthorizer(clientID, secret string) bool {
+	log.Println("attempt to authorize oauth client...")
+	// Получение секрета из БД
+	secretHashSalt, err := myProfilesDB.DB.GetClientSecretHashSalt(clientID)
+	if err != nil {
+		log.Println("access denied: no such oauth client")
+		return false
+	}
+	// Проверка секрета
+	err = bcrypt.CompareHashAndPassword([]byte(secretHashSalt), []byte(secret))
+	if err != nil {
+		log.Println("access denied: wrong oauth client secret")
+		return false
+	}
+	log.Printf("access granted to oauth client \"%s\"", clientID)
+	return true
+}
@@ -0,0 +1,362 @@
+package cluster
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// БД узла кластера, изменения которой фиксируются в журнале Raft
+type Store interface {
+	AttachCluster(clusterLog myProfilesDB.ClusterLog) raft.FSM // подключение БД к журналу Raft
+	CommitClusterSnapshot() error                              // фиксация всех данных БД новым лидером
+	ClusterSeeded() bool                                       // БД получила данные из журнала Raft
+}
+
+// Узел кластера: журнал Raft, к которому подключена БД узла
+type Node struct {
+	id           string
+	raft         *raft.Raft
+	store        Store
+	peers        []peerConfig
+	applyTimeout time.Duration
+	writable     int32         // 1 - узел является лидером, применил команды предыдущих лидеров и принимает изменения
+	closers      []io.Closer   // хранилища и транспорт Raft, закрываемые при остановке узла
+	done         chan struct{} // канал, закрываемый при остановке узла
+}
+
+// Ошибка: новый лидер не применил команды предыдущих лидеров или не зафиксировал данные своей БД
+var leaderNotReadyErr error = errors.New("new leader failed to catch up with the log or to commit its data")
+
+// Конфигурация кластера (заменяется конфигом функцией Configure)
+var config clusterConfig
+
+// Узел кластера экземпляра (nil - экземпляр не входит в кластер)
+var node *Node
+
+/*
+Чтение конфигурации кластера из конфига "../../configs/clusterConfig.json"
+
+:return: ошибка, если конфиг не удалось прочитать
+*/
+func Configure() error {
+	var err error
+	config, err = getClusterConfig()
+	if err != nil {
+		return err
+	}
+	if config.Enabled {
+		log.Printf("cluster configured: node \"%s\" of %d nodes, writes are forwarded to leader: %t", config.NodeID, len(config.Peers), config.ForwardWrites)
+	}
+	return nil
+}
+
+/*
+Проверка, является ли экземпляр узлом кластера
+
+:return: true - если кластер включен в конфиге
+*/
+func Enabled() bool {
+	return config.Enabled
+}
+
+/*
+Проверка, перенаправляются ли изменяющие запросы к узлу, не являющемуся лидером, лидеру кластера
+
+:return: true - если запросы перенаправляются, false - если отклоняются
+*/
+func ForwardWrites() bool {
+	return config.ForwardWrites
+}
+
+/*
+Запуск узла кластера экземпляра: журнал Raft и снимки хранятся в каталоге из конфига, узлы соединяются по TCP;
+узел с признаком bootstrap при первом запуске создает кластер из одного себя, а став лидером, добавляет в него остальные узлы из конфига
+
+:param store Store: БД экземпляра
+
+:return: ошибка, если каталог, хранилища или транспорт Raft не удалось создать
+*/
+func Start(store Store) error {
+	err := os.MkdirAll(config.DataDir, 0700)
+	if err != nil {
+		return err
+	}
+	logs, err := raftboltdb.NewBoltStore(filepath.Join(config.DataDir, "raft.db"))
+	if err != nil {
+		return err
+	}
+	snapshots, err := raft.NewFileSnapshotStore(config.DataDir, 2, log.Writer())
+	if err != nil {
+		logs.Close()
+		return err
+	}
+	transport, err := raft.NewTCPTransport(config.RaftAddr, nil, 3, 10*time.Second, log.Writer())
+	if err != nil {
+		logs.Close()
+		return err
+	}
+
+	raftConfig := raft.DefaultConfig()
+	node, err = newNode(config, raftConfig, store, transport, logs, logs, snapshots)
+	if err != nil {
+		transport.Close()
+		logs.Close()
+		return err
+	}
+	node.closers = append(node.closers, transport, logs)
+	return nil
+}
+
+/*
+Создание узла кластера
+
+:param nodeConfig clusterConfig: конфигурация узла
+:param raftConfig *raft.Config: настройки Raft (идентификатор узла и логгер задаются функцией)
+:param store Store: БД узла
+:param transport raft.Transport: транспорт Raft
+:param logs raft.LogStore: хранилище журнала Raft
+:param stable raft.StableStore: хранилище состояния Raft
+:param snapshots raft.SnapshotStore: хранилище снимков Raft
+
+:return: узел кластера или ошибка, если кластер не удалось создать или Raft не удалось запустить
+*/
+func newNode(nodeConfig clusterConfig, raftConfig *raft.Config, store Store, transport raft.Transport, logs raft.LogStore, stable raft.StableStore, snapshots raft.SnapshotStore) (*Node, error) {
+	raftConfig.LocalID = raft.ServerID(nodeConfig.NodeID)
+	raftConfig.Logger = hclog.New(&hclog.LoggerOptions{Name: "raft-" + nodeConfig.NodeID, Level: hclog.Warn, Output: log.Writer()})
+	n := &Node{
+		id:           nodeConfig.NodeID,
+		store:        store,
+		peers:        nodeConfig.Peers,
+		applyTimeout: time.Duration(nodeConfig.ApplyTimeout) * time.Second,
+		done:         make(chan struct{}),
+	}
+	fsm := store.AttachCluster(n)
+
+	// Создание кластера из одного узла при первом запуске узла с признаком bootstrap
+	hasState, err := raft.HasExistingState(logs, stable, snapshots)
+	if err != nil {
+		return nil, err
+	}
+	if !hasState && nodeConfig.Bootstrap {
+		configuration := raft.Configuration{Servers: []raft.Server{{Suffrage: raft.Voter, ID: raftConfig.LocalID, Address: transport.LocalAddr()}}}
+		err = raft.BootstrapCluster(raftConfig, logs, stable, snapshots, transport, configuration)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("cluster: node \"%s\" bootstrapped new cluster", n.id)
+	}
+
+	n.raft, err = raft.NewRaft(raftConfig, fsm, logs, stable, snapshots, transport)
+	if err != nil {
+		return nil, err
+	}
+	go n.watchLeadership()
+	return n, nil
+}
+
+/*
+Отслеживание лидерства узла (функция возвращает управление после остановки узла): новый лидер дожидается применения команд предыдущих лидеров,
+фиксирует все данные своей БД, после чего принимает изменения и добавляет в конфигурацию Raft узлы из конфига; если это не удалось, лидерство передается другому узлу
+*/
+func (n *Node) watchLeadership() {
+	for {
+		var isLeader bool
+		select {
+		case isLeader = <-n.raft.LeaderCh():
+		case <-n.done:
+			return
+		}
+		if !isLeader {
+			atomic.StoreInt32(&n.writable, 0)
+			log.Printf("cluster: node \"%s\" lost leadership", n.id)
+			continue
+		}
+		log.Printf("cluster: node \"%s\" acquired leadership", n.id)
+		err := n.raft.Barrier(n.applyTimeout).Error()
+		if err == nil {
+			err = n.store.CommitClusterSnapshot()
+		}
+		if err != nil {
+			// Лидер, который не может принимать изменения, передает лидерство другому узлу
+			log.Printf("cluster: %s: %s", leaderNotReadyErr.Error(), err.Error())
+			n.raft.LeadershipTransfer()
+			continue
+		}
+		atomic.StoreInt32(&n.writable, 1)
+		go n.addPeers()
+	}
+}
+
+/*
+Добавление в конфигурацию Raft узлов из конфига, которых в ней нет (выполняется лидером)
+*/
+func (n *Node) addPeers() {
+	future := n.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		log.Printf("cluster: fail to read configuration: %s", err.Error())
+		return
+	}
+	known := make(map[raft.ServerID]struct{})
+	for _, server := range future.Configuration().Servers {
+		known[server.ID] = struct{}{}
+	}
+	for _, peer := range n.peers {
+		if _, ok := known[raft.ServerID(peer.ID)]; ok {
+			continue
+		}
+		err := n.raft.AddVoter(raft.ServerID(peer.ID), raft.ServerAddress(peer.RaftAddr), 0, n.applyTimeout).Error()
+		if err != nil {
+			log.Printf("cluster: fail to add node \"%s\": %s", peer.ID, err.Error())
+			return
+		}
+		log.Printf("cluster: node \"%s\" added to cluster", peer.ID)
+	}
+}
+
+/*
+Проверка, принимает ли узел изменения
+
+:return: true - если узел является лидером и применил команды предыдущих лидеров
+*/
+func (n *Node) Writable() bool {
+	return atomic.LoadInt32(&n.writable) == 1
+}
+
+/*
+Фиксация команды в журнале Raft большинством узлов (вызывается БД под блокировкой БД)
+
+:param command []byte: команда
+
+:return: ошибка, если узел не является лидером, лидерство потеряно или команда не зафиксирована за время ожидания из конфига
+*/
+func (n *Node) Commit(command []byte) error {
+	future := n.raft.Apply(command, n.applyTimeout)
+	err := future.Error()
+	if err != nil {
+		return err
+	}
+	if err, ok := future.Response().(error); ok {
+		return err
+	}
+	return nil
+}
+
+/*
+Получить состояние узла кластера
+
+:return: состояние узла в Raft, лидер кластера, позиция в журнале Raft и узлы кластера
+*/
+func (n *Node) Status() models.ClusterStatus {
+	leaderAddr, leaderID := n.raft.LeaderWithID()
+	status := models.ClusterStatus{
+		NodeID:       n.id,
+		State:        n.raft.State().String(),
+		Writable:     n.Writable(),
+		Leader:       string(leaderID),
+		LastIndex:    n.raft.LastIndex(),
+		AppliedIndex: n.raft.AppliedIndex(),
+		Servers:      []models.ClusterServer{},
+	}
+	status.Term, _ = strconv.ParseUint(n.raft.Stats()["term"], 10, 64)
+	status.LeaderAPIURL = n.apiURL(string(leaderID))
+	status.Ready = n.store.ClusterSeeded() && leaderAddr != ""
+	future := n.raft.GetConfiguration()
+	if future.Error() == nil {
+		for _, server := range future.Configuration().Servers {
+			status.Servers = append(status.Servers, models.ClusterServer{
+				ID:       string(server.ID),
+				RaftAddr: string(server.Address),
+				APIURL:   n.apiURL(string(server.ID)),
+				Voter:    server.Suffrage == raft.Voter,
+			})
+		}
+	}
+	return status
+}
+
+/*
+Получить адрес API узла кластера из конфига
+
+:param id string: идентификатор узла
+
+:return: адрес API узла (пустая строка, если узла нет в конфиге)
+*/
+func (n *Node) apiURL(id string) string {
+	for _, peer := range n.peers {
+		if peer.ID == id {
+			return peer.APIURL
+		}
+	}
+	return ""
+}
+
+/*
+Остановка узла кластера: узел перестает принимать изменения, журнал Raft останавливается, хранилища и транспорт закрываются
+
+:return: ошибка остановки Raft
+*/
+func (n *Node) Shutdown() error {
+	atomic.StoreInt32(&n.writable, 0)
+	close(n.done)
+	err := n.raft.Shutdown().Error()
+	for _, closer := range n.closers {
+		closer.Close()
+	}
+	return err
+}
+
+/*
+Проверка, является ли узел экземпляра лидером кластера
+
+:return: true - если узел принимает изменения
+*/
+func IsLeader() bool {
+	return node != nil && node.Writable()
+}
+
+/*
+Получить адрес API лидера кластера для перенаправления изменяющих запросов
+
+:return: адрес API лидера (пустая строка, если лидер неизвестен или его адреса нет в конфиге)
+*/
+func LeaderAPIURL() string {
+	if node == nil {
+		return ""
+	}
+	_, leaderID := node.raft.LeaderWithID()
+	return node.apiURL(string(leaderID))
+}
+
+/*
+Проверка, готов ли узел экземпляра обслуживать запросы
+
+:return: true - если узел получил данные БД из журнала Raft
+*/
+func Ready() bool {
+	return node != nil && node.store.ClusterSeeded()
+}
+
+/*
+Получить состояние узла кластера экземпляра
+
+:return: состояние узла
+*/
+func Status() models.ClusterStatus {
+	if node == nil {
+		return models.ClusterStatus{NodeID: config.NodeID, State: raft.Shutdown.String(), Servers: []models.ClusterServer{}}
+	}
+	return node.Status()
+}
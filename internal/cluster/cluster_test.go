@@ -0,0 +1,269 @@
+package cluster
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Тенант, в котором тесты кластера создают профили
+const testTenant = "cluster-test"
+
+// Время ожидания выбора лидера и репликации в тестах
+const testTimeout = 10 * time.Second
+
+// БД узла кластера, к которой тест обращается напрямую
+type testStore interface {
+	Store
+	AddTenant(name string) error
+	GetTenant(name string) (*myProfilesDB.Tenant, error)
+}
+
+// Тесты выполняются из каталога пакета: конфиги читаются по путям "../../configs/*.json" из каталога конфигов репозитория; логи Raft не выводятся
+func TestMain(m *testing.M) {
+	log.SetOutput(ioutil.Discard)
+	os.Exit(m.Run())
+}
+
+/*
+Запуск кластера из узлов с временными БД и ожидание, пока все узлы получат данные лидера
+
+:param t *testing.T: тест
+:param nodesNumber int: количество узлов
+:param apiURLs []string: адреса API узлов (nil - адреса не заданы)
+
+:return: набор узлов (останавливается после теста), БД узлов и пути к файлам БД узлов
+*/
+func startTestCluster(t *testing.T, nodesNumber int, apiURLs []string) (*Harness, []testStore, []string) {
+	t.Helper()
+	dir := t.TempDir()
+	stores := make([]testStore, nodesNumber)
+	clusterStores := make([]Store, nodesNumber)
+	dumpPaths := make([]string, nodesNumber)
+	for i := range stores {
+		dumpPaths[i] = filepath.Join(dir, fmt.Sprintf("node%d.json", i+1))
+		db, err := myProfilesDB.OpenMyProfilesDB(dumpPaths[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		stores[i], clusterStores[i] = db, db
+	}
+	harness, err := StartHarness(clusterStores, apiURLs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(harness.Shutdown)
+	err = harness.WaitCaughtUp(testTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return harness, stores, dumpPaths
+}
+
+/*
+Добавление профиля в тестовый тенант
+
+:param store testStore: БД узла
+:param login string: логин профиля
+
+:return: ошибка, если профиль не удалось добавить
+*/
+func addTestProfile(store testStore, login string) error {
+	t, err := store.GetTenant(testTenant)
+	if err != nil {
+		return err
+	}
+	profileData := models.ProfileData{Login: login, FirstName: "Cluster", LastName: "Test"}
+	return t.AddProfile(login, profileData, "Passw0rd1", models.AccountStatusActive, "")
+}
+
+/*
+Добавление профиля в тестовый тенант на лидере кластера: запись повторяется, если лидерство сменилось после выбора узла
+(изменение, которое не удалось зафиксировать большинством узлов, отменяется, поэтому повтор безопасен)
+
+:param t *testing.T: тест
+:param harness *Harness: узлы кластера
+:param stores []testStore: БД узлов
+:param login string: логин профиля
+
+:return: номер узла, принявшего изменение
+*/
+func addOnLeader(t *testing.T, harness *Harness, stores []testStore, login string) int {
+	t.Helper()
+	deadline := time.Now().Add(testTimeout)
+	for {
+		leader, err := harness.WaitLeader(testTimeout)
+		if err == nil {
+			err = addTestProfile(stores[leader], login)
+		}
+		if err == nil {
+			return leader
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+/*
+Проверка, что профиль появляется в БД всех работающих узлов после применения ими журнала лидера (журнал Raft считает запись примененной
+до того, как конечный автомат узла применил ее к БД, поэтому БД узлов опрашиваются до истечения времени ожидания)
+
+:param t *testing.T: тест
+:param harness *Harness: узлы кластера
+:param stores []testStore: БД узлов
+:param login string: логин профиля
+*/
+func checkReplicated(t *testing.T, harness *Harness, stores []testStore, login string) {
+	t.Helper()
+	err := harness.WaitCaughtUp(testTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, store := range stores {
+		if harness.Stopped(i) {
+			continue
+		}
+		deadline := time.Now().Add(testTimeout)
+		for {
+			tenant, err := store.GetTenant(testTenant)
+			if err == nil {
+				_, _, err = tenant.GetProfileData(login)
+			}
+			if err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("node %d: %s", i+1, err.Error())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+// Первый узел становится лидером, его изменения реплицируются на все узлы, остальные узлы отклоняют изменения
+func TestLeaderElectionAndReplication(t *testing.T) {
+	harness, stores, _ := startTestCluster(t, 3, nil)
+	leader, err := harness.WaitLeader(testTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := harness.Nodes[leader].Status()
+	if len(status.Servers) != 3 || !status.Ready || !status.Writable || status.Leader != status.NodeID {
+		t.Fatalf("unexpected leader status %+v", status)
+	}
+
+	err = stores[leader].AddTenant(testTenant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = addTestProfile(stores[leader], "user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkReplicated(t, harness, stores, "user1")
+
+	follower := (leader + 1) % len(stores)
+	if addTestProfile(stores[follower], "rejected") == nil {
+		t.Fatalf("node %d accepted write while not being the leader", follower+1)
+	}
+	if status := harness.Nodes[follower].Status(); !status.Ready || status.Writable || status.Leader != harness.Nodes[leader].id {
+		t.Fatalf("unexpected follower status %+v", status)
+	}
+}
+
+// Кластер переживает потерю меньшинства узлов (в том числе лидера), а после потери большинства узлов лидер не выбирается и изменения отклоняются
+func TestMinorityLossSurvival(t *testing.T) {
+	harness, stores, _ := startTestCluster(t, 3, nil)
+	leader, _ := harness.WaitLeader(testTimeout)
+	err := stores[leader].AddTenant(testTenant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = addTestProfile(stores[leader], "user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkReplicated(t, harness, stores, "user1")
+
+	// Потеря лидера: оставшиеся узлы выбирают нового лидера, изменения которого реплицируются
+	harness.Stop(leader)
+	newLeader := addOnLeader(t, harness, stores, "user2")
+	checkReplicated(t, harness, stores, "user2")
+
+	// Потеря большинства узлов: лидер не выбирается, изменения отклоняются, данные остаются доступны для чтения
+	harness.Stop(newLeader)
+	survivor := 0
+	for harness.Stopped(survivor) {
+		survivor++
+	}
+	if _, err := harness.WaitLeader(2 * time.Second); err == nil {
+		t.Fatalf("leader is elected without majority of nodes")
+	}
+	if addTestProfile(stores[survivor], "rejected") == nil {
+		t.Fatalf("write is accepted without majority of nodes")
+	}
+	tenant, err := stores[survivor].GetTenant(testTenant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = tenant.GetProfileData("user2"); err != nil {
+		t.Fatalf("committed data is lost after loss of majority: %s", err.Error())
+	}
+	if status := harness.Nodes[survivor].Status(); status.Ready || status.Writable {
+		t.Fatalf("node without majority is ready: %+v", status)
+	}
+}
+
+// Изменение, зафиксированное в журнале Raft, остается на лидере, даже если лидер не смог переписать файл БД: данные лидера совпадают с данными остальных узлов,
+// а файл лидера перезаписывается следующим сохранением
+func TestCommittedChangeSurvivesLocalWriteFailure(t *testing.T) {
+	harness, stores, dumpPaths := startTestCluster(t, 3, nil)
+	leader, err := harness.WaitLeader(testTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = stores[leader].AddTenant(testTenant)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Файл БД лидера заменяется непустым каталогом, поэтому новый файл не удается переименовать в файл БД
+	err = os.Remove(dumpPaths[leader])
+	if err == nil {
+		err = os.MkdirAll(filepath.Join(dumpPaths[leader], "blocked"), 0700)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = addTestProfile(stores[leader], "user1")
+	if err != nil {
+		t.Fatalf("committed change is rejected after local write failure: %s", err.Error())
+	}
+	checkReplicated(t, harness, stores, "user1")
+
+	err = os.RemoveAll(dumpPaths[leader])
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = addTestProfile(stores[leader], "user2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump, err := ioutil.ReadFile(dumpPaths[leader])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(dump), `"user1"`) || !strings.Contains(string(dump), `"user2"`) {
+		t.Fatal("leader database dump is not rewritten with committed changes")
+	}
+}
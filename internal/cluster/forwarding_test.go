@@ -0,0 +1,132 @@
+package cluster_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ZotovSergey/authenticationservice/internal/cluster"
+	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/rest/handlers"
+	"github.com/ZotovSergey/authenticationservice/internal/testutil"
+)
+
+// API узла кластера в тесте: запоминает полученные запросы и отвечает идентификатором узла
+type nodeAPI struct {
+	server   *httptest.Server
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+/*
+Запуск API узла
+
+:param t *testing.T: тест
+:param id string: идентификатор узла, которым отвечает API
+
+:return: API узла (останавливается после теста)
+*/
+func newNodeAPI(t *testing.T, id string) *nodeAPI {
+	api := &nodeAPI{}
+	api.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		api.requests = append(api.requests, r)
+		api.mu.Unlock()
+		w.Write([]byte(id))
+	}))
+	t.Cleanup(api.server.Close)
+	return api
+}
+
+// Узел, не являющийся лидером, обслуживает чтение локально и перенаправляет изменяющие запросы лидеру кластера
+func TestWriteForwarding(t *testing.T) {
+	// Узлы кластера с API, отвечающими идентификаторами узлов
+	dir := t.TempDir()
+	stores := make([]cluster.Store, 3)
+	apis := make([]*nodeAPI, 3)
+	apiURLs := make([]string, 3)
+	for i := range stores {
+		db, err := myProfilesDB.OpenMyProfilesDB(filepath.Join(dir, fmt.Sprintf("node%d.json", i+1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stores[i] = db
+		apis[i] = newNodeAPI(t, fmt.Sprintf("node%d", i+1))
+		apiURLs[i] = apis[i].server.URL
+	}
+	harness, err := cluster.StartHarness(stores, apiURLs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer harness.Shutdown()
+	err = harness.WaitCaughtUp(10 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leader, err := harness.WaitLeader(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	followerIndex := (leader + 1) % 3
+
+	// БД экземпляра нужна middleware только для определения тенанта платформы
+	previousDB := myProfilesDB.DB
+	defer func() { myProfilesDB.DB = previousDB }()
+	myProfilesDB.DB, err = myProfilesDB.OpenMyProfilesDB(filepath.Join(dir, "api.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	harness.Use(t, followerIndex, true)
+
+	// Чтение обслуживается локально
+	if status, body := testutil.GuardedRequest(t, handlers.FollowerGuard, http.MethodGet, "/logins", nil); status != http.StatusOK || body != "local" {
+		t.Fatalf("read is not served locally: %d %s", status, body)
+	}
+	// Изменяющий запрос перенаправляется лидеру с пометкой перенаправленного запроса
+	status, body := testutil.GuardedRequest(t, handlers.FollowerGuard, http.MethodPost, "/profile?x=1", nil)
+	if status != http.StatusOK || body != fmt.Sprintf("node%d", leader+1) {
+		t.Fatalf("write is not forwarded to leader: %d %s", status, body)
+	}
+	apis[leader].mu.Lock()
+	forwarded := apis[leader].requests[len(apis[leader].requests)-1]
+	apis[leader].mu.Unlock()
+	if forwarded.Method != http.MethodPost || forwarded.URL.String() != "/profile?x=1" || forwarded.Header.Get("X-Forwarded-Write") == "" {
+		t.Fatalf("unexpected forwarded request %s %s %v", forwarded.Method, forwarded.URL, forwarded.Header)
+	}
+	// Запрос, уже перенаправленный другим узлом, повторно не перенаправляется
+	if status, _ := testutil.GuardedRequest(t, handlers.FollowerGuard, http.MethodPost, "/profile", map[string]string{"X-Forwarded-Write": "1"}); status != http.StatusServiceUnavailable {
+		t.Fatalf("forwarded write is forwarded again: %d", status)
+	}
+	// Без перенаправления изменяющие запросы отклоняются
+	harness.Use(t, followerIndex, false)
+	if status, _ := testutil.GuardedRequest(t, handlers.FollowerGuard, http.MethodPost, "/profile", nil); status != http.StatusServiceUnavailable {
+		t.Fatalf("write is accepted by follower without forwarding: %d", status)
+	}
+	// Лидер обслуживает изменяющие запросы локально
+	harness.Use(t, leader, true)
+	if status, body := testutil.GuardedRequest(t, handlers.FollowerGuard, http.MethodPost, "/profile", nil); status != http.StatusOK || body != "local" {
+		t.Fatalf("write is not served by leader locally: %d %s", status, body)
+	}
+
+	// После потери большинства узлов лидер неизвестен, изменяющие запросы отклоняются, чтение обслуживается локально
+	harness.Use(t, followerIndex, true)
+	for i := range stores {
+		if i != followerIndex {
+			harness.Stop(i)
+		}
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for cluster.LeaderAPIURL() != "" && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if status, _ := testutil.GuardedRequest(t, handlers.FollowerGuard, http.MethodPost, "/profile", nil); status != http.StatusServiceUnavailable {
+		t.Fatalf("write is accepted without leader: %d", status)
+	}
+	if status, body := testutil.GuardedRequest(t, handlers.FollowerGuard, http.MethodGet, "/logins", nil); status != http.StatusOK || body != "local" {
+		t.Fatalf("read is not served without leader: %d %s", status, body)
+	}
+}
@@ -0,0 +1,68 @@
+package cluster
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Структура узла кластера в конфиге
+type peerConfig struct {
+	ID       string `json:"id"`       // идентификатор узла
+	RaftAddr string `json:"raftAddr"` // адрес транспорта Raft узла
+	APIURL   string `json:"apiUrl"`   // адрес API узла, которому перенаправляются изменяющие запросы, когда узел является лидером
+}
+
+// Структура конфигурации кластера
+type clusterConfig struct {
+	Enabled       bool         `json:"enabled"`       // true - экземпляр является узлом кластера
+	NodeID        string       `json:"nodeId"`        // идентификатор узла (должен быть в списке узлов кластера)
+	RaftAddr      string       `json:"raftAddr"`      // адрес, на котором узел принимает соединения транспорта Raft
+	DataDir       string       `json:"dataDir"`       // каталог журнала и снимков Raft узла
+	Bootstrap     bool         `json:"bootstrap"`     // true - узел создает кластер при первом запуске, его данные БД становятся исходными данными кластера (задается одному узлу)
+	ForwardWrites bool         `json:"forwardWrites"` // true - изменяющие запросы к узлу, не являющемуся лидером, перенаправляются лидеру, false - отклоняются
+	ApplyTimeout  int64        `json:"applyTimeout"`  // время ожидания фиксации изменения большинством узлов в секундах
+	Peers         []peerConfig `json:"peers"`         // узлы кластера (лидер добавляет их в конфигурацию Raft)
+}
+
+/*
+Чтение конфигурации кластера из конфига "../../configs/clusterConfig.json"
+
+:return: конфигурация кластера или ошибка, если конфиг не удалось прочитать, узел не найден в списке узлов кластера, идентификаторы узлов повторяются
+или время ожидания не положительно
+*/
+func getClusterConfig() (clusterConfig, error) {
+	configFilePath := "../../configs/clusterConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return clusterConfig{}, errors.New("fail to read cluster config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var config clusterConfig
+	json.Unmarshal(byteValue, &config)
+	if !config.Enabled {
+		return config, nil
+	}
+	if config.ApplyTimeout <= 0 {
+		return clusterConfig{}, errors.New("apply timeout must be positive in cluster config " + configFilePath)
+	}
+	if config.DataDir == "" || config.RaftAddr == "" {
+		return clusterConfig{}, errors.New("data dir and raft address are required in cluster config " + configFilePath)
+	}
+	ids := make(map[string]struct{}, len(config.Peers))
+	for i, peer := range config.Peers {
+		if _, ok := ids[peer.ID]; ok || peer.ID == "" || peer.RaftAddr == "" {
+			return clusterConfig{}, errors.New("peers must have unique ids and raft addresses in cluster config " + configFilePath)
+		}
+		ids[peer.ID] = struct{}{}
+		config.Peers[i].APIURL = strings.TrimSuffix(peer.APIURL, "/")
+	}
+	if _, ok := ids[config.NodeID]; !ok {
+		return clusterConfig{}, errors.New("node id must be in peers list in cluster config " + configFilePath)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,169 @@
+package cluster
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+)
+
+// Набор узлов кластера в одном процессе для тестов: узлы соединены транспортом Raft в памяти, журналы и снимки Raft хранятся в памяти
+// (для проверки репликации и смены лидера без сети и отдельных процессов)
+type Harness struct {
+	Nodes      []*Node                // узлы кластера (первый узел создает кластер)
+	transports []*raft.InmemTransport // транспорты узлов
+	stopped    []bool                 // true - узел остановлен
+}
+
+// Ошибки набора узлов
+var noLeaderErr error = errors.New("cluster leader is not elected in time")
+var notCaughtUpErr error = errors.New("cluster nodes did not apply leader log in time")
+
+/*
+Запуск кластера из узлов в одном процессе с короткими таймаутами Raft: первый узел создает кластер и, став лидером, добавляет в него остальные узлы
+
+:param stores []Store: БД узлов (у каждого узла своя БД)
+:param apiURLs []string: адреса API узлов, которым перенаправляются изменяющие запросы (nil - адреса не заданы)
+
+:return: набор узлов или ошибка, если узел не удалось запустить
+*/
+func StartHarness(stores []Store, apiURLs []string) (*Harness, error) {
+	h := &Harness{stopped: make([]bool, len(stores))}
+	peers := make([]peerConfig, len(stores))
+	for i := range stores {
+		addr, transport := raft.NewInmemTransport("")
+		peers[i] = peerConfig{ID: "node" + strconv.Itoa(i+1), RaftAddr: string(addr)}
+		if apiURLs != nil {
+			peers[i].APIURL = apiURLs[i]
+		}
+		h.transports = append(h.transports, transport)
+	}
+	for _, transport := range h.transports {
+		for _, peer := range h.transports {
+			if peer != transport {
+				transport.Connect(peer.LocalAddr(), peer)
+			}
+		}
+	}
+
+	for i, store := range stores {
+		raftConfig := raft.DefaultConfig()
+		raftConfig.HeartbeatTimeout = 100 * time.Millisecond
+		raftConfig.ElectionTimeout = 100 * time.Millisecond
+		raftConfig.LeaderLeaseTimeout = 50 * time.Millisecond
+		raftConfig.CommitTimeout = 5 * time.Millisecond
+		nodeConfig := clusterConfig{Enabled: true, NodeID: peers[i].ID, Bootstrap: i == 0, ApplyTimeout: 5, Peers: peers}
+		logs := raft.NewInmemStore()
+		n, err := newNode(nodeConfig, raftConfig, store, h.transports[i], logs, logs, raft.NewInmemSnapshotStore())
+		if err != nil {
+			h.Shutdown()
+			return nil, err
+		}
+		h.Nodes = append(h.Nodes, n)
+	}
+	return h, nil
+}
+
+/*
+Ожидание лидера среди работающих узлов
+
+:param timeout time.Duration: время ожидания
+
+:return: номер узла-лидера, принимающего изменения, или ошибка, если лидер не выбран за время ожидания
+*/
+func (h *Harness) WaitLeader(timeout time.Duration) (int, error) {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		for i, n := range h.Nodes {
+			if !h.stopped[i] && n.Writable() {
+				return i, nil
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return 0, noLeaderErr
+}
+
+/*
+Ожидание, пока все работающие узлы войдут в конфигурацию Raft и применят все команды журнала лидера
+
+:param timeout time.Duration: время ожидания
+
+:return: ошибка, если лидера нет или узлы не догнали лидера за время ожидания
+*/
+func (h *Harness) WaitCaughtUp(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	leader, err := h.WaitLeader(timeout)
+	if err != nil {
+		return err
+	}
+	for time.Now().Before(deadline) {
+		status := h.Nodes[leader].Status()
+		caughtUp := len(status.Servers) == len(h.Nodes)
+		for i, n := range h.Nodes {
+			if !h.stopped[i] && n.raft.AppliedIndex() < status.LastIndex {
+				caughtUp = false
+			}
+		}
+		if caughtUp {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return notCaughtUpErr
+}
+
+/*
+Остановка узла (потеря узла): узел отключается от остальных узлов, его журнал Raft останавливается
+
+:param i int: номер узла
+*/
+func (h *Harness) Stop(i int) {
+	if h.stopped[i] {
+		return
+	}
+	h.stopped[i] = true
+	for j, transport := range h.transports {
+		if j != i {
+			transport.Disconnect(h.transports[i].LocalAddr())
+		}
+	}
+	h.transports[i].DisconnectAll()
+	h.Nodes[i].Shutdown()
+}
+
+/*
+Проверка, остановлен ли узел
+
+:param i int: номер узла
+
+:return: true - если узел остановлен
+*/
+func (h *Harness) Stopped(i int) bool {
+	return h.stopped[i]
+}
+
+/*
+Использование узла набора как узла кластера экземпляра до конца теста (для проверки функций пакета и middleware, работающих с узлом экземпляра)
+
+:param t *testing.T: тест
+:param i int: номер узла
+:param forwardWrites bool: перенаправлять ли изменяющие запросы лидеру
+*/
+func (h *Harness) Use(t *testing.T, i int, forwardWrites bool) {
+	previousConfig, previousNode := config, node
+	config = clusterConfig{Enabled: true, NodeID: h.Nodes[i].id, ForwardWrites: forwardWrites, Peers: h.Nodes[i].peers}
+	node = h.Nodes[i]
+	t.Cleanup(func() { config, node = previousConfig, previousNode })
+}
+
+/*
+Остановка всех узлов
+*/
+func (h *Harness) Shutdown() {
+	for i := range h.Nodes {
+		h.Stop(i)
+	}
+}
@@ -0,0 +1,269 @@
+package myProfilesDB
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Допустимые названия атрибутов
+var attributeNameRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// Формат значений атрибутов типа date
+const attributeDateLayout = "2006-01-02"
+
+/*
+Проверка корректности описания атрибута; пустая видимость заменяется на public
+
+:param definition *models.AttributeDefinition: описание атрибута
+
+:return: возвращается ошибка, если описание атрибута некорректно
+*/
+func validateAttributeDefinition(definition *models.AttributeDefinition) error {
+	if !attributeNameRegexp.MatchString(definition.Name) {
+		return fmt.Errorf("%w: name must start with a latin letter and contain only latin letters, digits and underscores", invalidAttributeDefinitionErr)
+	}
+	switch definition.Type {
+	case models.AttributeTypeString, models.AttributeTypeInt, models.AttributeTypeDate:
+	case models.AttributeTypeBool, models.AttributeTypeList:
+		if definition.Unique {
+			return fmt.Errorf("%w: type \"%s\" can not be unique", invalidAttributeDefinitionErr, definition.Type)
+		}
+	case models.AttributeTypeEnum:
+		if len(definition.EnumValues) == 0 {
+			return fmt.Errorf("%w: enum values are required for type \"enum\"", invalidAttributeDefinitionErr)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type \"%s\"", invalidAttributeDefinitionErr, definition.Type)
+	}
+	if definition.Regex != "" {
+		if _, err := regexp.Compile(definition.Regex); err != nil {
+			return fmt.Errorf("%w: %s", invalidAttributeDefinitionErr, err.Error())
+		}
+	}
+	if definition.Visibility == "" {
+		definition.Visibility = models.VisibilitySelf
+	}
+	if err := validateVisibility(definition.Visibility); err != nil {
+		return fmt.Errorf("%w: %s", invalidAttributeDefinitionErr, err.Error())
+	}
+	return nil
+}
+
+/*
+Проверка значения атрибута на соответствие описанию атрибута
+
+:param definition models.AttributeDefinition: описание атрибута
+:param value interface{}: значение атрибута (в виде, полученном из json)
+
+:return: возвращается ошибка, если значение не соответствует описанию
+*/
+func validateAttributeValue(definition models.AttributeDefinition, value interface{}) error {
+	var pattern *regexp.Regexp
+	if definition.Regex != "" {
+		pattern = regexp.MustCompile(definition.Regex)
+	}
+	switch definition.Type {
+	case models.AttributeTypeString:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%w \"%s\": string expected", invalidAttributeErr, definition.Name)
+		}
+		if pattern != nil && !pattern.MatchString(s) {
+			return fmt.Errorf("%w \"%s\": value does not match regex", invalidAttributeErr, definition.Name)
+		}
+	case models.AttributeTypeInt:
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return fmt.Errorf("%w \"%s\": integer expected", invalidAttributeErr, definition.Name)
+		}
+	case models.AttributeTypeBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%w \"%s\": boolean expected", invalidAttributeErr, definition.Name)
+		}
+	case models.AttributeTypeDate:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%w \"%s\": date string expected", invalidAttributeErr, definition.Name)
+		}
+		if _, err := time.Parse(attributeDateLayout, s); err != nil {
+			return fmt.Errorf("%w \"%s\": date must be in format YYYY-MM-DD", invalidAttributeErr, definition.Name)
+		}
+	case models.AttributeTypeEnum:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%w \"%s\": string expected", invalidAttributeErr, definition.Name)
+		}
+		for _, enumValue := range definition.EnumValues {
+			if s == enumValue {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w \"%s\": value is not one of enum values", invalidAttributeErr, definition.Name)
+	case models.AttributeTypeList:
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%w \"%s\": list of strings expected", invalidAttributeErr, definition.Name)
+		}
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("%w \"%s\": list of strings expected", invalidAttributeErr, definition.Name)
+			}
+			if pattern != nil && !pattern.MatchString(s) {
+				return fmt.Errorf("%w \"%s\": list item does not match regex", invalidAttributeErr, definition.Name)
+			}
+		}
+	}
+	return nil
+}
+
+/*
+Проверка атрибутов профиля по схеме атрибутов тенанта: неизвестные атрибуты запрещены, обязательные атрибуты должны быть заданы,
+значения уникальных атрибутов не должны совпадать со значениями других профилей; атрибуты со значением null удаляются
+
+:param id string: идентификатор профиля, которому принадлежат атрибуты (профиль не сравнивается сам с собой при проверке уникальности)
+:param attributes map[string]interface{}: атрибуты профиля
+
+:return: возвращается ошибка, если атрибуты не соответствуют схеме
+*/
+func (t *Tenant) validateAttributes(id string, attributes map[string]interface{}) error {
+	for name, value := range attributes {
+		if value == nil {
+			delete(attributes, name)
+			continue
+		}
+		definition, ok := t.attributesSchemaTab[name]
+		if !ok {
+			return fmt.Errorf("%w \"%s\"", unknownAttributeErr, name)
+		}
+		if err := validateAttributeValue(definition, value); err != nil {
+			return err
+		}
+	}
+	for name, definition := range t.attributesSchemaTab {
+		value, ok := attributes[name]
+		if !ok {
+			if definition.Required {
+				return fmt.Errorf("%w \"%s\"", requiredAttributeErr, name)
+			}
+			continue
+		}
+		if definition.Unique {
+			for otherID, otherProfileData := range t.profilesDataTab {
+				if otherID != id && reflect.DeepEqual(otherProfileData.Attributes[name], value) {
+					return fmt.Errorf("%w \"%s\"", notUniqueAttributeErr, name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+/*
+Получить схему атрибутов тенанта
+
+:return: список описаний атрибутов, отсортированный по названию
+*/
+func (t *Tenant) GetAttributesSchema() []models.AttributeDefinition {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	schema := make([]models.AttributeDefinition, 0, len(t.attributesSchemaTab))
+	for _, definition := range t.attributesSchemaTab {
+		schema = append(schema, definition)
+	}
+	sort.Slice(schema, func(i, j int) bool { return schema[i].Name < schema[j].Name })
+	return schema
+}
+
+/*
+Добавление или замена описания атрибута в схеме атрибутов тенанта; новое описание должно выполняться для всех существующих профилей
+
+:param definition models.AttributeDefinition: описание атрибута
+
+:return: возвращается ошибка, если описание некорректно, одной из групп видимости не существует, существующие профили ему не соответствуют или базу данных не удалось сохранить
+*/
+func (t *Tenant) SetAttributeDefinition(definition models.AttributeDefinition) error {
+	return t.Update(func(tx *Tx) error {
+		// Проверка описания
+		err := validateAttributeDefinition(&definition)
+		if err != nil {
+			return err
+		}
+		err = t.validateVisibleToGroups(definition.VisibleToGroups)
+		if err != nil {
+			return err
+		}
+
+		// Проверка существующих профилей по новому описанию
+		seenValues := make([]interface{}, 0, len(t.profilesDataTab))
+		for _, profileData := range t.profilesDataTab {
+			value, ok := profileData.Attributes[definition.Name]
+			if !ok {
+				if definition.Required {
+					return fmt.Errorf("%w: existing profiles miss required attribute \"%s\"", invalidAttributeDefinitionErr, definition.Name)
+				}
+				continue
+			}
+			if err := validateAttributeValue(definition, value); err != nil {
+				return fmt.Errorf("%w: existing profiles do not match it: %s", invalidAttributeDefinitionErr, err.Error())
+			}
+			if definition.Unique {
+				for _, seenValue := range seenValues {
+					if reflect.DeepEqual(seenValue, value) {
+						return fmt.Errorf("%w: existing profiles have equal values of attribute \"%s\"", invalidAttributeDefinitionErr, definition.Name)
+					}
+				}
+				seenValues = append(seenValues, value)
+			}
+		}
+
+		// Запись описания в схему
+		setEntry(t.db.undo, t.attributesSchemaTab, definition.Name, definition)
+
+		return nil
+	})
+}
+
+/*
+Удаление описания атрибута из схемы атрибутов тенанта вместе со значениями атрибута во всех профилях
+
+:param name string: название атрибута
+:param author string: логин пользователя, удаляющего атрибут (изменения профилей записываются в их истории)
+
+:return: возвращается ошибка, если атрибута нет в схеме или базу данных не удалось сохранить
+*/
+func (t *Tenant) RemoveAttributeDefinition(name string, author string) error {
+	return t.Update(func(tx *Tx) error {
+		// Проверка наличия атрибута в схеме
+		_, ok := t.attributesSchemaTab[name]
+		if !ok {
+			return fmt.Errorf("%w \"%s\"", unknownAttributeErr, name)
+		}
+
+		// Удаление атрибута из схемы и из профилей
+		deleteEntry(t.db.undo, t.attributesSchemaTab, name)
+		for id, profileData := range t.profilesDataTab {
+			if _, ok := profileData.Attributes[name]; ok {
+				// Атрибуты профиля не изменяются на месте: профиль получает новую таблицу атрибутов
+				attributes := make(map[string]interface{}, len(profileData.Attributes))
+				for attributeName, value := range profileData.Attributes {
+					if attributeName != name {
+						attributes[attributeName] = value
+					}
+				}
+				profileData.Attributes = attributes
+				setEntry(t.db.undo, t.profilesDataTab, id, profileData)
+				t.recordProfileHistory(id, author, models.HistoryActionSchema, t.nextProfileVersion(id))
+			}
+		}
+
+		return nil
+	})
+}
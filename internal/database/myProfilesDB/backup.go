@@ -0,0 +1,196 @@
+package myProfilesDB
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Шаблон имен файлов резервных копий, создаваемых по расписанию (имена сортируются по времени создания)
+const (
+	backupFilePrefix = "db-"
+	backupFileSuffix = ".bak"
+	backupTimeLayout = "20060102-150405"
+)
+
+/*
+Создание резервной копии БД: согласованный снимок всех данных (снимок составляется под блокировкой и не содержит частично выполненных изменений);
+если заданы мастер-ключи, копия шифруется так же, как файл базы данных
+
+:return: содержимое резервной копии в формате файла базы данных или ошибка, если копию не удалось зашифровать
+*/
+func (db *myProfilesDB) Backup() ([]byte, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.keys == nil {
+		return db.fileData(), nil
+	}
+	return db.keys.encrypt(db.fileData())
+}
+
+/*
+Восстановление БД из резервной копии без остановки сервиса: копия расшифровывается и проверяется, после чего все данные БД атомарно заменяются данными копии
+и сохраняются в файл базы данных; если БД не удалось сохранить, возвращаются прежние данные. Журналы изменений профилей восстановленных тенантов очищаются,
+а номер последнего события становится больше прежнего: номера событий не уменьшаются, и клиенты потока событий получают статус 410
+
+:param data []byte: содержимое резервной копии (зашифрованное или нет)
+
+:return: ошибка InvalidBackupErr, если копия повреждена или изменена, зашифрована неизвестным ключом, не является файлом базы данных, в ней нет тенанта платформы
+или администраторов платформы; ошибка, если базу данных не удалось сохранить (данные БД при этом не меняются)
+*/
+func (db *myProfilesDB) Restore(data []byte) error {
+	restored, err := db.parseBackup(data)
+	if err != nil {
+		return err
+	}
+
+	// Атомарная замена данных БД данными резервной копии
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	previous := &myProfilesDB{
+		tenantsTab:        db.tenantsTab,
+		platformAdminsTab: db.platformAdminsTab,
+		clientsDataTab:    db.clientsDataTab,
+		clientsSecretsTab: db.clientsSecretsTab,
+		tokensTab:         db.tokensTab,
+	}
+	for name, t := range restored.tenantsTab {
+		if previousTenant, ok := db.tenantsTab[name]; ok {
+			t.resetChangeLog(previousTenant.lastChangeSeq)
+		}
+	}
+	db.replaceData(restored)
+
+	// Сохранение данных в файл, при ошибке - возврат прежних данных
+	err = db.Dump()
+	if err != nil {
+		db.replaceData(previous)
+		return err
+	}
+	db.wakeWebhookJob()
+	return nil
+}
+
+/*
+Построение и проверка БД из резервной копии (без блокировки: копия не связана с используемой БД)
+
+:param data []byte: содержимое резервной копии (зашифрованное или нет)
+
+:return: указатель на БД с данными копии или ошибка InvalidBackupErr, если копия повреждена или изменена, зашифрована неизвестным ключом, не является файлом базы данных,
+в ней нет тенанта платформы или администраторов платформы
+*/
+func (db *myProfilesDB) parseBackup(data []byte) (*myProfilesDB, error) {
+	// Расшифровка резервной копии
+	if isEncryptedDump(data) {
+		if db.keys == nil {
+			return nil, fmt.Errorf("%w: %s", InvalidBackupErr, encryptedDumpWithoutKeyErr.Error())
+		}
+		var err error
+		data, _, err = db.keys.decrypt(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", InvalidBackupErr, err.Error())
+		}
+	}
+
+	// Построение БД из данных копии и проверка тенанта и администраторов платформы
+	restored := &myProfilesDB{
+		tenantsTab:        make(map[string]*Tenant),
+		platformAdminsTab: make(map[string]struct{}),
+		clientsDataTab:    make(map[string]models.OAuthClientData),
+		clientsSecretsTab: make(map[string]string),
+		tokensTab:         make(map[string]models.OAuthTokenData),
+		platformTenant:    db.platformTenant,
+	}
+	_, err := restored.loadFileData(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", InvalidBackupErr, err.Error())
+	}
+	if _, ok := restored.tenantsTab[db.platformTenant]; !ok {
+		return nil, fmt.Errorf("%w: no platform tenant \"%s\"", InvalidBackupErr, db.platformTenant)
+	}
+	if len(restored.platformAdminsTab) == 0 {
+		return nil, fmt.Errorf("%w: no platform admins", InvalidBackupErr)
+	}
+	return restored, nil
+}
+
+/*
+Замена всех данных БД данными другого экземпляра БД (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются)
+
+:param restored *myProfilesDB: экземпляр БД с новыми данными
+*/
+func (db *myProfilesDB) replaceData(restored *myProfilesDB) {
+	for _, t := range restored.tenantsTab {
+		t.db = db
+	}
+	db.tenantsTab = restored.tenantsTab
+	db.platformAdminsTab = restored.platformAdminsTab
+	db.clientsDataTab = restored.clientsDataTab
+	db.clientsSecretsTab = restored.clientsSecretsTab
+	db.tokensTab = restored.tokensTab
+	db.notifyChanges()
+}
+
+/*
+Запись резервной копии БД в каталог резервных копий из конфига; старые копии сверх количества из конфига удаляются
+
+:return: путь к файлу резервной копии или ошибка, если копию не удалось создать или записать
+*/
+func (db *myProfilesDB) WriteBackup() (string, error) {
+	data, err := db.Backup()
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(db.backupDir, 0700)
+	if err != nil {
+		return "", err
+	}
+	backupPath := filepath.Join(db.backupDir, backupFilePrefix+time.Now().UTC().Format(backupTimeLayout)+backupFileSuffix)
+	err = writeDumpFile(backupPath, data, nil)
+	if err != nil {
+		return "", err
+	}
+
+	// Удаление старых резервных копий
+	backupPaths, err := filepath.Glob(filepath.Join(db.backupDir, backupFilePrefix+"*"+backupFileSuffix))
+	if err != nil {
+		return backupPath, err
+	}
+	sort.Strings(backupPaths)
+	for len(backupPaths) > db.backupRetention {
+		err = os.Remove(backupPaths[0])
+		if err != nil {
+			return backupPath, err
+		}
+		backupPaths = backupPaths[1:]
+	}
+	return backupPath, nil
+}
+
+/*
+Фоновое резервное копирование: резервные копии записываются с периодом, заданным в конфиге (если период равен 0, копии по расписанию не создаются
+и функция сразу возвращает управление, иначе функция не возвращает управление)
+*/
+func (db *myProfilesDB) RunBackupJob() {
+	if db.backupInterval <= 0 {
+		log.Println("scheduled backups are disabled")
+		return
+	}
+	ticker := time.NewTicker(time.Duration(db.backupInterval) * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		backupPath, err := db.WriteBackup()
+		if err != nil {
+			log.Printf("scheduled backup error: %s", err.Error())
+			continue
+		}
+		log.Printf("scheduled backup written to %s", backupPath)
+	}
+}
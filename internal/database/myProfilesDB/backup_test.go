@@ -0,0 +1,85 @@
+package myProfilesDB
+
+import (
+	"errors"
+	"testing"
+)
+
+// Если БД после восстановления не удалось сохранить, данные БД не меняются
+func TestRestoreRollback(t *testing.T) {
+	db, platform := openTestDB(t)
+	addTestProfile(t, platform, "alice")
+	backup, err := db.Backup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addTestProfile(t, platform, "bob")
+
+	db.mu.Lock()
+	db.follower = true // сохранение БД ведомого экземпляра отклоняется
+	db.mu.Unlock()
+	err = db.Restore(backup)
+	db.mu.Lock()
+	db.follower = false
+	db.mu.Unlock()
+	if err == nil {
+		t.Fatal("restore succeeded without saving database")
+	}
+
+	current, err := db.GetTenant(db.PlatformTenant())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current != platform {
+		t.Fatal("platform tenant is replaced by failed restore")
+	}
+	if _, _, err = current.GetProfileData("bob"); err != nil {
+		t.Fatalf("profile created after backup is lost: %s", err)
+	}
+}
+
+// После восстановления номера событий журнала изменений не уменьшаются, а события, полученные клиентами до восстановления, считаются удаленными
+func TestRestoreResetsChangeFeed(t *testing.T) {
+	db, platform := openTestDB(t)
+	addTestProfile(t, platform, "alice")
+	backup, err := db.Backup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addTestProfile(t, platform, "bob")
+	addTestProfile(t, platform, "carol")
+	_, lastSeq, changed, err := platform.WatchChanges(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.Restore(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+	default:
+		t.Fatal("watchers are not notified about restore")
+	}
+	restored, err := db.GetTenant(db.PlatformTenant())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = restored.GetProfileData("bob"); err == nil {
+		t.Fatal("profile created after backup is kept after restore")
+	}
+	if _, _, _, err = restored.WatchChanges(lastSeq); !errors.Is(err, ChangeLogTruncatedErr) {
+		t.Fatalf("events received before restore are not truncated: %v", err)
+	}
+
+	// Новые события получают номера больше номеров событий до восстановления
+	addTestProfile(t, restored, "dave")
+	events, _, _, err := restored.WatchChanges(lastSeq + 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Seq <= lastSeq || events[0].Login != "dave" {
+		t.Fatalf("unexpected events after restore: %+v", events)
+	}
+}
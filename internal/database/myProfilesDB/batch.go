@@ -0,0 +1,150 @@
+package myProfilesDB
+
+import (
+	"fmt"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Выполнение пакета операций над тенантом по принципу "все или ничего": операции выполняются по порядку в одной транзакции, данные сохраняются в файл один раз;
+если одна из операций не выполнена, транзакция отменяется вместе со всеми выполненными операциями. Письма, отправляемые операциями, откладываются до фиксации пакета
+
+:param operations []models.BatchOperation: операции пакета
+:param keepAdmin string: логин профиля, который должен остаться администратором тенанта после пакета, если был им до пакета (пустая строка - не проверяется)
+:param author string: логин пользователя, выполняющего пакет (записывается в историю профилей)
+
+:return: результат пакета (отмененный пакет - тоже результат) или ошибка, если базу данных не удалось сохранить (пакет при этом отменяется)
+*/
+func (t *Tenant) Batch(operations []models.BatchOperation, keepAdmin string, author string) (models.BatchResult, error) {
+	result := models.BatchResult{Results: make([]models.BatchOperationResult, len(operations))}
+	for i, operation := range operations {
+		result.Results[i] = models.BatchOperationResult{Index: i, Op: operation.Op, Login: operation.Login, Status: models.BatchStatusSkipped}
+	}
+
+	// Шифрование паролей (до транзакции)
+	passwordHashes := make([]string, len(operations))
+	passwordErrs := make([]error, len(operations))
+	runParallel(len(operations), func(i int) {
+		if operations[i].Password != "" {
+			passwordHashes[i], passwordErrs[i] = HashPassword(operations[i].Password)
+		}
+	})
+	for i, err := range passwordErrs {
+		if err != nil {
+			return failBatch(result, 0, i, err), nil
+		}
+	}
+
+	// Выполнение операций в одной транзакции (при ошибке одной из операций транзакция отменяется, отложенные письма не отправляются)
+	executed, failed := 0, -1 // количество выполненных операций и номер невыполненной операции
+	applied := false          // true - все операции выполнены, транзакция фиксируется
+	err := t.Update(func(tx *Tx) error {
+		wasAdmin := keepAdmin != "" && tx.IsAdmin(keepAdmin)
+		for i, operation := range operations {
+			id, version, err := tx.applyBatchOperation(operation, passwordHashes[i], author)
+			if err != nil {
+				failed = i
+				return err
+			}
+			executed = i + 1
+			result.Results[i].Status = models.BatchStatusOK
+			result.Results[i].ID = id
+			result.Results[i].Version = version
+		}
+		if wasAdmin && !tx.IsAdmin(keepAdmin) {
+			return batchRemovesOwnAdminRightsErr
+		}
+		applied = true
+		return nil
+	})
+	if err != nil {
+		result = failBatch(result, executed, failed, err)
+		if applied {
+			return result, err
+		}
+		return result, nil
+	}
+	result.Committed = true
+	return result, nil
+}
+
+/*
+Отмена пакета операций: выполненные операции отмечаются отмененными, операция failed - невыполненной, остальные - невыполнявшимися
+
+:param result models.BatchResult: результат пакета
+:param executed int: количество выполненных операций
+:param failed int: номер невыполненной операции (-1 - пакет отменен после выполнения всех операций)
+:param err error: причина отмены пакета
+
+:return: результат отмененного пакета
+*/
+func failBatch(result models.BatchResult, executed int, failed int, err error) models.BatchResult {
+	for i := range result.Results {
+		switch {
+		case i < executed:
+			result.Results[i].Status = models.BatchStatusRolledBack
+			result.Results[i].ID = ""
+			result.Results[i].Version = 0
+		case i == failed:
+			result.Results[i].Status = models.BatchStatusFailed
+			result.Results[i].Error = err.Error()
+		}
+	}
+	result.Committed = false
+	if failed >= 0 {
+		result.Error = fmt.Sprintf("operation %d (%s) failed: %s", failed, result.Results[failed].Op, err.Error())
+	} else {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+/*
+Выполнение операции пакета в транзакции
+
+:param operation models.BatchOperation: операция
+:param passwordHashSalt string: зашифрованный пароль операции (пустая строка - пароль не задан)
+:param author string: логин пользователя, выполняющего пакет
+
+:return: идентификатор созданного профиля и новая версия созданного или измененного профиля или ошибка, если операция неизвестна или не выполнена
+*/
+func (tx *Tx) applyBatchOperation(operation models.BatchOperation, passwordHashSalt string, author string) (string, int64, error) {
+	profileData := models.ProfileData{FirstName: operation.FirstName, LastName: operation.LastName, Email: operation.Email, Attributes: operation.Attributes}
+	switch operation.Op {
+	case models.BatchOpCreateProfile:
+		if operation.Login == "" {
+			return "", 0, emptyLoginErr
+		}
+		id, err := tx.AddProfile(operation.Login, profileData, passwordHashSalt, operation.Status, author)
+		if err != nil {
+			return "", 0, err
+		}
+		_, version, err := tx.GetProfileData(operation.Login)
+		return id, version, err
+	case models.BatchOpEditProfile:
+		currentProfileData, _, err := tx.GetProfileData(operation.Login)
+		if err != nil {
+			return "", 0, err
+		}
+		version, err := tx.EditProfile(operation.Login, mergeProfileData(currentProfileData, profileData), operation.Version, author)
+		return "", version, err
+	case models.BatchOpSetPassword:
+		return "", 0, tx.ChangePassword(operation.Login, passwordHashSalt)
+	case models.BatchOpRemoveProfile:
+		return "", 0, tx.RemoveProfile(operation.Login, operation.Version, operation.Hard, author)
+	case models.BatchOpAddAdmin:
+		return "", 0, tx.AddAdmin(operation.Login)
+	case models.BatchOpDropAdmin:
+		return "", 0, tx.DropAdmin(operation.Login)
+	case models.BatchOpAddGroupMember:
+		return "", 0, tx.AddGroupMember(operation.Group, operation.Login)
+	case models.BatchOpRemoveGroupMember:
+		return "", 0, tx.RemoveGroupMember(operation.Group, operation.Login)
+	case models.BatchOpActivateAccount:
+		return "", 0, tx.ActivateAccount(operation.Login, author)
+	case models.BatchOpSuspendAccount:
+		return "", 0, tx.SuspendAccount(operation.Login, operation.Reason, author)
+	}
+	return "", 0, fmt.Errorf("%w \"%s\"", unknownBatchOperationErr, operation.Op)
+}
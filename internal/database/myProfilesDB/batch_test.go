@@ -0,0 +1,125 @@
+package myProfilesDB
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Проверка результата отмененного пакета: операции до failed отменены, операция failed не выполнена, остальные не выполнялись
+
+:param t *testing.T: тест
+:param result models.BatchResult: результат пакета
+:param failed int: номер невыполненной операции (-1 - пакет отменен после выполнения всех операций)
+*/
+func checkRolledBackBatch(t *testing.T, result models.BatchResult, failed int) {
+	t.Helper()
+	if result.Committed || result.Error == "" {
+		t.Fatalf("batch is not rolled back: %+v", result)
+	}
+	for i, operationResult := range result.Results {
+		expected := models.BatchStatusRolledBack
+		switch {
+		case failed >= 0 && i == failed:
+			expected = models.BatchStatusFailed
+		case failed >= 0 && i > failed:
+			expected = models.BatchStatusSkipped
+		}
+		if operationResult.Status != expected || operationResult.ID != "" || operationResult.Version != 0 {
+			t.Fatalf("operation %d: expected status %s, got %+v", i, expected, operationResult)
+		}
+	}
+}
+
+// Операция пакета, не выполненная в середине пакета, отменяет все выполненные операции: данные БД, файл базы данных и журнал изменений БД не меняются
+func TestBatchRollbackOnFailedOperation(t *testing.T) {
+	db, platform := openTestDB(t)
+	fillTestTenant(t, platform, newWebhookReceiver(t, http.StatusOK))
+	before := dbState(t, db)
+	fileBefore, err := ioutil.ReadFile(db.dumpFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	epoch, seq := db.ReplicationPosition()
+
+	operations := []models.BatchOperation{
+		{Op: models.BatchOpCreateProfile, Login: "erin", FirstName: "Erin", LastName: "User", Password: "Passw0rd!e"},
+		{Op: models.BatchOpEditProfile, Login: "alice", FirstName: "Alicia"},
+		{Op: models.BatchOpAddAdmin, Login: "erin"},
+		{Op: models.BatchOpAddGroupMember, Login: "bob", Group: "staff"},
+		{Op: models.BatchOpSuspendAccount, Login: "alice", Reason: "test"},
+		{Op: models.BatchOpRemoveProfile, Login: "nobody"},
+		{Op: models.BatchOpSetPassword, Login: "bob", Password: "Passw0rd!b"},
+	}
+	result, err := platform.Batch(operations, "admin", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRolledBackBatch(t, result, 5)
+	if result.Results[5].Error == "" {
+		t.Fatalf("error of failed operation is not returned: %+v", result.Results[5])
+	}
+
+	if after := dbState(t, db); after != before {
+		t.Fatalf("database is changed by rolled back batch:\nbefore: %s\nafter:  %s", before, after)
+	}
+	fileAfter, err := ioutil.ReadFile(db.dumpFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(fileAfter, fileBefore) {
+		t.Fatal("database dump is rewritten by rolled back batch")
+	}
+	if epochAfter, seqAfter := db.ReplicationPosition(); epochAfter != epoch || seqAfter != seq {
+		t.Fatalf("replication log is changed by rolled back batch: %d -> %d", seq, seqAfter)
+	}
+
+	// Тот же пакет без невыполнимой операции фиксируется
+	operations = append(operations[:5], operations[6])
+	result, err = platform.Batch(operations, "admin", "admin")
+	if err != nil || !result.Committed {
+		t.Fatalf("batch is not committed: %v %+v", err, result)
+	}
+	if result.Results[0].ID == "" || !platform.IsAdmin("erin") {
+		t.Fatalf("batch operations are not applied: %+v", result)
+	}
+}
+
+// Пакет, после которого пользователь, выполняющий пакет, теряет права администратора, отменяется
+func TestBatchRemovesOwnAdminRights(t *testing.T) {
+	db, platform := openTestDB(t)
+	addTestProfile(t, platform, "bob")
+	for _, err := range []error{platform.AddAdmin("bob"), platform.AddGroup("admins"), platform.AddAdminGroup("admins")} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := dbState(t, db)
+
+	operations := []models.BatchOperation{
+		{Op: models.BatchOpAddGroupMember, Login: "bob", Group: "admins"},
+		{Op: models.BatchOpDropAdmin, Login: "bob"},
+		{Op: models.BatchOpRemoveGroupMember, Login: "bob", Group: "admins"},
+	}
+	result, err := platform.Batch(operations, "bob", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRolledBackBatch(t, result, -1)
+	if result.Error != batchRemovesOwnAdminRightsErr.Error() {
+		t.Fatalf("unexpected batch error: %s", result.Error)
+	}
+	if after := dbState(t, db); after != before {
+		t.Fatalf("database is changed by rolled back batch:\nbefore: %s\nafter:  %s", before, after)
+	}
+
+	// Права администратора, сохраненные через группу администраторов, не отменяют пакет
+	result, err = platform.Batch(operations[:2], "bob", "bob")
+	if err != nil || !result.Committed {
+		t.Fatalf("batch keeping admin rights is not committed: %v %+v", err, result)
+	}
+}
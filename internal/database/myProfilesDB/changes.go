@@ -0,0 +1,85 @@
+package myProfilesDB
+
+import (
+	"time"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Запись события в журнал изменений профилей тенанта (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются);
+журнал хранит не больше db.changeLogSize последних событий. Изменения журнала записываются в журнал отмены транзакции,
+поэтому события отмененной транзакции удаляются из журнала вместе с ее изменениями
+
+:param eventType string: тип события
+:param id string: идентификатор профиля
+:param version int64: версия профиля после изменения (0 - событие не меняет данные профиля)
+:param author string: логин пользователя, выполнившего изменение
+*/
+func (t *Tenant) recordChange(eventType string, id string, version int64, author string) {
+	setValue(t.db.undo, &t.lastChangeSeq, t.lastChangeSeq+1)
+	setValue(t.db.undo, &t.changeLog, append(t.changeLog, models.ChangeEvent{
+		Seq:       t.lastChangeSeq,
+		Type:      eventType,
+		ProfileID: id,
+		Login:     t.profilesDataTab[id].Login,
+		Version:   version,
+		Author:    author,
+		At:        time.Now().Unix(),
+	}))
+	t.enqueueWebhookDeliveries(t.changeLog[len(t.changeLog)-1])
+	if extra := len(t.changeLog) - t.db.changeLogSize; extra > 0 {
+		setValue(t.db.undo, &t.changeLog, append([]models.ChangeEvent(nil), t.changeLog[extra:]...))
+	}
+}
+
+/*
+Получить события журнала изменений профилей тенанта после заданного номера и канал уведомления о новых событиях
+
+:param since int64: номер последнего полученного события (отрицательное значение - прошлые события не нужны, только новые)
+
+:return: события с номерами больше since в порядке номеров, номер последнего события тенанта и канал, который закрывается при фиксации следующих изменений;
+возвращается ошибка, если события после since уже удалены из журнала или since больше номера последнего события (журнал заменен при восстановлении из резервной копии)
+*/
+func (t *Tenant) WatchChanges(since int64) ([]models.ChangeEvent, int64, <-chan struct{}, error) {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	changed := t.db.changed
+	if since < 0 {
+		return nil, t.lastChangeSeq, changed, nil
+	}
+	if since > t.lastChangeSeq {
+		return nil, t.lastChangeSeq, changed, ChangeLogTruncatedErr
+	}
+	firstSeq := t.lastChangeSeq - int64(len(t.changeLog)) + 1
+	if since < firstSeq-1 {
+		return nil, t.lastChangeSeq, changed, ChangeLogTruncatedErr
+	}
+	events := make([]models.ChangeEvent, 0, t.lastChangeSeq-since)
+	events = append(events, t.changeLog[since-firstSeq+1:]...)
+	return events, t.lastChangeSeq, changed, nil
+}
+
+/*
+Очистка журнала изменений профилей тенанта, данные которого заменены данными резервной копии (вызывается методами БД под блокировкой db.mu):
+номер последнего события становится больше номеров событий прежних данных тенанта и журнала копии, поэтому события после номеров, полученных
+клиентами до восстановления, считаются удаленными из журнала (WatchChanges возвращает ChangeLogTruncatedErr)
+
+:param previousSeq int64: номер последнего события тенанта до восстановления
+*/
+func (t *Tenant) resetChangeLog(previousSeq int64) {
+	if previousSeq > t.lastChangeSeq {
+		t.lastChangeSeq = previousSeq
+	}
+	t.lastChangeSeq++
+	t.changeLog = nil
+}
+
+/*
+Уведомление ожидающих событий о фиксации изменений профилей (вызывается методами БД под блокировкой db.mu)
+*/
+func (db *myProfilesDB) notifyChanges() {
+	close(db.changed)
+	db.changed = make(chan struct{})
+}
@@ -0,0 +1,308 @@
+package myProfilesDB
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"log"
+	"sync"
+
+	"github.com/hashicorp/raft"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Журнал Raft, через который изменения БД фиксируются большинством узлов кластера
+type ClusterLog interface {
+	Writable() bool              // true - узел является лидером кластера и принимает изменения
+	Commit(command []byte) error // фиксация команды в журнале Raft (возвращает управление после применения команды на узле)
+}
+
+// Структура команды журнала Raft: запись журнала изменений БД и узел, на котором изменение уже применено
+type clusterCommand struct {
+	Origin string                  `json:"origin"` // эпоха журнала изменений БД узла, зафиксировавшего изменение (на этом узле команда к БД не применяется)
+	Entry  models.ReplicationEntry `json:"entry"`  // запись журнала изменений БД
+}
+
+// Конечный автомат Raft узла кластера: хранит данные БД, зафиксированные в журнале Raft, и применяет к БД изменения, зафиксированные другими узлами
+type clusterFSM struct {
+	db    *myProfilesDB
+	state models.ReplicationEntry // зафиксированные данные всех тенантов и общие данные БД (Tenants == nil - данные еще не получены)
+	mu    sync.Mutex              // блокировка state (отдельная от db.mu: лидер ждет применения своих команд под блокировкой db.mu)
+}
+
+// Снимок конечного автомата для журнала Raft
+type clusterSnapshot struct {
+	db    *myProfilesDB
+	state models.ReplicationEntry
+}
+
+/*
+Подключение БД к журналу Raft: после подключения каждое сохранение БД сначала фиксируется в журнале Raft, а на узлах, не являющихся лидером, изменения отклоняются
+
+:param clusterLog ClusterLog: журнал Raft узла кластера
+
+:return: конечный автомат Raft, который нужно передать журналу Raft узла
+*/
+func (db *myProfilesDB) AttachCluster(clusterLog ClusterLog) raft.FSM {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.cluster = clusterLog
+	db.clusterFSM = &clusterFSM{db: db}
+	return db.clusterFSM
+}
+
+/*
+Проверка, получил ли узел кластера данные БД из журнала Raft (до этого данные узла не актуальны)
+
+:return: true - если к узлу применена хотя бы одна полная запись журнала изменений БД или снимок журнала Raft
+*/
+func (db *myProfilesDB) ClusterSeeded() bool {
+	if db.clusterFSM == nil {
+		return false
+	}
+	db.clusterFSM.mu.Lock()
+	defer db.clusterFSM.mu.Unlock()
+
+	return db.clusterFSM.state.Tenants != nil
+}
+
+/*
+Фиксация всех данных БД в журнале Raft (вызывается новым лидером кластера после применения всех команд предыдущих лидеров): первый лидер кластера
+записывает в журнал исходные данные БД, следующие лидеры выравнивают данные узлов, которые не применили свою последнюю команду из-за ошибки
+
+:return: ошибка, если запись не удалось зафиксировать в журнале Raft
+*/
+func (db *myProfilesDB) CommitClusterSnapshot() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	err := db.commitClusterEntry(db.replicationEntry(nil))
+	if err != nil {
+		return err
+	}
+	db.wakeWebhookJob()
+	return nil
+}
+
+/*
+Фиксация записи журнала изменений БД в журнале Raft (вызывается методами БД под блокировкой db.mu); если узел не является лидером, запись отклоняется,
+и изменение откатывается вызывающим методом. Если лидерство потеряно во время фиксации, запись может быть зафиксирована новым лидером, хотя на узле изменение откатывается -
+данные узла выравниваются полной записью, которую фиксирует новый лидер
+
+:param entry models.ReplicationEntry: запись журнала изменений БД
+
+:return: ошибка, если узел не является лидером кластера или запись не удалось зафиксировать
+*/
+func (db *myProfilesDB) commitClusterEntry(entry models.ReplicationEntry) error {
+	command, err := json.Marshal(clusterCommand{Origin: db.replicationEpoch, Entry: entry})
+	if err != nil {
+		return err
+	}
+	command, err = db.sealClusterData(command)
+	if err != nil {
+		return err
+	}
+	return db.cluster.Commit(command)
+}
+
+/*
+Применение записи журнала изменений БД, зафиксированной другим узлом кластера: данные записи заменяют данные БД и сохраняются в файл базы данных,
+запись добавляется в журнал изменений БД узла для ведомых экземпляров
+
+:param entry models.ReplicationEntry: запись журнала изменений БД
+
+:return: ошибка, если данные тенанта в записи некорректны (БД при этом не меняется)
+*/
+func (db *myProfilesDB) applyClusterEntry(entry models.ReplicationEntry) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	err := db.applyEntryData(entry)
+	if err != nil {
+		return err
+	}
+	err = writeDumpFile(db.dumpFilePath, db.fileData(), db.keys)
+	if err != nil {
+		log.Printf("cluster: %s", dbDumpFailErr.Error())
+	}
+	db.appendReplicationEntry(entry)
+	db.notifyChanges()
+	return nil
+}
+
+/*
+Проверка, находится ли узел кластера в резерве (узел не является лидером): фоновые задачи, изменяющие данные, выполняются только лидером
+
+:return: true - если БД подключена к журналу Raft и узел не является лидером
+*/
+func (db *myProfilesDB) standby() bool {
+	return db.cluster != nil && !db.cluster.Writable()
+}
+
+/*
+Шифрование команды или снимка журнала Raft так же, как файл базы данных (если мастер-ключи не заданы, данные не шифруются)
+
+:param data []byte: данные
+
+:return: зашифрованные данные или ошибка, если данные не удалось зашифровать
+*/
+func (db *myProfilesDB) sealClusterData(data []byte) ([]byte, error) {
+	if db.keys == nil {
+		return data, nil
+	}
+	return db.keys.encrypt(data)
+}
+
+/*
+Расшифровка команды или снимка журнала Raft
+
+:param data []byte: данные (зашифрованные или нет)
+
+:return: расшифрованные данные или ошибка, если данные зашифрованы, а ключи не заданы, или данные повреждены
+*/
+func (db *myProfilesDB) openClusterData(data []byte) ([]byte, error) {
+	if !isEncryptedDump(data) {
+		return data, nil
+	}
+	if db.keys == nil {
+		return nil, encryptedDumpWithoutKeyErr
+	}
+	data, _, err := db.keys.decrypt(data)
+	return data, err
+}
+
+/*
+Добавление записи журнала изменений БД к зафиксированным данным (вызывается под блокировкой f.mu)
+
+:param entry models.ReplicationEntry: запись журнала изменений БД
+*/
+func (f *clusterFSM) merge(entry models.ReplicationEntry) {
+	if entry.Full || f.state.Tenants == nil {
+		f.state.Tenants = make(map[string]json.RawMessage, len(entry.Tenants))
+	}
+	for name, rawData := range entry.Tenants {
+		if string(rawData) == "null" {
+			delete(f.state.Tenants, name)
+			continue
+		}
+		f.state.Tenants[name] = rawData
+	}
+	f.state.Full = true
+	f.state.SchemaVersion = entry.SchemaVersion
+	f.state.PlatformAdminsTab = entry.PlatformAdminsTab
+	f.state.ClientsDataTab = entry.ClientsDataTab
+	f.state.ClientsSecretsTab = entry.ClientsSecretsTab
+	f.state.TokensTab = entry.TokensTab
+}
+
+/*
+Применение зафиксированной команды журнала Raft (вызывается журналом Raft по порядку команд)
+
+:param l *raft.Log: команда журнала Raft
+
+:return: ошибка, если команду не удалось прочитать или применить к БД
+*/
+func (f *clusterFSM) Apply(l *raft.Log) interface{} {
+	data, err := f.db.openClusterData(l.Data)
+	if err != nil {
+		log.Printf("cluster: fail to read command %d: %s", l.Index, err.Error())
+		return err
+	}
+	var command clusterCommand
+	err = json.Unmarshal(data, &command)
+	if err != nil {
+		log.Printf("cluster: fail to read command %d: %s", l.Index, err.Error())
+		return err
+	}
+
+	f.mu.Lock()
+	f.merge(command.Entry)
+	f.mu.Unlock()
+
+	// Узел, зафиксировавший изменение, уже применил его к БД
+	if command.Origin == f.db.replicationEpoch {
+		return nil
+	}
+	err = f.db.applyClusterEntry(command.Entry)
+	if err != nil {
+		log.Printf("cluster: fail to apply command %d: %s", l.Index, err.Error())
+		return err
+	}
+	return nil
+}
+
+/*
+Снимок зафиксированных данных для сжатия журнала Raft (вызывается журналом Raft, не блокирует БД)
+
+:return: снимок данных
+*/
+func (f *clusterFSM) Snapshot() (raft.FSMSnapshot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	state := f.state
+	state.Tenants = make(map[string]json.RawMessage, len(f.state.Tenants))
+	for name, rawData := range f.state.Tenants {
+		state.Tenants[name] = rawData
+	}
+	return &clusterSnapshot{db: f.db, state: state}, nil
+}
+
+/*
+Восстановление данных узла из снимка журнала Raft (при запуске узла или если узел отстал от лидера больше, чем хранит журнал Raft)
+
+:param snapshot io.ReadCloser: снимок данных
+
+:return: ошибка, если снимок не удалось прочитать или применить к БД
+*/
+func (f *clusterFSM) Restore(snapshot io.ReadCloser) error {
+	defer snapshot.Close()
+	data, err := ioutil.ReadAll(snapshot)
+	if err != nil {
+		return err
+	}
+	data, err = f.db.openClusterData(data)
+	if err != nil {
+		return err
+	}
+	var state models.ReplicationEntry
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return err
+	}
+	state.Full = true
+
+	f.mu.Lock()
+	f.state = models.ReplicationEntry{}
+	f.merge(state)
+	f.mu.Unlock()
+
+	return f.db.applyClusterEntry(state)
+}
+
+/*
+Запись снимка журнала Raft (шифруется так же, как файл базы данных)
+
+:param sink raft.SnapshotSink: хранилище снимка
+
+:return: ошибка, если снимок не удалось записать
+*/
+func (s *clusterSnapshot) Persist(sink raft.SnapshotSink) error {
+	data, err := json.Marshal(s.state)
+	if err == nil {
+		data, err = s.db.sealClusterData(data)
+	}
+	if err == nil {
+		_, err = sink.Write(data)
+	}
+	if err != nil {
+		sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+// Освобождение снимка (снимок не держит ресурсов)
+func (s *clusterSnapshot) Release() {}
@@ -0,0 +1,292 @@
+package myProfilesDB
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Заголовок зашифрованного файла базы данных: сигнатура формата, за которой следуют версия ключа (4 байта), nonce и шифротекст AES-GCM;
+// заголовок вместе с версией ключа аутентифицируется как дополнительные данные AES-GCM, поэтому изменение любой части файла обнаруживается при чтении
+var encryptedDumpMagic = []byte("MPDBENC1")
+
+// Набор мастер-ключей шифрования файлов базы данных по версиям: файлы шифруются ключом текущей (максимальной) версии,
+// расшифровываются ключом версии из заголовка файла, что позволяет менять мастер-ключ без потери доступа к старым файлам
+type keyring struct {
+	keys    map[uint32][]byte // ключи AES (16, 24 или 32 байта) по версиям
+	current uint32            // текущая версия ключа, которой шифруются файлы
+}
+
+/*
+Разбор набора ключей: ключи задаются парами "версия:ключ в base64", разделенными запятыми, пробелами или переводами строк
+
+:param source string: текст с набором ключей
+:param sourceName string: название источника ключей (для сообщений об ошибках)
+
+:return: набор ключей (nil, если ключи не заданы) или ошибка, если ключ или версия заданы некорректно
+*/
+func parseKeyring(source string, sourceName string) (*keyring, error) {
+	fields := strings.FieldsFunc(source, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	})
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	k := keyring{keys: make(map[uint32][]byte, len(fields))}
+	for _, field := range fields {
+		versionText, keyText, ok := strings.Cut(field, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w in %s: \"version:base64 key\" expected", invalidEncryptionKeyErr, sourceName)
+		}
+		version, err := strconv.ParseUint(versionText, 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("%w in %s: version must be a positive integer", invalidEncryptionKeyErr, sourceName)
+		}
+		key, err := base64.StdEncoding.DecodeString(keyText)
+		if err != nil || (len(key) != 16 && len(key) != 24 && len(key) != 32) {
+			return nil, fmt.Errorf("%w in %s: key %d must be 16, 24 or 32 bytes in base64", invalidEncryptionKeyErr, sourceName, version)
+		}
+		if _, ok := k.keys[uint32(version)]; ok {
+			return nil, fmt.Errorf("%w in %s: duplicate key version %d", invalidEncryptionKeyErr, sourceName, version)
+		}
+		k.keys[uint32(version)] = key
+		if uint32(version) > k.current {
+			k.current = uint32(version)
+		}
+	}
+	return &k, nil
+}
+
+/*
+Чтение набора мастер-ключей: ключи берутся из переменной окружения, заданной в конфиге, или, если она не задана, из файла ключей, заданного в конфиге
+
+:return: набор ключей (nil, если ключи не заданы - файлы базы данных не шифруются) или ошибка, если конфиг, файл ключей или ключи не удалось прочитать
+*/
+func loadKeyring() (*keyring, error) {
+	keyFilePath, keyEnv, err := getEncryptionKeySource()
+	if err != nil {
+		return nil, err
+	}
+	if keyEnv != "" {
+		if source := os.Getenv(keyEnv); source != "" {
+			return parseKeyring(source, "environment variable "+keyEnv)
+		}
+	}
+	if keyFilePath != "" {
+		source, err := ioutil.ReadFile(keyFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("%w: fail to read key file %s", invalidEncryptionKeyErr, keyFilePath)
+		}
+		return parseKeyring(string(source), "key file "+keyFilePath)
+	}
+	return nil, nil
+}
+
+/*
+Получение шифра AES-GCM по версии ключа
+
+:param version uint32: версия ключа
+
+:return: шифр или ошибка, если ключа с такой версией нет в наборе
+*/
+func (k *keyring) aead(version uint32) (cipher.AEAD, error) {
+	key, ok := k.keys[version]
+	if !ok {
+		return nil, fmt.Errorf("%w %d", noEncryptionKeyErr, version)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+/*
+Шифрование данных файла базы данных ключом текущей версии
+
+:param plaintext []byte: данные файла
+
+:return: зашифрованный файл (заголовок, версия ключа, nonce, шифротекст) или ошибка, если данные не удалось зашифровать
+*/
+func (k *keyring) encrypt(plaintext []byte) ([]byte, error) {
+	aead, err := k.aead(k.current)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, len(encryptedDumpMagic)+4)
+	copy(header, encryptedDumpMagic)
+	binary.BigEndian.PutUint32(header[len(encryptedDumpMagic):], k.current)
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+	data := append(header, nonce...)
+	return aead.Seal(data, nonce, plaintext, header), nil
+}
+
+/*
+Расшифровка зашифрованного файла базы данных ключом версии из заголовка файла
+
+:param data []byte: зашифрованный файл
+
+:return: данные файла и версия ключа, которой файл был зашифрован, или ошибка, если ключа такой версии нет или файл поврежден или изменен
+*/
+func (k *keyring) decrypt(data []byte) ([]byte, uint32, error) {
+	headerSize := len(encryptedDumpMagic) + 4
+	if len(data) < headerSize {
+		return nil, 0, tamperedDumpErr
+	}
+	header := data[:headerSize]
+	version := binary.BigEndian.Uint32(header[len(encryptedDumpMagic):])
+	aead, err := k.aead(version)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(data) < headerSize+aead.NonceSize() {
+		return nil, 0, tamperedDumpErr
+	}
+	nonce := data[headerSize : headerSize+aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, data[headerSize+aead.NonceSize():], header)
+	if err != nil {
+		return nil, 0, tamperedDumpErr
+	}
+	return plaintext, version, nil
+}
+
+/*
+Проверка, зашифрован ли файл базы данных
+
+:param data []byte: содержимое файла
+
+:return: true, если файл начинается с заголовка зашифрованного файла
+*/
+func isEncryptedDump(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedDumpMagic)
+}
+
+/*
+Чтение файла базы данных с расшифровкой
+
+:param path string: путь к файлу
+:param keys *keyring: набор ключей (nil - шифрование не настроено)
+
+:return: данные файла, признак того, что файл нужно перешифровать ключом текущей версии (файл не зашифрован или зашифрован старым ключом),
+или ошибка, если файл не удалось прочитать, он зашифрован, а ключи не заданы, или файл поврежден или изменен
+*/
+func readDumpFile(path string, keys *keyring) ([]byte, bool, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if !isEncryptedDump(data) {
+		return data, keys != nil, nil
+	}
+	if keys == nil {
+		return nil, false, encryptedDumpWithoutKeyErr
+	}
+	plaintext, version, err := keys.decrypt(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", path, err)
+	}
+	return plaintext, version != keys.current, nil
+}
+
+/*
+Запись файла базы данных с шифрованием: файл записывается во временный файл, который затем атомарно заменяет старый файл,
+файл доступен только владельцу процесса
+
+:param path string: путь к файлу
+:param data []byte: данные файла
+:param keys *keyring: набор ключей (nil - файл записывается без шифрования)
+
+:return: ошибка, если данные не удалось зашифровать или файл не удалось записать
+*/
+func writeDumpFile(path string, data []byte, keys *keyring) error {
+	var err error
+	if keys != nil {
+		data, err = keys.encrypt(data)
+		if err != nil {
+			return err
+		}
+	}
+	tempFile, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+	_, err = tempFile.Write(data)
+	if err == nil {
+		err = tempFile.Sync()
+	}
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(tempFile.Name(), 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tempFile.Name(), path)
+}
+
+/*
+Перешифровка файла базы данных ключом текущей версии (после добавления новой версии мастер-ключа); сервис при этом должен быть остановлен
+
+:return: версия ключа, которой файл был зашифрован (0 - файл не был зашифрован), и текущая версия ключа или ошибка, если ключи не заданы,
+файл не удалось прочитать, расшифровать или записать
+*/
+func ReencryptDump() (uint32, uint32, error) {
+	dataFilePath, err := getDBDumpPath()
+	if err != nil {
+		return 0, 0, err
+	}
+	keys, err := loadKeyring()
+	if err != nil {
+		return 0, 0, err
+	}
+	if keys == nil {
+		return 0, 0, noEncryptionKeysErr
+	}
+	data, err := ioutil.ReadFile(dataFilePath)
+	if err != nil {
+		return 0, 0, err
+	}
+	var version uint32
+	if isEncryptedDump(data) {
+		data, version, err = keys.decrypt(data)
+		if err != nil {
+			return 0, 0, fmt.Errorf("%s: %w", dataFilePath, err)
+		}
+	}
+	err = writeDumpFile(dataFilePath, data, keys)
+	if err != nil {
+		return 0, 0, err
+	}
+	return version, keys.current, nil
+}
+
+/*
+Генерация нового мастер-ключа для набора ключей шифрования
+
+:return: ключ AES-256 в base64 или ошибка, если ключ не удалось сгенерировать
+*/
+func GenerateEncryptionKey() (string, error) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
@@ -0,0 +1,210 @@
+package myProfilesDB
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+/*
+Составление мастер-ключа для теста
+
+:param size int: длина ключа в байтах
+:param fill byte: значение байтов ключа
+
+:return: ключ в base64
+*/
+func testKey(size int, fill byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, size))
+}
+
+/*
+Разбор набора ключей для теста
+
+:param t *testing.T: тест
+:param source string: набор ключей "версия:ключ в base64"
+
+:return: набор ключей
+*/
+func testKeyring(t *testing.T, source string) *keyring {
+	t.Helper()
+	keys, err := parseKeyring(source, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+/*
+Версия ключа, которой зашифрован файл базы данных
+
+:param t *testing.T: тест
+:param path string: путь к файлу
+
+:return: версия ключа из заголовка файла (0 - файл не зашифрован)
+*/
+func dumpKeyVersion(t *testing.T, path string) uint32 {
+	t.Helper()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isEncryptedDump(data) {
+		return 0
+	}
+	return binary.BigEndian.Uint32(data[len(encryptedDumpMagic):])
+}
+
+// Зашифрованный файл не содержит данных в открытом виде и читается тем же набором ключей
+func TestDumpEncryptionRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	plaintext := []byte(`{"schemaVersion":1,"tenantsTab":{}}`)
+	for _, source := range []string{"1:" + testKey(16, 1), "1:" + testKey(24, 1), "1:" + testKey(32, 1) + ",3:" + testKey(32, 3)} {
+		keys := testKeyring(t, source)
+		err := writeDumpFile(path, plaintext, keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !isEncryptedDump(raw) || bytes.Contains(raw, []byte("tenantsTab")) {
+			t.Fatalf("dump is not encrypted with keys %s", source)
+		}
+		if version := dumpKeyVersion(t, path); version != keys.current {
+			t.Fatalf("dump is encrypted with key version %d instead of %d", version, keys.current)
+		}
+		data, reencrypt, err := readDumpFile(path, keys)
+		if err != nil || reencrypt || !bytes.Equal(data, plaintext) {
+			t.Fatalf("dump is not decrypted: %v %v %s", err, reencrypt, data)
+		}
+	}
+}
+
+// Изменение одного байта заголовка, nonce или шифротекста обнаруживается при расшифровке
+func TestDumpTamperDetection(t *testing.T) {
+	keys := testKeyring(t, "1:"+testKey(32, 1)+",2:"+testKey(32, 2))
+	encrypted, err := keys.encrypt([]byte(`{"schemaVersion":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	headerSize := len(encryptedDumpMagic) + 4
+	offsets := map[string]int{
+		"magic":       0,
+		"key version": headerSize - 1, // версия 2 заменяется версией 3, для которой в набор добавляется другой ключ
+		"nonce":       headerSize,
+		"ciphertext":  headerSize + 12,
+		"tag":         len(encrypted) - 1,
+	}
+	for name, offset := range offsets {
+		tampered := append([]byte(nil), encrypted...)
+		tampered[offset] ^= 1
+		if name == "key version" {
+			keys.keys[3] = keys.keys[1]
+		}
+		if _, _, err := keys.decrypt(tampered); !errors.Is(err, tamperedDumpErr) {
+			t.Errorf("change of %s is not detected: %v", name, err)
+		}
+		delete(keys.keys, 3)
+	}
+	if _, _, err := keys.decrypt(encrypted[:headerSize+4]); !errors.Is(err, tamperedDumpErr) {
+		t.Errorf("truncated dump is not detected: %v", err)
+	}
+
+	// Измененный файл не загружается
+	path := filepath.Join(t.TempDir(), "db.json")
+	encrypted[len(encrypted)-1] ^= 1
+	err = ioutil.WriteFile(path, encrypted, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = readDumpFile(path, keys); !errors.Is(err, tamperedDumpErr) {
+		t.Fatalf("tampered dump file is read: %v", err)
+	}
+}
+
+// Файл, зашифрованный неизвестной версией ключа или прочитанный без ключей, не расшифровывается
+func TestDumpWithoutKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	err := writeDumpFile(path, []byte(`{}`), testKeyring(t, "2:"+testKey(32, 2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = readDumpFile(path, testKeyring(t, "1:"+testKey(32, 1))); !errors.Is(err, noEncryptionKeyErr) {
+		t.Fatalf("dump is read without key of its version: %v", err)
+	}
+	if _, _, err = readDumpFile(path, nil); !errors.Is(err, encryptedDumpWithoutKeyErr) {
+		t.Fatalf("encrypted dump is read without keys: %v", err)
+	}
+}
+
+// При загрузке БД файл, не зашифрованный или зашифрованный старой версией ключа, перешифровывается ключом текущей версии
+func TestDumpKeyRotationOnLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	db, err := OpenMyProfilesDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	platform, err := db.GetTenant(db.PlatformTenant())
+	if err != nil {
+		t.Fatal(err)
+	}
+	addTestProfile(t, platform, "alice")
+	if version := dumpKeyVersion(t, path); version != 0 {
+		t.Fatalf("dump is encrypted without keys: %d", version)
+	}
+
+	for _, rotation := range []struct {
+		keys    string
+		version uint32
+	}{
+		{"1:" + testKey(32, 1), 1},
+		{"1:" + testKey(32, 1) + "\n2:" + testKey(16, 2), 2},
+	} {
+		t.Setenv("AUTHSERVICE_DB_KEYS", rotation.keys)
+		db, err = OpenMyProfilesDB(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version := dumpKeyVersion(t, path); version != rotation.version {
+			t.Fatalf("dump is encrypted with key version %d instead of %d", version, rotation.version)
+		}
+		platform, err = db.GetTenant(db.PlatformTenant())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err = platform.GetProfileData("alice"); err != nil {
+			t.Fatalf("profile is lost after key rotation: %s", err)
+		}
+	}
+}
+
+// Набор ключей с повторяющимися версиями, версией 0, некорректными ключами или без версии отклоняется
+func TestParseKeyringRejectsInvalidKeys(t *testing.T) {
+	sources := []string{
+		"1:" + testKey(32, 1) + ",1:" + testKey(32, 2),
+		"0:" + testKey(32, 1),
+		"-1:" + testKey(32, 1),
+		"1:" + testKey(20, 1),
+		"1:" + testKey(33, 1),
+		"1:not base64!",
+		testKey(32, 1),
+	}
+	for _, source := range sources {
+		if _, err := parseKeyring(source, "test"); !errors.Is(err, invalidEncryptionKeyErr) {
+			t.Errorf("keys %q are accepted: %v", source, err)
+		}
+	}
+	if keys, err := parseKeyring(" ,\n", "test"); keys != nil || err != nil {
+		t.Fatalf("empty keys are parsed: %v %v", keys, err)
+	}
+	keys := testKeyring(t, "3:"+testKey(32, 3)+", 1:"+testKey(32, 1))
+	if keys.current != 3 || len(keys.keys) != 2 || !bytes.Equal(keys.keys[1], bytes.Repeat([]byte{1}, 32)) {
+		t.Fatalf("unexpected keyring %+v", keys)
+	}
+}
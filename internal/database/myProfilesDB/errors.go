@@ -9,3 +9,69 @@ var dbDumpFailErr error = errors.New("failed to dump database")
 var noProfileErr error = errors.New("no such profile")
 var profileExistsErr error = errors.New("such profile is already exists")
 var incorrectPasswordErr error = errors.New("incorrect password")
+var noClientErr error = errors.New("no such oauth client")
+var clientExistsErr error = errors.New("such oauth client is already exists")
+var emptyClientIDErr error = errors.New("oauth client id must not be empty")
+var tokenGenerationFailErr error = errors.New("failed to generate access token")
+var noGroupErr error = errors.New("no such group")
+var groupExistsErr error = errors.New("such group is already exists")
+var noGroupMemberErr error = errors.New("profile is not a member of group")
+var noSubgroupErr error = errors.New("group is not a subgroup of group")
+var groupCycleErr error = errors.New("nesting groups would create a cycle")
+var emptyGroupNameErr error = errors.New("group name must not be empty")
+var noTenantErr error = errors.New("no such tenant")
+var tenantExistsErr error = errors.New("such tenant is already exists")
+var invalidTenantNameErr error = errors.New("tenant name must consist of lowercase latin letters, digits and hyphens")
+var canNotRemovePlatformTenantErr error = errors.New("platform tenant can not be removed")
+var invalidAttributeDefinitionErr error = errors.New("invalid attribute definition")
+var invalidAttributeErr error = errors.New("invalid value of attribute")
+var unknownAttributeErr error = errors.New("unknown attribute")
+var requiredAttributeErr error = errors.New("missing required attribute")
+var notUniqueAttributeErr error = errors.New("value is not unique for attribute")
+var unknownVisibilityErr error = errors.New("unknown visibility")
+var emptyLoginErr error = errors.New("login must not be empty")
+var loginIsAliasErr error = errors.New("login is reserved as an alias of renamed profile")
+var noDeletedProfileErr error = errors.New("no such deleted profile")
+var noHistoryEntryErr error = errors.New("no such version in profile history")
+var unknownAccountStatusErr error = errors.New("unknown account status")
+var invalidStatusTransitionErr error = errors.New("account status transition is not allowed")
+var noInvitationErr error = errors.New("no such invitation or invitation expired")
+var invalidEncryptionKeyErr error = errors.New("invalid database encryption key")
+var noEncryptionKeyErr error = errors.New("no database encryption key of version")
+var noEncryptionKeysErr error = errors.New("database encryption keys are not configured")
+var encryptedDumpWithoutKeyErr error = errors.New("database dump is encrypted, but database encryption keys are not configured")
+var invalidDumpErr error = errors.New("invalid database dump")
+var unsupportedSchemaVersionErr error = errors.New("database dump schema version is not supported")
+var tamperedDumpErr error = errors.New("database dump is corrupted or has been tampered with")
+var noRegistrationErr error = errors.New("no such registration")
+var emailNotVerifiedErr error = errors.New("email of registered user is not verified")
+var emptyEmailErr error = errors.New("email must not be empty")
+var emailAlreadyVerifiedErr error = errors.New("email is already verified")
+var noVerificationErr error = errors.New("no such email verification or verification expired")
+var invalidInvitationExpiryErr error = errors.New("invitation expiry time must be in the future")
+var emptySuspensionReasonErr error = errors.New("reason of account suspension must not be empty")
+var invalidAccountExpiryErr error = errors.New("account expiry time must be 0 or in the future")
+var unknownImportModeErr error = errors.New("unknown import mode")
+var passwordAndHashErr error = errors.New("only one of password and password hash can be set")
+var invalidPasswordHashErr error = errors.New("password hash is not a bcrypt hash")
+var duplicateImportLoginErr error = errors.New("login is repeated in import")
+var unknownBatchOperationErr error = errors.New("unknown batch operation")
+var emptyPasswordErr error = errors.New("password must not be empty")
+var batchRemovesOwnAdminRightsErr error = errors.New("batch removes admin rights of its author")
+var readOnlyTransactionErr error = errors.New("transaction is read-only")
+var closedTransactionErr error = errors.New("transaction is already completed")
+var invalidWebhookURLErr error = errors.New("invalid webhook url")
+var unknownWebhookEventErr error = errors.New("unknown webhook event type")
+var noWebhookErr error = errors.New("no such webhook")
+var noWebhookDeadLetterErr error = errors.New("no such undelivered webhook event")
+var followerReadOnlyErr error = errors.New("database of follower is read-only, changes are made on the leader")
+var clusterNotLeaderErr error = errors.New("cluster node is not the leader, writes are accepted only by the leader")
+var notFollowerErr error = errors.New("database is not a follower")
+var replicationGapErr error = errors.New("replication entry is out of order")
+var replicationSchemaVersionErr error = errors.New("replication entry has unsupported schema version")
+
+// Ошибки БД, по которым обработчики запросов определяют код ответа
+var ProfileVersionMismatchErr error = errors.New("profile version does not match")
+var InvalidBackupErr error = errors.New("invalid backup")
+var ChangeLogTruncatedErr error = errors.New("change events are no longer retained")
+var ReplicationLogTruncatedErr error = errors.New("replication log entries are no longer retained, snapshot is required")
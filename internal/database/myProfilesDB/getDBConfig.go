@@ -32,6 +32,330 @@ func getDBDumpPath() (string, error) {
 	return dbData.DatabaseDumpPath, nil
 }
 
+/*
+Получение источников мастер-ключей шифрования файлов базы данных из конфига "../../configs/dbConfig.json"
+
+:return: путь к файлу ключей и название переменной окружения с ключами (пустые строки - источник не используется) или ошибка, если конфиг не удалось прочитать
+*/
+func getEncryptionKeySource() (string, string, error) {
+	// Структура источников ключей шифрования в конфигурации БД
+	type dbConfig struct {
+		EncryptionKeyFile string `json:"encryptionKeyFile"` // путь к файлу ключей шифрования
+		EncryptionKeyEnv  string `json:"encryptionKeyEnv"`  // название переменной окружения с ключами шифрования (имеет приоритет над файлом ключей)
+	}
+	configFilePath := "../../configs/dbConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return "", "", errors.New("fail to read database config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var dbData dbConfig
+	json.Unmarshal(byteValue, &dbData)
+
+	return dbData.EncryptionKeyFile, dbData.EncryptionKeyEnv, nil
+}
+
+/*
+Получение времени жизни токенов доступа OAuth 2.0 из конфига "../../configs/dbConfig.json"
+
+:return: время жизни токенов доступа в секундах или ошибка, если конфиг не удалось прочитать
+*/
+func getAccessTokenLifetime() (int64, error) {
+	// Структура времени жизни токенов в конфигурации БД
+	type dbConfig struct {
+		AccessTokenLifetime int64 `json:"accessTokenLifetime"` // время жизни токенов доступа в секундах
+	}
+	configFilePath := "../../configs/dbConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return 0, errors.New("fail to read database config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var dbData dbConfig
+	json.Unmarshal(byteValue, &dbData)
+
+	return dbData.AccessTokenLifetime, nil
+}
+
+/*
+Получение времени жизни псевдонимов старых логинов переименованных профилей из конфига "../../configs/dbConfig.json"
+
+:return: время жизни псевдонимов в секундах или ошибка, если конфиг не удалось прочитать
+*/
+func getLoginAliasLifetime() (int64, error) {
+	// Структура времени жизни псевдонимов в конфигурации БД
+	type dbConfig struct {
+		LoginAliasLifetime int64 `json:"loginAliasLifetime"` // время жизни псевдонимов старых логинов в секундах
+	}
+	configFilePath := "../../configs/dbConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return 0, errors.New("fail to read database config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var dbData dbConfig
+	json.Unmarshal(byteValue, &dbData)
+
+	return dbData.LoginAliasLifetime, nil
+}
+
+/*
+Получение времени жизни приглашений по умолчанию из конфига "../../configs/dbConfig.json"
+
+:return: время жизни приглашений в секундах или ошибка, если конфиг не удалось прочитать
+*/
+func getInvitationLifetime() (int64, error) {
+	// Структура времени жизни приглашений в конфигурации БД
+	type dbConfig struct {
+		InvitationLifetime int64 `json:"invitationLifetime"` // время жизни приглашений в секундах
+	}
+	configFilePath := "../../configs/dbConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return 0, errors.New("fail to read database config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var dbData dbConfig
+	json.Unmarshal(byteValue, &dbData)
+
+	return dbData.InvitationLifetime, nil
+}
+
+/*
+Получение времени жизни токенов подтверждения адресов электронной почты из конфига "../../configs/dbConfig.json"
+
+:return: время жизни токенов подтверждения в секундах или ошибка, если конфиг не удалось прочитать
+*/
+func getVerificationLifetime() (int64, error) {
+	// Структура времени жизни токенов подтверждения в конфигурации БД
+	type dbConfig struct {
+		VerificationLifetime int64 `json:"verificationLifetime"` // время жизни токенов подтверждения адресов электронной почты в секундах
+	}
+	configFilePath := "../../configs/dbConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return 0, errors.New("fail to read database config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var dbData dbConfig
+	json.Unmarshal(byteValue, &dbData)
+
+	return dbData.VerificationLifetime, nil
+}
+
+/*
+Получение времени хранения удаленных профилей в корзине из конфига "../../configs/dbConfig.json"
+
+:return: время хранения удаленных профилей в секундах или ошибка, если конфиг не удалось прочитать
+*/
+func getDeletedRetention() (int64, error) {
+	// Структура времени хранения удаленных профилей в конфигурации БД
+	type dbConfig struct {
+		DeletedRetention int64 `json:"deletedRetention"` // время хранения удаленных профилей в секундах
+	}
+	configFilePath := "../../configs/dbConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return 0, errors.New("fail to read database config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var dbData dbConfig
+	json.Unmarshal(byteValue, &dbData)
+
+	return dbData.DeletedRetention, nil
+}
+
+/*
+Получение периода очистки корзины удаленных профилей из конфига "../../configs/dbConfig.json"
+
+:return: период очистки корзины в секундах или ошибка, если конфиг не удалось прочитать или период не положителен
+*/
+func getPurgeInterval() (int64, error) {
+	// Структура периода очистки корзины в конфигурации БД
+	type dbConfig struct {
+		PurgeInterval int64 `json:"purgeInterval"` // период очистки корзины в секундах
+	}
+	configFilePath := "../../configs/dbConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return 0, errors.New("fail to read database config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var dbData dbConfig
+	json.Unmarshal(byteValue, &dbData)
+	if dbData.PurgeInterval <= 0 {
+		return 0, errors.New("purge interval must be positive in database config " + configFilePath)
+	}
+
+	return dbData.PurgeInterval, nil
+}
+
+/*
+Получение настроек резервного копирования по расписанию из конфига "../../configs/dbConfig.json"
+
+:return: каталог резервных копий, период создания копий в секундах (0 - копии по расписанию не создаются) и количество хранимых копий
+или ошибка, если конфиг не удалось прочитать или настройки некорректны
+*/
+func getBackupConfig() (string, int64, int, error) {
+	// Структура настроек резервного копирования в конфигурации БД
+	type dbConfig struct {
+		BackupDir       string `json:"backupDir"`       // каталог резервных копий
+		BackupInterval  int64  `json:"backupInterval"`  // период создания резервных копий в секундах
+		BackupRetention int    `json:"backupRetention"` // количество хранимых резервных копий
+	}
+	configFilePath := "../../configs/dbConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return "", 0, 0, errors.New("fail to read database config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var dbData dbConfig
+	json.Unmarshal(byteValue, &dbData)
+	if dbData.BackupInterval > 0 && (dbData.BackupDir == "" || dbData.BackupRetention <= 0) {
+		return "", 0, 0, errors.New("backup directory and positive backup retention are required in database config " + configFilePath)
+	}
+
+	return dbData.BackupDir, dbData.BackupInterval, dbData.BackupRetention, nil
+}
+
+/*
+Получение размера журнала изменений профилей тенанта из конфига "../../configs/dbConfig.json"
+
+:return: количество хранимых событий изменений профилей в каждом тенанте или ошибка, если конфиг не удалось прочитать или размер не положителен
+*/
+func getChangeLogSize() (int, error) {
+	// Структура размера журнала изменений в конфигурации БД
+	type dbConfig struct {
+		ChangeLogSize int `json:"changeLogSize"` // количество хранимых событий изменений профилей
+	}
+	configFilePath := "../../configs/dbConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return 0, errors.New("fail to read database config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var dbData dbConfig
+	json.Unmarshal(byteValue, &dbData)
+	if dbData.ChangeLogSize <= 0 {
+		return 0, errors.New("change log size must be positive in database config " + configFilePath)
+	}
+
+	return dbData.ChangeLogSize, nil
+}
+
+/*
+Получение размера истории доставок вебхука из конфига "../../configs/dbConfig.json"
+
+:return: количество хранимых завершенных доставок каждого вебхука (и недоставленных событий тенанта) или ошибка, если конфиг не удалось прочитать или размер не положителен
+*/
+func getWebhookHistorySize() (int, error) {
+	// Структура размера истории доставок вебхука в конфигурации БД
+	type dbConfig struct {
+		WebhookHistorySize int `json:"webhookHistorySize"` // количество хранимых завершенных доставок вебхука
+	}
+	configFilePath := "../../configs/dbConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return 0, errors.New("fail to read database config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var dbData dbConfig
+	json.Unmarshal(byteValue, &dbData)
+	if dbData.WebhookHistorySize <= 0 {
+		return 0, errors.New("webhook history size must be positive in database config " + configFilePath)
+	}
+
+	return dbData.WebhookHistorySize, nil
+}
+
+/*
+Получение размера истории изменений профиля из конфига "../../configs/dbConfig.json"
+
+:return: количество хранимых записей истории каждого профиля или ошибка, если конфиг не удалось прочитать или размер не положителен
+*/
+func getProfileHistorySize() (int, error) {
+	// Структура размера истории изменений профиля в конфигурации БД
+	type dbConfig struct {
+		ProfileHistorySize int `json:"profileHistorySize"` // количество хранимых записей истории профиля
+	}
+	configFilePath := "../../configs/dbConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return 0, errors.New("fail to read database config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var dbData dbConfig
+	json.Unmarshal(byteValue, &dbData)
+	if dbData.ProfileHistorySize <= 0 {
+		return 0, errors.New("profile history size must be positive in database config " + configFilePath)
+	}
+
+	return dbData.ProfileHistorySize, nil
+}
+
+/*
+Получение размера журнала изменений БД для репликации из конфига "../../configs/dbConfig.json"
+
+:return: количество хранимых записей журнала изменений БД или ошибка, если конфиг не удалось прочитать или размер не положителен
+*/
+func getReplicationLogSize() (int, error) {
+	// Структура размера журнала изменений БД в конфигурации БД
+	type dbConfig struct {
+		ReplicationLogSize int `json:"replicationLogSize"` // количество хранимых записей журнала изменений БД
+	}
+	configFilePath := "../../configs/dbConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return 0, errors.New("fail to read database config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var dbData dbConfig
+	json.Unmarshal(byteValue, &dbData)
+	if dbData.ReplicationLogSize <= 0 {
+		return 0, errors.New("replication log size must be positive in database config " + configFilePath)
+	}
+
+	return dbData.ReplicationLogSize, nil
+}
+
+/*
+Получение названия тенанта платформы из конфига "../../configs/dbConfig.json"
+
+:return: название тенанта платформы или ошибка, если конфиг не удалось прочитать или название не задано
+*/
+func getPlatformTenant() (string, error) {
+	// Структура названия тенанта платформы в конфигурации БД
+	type dbConfig struct {
+		PlatformTenant string `json:"platformTenant"` // название тенанта платформы
+	}
+	configFilePath := "../../configs/dbConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return "", errors.New("fail to read database config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var dbData dbConfig
+	json.Unmarshal(byteValue, &dbData)
+	if dbData.PlatformTenant == "" {
+		return "", errors.New("platform tenant is not set in database config " + configFilePath)
+	}
+
+	return dbData.PlatformTenant, nil
+}
+
 /*
 Получение данных профиля администратора по умолчанию и его пароля из БД конфига "../../configs/dbConfig.json"
 
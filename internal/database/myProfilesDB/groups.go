@@ -0,0 +1,599 @@
+package myProfilesDB
+
+import (
+	"sort"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Структура группы профилей в in memory базе данных
+type group struct {
+	members   map[string]struct{} // идентификаторы профилей, непосредственно входящих в группу
+	subgroups map[string]struct{} // названия групп, вложенных в группу
+}
+
+/*
+Построение группы in memory БД по данным группы из файла
+
+:param groupData models.GroupData: данные группы
+
+:return: указатель на группу
+*/
+func newGroup(groupData models.GroupData) *group {
+	g := group{
+		members:   make(map[string]struct{}, len(groupData.Members)),
+		subgroups: make(map[string]struct{}, len(groupData.Subgroups)),
+	}
+	for _, login := range groupData.Members {
+		g.members[login] = struct{}{}
+	}
+	for _, subgroupName := range groupData.Subgroups {
+		g.subgroups[subgroupName] = struct{}{}
+	}
+	return &g
+}
+
+/*
+Составление данных группы для хранения в файле
+
+:param name string: название группы
+
+:return: данные группы с отсортированными списками идентификаторов участников и вложенных групп
+*/
+func (g *group) groupData(name string) models.GroupData {
+	groupData := models.GroupData{
+		Name:      name,
+		Members:   make([]string, 0, len(g.members)),
+		Subgroups: make([]string, 0, len(g.subgroups)),
+	}
+	for id := range g.members {
+		groupData.Members = append(groupData.Members, id)
+	}
+	for subgroupName := range g.subgroups {
+		groupData.Subgroups = append(groupData.Subgroups, subgroupName)
+	}
+	sort.Strings(groupData.Members)
+	sort.Strings(groupData.Subgroups)
+	return groupData
+}
+
+/*
+Получить данные группы по названию
+
+:param name string: название получаемой группы
+
+:return: данные группы (участники заданы логинами) или ошибка, если группы с таким названием нет
+*/
+func (t *Tenant) GetGroupData(name string) (models.GroupData, error) {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	g, ok := t.groupsTab[name]
+	if !ok {
+		return models.GroupData{}, noGroupErr
+	}
+	groupData := g.groupData(name)
+	for i, id := range groupData.Members {
+		groupData.Members[i] = t.profilesDataTab[id].Login
+	}
+	sort.Strings(groupData.Members)
+	return groupData, nil
+}
+
+/*
+Получить список названий всех групп
+
+:return: список названий всех групп
+*/
+func (t *Tenant) GetAllGroups() (names []string) {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	for name := range t.groupsTab {
+		names = append(names, name)
+	}
+	return
+}
+
+/*
+Получить список всех групп, в которые профиль входит непосредственно или через вложенные группы
+
+:param login string: логин профиля
+
+:return: отсортированный список названий групп профиля
+*/
+func (t *Tenant) GetEffectiveGroups(login string) []string {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	effectiveGroups := t.effectiveGroups(login, "")
+	names := make([]string, 0, len(effectiveGroups))
+	for name := range effectiveGroups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/*
+Составление множества групп, в которые профиль входит непосредственно или через вложенные группы
+
+:param login string: логин профиля
+:param excludedGroup string: название группы, которая считается отсутствующей (пустая строка - учитываются все группы)
+
+:return: множество названий групп профиля
+*/
+func (t *Tenant) effectiveGroups(login string, excludedGroup string) map[string]struct{} {
+	effectiveGroups := make(map[string]struct{})
+	id, ok := t.loginsTab[login]
+	if !ok {
+		return effectiveGroups
+	}
+
+	// Составление списка родительских групп для каждой группы и групп, в которые профиль входит непосредственно
+	parents := make(map[string][]string)
+	var queue []string
+	for name, g := range t.groupsTab {
+		if name == excludedGroup {
+			continue
+		}
+		for subgroupName := range g.subgroups {
+			parents[subgroupName] = append(parents[subgroupName], name)
+		}
+		if _, ok := g.members[id]; ok {
+			queue = append(queue, name)
+		}
+	}
+	// Обход родительских групп в ширину
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if _, ok := effectiveGroups[name]; ok || name == excludedGroup {
+			continue
+		}
+		effectiveGroups[name] = struct{}{}
+		queue = append(queue, parents[name]...)
+	}
+	return effectiveGroups
+}
+
+/*
+Проверка прав администратора профиля с возможностью не учитывать одну из групп
+
+:param login string: логин проверяемого профиля
+:param excludedGroup string: название группы, которая считается отсутствующей (пустая строка - учитываются все группы)
+:param excludedAdminGroup string: название группы, которая не считается группой администраторов (пустая строка - учитываются все группы администраторов)
+
+:return: true, если профиль является администратором, иначе - false
+*/
+func (t *Tenant) isAdmin(login string, excludedGroup string, excludedAdminGroup string) bool {
+	id, ok := t.loginsTab[login]
+	if !ok {
+		return false
+	}
+	if _, ok := t.adminsTab[id]; ok {
+		return true
+	}
+	for name := range t.effectiveGroups(login, excludedGroup) {
+		if _, ok := t.adminGroupsTab[name]; ok && name != excludedAdminGroup {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Проверка, останется ли профиль администратором после удаления группы
+
+:param login string: логин проверяемого профиля
+:param name string: название удаляемой группы
+
+:return: true, если профиль останется администратором, иначе - false
+*/
+func (t *Tenant) IsAdminWithoutGroup(login string, name string) bool {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	return t.isAdmin(login, name, "")
+}
+
+/*
+Проверка, останется ли профиль администратором после удаления группы из списка групп администраторов
+
+:param login string: логин проверяемого профиля
+:param name string: название группы, удаляемой из списка групп администраторов
+
+:return: true, если профиль останется администратором, иначе - false
+*/
+func (t *Tenant) IsAdminWithoutAdminGroup(login string, name string) bool {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	return t.isAdmin(login, "", name)
+}
+
+/*
+Составление множества профилей, являющихся администраторами непосредственно или через группы администраторов (с учетом вложенности групп)
+
+:return: множество идентификаторов профилей администраторов
+*/
+func (t *Tenant) effectiveAdmins() map[string]struct{} {
+	admins := make(map[string]struct{}, len(t.adminsTab))
+	for id := range t.adminsTab {
+		admins[id] = struct{}{}
+	}
+	// Обход групп администраторов и вложенных в них групп в глубину
+	visited := make(map[string]struct{})
+	stack := make([]string, 0, len(t.adminGroupsTab))
+	for name := range t.adminGroupsTab {
+		stack = append(stack, name)
+	}
+	for len(stack) > 0 {
+		name := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, ok := visited[name]; ok {
+			continue
+		}
+		visited[name] = struct{}{}
+		g, ok := t.groupsTab[name]
+		if !ok {
+			continue
+		}
+		for id := range g.members {
+			admins[id] = struct{}{}
+		}
+		for subgroupName := range g.subgroups {
+			stack = append(stack, subgroupName)
+		}
+	}
+	return admins
+}
+
+/*
+Запись в журнал изменений событий о получении и потере прав администратора профилями, права которых изменились (в том числе через группы) (вызывается методами БД под блокировкой db.mu)
+
+:param admins map[string]struct{}: администраторы до изменения групп (effectiveAdmins)
+:param author string: логин автора изменения (пустая строка - автор не известен)
+*/
+func (t *Tenant) recordAdminChanges(admins map[string]struct{}, author string) {
+	currentAdmins := t.effectiveAdmins()
+	var granted, revoked []string
+	for id := range currentAdmins {
+		if _, ok := admins[id]; !ok {
+			granted = append(granted, id)
+		}
+	}
+	for id := range admins {
+		if _, ok := currentAdmins[id]; !ok {
+			revoked = append(revoked, id)
+		}
+	}
+	sort.Strings(granted)
+	sort.Strings(revoked)
+	for _, id := range granted {
+		t.recordChange(models.ChangeAdminGranted, id, 0, author)
+	}
+	for _, id := range revoked {
+		t.recordChange(models.ChangeAdminRevoked, id, 0, author)
+	}
+}
+
+/*
+Проверка, вложена ли группа в другую группу непосредственно или через другие группы
+
+:param name string: название группы, в которой ищется вложенная группа
+:param subgroupName string: название искомой вложенной группы
+
+:return: true, если группа subgroupName вложена в группу name, иначе - false
+*/
+func (t *Tenant) containsSubgroup(name string, subgroupName string) bool {
+	visited := make(map[string]struct{})
+	stack := []string{name}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, ok := visited[current]; ok {
+			continue
+		}
+		visited[current] = struct{}{}
+		g, ok := t.groupsTab[current]
+		if !ok {
+			continue
+		}
+		for child := range g.subgroups {
+			if child == subgroupName {
+				return true
+			}
+			stack = append(stack, child)
+		}
+	}
+	return false
+}
+
+/*
+Создание новой пустой группы
+
+:param name string: название новой группы
+
+:return: возвращается ошибка, если название пустое, группа с таким названием уже существует или базу данных не удалось сохранить
+*/
+func (t *Tenant) AddGroup(name string) error {
+	return t.Update(func(tx *Tx) error {
+		// Проверка названия и наличия группы с названием name
+		if name == "" {
+			return emptyGroupNameErr
+		}
+		_, ok := t.groupsTab[name]
+		if ok {
+			return groupExistsErr
+		}
+
+		// Добавление группы
+		setEntry(t.db.undo, t.groupsTab, name, newGroup(models.GroupData{}))
+
+		return nil
+	})
+}
+
+/*
+Переименование группы с обновлением ссылок на нее в родительских группах, в списке групп администраторов и в настройках видимости полей
+
+:param name string: текущее название группы
+:param newName string: новое название группы
+
+:return: возвращается ошибка, если группы name не существует, новое название пустое, группа newName уже существует или базу данных не удалось сохранить
+*/
+func (t *Tenant) RenameGroup(name string, newName string) error {
+	return t.Update(func(tx *Tx) error {
+		// Проверка наличия групп
+		g, ok := t.groupsTab[name]
+		if !ok {
+			return noGroupErr
+		}
+		if newName == "" {
+			return emptyGroupNameErr
+		}
+		_, ok = t.groupsTab[newName]
+		if ok {
+			return groupExistsErr
+		}
+
+		// Перенос группы под новым названием
+		deleteEntry(t.db.undo, t.groupsTab, name)
+		setEntry(t.db.undo, t.groupsTab, newName, g)
+		for _, parent := range t.groupsTab {
+			if _, ok := parent.subgroups[name]; ok {
+				deleteEntry(t.db.undo, parent.subgroups, name)
+				setEntry(t.db.undo, parent.subgroups, newName, struct{}{})
+			}
+		}
+		if _, ok := t.adminGroupsTab[name]; ok {
+			deleteEntry(t.db.undo, t.adminGroupsTab, name)
+			setEntry(t.db.undo, t.adminGroupsTab, newName, struct{}{})
+		}
+		t.renameVisibleToGroup(name, newName)
+
+		return nil
+	})
+}
+
+/*
+Удаление группы; группа удаляется из родительских групп, из списка групп администраторов и из настроек видимости полей, вложенные группы не удаляются
+
+:param name string: название удаляемой группы
+
+:return: возвращается ошибка, если группы с названием name не существует или базу данных не удалось сохранить
+*/
+func (t *Tenant) RemoveGroup(name string) error {
+	return t.Update(func(tx *Tx) error {
+		// Проверка наличия группы с названием name
+		_, ok := t.groupsTab[name]
+		if !ok {
+			return noGroupErr
+		}
+
+		// Удаление группы из всех таблиц
+		admins := t.effectiveAdmins()
+		deleteEntry(t.db.undo, t.groupsTab, name)
+		for _, parent := range t.groupsTab {
+			deleteEntry(t.db.undo, parent.subgroups, name)
+		}
+		deleteEntry(t.db.undo, t.adminGroupsTab, name)
+		t.renameVisibleToGroup(name, "")
+		t.recordAdminChanges(admins, "")
+
+		return nil
+	})
+}
+
+/*
+Добавление профиля в группу
+
+:param name string: название группы
+:param login string: логин добавляемого профиля
+
+:return: возвращается ошибка, если группы или профиля не существует или базу данных не удалось сохранить
+*/
+func (t *Tenant) AddGroupMember(name string, login string) error {
+	return t.Update(func(tx *Tx) error {
+		return tx.AddGroupMember(name, login)
+	})
+}
+
+/*
+Добавление профиля в группу (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются)
+
+:param name string: название группы
+:param login string: логин добавляемого профиля
+
+:return: возвращается ошибка, если группы или профиля не существует
+*/
+func (t *Tenant) addGroupMember(name string, login string) error {
+	// Проверка наличия группы и профиля
+	g, ok := t.groupsTab[name]
+	if !ok {
+		return noGroupErr
+	}
+	id, ok := t.loginsTab[login]
+	if !ok {
+		return noProfileErr
+	}
+
+	// Добавление профиля в группу
+	admins := t.effectiveAdmins()
+	setEntry(t.db.undo, g.members, id, struct{}{})
+	t.recordAdminChanges(admins, "")
+	return nil
+}
+
+/*
+Удаление профиля из группы
+
+:param name string: название группы
+:param login string: логин удаляемого из группы профиля
+
+:return: возвращается ошибка, если группы не существует, профиль не входит в группу или базу данных не удалось сохранить
+*/
+func (t *Tenant) RemoveGroupMember(name string, login string) error {
+	return t.Update(func(tx *Tx) error {
+		return tx.RemoveGroupMember(name, login)
+	})
+}
+
+/*
+Удаление профиля из группы (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются)
+
+:param name string: название группы
+:param login string: логин удаляемого из группы профиля
+
+:return: возвращается ошибка, если группы не существует или профиль не входит в группу
+*/
+func (t *Tenant) removeGroupMember(name string, login string) error {
+	// Проверка наличия группы и профиля в ней
+	g, ok := t.groupsTab[name]
+	if !ok {
+		return noGroupErr
+	}
+	id := t.loginsTab[login]
+	_, ok = g.members[id]
+	if !ok {
+		return noGroupMemberErr
+	}
+
+	// Удаление профиля из группы
+	admins := t.effectiveAdmins()
+	deleteEntry(t.db.undo, g.members, id)
+	t.recordAdminChanges(admins, "")
+	return nil
+}
+
+/*
+Вложение группы в другую группу; вложение, образующее цикл, запрещено
+
+:param name string: название родительской группы
+:param subgroupName string: название вкладываемой группы
+
+:return: возвращается ошибка, если одной из групп не существует, вложение образует цикл или базу данных не удалось сохранить
+*/
+func (t *Tenant) AddSubgroup(name string, subgroupName string) error {
+	return t.Update(func(tx *Tx) error {
+		// Проверка наличия групп
+		g, ok := t.groupsTab[name]
+		if !ok {
+			return noGroupErr
+		}
+		_, ok = t.groupsTab[subgroupName]
+		if !ok {
+			return noGroupErr
+		}
+
+		// Проверка на цикл: группа name не должна совпадать с subgroupName или быть вложенной в нее
+		if name == subgroupName || t.containsSubgroup(subgroupName, name) {
+			return groupCycleErr
+		}
+
+		// Вложение группы
+		admins := t.effectiveAdmins()
+		setEntry(t.db.undo, g.subgroups, subgroupName, struct{}{})
+		t.recordAdminChanges(admins, "")
+
+		return nil
+	})
+}
+
+/*
+Удаление вложенной группы из родительской группы (сама вложенная группа не удаляется)
+
+:param name string: название родительской группы
+:param subgroupName string: название вложенной группы
+
+:return: возвращается ошибка, если группы не существует, группа subgroupName не вложена в нее или базу данных не удалось сохранить
+*/
+func (t *Tenant) RemoveSubgroup(name string, subgroupName string) error {
+	return t.Update(func(tx *Tx) error {
+		// Проверка наличия группы и вложенной группы
+		g, ok := t.groupsTab[name]
+		if !ok {
+			return noGroupErr
+		}
+		_, ok = g.subgroups[subgroupName]
+		if !ok {
+			return noSubgroupErr
+		}
+
+		// Удаление вложенной группы
+		admins := t.effectiveAdmins()
+		deleteEntry(t.db.undo, g.subgroups, subgroupName)
+		t.recordAdminChanges(admins, "")
+
+		return nil
+	})
+}
+
+/*
+Добавление группы в список групп администраторов: все участники группы и вложенных групп становятся администраторами
+
+:param name string: название группы
+
+:return: возвращается ошибка, если группы не существует или базу данных не удалось сохранить
+*/
+func (t *Tenant) AddAdminGroup(name string) error {
+	return t.Update(func(tx *Tx) error {
+		// Проверка наличия группы с названием name
+		_, ok := t.groupsTab[name]
+		if !ok {
+			return noGroupErr
+		}
+
+		// Добавление группы в список групп администраторов
+		admins := t.effectiveAdmins()
+		setEntry(t.db.undo, t.adminGroupsTab, name, struct{}{})
+		t.recordAdminChanges(admins, "")
+
+		return nil
+	})
+}
+
+/*
+Удаление группы из списка групп администраторов
+
+:param name string: название группы
+
+:return: возвращается ошибка, если группы не существует или базу данных не удалось сохранить
+*/
+func (t *Tenant) DropAdminGroup(name string) error {
+	return t.Update(func(tx *Tx) error {
+		// Проверка наличия группы с названием name
+		_, ok := t.groupsTab[name]
+		if !ok {
+			return noGroupErr
+		}
+
+		// Удаление группы из списка групп администраторов
+		admins := t.effectiveAdmins()
+		deleteEntry(t.db.undo, t.adminGroupsTab, name)
+		t.recordAdminChanges(admins, "")
+
+		return nil
+	})
+}
@@ -0,0 +1,169 @@
+package myProfilesDB
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Выполнение изменения групп и получение событий о правах администратора, записанных изменением
+
+:param t *testing.T: тест
+:param tenant *Tenant: тенант
+:param change func() error: изменение групп
+
+:return: события admin.granted и admin.revoked в виде "тип логин"
+*/
+func adminChangeEvents(t *testing.T, tenant *Tenant, change func() error) []string {
+	t.Helper()
+	_, lastSeq, _, err := tenant.WatchChanges(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = change()
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, _, _, err := tenant.WatchChanges(lastSeq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var adminEvents []string
+	for _, event := range events {
+		if event.Type == models.ChangeAdminGranted || event.Type == models.ChangeAdminRevoked {
+			adminEvents = append(adminEvents, event.Type+" "+event.Login)
+		}
+	}
+	return adminEvents
+}
+
+// Изменения групп администраторов, участников и вложенности групп записывают события для каждого профиля, права администратора которого изменились
+func TestAdminGroupChangeEvents(t *testing.T) {
+	_, platform := openTestDB(t)
+	for _, login := range []string{"alice", "bob", "carol"} {
+		addTestProfile(t, platform, login)
+	}
+	for _, err := range []error{
+		platform.AddGroup("staff"), platform.AddGroup("ops"),
+		platform.AddGroupMember("staff", "alice"), platform.AddGroupMember("ops", "bob"),
+		platform.AddAdmin("carol"), platform.AddGroupMember("staff", "carol"),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	granted, revoked := models.ChangeAdminGranted, models.ChangeAdminRevoked
+	steps := []struct {
+		name     string
+		change   func() error
+		expected []string
+	}{
+		// carol уже администратор, события о ней не записываются
+		{"add admin group", func() error { return platform.AddAdminGroup("staff") }, []string{granted + " alice"}},
+		{"add subgroup", func() error { return platform.AddSubgroup("staff", "ops") }, []string{granted + " bob"}},
+		{"add member of admin group", func() error { return platform.AddGroupMember("ops", "carol") }, nil},
+		{"remove subgroup", func() error { return platform.RemoveSubgroup("staff", "ops") }, []string{revoked + " bob"}},
+		{"remove member", func() error { return platform.RemoveGroupMember("staff", "alice") }, []string{revoked + " alice"}},
+		{"add member", func() error { return platform.AddGroupMember("staff", "bob") }, []string{granted + " bob"}},
+		{"drop admin group", func() error { return platform.DropAdminGroup("staff") }, []string{revoked + " bob"}},
+		{"remove group", func() error {
+			err := platform.AddAdminGroup("ops")
+			if err != nil {
+				return err
+			}
+			return platform.RemoveGroup("ops")
+		}, []string{granted + " bob", revoked + " bob"}},
+	}
+	for _, step := range steps {
+		events := adminChangeEvents(t, platform, step.change)
+		if fmt.Sprint(events) != fmt.Sprint(step.expected) {
+			t.Fatalf("%s: expected events %v, got %v", step.name, step.expected, events)
+		}
+	}
+
+	// Профиль из группы администраторов, восстановленный из корзины, снова получает права администратора
+	for _, err := range []error{platform.AddGroup("admins"), platform.AddAdminGroup("admins"), platform.AddGroupMember("admins", "alice")} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	id := trashTestProfile(t, platform, "alice")
+	events := adminChangeEvents(t, platform, func() error {
+		_, err := platform.RestoreProfile(id, "admin")
+		return err
+	})
+	if fmt.Sprint(events) != fmt.Sprint([]string{granted + " alice"}) {
+		t.Fatalf("restore: unexpected events %v", events)
+	}
+}
+
+// Вложение группы в саму себя и вложение, замыкающее цикл через другую группу, отклоняются
+func TestAddSubgroupRejectsCycles(t *testing.T) {
+	_, platform := openTestDB(t)
+	for _, name := range []string{"a", "b", "c"} {
+		if err := platform.AddGroup(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := platform.AddSubgroup("a", "a"); !errors.Is(err, groupCycleErr) {
+		t.Fatalf("direct cycle is not rejected: %v", err)
+	}
+	if err := platform.AddSubgroup("a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := platform.AddSubgroup("b", "a"); !errors.Is(err, groupCycleErr) {
+		t.Fatalf("indirect cycle is not rejected: %v", err)
+	}
+	if err := platform.AddSubgroup("b", "c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := platform.AddSubgroup("c", "a"); !errors.Is(err, groupCycleErr) {
+		t.Fatalf("cycle through two groups is not rejected: %v", err)
+	}
+	// Отклоненное вложение не меняет групп
+	if groupData, err := platform.GetGroupData("b"); err != nil || fmt.Sprint(groupData.Subgroups) != "[c]" {
+		t.Fatalf("unexpected subgroups of b: %v %+v", err, groupData)
+	}
+	if groupData, err := platform.GetGroupData("c"); err != nil || len(groupData.Subgroups) != 0 {
+		t.Fatalf("unexpected subgroups of c: %v %+v", err, groupData)
+	}
+}
+
+// Участник вложенной группы входит во все группы, в которые она вложена непосредственно или через другие группы, и получает права администратора группы администраторов
+func TestEffectiveMembershipThroughTwoLevels(t *testing.T) {
+	_, platform := openTestDB(t)
+	addTestProfile(t, platform, "alice")
+	for _, err := range []error{
+		platform.AddGroup("company"), platform.AddGroup("department"), platform.AddGroup("team"), platform.AddGroup("other"),
+		platform.AddSubgroup("company", "department"), platform.AddSubgroup("department", "team"),
+		platform.AddGroupMember("team", "alice"), platform.AddAdminGroup("company"),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if groups := platform.GetEffectiveGroups("alice"); fmt.Sprint(groups) != "[company department team]" {
+		t.Fatalf("unexpected effective groups: %v", groups)
+	}
+	if !platform.IsAdmin("alice") {
+		t.Fatal("member of nested admin group is not an admin")
+	}
+	if platform.IsAdminWithoutGroup("alice", "department") {
+		t.Fatal("admin rights are kept without intermediate group")
+	}
+
+	// После удаления промежуточного вложения профиль остается только в ближайших группах
+	if err := platform.RemoveSubgroup("company", "department"); err != nil {
+		t.Fatal(err)
+	}
+	if groups := platform.GetEffectiveGroups("alice"); fmt.Sprint(groups) != "[department team]" {
+		t.Fatalf("unexpected effective groups after removing subgroup: %v", groups)
+	}
+	if platform.IsAdmin("alice") {
+		t.Fatal("admin rights are kept after removing subgroup")
+	}
+}
@@ -0,0 +1,238 @@
+package myProfilesDB
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Запись текущих данных профиля в историю изменений профиля; история хранит не больше db.profileHistorySize последних записей
+
+:param id string: идентификатор профиля
+:param author string: логин пользователя, выполнившего изменение (пустая строка - изменение выполнено сервисом)
+:param action string: действие с профилем
+:param version int64: версия профиля после изменения
+*/
+func (t *Tenant) recordProfileHistory(id string, author string, action string, version int64) {
+	// Копия данных профиля: запись истории не разделяет таблицу атрибутов с текущими данными профиля
+	profileData := t.profilesDataTab[id]
+	if profileData.Attributes != nil {
+		attributes := make(map[string]interface{}, len(profileData.Attributes))
+		for name, value := range profileData.Attributes {
+			attributes[name] = value
+		}
+		profileData.Attributes = attributes
+	}
+	history := append(t.profilesHistoryTab[id], models.ProfileHistoryEntry{
+		Version:   version,
+		ChangedAt: time.Now().Unix(),
+		ChangedBy: author,
+		Action:    action,
+		Profile:   &profileData,
+	})
+	if extra := len(history) - t.db.profileHistorySize; extra > 0 {
+		history = append([]models.ProfileHistoryEntry(nil), history[extra:]...)
+	}
+	setEntry(t.db.undo, t.profilesHistoryTab, id, history)
+
+	// Событие в журнале изменений профилей (начальная запись истории при чтении файла изменением не является)
+	switch action {
+	case models.HistoryActionCreate, models.HistoryActionRestore:
+		t.recordChange(models.ChangeProfileCreated, id, version, author)
+	case models.HistoryActionDelete:
+		t.recordChange(models.ChangeProfileDeleted, id, version, author)
+	case models.HistoryActionImport:
+	default:
+		t.recordChange(models.ChangeProfileUpdated, id, version, author)
+	}
+}
+
+/*
+Поиск записи истории профиля по версии или по времени
+
+:param id string: идентификатор профиля
+:param version int64: версия профиля (0 - запись ищется по времени)
+:param at int64: время (unix-время в секундах): выбирается последняя запись, сделанная не позже этого времени
+
+:return: запись истории или ошибка, если подходящей записи нет
+*/
+func (t *Tenant) profileHistoryEntry(id string, version int64, at int64) (models.ProfileHistoryEntry, error) {
+	history := t.profilesHistoryTab[id]
+	for i := len(history) - 1; i >= 0; i-- {
+		if (version != 0 && history[i].Version == version) || (version == 0 && history[i].ChangedAt <= at) {
+			return history[i], nil
+		}
+	}
+	return models.ProfileHistoryEntry{}, noHistoryEntryErr
+}
+
+/*
+Получить историю изменений профиля
+
+:param login string: логин профиля
+
+:return: записи истории профиля в порядке изменений (с данными профиля после каждого изменения) или ошибка, если профиля с логином login нет
+*/
+func (t *Tenant) GetProfileHistory(login string) ([]models.ProfileHistoryEntry, error) {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	id, ok := t.loginsTab[login]
+	if !ok {
+		return nil, noProfileErr
+	}
+	return append([]models.ProfileHistoryEntry(nil), t.profilesHistoryTab[id]...), nil
+}
+
+/*
+Получить данные профиля на момент одной из предыдущих версий или на заданное время
+
+:param login string: логин профиля
+:param version int64: версия профиля (0 - данные ищутся по времени)
+:param at int64: время (unix-время в секундах)
+
+:return: запись истории профиля с данными профиля или ошибка, если профиля с логином login нет или в истории нет подходящей записи
+*/
+func (t *Tenant) GetProfileAsOf(login string, version int64, at int64) (models.ProfileHistoryEntry, error) {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	id, ok := t.loginsTab[login]
+	if !ok {
+		return models.ProfileHistoryEntry{}, noProfileErr
+	}
+	return t.profileHistoryEntry(id, version, at)
+}
+
+/*
+Возврат данных профиля (имени, фамилии, адреса электронной почты и атрибутов) к одной из предыдущих версий или к данным на заданное время; логин и идентификатор профиля не меняются,
+возврат записывается в историю как новое изменение
+
+:param login string: логин профиля
+:param version int64: версия профиля, к которой возвращаются данные (0 - данные ищутся по времени)
+:param at int64: время (unix-время в секундах)
+:param author string: логин пользователя, выполняющего возврат
+:param expectedVersion int64: ожидаемая текущая версия профиля (0 - версия не проверяется)
+
+:return: новая версия профиля; возвращается ошибка, если профиль с логином login не существует, в истории нет подходящей записи, версия профиля не совпадает с ожидаемой,
+атрибуты не соответствуют текущей схеме атрибутов или базу данных не удалось сохранить
+*/
+func (t *Tenant) RevertProfile(login string, version int64, at int64, author string, expectedVersion int64) (newVersion int64, err error) {
+	err = t.Update(func(tx *Tx) error {
+		newVersion, err = tx.RevertProfile(login, version, at, author, expectedVersion)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return newVersion, nil
+}
+
+/*
+Возврат данных профиля (имени, фамилии, адреса электронной почты и атрибутов) к одной из предыдущих версий или к данным на заданное время; логин и идентификатор профиля не меняются,
+возврат записывается в историю как новое изменение (в транзакции)
+
+:param login string: логин профиля
+:param version int64: версия профиля, к которой возвращаются данные (0 - данные ищутся по времени)
+:param at int64: время (unix-время в секундах)
+:param author string: логин пользователя, выполняющего возврат
+:param expectedVersion int64: ожидаемая текущая версия профиля (0 - версия не проверяется)
+
+:return: новая версия профиля; возвращается ошибка, если транзакция только для чтения, профиль с логином login не существует, в истории нет подходящей записи,
+версия профиля не совпадает с ожидаемой или атрибуты не соответствуют текущей схеме атрибутов
+*/
+func (tx *Tx) RevertProfile(login string, version int64, at int64, author string, expectedVersion int64) (int64, error) {
+	err := tx.checkWritable()
+	if err != nil {
+		return 0, err
+	}
+	t := tx.t
+
+	// Проверка наличия профиля с логином login, его версии и записи истории
+	id, ok := t.loginsTab[login]
+	if !ok {
+		return 0, noProfileErr
+	}
+	err = t.checkProfileVersion(id, expectedVersion)
+	if err != nil {
+		return 0, err
+	}
+	entry, err := t.profileHistoryEntry(id, version, at)
+	if err != nil {
+		return 0, err
+	}
+
+	// Составление и проверка данных профиля (атрибуты проверяются по текущей схеме атрибутов тенанта)
+	profileData := t.profilesDataTab[id]
+	profileData.FirstName = entry.Profile.FirstName
+	profileData.LastName = entry.Profile.LastName
+	profileData.Email = entry.Profile.Email
+	profileData.Attributes = nil
+	if entry.Profile.Attributes != nil {
+		profileData.Attributes = make(map[string]interface{}, len(entry.Profile.Attributes))
+		for name, value := range entry.Profile.Attributes {
+			profileData.Attributes[name] = value
+		}
+	}
+	err = t.validateAttributes(id, profileData.Attributes)
+	if err != nil {
+		return 0, err
+	}
+
+	// Запись данных профиля (возвращенный адрес электронной почты, отличный от текущего, ожидает подтверждения)
+	t.applyEmailChange(id, t.profilesDataTab[id], &profileData)
+	setEntry(t.db.undo, t.profilesDataTab, id, profileData)
+	newVersion := t.nextProfileVersion(id)
+	t.recordProfileHistory(id, author, models.HistoryActionRevert, newVersion)
+	return newVersion, nil
+}
+
+/*
+Составление списка изменений полей профиля
+
+:param oldProfileData models.ProfileData: данные профиля до изменения
+:param newProfileData models.ProfileData: данные профиля после изменения
+
+:return: список изменившихся полей (дополнительные атрибуты отсортированы по названию)
+*/
+func ProfileChanges(oldProfileData models.ProfileData, newProfileData models.ProfileData) []models.FieldChange {
+	var changes []models.FieldChange
+	addChange := func(field string, oldValue interface{}, newValue interface{}) {
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, models.FieldChange{Field: field, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	// Пустые строки не выводятся в изменениях
+	nonEmpty := func(s string) interface{} {
+		if s == "" {
+			return nil
+		}
+		return s
+	}
+	addChange("login", nonEmpty(oldProfileData.Login), nonEmpty(newProfileData.Login))
+	addChange(models.FieldFirstName, nonEmpty(oldProfileData.FirstName), nonEmpty(newProfileData.FirstName))
+	addChange(models.FieldLastName, nonEmpty(oldProfileData.LastName), nonEmpty(newProfileData.LastName))
+	addChange(models.FieldEmail, nonEmpty(oldProfileData.Email), nonEmpty(newProfileData.Email))
+	addChange("pendingEmail", nonEmpty(oldProfileData.PendingEmail), nonEmpty(newProfileData.PendingEmail))
+	if oldProfileData.VerifiedAt != newProfileData.VerifiedAt {
+		changes = append(changes, models.FieldChange{Field: "verifiedAt", OldValue: oldProfileData.VerifiedAt, NewValue: newProfileData.VerifiedAt})
+	}
+
+	names := make([]string, 0, len(oldProfileData.Attributes)+len(newProfileData.Attributes))
+	for name := range oldProfileData.Attributes {
+		names = append(names, name)
+	}
+	for name := range newProfileData.Attributes {
+		if _, ok := oldProfileData.Attributes[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		addChange("attributes."+name, oldProfileData.Attributes[name], newProfileData.Attributes[name])
+	}
+	return changes
+}
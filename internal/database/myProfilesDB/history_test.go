@@ -0,0 +1,36 @@
+package myProfilesDB
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// История изменений профиля хранит не больше заданного количества последних записей
+func TestProfileHistoryRetention(t *testing.T) {
+	db, platform := openTestDB(t)
+	db.mu.Lock()
+	db.profileHistorySize = 3
+	db.mu.Unlock()
+	addTestProfile(t, platform, "alice")
+	var lastVersion int64
+	for _, firstName := range []string{"A", "B", "C", "D", "E"} {
+		version, err := platform.EditProfile("alice", models.ProfileData{FirstName: firstName, LastName: "User"}, 0, "admin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		lastVersion = version
+	}
+
+	history, err := platform.GetProfileHistory("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 || history[2].Version != lastVersion || history[0].Profile.FirstName != "C" {
+		t.Fatalf("unexpected profile history: %+v", history)
+	}
+	if _, err = platform.GetProfileAsOf("alice", history[0].Version-1, 0); !errors.Is(err, noHistoryEntryErr) {
+		t.Fatalf("pruned history entry is kept: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package myProfilesDB
+
+import (
+	"github.com/google/uuid"
+)
+
+/*
+Генерация нового идентификатора профиля
+
+:return: случайный UUID
+*/
+func newProfileID() string {
+	return uuid.NewString()
+}
+
+/*
+Получение идентификатора профиля по ссылке на профиль из файла: в файлах, сохраненных до появления идентификаторов, на профили ссылаются по логинам
+
+:param ref string: идентификатор или логин профиля
+
+:return: идентификатор профиля и true или false, если профиля не существует
+*/
+func (t *Tenant) profileIDByRef(ref string) (string, bool) {
+	if _, ok := t.profilesDataTab[ref]; ok {
+		return ref, true
+	}
+	id, ok := t.loginsTab[ref]
+	return id, ok
+}
+
+/*
+Получение логина профиля по идентификатору
+
+:param id string: идентификатор профиля
+
+:return: логин профиля или ошибка, если профиля с таким идентификатором нет
+*/
+func (t *Tenant) GetLoginByID(id string) (string, error) {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	profileData, ok := t.profilesDataTab[id]
+	if !ok {
+		return "", noProfileErr
+	}
+	return profileData.Login, nil
+}
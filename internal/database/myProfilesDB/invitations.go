@@ -0,0 +1,199 @@
+package myProfilesDB
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Создание приглашения: создается профиль, ожидающий активации, без пароля с правами администратора и членством в группах из приглашения,
+приглашенный пользователь задает пароль по одноразовому токену; заодно удаляются истекшие приглашения
+
+:param invitationData models.InvitationData: данные приглашения
+:param author string: логин пользователя, создающего приглашение
+
+:return: токен приглашения (в БД хранится только sha256 от токена) и приглашение или ошибка, если не заданы ни логин, ни адрес электронной почты,
+время истечения прошло, одной из групп не существует, профиль с таким логином уже существует, токен не удалось сгенерировать или базу данных не удалось сохранить
+*/
+func (t *Tenant) CreateInvitation(invitationData models.InvitationData, author string) (token string, invitation models.Invitation, err error) {
+	err = t.Update(func(tx *Tx) error {
+		// Проверка данных приглашения
+		login := invitationData.Login
+		if login == "" {
+			login = invitationData.Email
+		}
+		if login == "" {
+			return emptyLoginErr
+		}
+		now := time.Now().Unix()
+		expiresAt := invitationData.ExpiresAt
+		if expiresAt == 0 {
+			expiresAt = now + t.db.invitationLifetime
+		}
+		if expiresAt <= now {
+			return invalidInvitationExpiryErr
+		}
+		for _, groupName := range invitationData.Groups {
+			if _, ok := t.groupsTab[groupName]; !ok {
+				return fmt.Errorf("%w \"%s\"", noGroupErr, groupName)
+			}
+		}
+
+		// Генерация токена приглашения
+		token, err = generateToken()
+		if err != nil {
+			return err
+		}
+
+		// Создание профиля, ожидающего активации, с правами из приглашения
+		id, err := t.addProfile(login, models.ProfileData{Email: invitationData.Email}, "", models.AccountStatusPending, author)
+		if err != nil {
+			return err
+		}
+		if invitationData.Admin {
+			setEntry(t.db.undo, t.adminsTab, id, struct{}{})
+			t.recordChange(models.ChangeAdminGranted, id, 0, author)
+		}
+		for _, groupName := range invitationData.Groups {
+			setEntry(t.db.undo, t.groupsTab[groupName].members, id, struct{}{})
+		}
+
+		// Удаление истекших приглашений и запись приглашения
+		for key, invitation := range t.invitationsTab {
+			if invitation.ExpiresAt <= now {
+				deleteEntry(t.db.undo, t.invitationsTab, key)
+			}
+		}
+		invitation = models.Invitation{
+			ID:        uuid.NewString(),
+			ProfileID: id,
+			Login:     login,
+			Email:     invitationData.Email,
+			Admin:     invitationData.Admin,
+			Groups:    invitationData.Groups,
+			CreatedBy: author,
+			CreatedAt: now,
+			ExpiresAt: expiresAt,
+		}
+		setEntry(t.db.undo, t.invitationsTab, tokenKey(token), invitation)
+
+		return nil
+	})
+	if err != nil {
+		return "", models.Invitation{}, err
+	}
+	return
+}
+
+/*
+Получить список приглашений тенанта, которые еще не приняты (включая истекшие)
+
+:return: список приглашений, отсортированный по времени создания
+*/
+func (t *Tenant) GetInvitations() []models.Invitation {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	invitations := make([]models.Invitation, 0, len(t.invitationsTab))
+	for _, invitation := range t.invitationsTab {
+		invitations = append(invitations, invitation)
+	}
+	sort.Slice(invitations, func(i, j int) bool {
+		if invitations[i].CreatedAt != invitations[j].CreatedAt {
+			return invitations[i].CreatedAt < invitations[j].CreatedAt
+		}
+		return invitations[i].ID < invitations[j].ID
+	})
+	return invitations
+}
+
+/*
+Отзыв приглашения: приглашение удаляется, а профиль приглашенного пользователя, если он все еще ожидает активации, удаляется окончательно
+
+:param id string: идентификатор приглашения
+
+:return: возвращается ошибка, если приглашения не существует или базу данных не удалось сохранить
+*/
+func (t *Tenant) RevokeInvitation(id string) error {
+	return t.Update(func(tx *Tx) error {
+		// Поиск приглашения
+		for key, invitation := range t.invitationsTab {
+			if invitation.ID != id {
+				continue
+			}
+
+			// Удаление приглашения и профиля, ожидающего активации
+			deleteEntry(t.db.undo, t.invitationsTab, key)
+			if _, ok := t.profilesDataTab[invitation.ProfileID]; ok && t.accountStatus(invitation.ProfileID).Status == models.AccountStatusPending {
+				t.detachProfile(invitation.ProfileID, "")
+				t.eraseProfile(invitation.ProfileID)
+			}
+			return nil
+		}
+		return noInvitationErr
+	})
+}
+
+/*
+Принятие приглашения по одноразовому токену: приглашенный пользователь задает пароль (и, при необходимости, имя и фамилию), учетная запись становится активной,
+приглашение удаляется
+
+:param token string: токен приглашения
+:param password string: пароль приглашенного пользователя (должен быть проверен по политике паролей до вызова)
+:param firstName string: имя пользователя (пустая строка - не меняется)
+:param lastName string: фамилия пользователя (пустая строка - не меняется)
+
+:return: логин профиля приглашенного пользователя или ошибка, если приглашения с таким токеном нет, оно истекло, профиль больше не ожидает активации,
+неподходящий пароль или базу данных не удалось сохранить
+*/
+func (t *Tenant) AcceptInvitation(token string, password string, firstName string, lastName string) (login string, err error) {
+	err = t.Update(func(tx *Tx) error {
+		// Поиск действующего приглашения и профиля, ожидающего активации
+		key := tokenKey(token)
+		invitation, ok := t.invitationsTab[key]
+		if !ok || invitation.ExpiresAt <= time.Now().Unix() {
+			return noInvitationErr
+		}
+		id := invitation.ProfileID
+		profileData, ok := t.profilesDataTab[id]
+		if !ok || t.accountStatus(id).Status != models.AccountStatusPending {
+			return noInvitationErr
+		}
+
+		// Генерация хэша и соли пароля
+		passwordHashSalt, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return incorrectPasswordErr
+		}
+
+		// Запись имени и фамилии, пароля и активация учетной записи
+		if (firstName != "" && firstName != profileData.FirstName) || (lastName != "" && lastName != profileData.LastName) {
+			if firstName != "" {
+				profileData.FirstName = firstName
+			}
+			if lastName != "" {
+				profileData.LastName = lastName
+			}
+			setEntry(t.db.undo, t.profilesDataTab, id, profileData)
+			t.recordProfileHistory(id, profileData.Login, models.HistoryActionEdit, t.nextProfileVersion(id))
+		}
+		setEntry(t.db.undo, t.profilesPasswordsTab, id, string(passwordHashSalt))
+		t.recordChange(models.ChangePasswordChanged, id, 0, profileData.Login)
+		setEntry(t.db.undo, t.accountsStatusTab, id, models.AccountStatus{Status: models.AccountStatusActive, ChangedAt: time.Now().Unix(), ExpiresAt: t.accountsStatusTab[id].ExpiresAt})
+		t.recordChange(models.ChangeAccountActivated, id, 0, profileData.Login)
+		deleteEntry(t.db.undo, t.invitationsTab, key)
+
+		login = profileData.Login
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return
+}
@@ -0,0 +1,63 @@
+package myProfilesDB
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+	"github.com/ZotovSergey/authenticationservice/internal/testutil"
+	"github.com/ZotovSergey/authenticationservice/internal/webhooks"
+)
+
+// Изменения конфигов репозитория для тестов: короткие задержки доставки событий и доставка на адрес httptest-сервера (127.0.0.1)
+var testConfigs = map[string]map[string]interface{}{
+	"webhooksConfig.json": {
+		"timeout":                 2,
+		"maxAttempts":             3,
+		"initialBackoff":          1,
+		"maxBackoff":              2,
+		"allowPrivateTargets":     true,
+		"privateTargetsAllowlist": []string{"127.0.0.0/8"},
+	},
+}
+
+// Тесты выполняются из временного каталога с конфигами репозитория, измененными testConfigs
+func TestMain(m *testing.M) {
+	os.Exit(testutil.RunWithConfigs(m, "../../../configs", testConfigs, webhooks.Configure))
+}
+
+/*
+Создание БД с временным файлом базы данных (в БД есть тенант платформы с администратором по умолчанию)
+
+:param t *testing.T: тест
+
+:return: БД и тенант платформы
+*/
+func openTestDB(t *testing.T) (*myProfilesDB, *Tenant) {
+	t.Helper()
+	db, err := OpenMyProfilesDB(filepath.Join(t.TempDir(), "db.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	platform, err := db.GetTenant(db.PlatformTenant())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, platform
+}
+
+/*
+Добавление активного профиля в тенант
+
+:param t *testing.T: тест
+:param tenant *Tenant: тенант
+:param login string: логин профиля
+*/
+func addTestProfile(t *testing.T, tenant *Tenant, login string) {
+	t.Helper()
+	err := tenant.AddProfile(login, models.ProfileData{Login: login, FirstName: "Test", LastName: "User"}, "Passw0rd!x", models.AccountStatusActive, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,128 @@
+package myProfilesDB
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Миграция файла базы данных с одной версии схемы на следующую: изменяет json-документ базы данных, разобранный до таблиц верхнего уровня
+type migration struct {
+	description string                                                                 // описание миграции (выводится в лог)
+	migrate     func(fileData map[string]json.RawMessage, platformTenant string) error // функция миграции
+}
+
+// Реестр миграций: миграция migrations[i] переводит файл базы данных с версии схемы i на версию i+1 (файлы без версии имеют версию 0);
+// новые миграции добавляются только в конец списка
+var migrations = []migration{
+	{"move tables of database saved before tenants to platform tenant", migrateToTenants},
+}
+
+// Текущая версия схемы файла базы данных
+var schemaVersion = len(migrations)
+
+/*
+Приведение содержимого файла базы данных к текущей версии схемы: к файлу последовательно применяются миграции, начиная с его версии
+
+:param byteValue []byte: расшифрованное содержимое файла базы данных
+:param platformTenant string: название тенанта платформы
+
+:return: содержимое файла в текущей версии схемы и исходная версия схемы файла или ошибка, если содержимое файла не является json-объектом,
+версия схемы файла новее текущей или миграция не удалась
+*/
+func migrateFileData(byteValue []byte, platformTenant string) ([]byte, int, error) {
+	var fileData map[string]json.RawMessage
+	err := json.Unmarshal(byteValue, &fileData)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %s", invalidDumpErr, err.Error())
+	}
+	if fileData == nil {
+		return nil, 0, fmt.Errorf("%w: database dump is not a json object", invalidDumpErr)
+	}
+
+	// Чтение версии схемы файла
+	var version int
+	if rawVersion, ok := fileData["schemaVersion"]; ok {
+		err = json.Unmarshal(rawVersion, &version)
+		if err != nil || version < 0 {
+			return nil, 0, fmt.Errorf("%w: invalid schema version %s", invalidDumpErr, string(rawVersion))
+		}
+	}
+	if version > schemaVersion {
+		return nil, version, fmt.Errorf("%w: schema version %d, supported version %d", unsupportedSchemaVersionErr, version, schemaVersion)
+	}
+	if version == schemaVersion {
+		return byteValue, version, nil
+	}
+
+	// Применение миграций
+	for v := version; v < schemaVersion; v++ {
+		err = migrations[v].migrate(fileData, platformTenant)
+		if err != nil {
+			return nil, version, fmt.Errorf("%w: migration to schema version %d (%s): %s", invalidDumpErr, v+1, migrations[v].description, err.Error())
+		}
+	}
+	fileData["schemaVersion"], _ = json.Marshal(schemaVersion)
+	byteValue, err = json.Marshal(fileData)
+	if err != nil {
+		return nil, version, err
+	}
+	return byteValue, version, nil
+}
+
+/*
+Сохранение копии файла базы данных перед миграцией рядом с файлом базы данных (файл копируется как есть, в том числе зашифрованным)
+
+:param dataFilePath string: путь к файлу базы данных
+:param version int: версия схемы файла
+
+:return: путь к копии файла или ошибка, если файл не удалось прочитать или копию не удалось записать
+*/
+func backupBeforeMigration(dataFilePath string, version int) (string, error) {
+	byteValue, err := os.ReadFile(dataFilePath)
+	if err != nil {
+		return "", err
+	}
+	backupPath := fmt.Sprintf("%s.v%d-%s%s", dataFilePath, version, time.Now().UTC().Format(backupTimeLayout), backupFileSuffix)
+	err = writeDumpFile(backupPath, byteValue, nil)
+	if err != nil {
+		return "", err
+	}
+	return backupPath, nil
+}
+
+/*
+Миграция 0 -> 1: в файлах, сохраненных до появления тенантов, таблицы профилей хранятся на верхнем уровне - они переносятся в тенант платформы,
+а админы становятся админами платформы
+
+:param fileData map[string]json.RawMessage: таблицы верхнего уровня файла базы данных
+:param platformTenant string: название тенанта платформы
+
+:return: ошибка, если таблицы не удалось перенести
+*/
+func migrateToTenants(fileData map[string]json.RawMessage, platformTenant string) error {
+	if _, ok := fileData["tenantsTab"]; ok {
+		return nil
+	}
+
+	// Таблицы, относящиеся ко всей БД, остаются на верхнем уровне, остальные переносятся в тенант платформы
+	legacyTenantData := make(map[string]json.RawMessage)
+	for key, value := range fileData {
+		switch key {
+		case "clientsDataTab", "clientsSecretsTab", "tokensTab", "schemaVersion":
+		default:
+			legacyTenantData[key] = value
+			delete(fileData, key)
+		}
+	}
+	tenantsTab, err := json.Marshal(map[string]map[string]json.RawMessage{platformTenant: legacyTenantData})
+	if err != nil {
+		return err
+	}
+	fileData["tenantsTab"] = tenantsTab
+	if adminsTab, ok := legacyTenantData["adminsTab"]; ok {
+		fileData["platformAdminsTab"] = adminsTab
+	}
+	return nil
+}
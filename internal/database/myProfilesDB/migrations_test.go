@@ -0,0 +1,161 @@
+package myProfilesDB
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Файл базы данных, сохраненный до появления тенантов (версия схемы 0): профили по логинам, пароли, админы сервиса
+// (путь определяется до перехода тестов во временный каталог)
+var preTenantDumpPath, _ = filepath.Abs("testdata/preTenantDump.json")
+
+/*
+Копирование файла базы данных из testdata во временный каталог теста
+
+:param t *testing.T: тест
+:param fixturePath string: путь к файлу в testdata
+
+:return: путь к копии файла и содержимое файла
+*/
+func copyTestDump(t *testing.T, fixturePath string) (string, []byte) {
+	t.Helper()
+	data, err := ioutil.ReadFile(fixturePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "db.json")
+	err = ioutil.WriteFile(path, data, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, data
+}
+
+// Таблицы файла до появления тенантов переносятся в тенант платформы, админы сервиса становятся админами платформы
+func TestMigrateToTenants(t *testing.T) {
+	data, err := ioutil.ReadFile(preTenantDumpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrated, version, err := migrateFileData(data, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 0 {
+		t.Fatalf("unexpected source schema version %d", version)
+	}
+	var fileData struct {
+		SchemaVersion     int                                   `json:"schemaVersion"`
+		TenantsTab        map[string]map[string]json.RawMessage `json:"tenantsTab"`
+		PlatformAdminsTab []string                              `json:"platformAdminsTab"`
+		AdminsTab         []string                              `json:"adminsTab"`
+		ProfilesDataTab   json.RawMessage                       `json:"profilesDataTab"`
+	}
+	err = json.Unmarshal(migrated, &fileData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileData.SchemaVersion != 1 || fileData.AdminsTab != nil || fileData.ProfilesDataTab != nil {
+		t.Fatalf("tables are not moved to tenants: %s", migrated)
+	}
+	if len(fileData.PlatformAdminsTab) != 1 || fileData.PlatformAdminsTab[0] != "admin" {
+		t.Fatalf("admins are not moved to platform admins: %v", fileData.PlatformAdminsTab)
+	}
+	platform, ok := fileData.TenantsTab["default"]
+	if len(fileData.TenantsTab) != 1 || !ok {
+		t.Fatalf("tables are not moved to platform tenant: %s", migrated)
+	}
+	tables := make([]string, 0, len(platform))
+	for name := range platform {
+		tables = append(tables, name)
+	}
+	sort.Strings(tables)
+	if len(tables) != 3 || tables[0] != "adminsTab" || tables[1] != "profilesDataTab" || tables[2] != "profilesPasswordsTab" {
+		t.Fatalf("unexpected tables of platform tenant: %v", tables)
+	}
+
+	// Файл текущей версии схемы не меняется
+	again, version, err := migrateFileData(migrated, "default")
+	if err != nil || version != 1 || !bytes.Equal(again, migrated) {
+		t.Fatalf("file of current schema version is changed: %v %d", err, version)
+	}
+}
+
+// При загрузке файла предыдущей версии схемы исходный файл сохраняется рядом, а файл перезаписывается в текущей версии
+func TestOpenMigratesDump(t *testing.T) {
+	path, original := copyTestDump(t, preTenantDumpPath)
+	db, err := OpenMyProfilesDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Копия исходного файла
+	backups, err := filepath.Glob(path + ".v0-*" + backupFileSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("unexpected backups before migration: %v", backups)
+	}
+	if backup, err := ioutil.ReadFile(backups[0]); err != nil || !bytes.Equal(backup, original) {
+		t.Fatalf("backup differs from original dump: %v", err)
+	}
+
+	// Перезаписанный файл и данные БД
+	rewritten, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fileData myProfilesDBFileData
+	if err = json.Unmarshal(rewritten, &fileData); err != nil || fileData.SchemaVersion != schemaVersion {
+		t.Fatalf("dump is not rewritten in current schema version: %v %d", err, fileData.SchemaVersion)
+	}
+	if !db.IsPlatformAdmin("admin") {
+		t.Fatal("admin is not a platform admin after migration")
+	}
+	platform, err := db.GetTenant("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	profileData, _, err := platform.GetProfileData("alice")
+	if err != nil || profileData.ID == "" || profileData.FirstName != "Alice" {
+		t.Fatalf("profile is not migrated: %v %+v", err, profileData)
+	}
+	passwordHashSalt, err := platform.GetPasswordHashSalt("alice")
+	if err != nil || bcrypt.CompareHashAndPassword([]byte(passwordHashSalt), []byte("Passw0rd!x")) != nil {
+		t.Fatalf("password is not migrated: %v", err)
+	}
+	if !platform.IsAdmin("admin") || platform.IsAdmin("alice") {
+		t.Fatal("tenant admins are not migrated")
+	}
+}
+
+// Файл более новой версии схемы не загружается и не изменяется
+func TestOpenRejectsNewerSchemaVersion(t *testing.T) {
+	newer, _ := json.Marshal(map[string]interface{}{"schemaVersion": schemaVersion + 1, "tenantsTab": map[string]interface{}{}})
+	if _, _, err := migrateFileData(newer, "default"); !errors.Is(err, unsupportedSchemaVersionErr) {
+		t.Fatalf("newer schema version is migrated: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "db.json")
+	err := ioutil.WriteFile(path, newer, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = OpenMyProfilesDB(path); !errors.Is(err, unsupportedSchemaVersionErr) {
+		t.Fatalf("dump of newer schema version is loaded: %v", err)
+	}
+	if data, err := ioutil.ReadFile(path); err != nil || !bytes.Equal(data, newer) {
+		t.Fatalf("dump of newer schema version is changed: %v", err)
+	}
+	if backups, _ := filepath.Glob(path + ".v*"); len(backups) != 0 {
+		t.Fatalf("backup is written for dump of newer schema version: %v", backups)
+	}
+}
@@ -3,10 +3,13 @@ package myProfilesDB
 import (
 	"encoding/json"
 	"errors"
-	"io/ioutil"
+	"fmt"
+	"log"
 	"os"
+	"sync"
+	"time"
 
-	"golang.org/x/crypto/bcrypt"
+	"github.com/google/uuid"
 
 	"github.com/ZotovSergey/authenticationservice/internal/models"
 )
@@ -16,17 +19,209 @@ var DB *myProfilesDB
 
 // Структура in memory базы данных профилей
 type myProfilesDB struct {
-	profilesDataTab      map[string]models.ProfileData // таблица данных профиля
-	profilesPasswordsTab map[string]string             // таблица зашифрованных паролей хэш+соль паролей профилей - используется для безопасного хранения паролей в зашифрованном виде)
-	adminsTab            map[string]struct{}           // таблица админов сервиса
-	dumpFilePath         string                        // путь к файлу с данными из базы на диске (из него данные для заполнения читаются и в него сохраняются)
+	tenantsTab           map[string]*Tenant                // таблица тенантов (организаций), у каждого из которых свои профили, админы и группы
+	platformAdminsTab    map[string]struct{}               // таблица админов платформы - идентификаторов профилей тенанта платформы, управляющих всеми тенантами
+	clientsDataTab       map[string]models.OAuthClientData // таблица данных клиентов OAuth 2.0
+	clientsSecretsTab    map[string]string                 // таблица зашифрованных секретов клиентов OAuth 2.0 (хэш+соль)
+	tokensTab            map[string]models.OAuthTokenData  // таблица выданных токенов доступа (ключ - sha256 от токена, сами токены не хранятся)
+	platformTenant       string                            // название тенанта платформы, в котором хранятся профили админов платформы
+	dumpFilePath         string                            // путь к файлу с данными из базы на диске (из него данные для заполнения читаются и в него сохраняются)
+	keys                 *keyring                          // мастер-ключи шифрования файла базы данных (nil - файл не шифруется)
+	accessTokenLifetime  int64                             // время жизни выдаваемых токенов доступа в секундах
+	loginAliasLifetime   int64                             // время жизни псевдонимов старых логинов переименованных профилей в секундах
+	invitationLifetime   int64                             // время жизни приглашений по умолчанию в секундах
+	verificationLifetime int64                             // время жизни токенов подтверждения адресов электронной почты в секундах
+	deletedRetention     int64                             // время хранения удаленных профилей в корзине в секундах (после него профили удаляются окончательно)
+	purgeInterval        int64                             // период проверки корзины на профили с истекшим временем хранения в секундах
+	backupDir            string                            // каталог резервных копий, создаваемых по расписанию
+	backupInterval       int64                             // период создания резервных копий по расписанию в секундах (0 - копии по расписанию не создаются)
+	backupRetention      int                               // количество хранимых резервных копий, создаваемых по расписанию
+	changeLogSize        int                               // количество хранимых событий в журнале изменений профилей каждого тенанта
+	changed              chan struct{}                     // канал, закрываемый при фиксации изменений профилей (ожидающие события получают уведомление), после чего заменяется новым
+	webhookHistorySize   int                               // количество хранимых завершенных доставок каждого вебхука и недоставленных событий каждого тенанта
+	webhooksWake         chan struct{}                     // канал пробуждения фоновой доставки событий вебхуков (появились новые доставки)
+	profileHistorySize   int                               // количество хранимых последних записей истории изменений каждого профиля
+	follower             bool                              // true - БД ведомого экземпляра: данные только читаются и заменяются записями журнала изменений ведущего экземпляра, файл не сохраняется
+	replicationEpoch     string                            // эпоха журнала изменений БД (генерируется при запуске ведущего экземпляра, ведомый получает ее от ведущего)
+	replicationSeq       int64                             // номер последней записи журнала изменений БД (для ведомого - последней примененной записи)
+	replicationLog       []models.ReplicationEntry         // журнал изменений БД - последние записи в порядке номеров (не больше replicationLogSize)
+	replicationLogSize   int                               // количество хранимых записей журнала изменений БД
+	replicated           chan struct{}                     // канал, закрываемый при добавлении записи в журнал изменений БД, после чего заменяется новым
+	dirtyTenants         map[string]struct{}               // тенанты, измененные с последнего сохранения БД (nil - измененные тенанты не известны, в журнал записываются все тенанты)
+	erasedProfiles       bool                              // true - с последнего сохранения БД профили удалены окончательно: после сохранения в журнале изменений БД остается только новая запись
+	cluster              ClusterLog                        // журнал Raft узла кластера (nil - БД не входит в кластер)
+	clusterFSM           *clusterFSM                       // конечный автомат Raft узла кластера
+	outbox               []func()                          // письма, отложенные до фиксации пакета операций (nil - письма отправляются сразу)
+	undo                 *undoLog                          // журнал отмены выполняемой транзакции (nil - транзакция не выполняется, изменения не записываются)
+	mu                   sync.RWMutex                      // блокировка БД: методы, изменяющие данные, выполняются атомарно и не пересекаются с чтением
+}
+
+// Структура данных тенанта in memory базы данных: первичным ключом профилей является неизменяемый идентификатор, логин уникален только в пределах тенанта
+type Tenant struct {
+	name                 string                                  // название тенанта
+	db                   *myProfilesDB                           // база данных, в которую входит тенант (используется для сохранения данных)
+	profilesDataTab      map[string]models.ProfileData           // таблица данных профиля по идентификаторам
+	loginsTab            map[string]string                       // индекс идентификаторов профилей по логинам (не хранится в файле, строится по данным профилей)
+	profilesPasswordsTab map[string]string                       // таблица зашифрованных паролей хэш+соль паролей профилей - используется для безопасного хранения паролей в зашифрованном виде)
+	adminsTab            map[string]struct{}                     // таблица админов тенанта (идентификаторов профилей)
+	groupsTab            map[string]*group                       // таблица групп профилей
+	adminGroupsTab       map[string]struct{}                     // таблица групп, все участники которых являются админами тенанта
+	attributesSchemaTab  map[string]models.AttributeDefinition   // схема дополнительных атрибутов профилей тенанта
+	fieldsVisibilityTab  map[string]models.FieldVisibility       // настройки видимости основных полей профилей тенанта (видимость атрибутов хранится в схеме)
+	profilesVersionsTab  map[string]int64                        // таблица версий профилей (версия увеличивается при каждом изменении данных профиля)
+	lastProfileVersion   int64                                   // последняя выданная версия профиля тенанта (версии не повторяются, в том числе после удаления и повторного создания профиля)
+	loginAliasesTab      map[string]models.LoginAlias            // таблица псевдонимов старых логинов переименованных профилей
+	accountsStatusTab    map[string]models.AccountStatus         // таблица статусов учетных записей профилей по идентификаторам
+	profilesHistoryTab   map[string][]models.ProfileHistoryEntry // таблица историй изменений профилей по идентификаторам (история удаленного профиля хранится до окончательного удаления)
+	invitationsTab       map[string]models.Invitation            // таблица приглашений (ключ - sha256 от токена приглашения, сами токены не хранятся)
+	registrationsTab     map[string]models.Registration          // очередь заявок на регистрацию, ожидающих подтверждения адреса электронной почты или одобрения администратора, по идентификаторам профилей
+	verificationsTab     map[string]models.EmailVerification     // таблица запросов на подтверждение адресов электронной почты (ключ - sha256 от токена подтверждения, сами токены не хранятся)
+	deletedProfilesTab   map[string]models.DeletedProfileData    // корзина - таблица удаленных профилей по идентификаторам (пароли удаленных профилей хранятся до окончательного удаления)
+	changeLog            []models.ChangeEvent                    // журнал изменений профилей - последние события в порядке номеров (не больше db.changeLogSize)
+	lastChangeSeq        int64                                   // номер последнего события изменения профилей тенанта
+	webhooksTab          map[string]models.Webhook               // таблица вебхуков тенанта по идентификаторам
+	webhookDeliveriesTab map[string][]models.WebhookDelivery     // таблица историй доставок событий по идентификаторам вебхуков (ожидающие и последние завершенные доставки)
+	webhookDeadLetters   []models.WebhookDelivery                // список недоставленных событий - доставок, попытки которых исчерпаны (не больше db.webhookHistorySize последних)
 }
 
 // Структура базы данных профилей в файле (для хранения данных в файле)
 type myProfilesDBFileData struct {
-	ProfilesDataTab      map[string]models.ProfileData `json:"profilesDataTab"`      // таблица данных профиля
-	ProfilesPasswordsTab map[string]string             `json:"profilesPasswordsTab"` // таблица зашифрованных паролей хэш+соль паролей профилей - используется для безопасного хранения паролей в зашифрованном виде)
-	AdminsTab            []string                      `json:"adminsTab"`            // таблица админов сервиса
+	SchemaVersion     int                               `json:"schemaVersion"`     // версия схемы файла (файлы предыдущих версий приводятся к текущей миграциями)
+	TenantsTab        map[string]tenantFileData         `json:"tenantsTab"`        // таблица тенантов
+	PlatformAdminsTab []string                          `json:"platformAdminsTab"` // таблица админов платформы
+	ClientsDataTab    map[string]models.OAuthClientData `json:"clientsDataTab"`    // таблица данных клиентов OAuth 2.0
+	ClientsSecretsTab map[string]string                 `json:"clientsSecretsTab"` // таблица зашифрованных секретов клиентов OAuth 2.0
+	TokensTab         map[string]models.OAuthTokenData  `json:"tokensTab"`         // таблица выданных токенов доступа
+}
+
+// Структура данных тенанта в файле (для хранения данных в файле)
+type tenantFileData struct {
+	ProfilesDataTab      map[string]models.ProfileData           `json:"profilesDataTab"`                // таблица данных профиля
+	ProfilesPasswordsTab map[string]string                       `json:"profilesPasswordsTab"`           // таблица зашифрованных паролей хэш+соль паролей профилей - используется для безопасного хранения паролей в зашифрованном виде)
+	AdminsTab            []string                                `json:"adminsTab"`                      // таблица админов тенанта
+	GroupsTab            []models.GroupData                      `json:"groupsTab"`                      // таблица групп профилей
+	AdminGroupsTab       []string                                `json:"adminGroupsTab"`                 // таблица групп админов тенанта
+	AttributesSchemaTab  map[string]models.AttributeDefinition   `json:"attributesSchemaTab"`            // схема дополнительных атрибутов профилей тенанта
+	FieldsVisibilityTab  map[string]models.FieldVisibility       `json:"fieldsVisibilityTab"`            // настройки видимости основных полей профилей тенанта
+	ProfilesVersionsTab  map[string]int64                        `json:"profilesVersionsTab"`            // таблица версий профилей
+	LastProfileVersion   int64                                   `json:"lastProfileVersion"`             // последняя выданная версия профиля тенанта
+	LoginAliasesTab      map[string]models.LoginAlias            `json:"loginAliasesTab"`                // таблица псевдонимов старых логинов переименованных профилей
+	AccountsStatusTab    map[string]models.AccountStatus         `json:"accountsStatusTab"`              // таблица статусов учетных записей профилей
+	ProfilesHistoryTab   map[string][]models.ProfileHistoryEntry `json:"profilesHistoryTab"`             // таблица историй изменений профилей
+	InvitationsTab       map[string]models.Invitation            `json:"invitationsTab"`                 // таблица приглашений
+	RegistrationsTab     map[string]models.Registration          `json:"registrationsTab"`               // очередь заявок на регистрацию
+	VerificationsTab     map[string]models.EmailVerification     `json:"verificationsTab"`               // таблица запросов на подтверждение адресов электронной почты
+	DeletedProfilesTab   map[string]models.DeletedProfileData    `json:"deletedProfilesTab"`             // корзина удаленных профилей
+	ChangeLog            []models.ChangeEvent                    `json:"changeLog,omitempty"`            // журнал изменений профилей
+	LastChangeSeq        int64                                   `json:"lastChangeSeq"`                  // номер последнего события изменения профилей
+	WebhooksTab          map[string]models.Webhook               `json:"webhooksTab,omitempty"`          // таблица вебхуков
+	WebhookDeliveriesTab map[string][]models.WebhookDelivery     `json:"webhookDeliveriesTab,omitempty"` // таблица историй доставок событий вебхуков
+	WebhookDeadLetters   []models.WebhookDelivery                `json:"webhookDeadLetters,omitempty"`   // список недоставленных событий
+}
+
+/*
+Построение пустого экземпляра базы данных с параметрами из конфига "../../configs/dbConfig.json" (данные из файла не читаются)
+
+:return: указатель на экземпляр БД или ошибка, если конфиг не удалось прочитать
+*/
+func buildMyProfilesDB() (*myProfilesDB, error) {
+	// Чтение пути к файлу с данными для заполнения базы (и для сохранения данных в него)
+	dataFilePath, err := getDBDumpPath()
+	if err != nil {
+		return nil, err
+	}
+	// Чтение мастер-ключей шифрования файла базы данных
+	keys, err := loadKeyring()
+	if err != nil {
+		return nil, err
+	}
+	// Чтение времени жизни токенов доступа
+	accessTokenLifetime, err := getAccessTokenLifetime()
+	if err != nil {
+		return nil, err
+	}
+	// Чтение времени жизни псевдонимов старых логинов
+	loginAliasLifetime, err := getLoginAliasLifetime()
+	if err != nil {
+		return nil, err
+	}
+	// Чтение времени жизни приглашений
+	invitationLifetime, err := getInvitationLifetime()
+	if err != nil {
+		return nil, err
+	}
+	// Чтение времени жизни токенов подтверждения адресов электронной почты
+	verificationLifetime, err := getVerificationLifetime()
+	if err != nil {
+		return nil, err
+	}
+	// Чтение времени хранения удаленных профилей и периода очистки корзины
+	deletedRetention, err := getDeletedRetention()
+	if err != nil {
+		return nil, err
+	}
+	purgeInterval, err := getPurgeInterval()
+	if err != nil {
+		return nil, err
+	}
+	// Чтение настроек резервного копирования по расписанию
+	backupDir, backupInterval, backupRetention, err := getBackupConfig()
+	if err != nil {
+		return nil, err
+	}
+	// Чтение размера журнала изменений профилей
+	changeLogSize, err := getChangeLogSize()
+	if err != nil {
+		return nil, err
+	}
+	// Чтение размера истории доставок вебхуков
+	webhookHistorySize, err := getWebhookHistorySize()
+	if err != nil {
+		return nil, err
+	}
+	// Чтение размера истории изменений профилей
+	profileHistorySize, err := getProfileHistorySize()
+	if err != nil {
+		return nil, err
+	}
+	// Чтение размера журнала изменений БД для репликации
+	replicationLogSize, err := getReplicationLogSize()
+	if err != nil {
+		return nil, err
+	}
+	// Чтение названия тенанта платформы
+	platformTenant, err := getPlatformTenant()
+	if err != nil {
+		return nil, err
+	}
+	db := &myProfilesDB{
+		tenantsTab:           make(map[string]*Tenant),
+		platformAdminsTab:    make(map[string]struct{}),
+		clientsDataTab:       make(map[string]models.OAuthClientData),
+		clientsSecretsTab:    make(map[string]string),
+		tokensTab:            make(map[string]models.OAuthTokenData),
+		platformTenant:       platformTenant,
+		dumpFilePath:         dataFilePath,
+		keys:                 keys,
+		accessTokenLifetime:  accessTokenLifetime,
+		loginAliasLifetime:   loginAliasLifetime,
+		invitationLifetime:   invitationLifetime,
+		verificationLifetime: verificationLifetime,
+		deletedRetention:     deletedRetention,
+		purgeInterval:        purgeInterval,
+		backupDir:            backupDir,
+		backupInterval:       backupInterval,
+		backupRetention:      backupRetention,
+		changeLogSize:        changeLogSize,
+		changed:              make(chan struct{}),
+		webhookHistorySize:   webhookHistorySize,
+		webhooksWake:         make(chan struct{}, 1),
+		profileHistorySize:   profileHistorySize,
+		replicationEpoch:     uuid.NewString(),
+		replicationLogSize:   replicationLogSize,
+		replicated:           make(chan struct{}),
+	}
+	return db, nil
 }
 
 /*
@@ -36,102 +231,223 @@ type myProfilesDBFileData struct {
 :return: ошибка, если базу данных не удается поднять
 */
 func RaiseMyProfilesDB() error {
-	// Чтение пути к файлу с данными для заполнения базы (и для сохранения данных в него)
-	dataFilePath, err := getDBDumpPath()
+	db, err := OpenMyProfilesDB("")
 	if err != nil {
 		return err
 	}
-	var db myProfilesDB
+
+	// Запись базы в глобальный указатель DB
+	DB = db
+	return nil
+}
+
+/*
+Построение экземпляра базы данных и его заполнение по json-файлу или построение пустого экземпляра базы, если файл отсутствует
+(без записи по глобальному адресу DB - так поднимаются несколько узлов кластера в одном процессе)
+
+:param dataFilePath string: путь к файлу базы данных (пустая строка - путь из конфига "../../configs/dbConfig.json")
+
+:return: указатель на экземпляр БД или ошибка, если базу данных не удается поднять
+*/
+func OpenMyProfilesDB(dataFilePath string) (*myProfilesDB, error) {
+	db, err := buildMyProfilesDB()
+	if err != nil {
+		return nil, err
+	}
+	if dataFilePath != "" {
+		db.dumpFilePath = dataFilePath
+	}
+	dataFilePath, keys, platformTenant := db.dumpFilePath, db.keys, db.platformTenant
+	if keys == nil {
+		log.Println("warning: database encryption keys are not configured, database dump is stored unencrypted")
+	}
 	// Проверка, существует ли файл с данными по пути dataFilePath
+	var reencrypt bool // файл не зашифрован или зашифрован старым ключом
 	_, err = os.Stat(dataFilePath)
 	switch {
 	// Если файл существует, из файла читаются данные для записи в экземпляр БД
 	case err == nil:
-		// Чтение данных из файла для записи в экземпляр БД со структурой myProfilesDBFileData
-		dataFile, err := os.Open(dataFilePath)
+		// Чтение и расшифровка данных из файла для записи в экземпляр БД со структурой myProfilesDBFileData
+		var byteValue []byte
+		byteValue, reencrypt, err = readDumpFile(dataFilePath, keys)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		defer dataFile.Close()
-		byteValue, _ := ioutil.ReadAll(dataFile)
-		var dbFromFile myProfilesDBFileData
-		json.Unmarshal(byteValue, &dbFromFile)
-
-		// Составление in memory БД со структурой myProfilesDB
-		//	map для списка админов
-		adminsTab := make(map[string]struct{})
-		for _, adminLogin := range dbFromFile.AdminsTab {
-			adminsTab[adminLogin] = struct{}{}
+		var version int // версия схемы файла
+		version, err = db.loadFileData(byteValue)
+		if err != nil {
+			return nil, err
+		}
+		// Файл предыдущей версии схемы сохраняется перед миграцией и перезаписывается в текущей версии
+		if version < schemaVersion {
+			backupPath, err := backupBeforeMigration(dataFilePath, version)
+			if err != nil {
+				return nil, err
+			}
+			log.Printf("database dump is migrated from schema version %d to %d, previous dump is saved to %s", version, schemaVersion, backupPath)
+			reencrypt = true
 		}
-		db = myProfilesDB{
-			profilesDataTab:      dbFromFile.ProfilesDataTab,
-			profilesPasswordsTab: dbFromFile.ProfilesPasswordsTab,
-			adminsTab:            adminsTab,
-			dumpFilePath:         dataFilePath,
+		// Тенант платформы создается, если его нет в файле
+		if _, ok := db.tenantsTab[platformTenant]; !ok {
+			db.tenantsTab[platformTenant] = newTenant(db, platformTenant, tenantFileData{})
 		}
 
-	// Если файла не существует, создается пустой экземпляр БД
+	// Если файла не существует, создается пустой экземпляр БД с тенантом платформы
 	case errors.Is(err, os.ErrNotExist):
-		db = myProfilesDB{
-			profilesDataTab:      make(map[string]models.ProfileData),
-			profilesPasswordsTab: make(map[string]string),
-			adminsTab:            make(map[string]struct{}),
-			dumpFilePath:         dataFilePath,
-		}
+		platform := newTenant(db, platformTenant, tenantFileData{})
+		db.tenantsTab[platformTenant] = platform
 		// Добавление профиля администратора по умолчанию
 		//	Чтение профиля администратора по умолчанию
 		defaultAdminLogin, defaultAdminProfilrData, defaultAdminPassword, err := getDefaultAdminProfile()
 		if err != nil {
-			return err
+			return nil, err
 		}
-		//	Добавление профиля
-		db.AddProfile(defaultAdminLogin, defaultAdminProfilrData, defaultAdminPassword)
-		db.AddAdmin(defaultAdminLogin)
+		//	Добавление профиля (администратор по умолчанию является администратором платформы)
+		platform.AddProfile(defaultAdminLogin, defaultAdminProfilrData, defaultAdminPassword, models.AccountStatusActive, "")
+		platform.AddAdmin(defaultAdminLogin)
+		db.AddPlatformAdmin(defaultAdminLogin)
 	}
 
-	// Запись базы в глобальный указатель DB
-	DB = &db
-	return nil
+	// Файл после миграции, не зашифрованный или зашифрованный старым ключом файл перезаписывается (и шифруется ключом текущей версии)
+	if reencrypt {
+		err = db.Dump()
+		if err != nil {
+			return nil, err
+		}
+		if keys != nil {
+			log.Printf("database dump is rewritten with key version %d", keys.current)
+		}
+	}
+
+	return db, nil
 }
 
 /*
-Сохранение данных из БД на диск в файл db.dumpFilePath
+Заполнение таблиц пустого экземпляра БД данными файла базы данных: файлы предыдущих версий схемы приводятся к текущей версии миграциями
+(файлы, сохраненные до появления идентификаторов, статусов и OAuth 2.0, дополняются при загрузке)
 
-:return: возвращаеся ошибка, если файл с данными не удается переписать
+:param byteValue []byte: расшифрованное содержимое файла базы данных
+
+:return: исходная версия схемы файла или ошибка, если содержимое файла не является json-документом базы данных, версия схемы файла новее текущей
+или миграция не удалась
 */
-func (db *myProfilesDB) Dump() error {
-	adminsList := make([]string, 0, len(db.adminsTab))
-	for adminLogin := range db.adminsTab {
-		adminsList = append(adminsList, adminLogin)
+func (db *myProfilesDB) loadFileData(byteValue []byte) (int, error) {
+	// Приведение файла к текущей версии схемы
+	byteValue, version, err := migrateFileData(byteValue, db.platformTenant)
+	if err != nil {
+		return version, err
+	}
+	var dbFromFile myProfilesDBFileData
+	err = json.Unmarshal(byteValue, &dbFromFile)
+	if err != nil {
+		return version, fmt.Errorf("%w: %s", invalidDumpErr, err.Error())
+	}
+
+	// Составление in memory БД со структурой myProfilesDB
+	//	тенанты
+	for tenantName, tenantData := range dbFromFile.TenantsTab {
+		db.tenantsTab[tenantName] = newTenant(db, tenantName, tenantData)
+	}
+	//	map для списка админов платформы (в файлах, сохраненных до появления идентификаторов, админы платформы заданы логинами)
+	if platform, ok := db.tenantsTab[db.platformTenant]; ok {
+		for _, adminRef := range dbFromFile.PlatformAdminsTab {
+			if id, ok := platform.profileIDByRef(adminRef); ok {
+				db.platformAdminsTab[id] = struct{}{}
+			}
+		}
+	}
+	//	таблицы клиентов и токенов отсутствуют в файлах, сохраненных до появления OAuth 2.0
+	if dbFromFile.ClientsDataTab != nil {
+		db.clientsDataTab = dbFromFile.ClientsDataTab
+	}
+	if dbFromFile.ClientsSecretsTab != nil {
+		db.clientsSecretsTab = dbFromFile.ClientsSecretsTab
+	}
+	if dbFromFile.TokensTab != nil {
+		db.tokensTab = dbFromFile.TokensTab
+	}
+	return version, nil
+}
+
+/*
+Составление содержимого файла базы данных (вызывается под блокировкой db.mu)
+
+:return: json-документ со всеми данными БД (без шифрования)
+*/
+func (db *myProfilesDB) fileData() []byte {
+	tenantsData := make(map[string]tenantFileData, len(db.tenantsTab))
+	for tenantName, t := range db.tenantsTab {
+		tenantsData[tenantName] = t.fileData()
+	}
+	platformAdminsList := make([]string, 0, len(db.platformAdminsTab))
+	for adminLogin := range db.platformAdminsTab {
+		platformAdminsList = append(platformAdminsList, adminLogin)
 	}
 	dbToFile := myProfilesDBFileData{
-		ProfilesDataTab:      db.profilesDataTab,
-		ProfilesPasswordsTab: db.profilesPasswordsTab,
-		AdminsTab:            adminsList,
+		SchemaVersion:     schemaVersion,
+		TenantsTab:        tenantsData,
+		PlatformAdminsTab: platformAdminsList,
+		ClientsDataTab:    db.clientsDataTab,
+		ClientsSecretsTab: db.clientsSecretsTab,
+		TokensTab:         db.tokensTab,
 	}
 
 	dataFile, _ := json.MarshalIndent(dbToFile, "", "	")
-	err := ioutil.WriteFile(db.dumpFilePath, dataFile, 0644)
-	if err != nil {
+	return dataFile
+}
+
+/*
+Сохранение данных из БД на диск в файл db.dumpFilePath (вызывается методами БД под блокировкой db.mu); если заданы мастер-ключи, файл шифруется ключом текущей версии.
+В кластере изменение, зафиксированное в журнале Raft, не отменяется, даже если файл не удалось переписать: узел применяет его так же, как изменения других узлов,
+файл перезаписывается следующим сохранением, а после перезапуска узел получает изменение из журнала Raft
+
+:return: возвращаеся ошибка, если файл с данными не удается переписать (в кластере - если изменение не удалось зафиксировать в журнале Raft)
+*/
+func (db *myProfilesDB) Dump() error {
+	dirtyTenants, erasedProfiles := db.dirtyTenants, db.erasedProfiles
+	db.dirtyTenants, db.erasedProfiles = nil, false
+	if db.follower {
+		return followerReadOnlyErr
+	}
+	// В кластере изменение сначала фиксируется большинством узлов
+	entry := db.replicationEntry(dirtyTenants)
+	if db.cluster != nil {
+		if !db.cluster.Writable() {
+			return clusterNotLeaderErr
+		}
+		err := db.commitClusterEntry(entry)
+		if err != nil {
+			return err
+		}
+	}
+	err := writeDumpFile(db.dumpFilePath, db.fileData(), db.keys)
+	if err != nil && db.cluster == nil {
 		return dbDumpFailErr
 	}
+	if err != nil {
+		log.Printf("cluster: %s", dbDumpFailErr.Error())
+	}
 
+	// Запись сохраненного изменения в журнал изменений БД для ведомых экземпляров (прежние записи с данными окончательно удаленных профилей удаляются)
+	db.appendReplicationEntry(entry)
+	if erasedProfiles {
+		db.replicationLog = append([]models.ReplicationEntry(nil), db.replicationLog[len(db.replicationLog)-1:]...)
+	}
 	return nil
 }
 
 /*
-Получить данные о профиле по логину
+Получить данные о профиле по логину вместе с текущей версией профиля
 
 :param login string: логин получаемого профиля
 
-:return: данные о профиле с логином login или ошибка, исли профиля с таким логином нет
+:return: данные о профиле с логином login (включая идентификатор профиля) и его версия или ошибка, исли профиля с таким логином нет
 */
-func (db *myProfilesDB) GetProfileData(login string) (profileData models.ProfileData, err error) {
-	// Поиск данных профиля
-	profileData, ok := db.profilesDataTab[login]
-	if !ok {
-		err = noProfileErr
-	}
+func (t *Tenant) GetProfileData(login string) (profileData models.ProfileData, version int64, err error) {
+	err = t.View(func(tx *Tx) error {
+		profileData, version, err = tx.GetProfileData(login)
+		return err
+	})
 	return
 }
 
@@ -140,10 +456,11 @@ func (db *myProfilesDB) GetProfileData(login string) (profileData models.Profile
 
 :return: список логинов всех зарегистрированных пользователей
 */
-func (db *myProfilesDB) GetAllLogins() (logins []string) {
-	for login := range db.profilesDataTab {
-		logins = append(logins, login)
-	}
+func (t *Tenant) GetAllLogins() (logins []string) {
+	t.View(func(tx *Tx) error {
+		logins = tx.GetAllLogins()
+		return nil
+	})
 	return
 }
 
@@ -154,96 +471,199 @@ func (db *myProfilesDB) GetAllLogins() (logins []string) {
 
 :return: зашифрованный пароль профиля с логином login или ошибка, исли профиля с таким логином нет
 */
-func (db *myProfilesDB) GetPasswordHashSalt(login string) (passwordHashSalt string, err error) {
+func (t *Tenant) GetPasswordHashSalt(login string) (string, error) {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
 	// Поиск пароля
-	passwordHashSalt, ok := db.profilesPasswordsTab[login]
+	id, ok := t.loginsTab[login]
 	if !ok {
-		err = noProfileErr
+		return "", noProfileErr
 	}
-	return
+	return t.profilesPasswordsTab[id], nil
 }
 
 /*
 Получение таблицы логинов и паролей (для авторизаторов)
 
-:return: map db.profilesPasswordsTab
+:return: копия таблицы зашифрованных паролей профилей по логинам
 */
-func (db *myProfilesDB) GetPasswordsTab() map[string]string {
-	return db.profilesPasswordsTab
+func (t *Tenant) GetPasswordsTab() map[string]string {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	passwordsTab := make(map[string]string, len(t.loginsTab))
+	for login, id := range t.loginsTab {
+		passwordsTab[login] = t.profilesPasswordsTab[id]
+	}
+	return passwordsTab
 }
 
 /*
-Проверка профиля, входит ли он в список администраторов
+Проверка профиля, входит ли он в список администраторов непосредственно или через группу администраторов (с учетом вложенности групп)
 
 :param login string: логин профиля, проверяемого по списку администраторов
 
-:return: возвращается true, если login в списке администраторов, иначе - false и ошибка
+:return: возвращается true, если login в списке администраторов или в группе администраторов, иначе - false
 */
-func (db *myProfilesDB) IsAdmin(login string) bool {
-	// Проверка логина на наличие в списке администраторов
-	_, ok := db.adminsTab[login]
-	return ok
+func (t *Tenant) IsAdmin(login string) (isAdmin bool) {
+	// Проверка логина на наличие в списке администраторов и групп профиля на наличие в списке групп администраторов
+	t.View(func(tx *Tx) error {
+		isAdmin = tx.IsAdmin(login)
+		return nil
+	})
+	return
 }
 
 /*
-Запись данных и пароля (в зашифрованном виде) нового пользователя (регистрация)
+Запись данных и пароля (в зашифрованном виде) нового пользователя (регистрация); профилю выдается новый идентификатор
 
 :param login string: логин нового профиля
 :param profileData models.ProfileData: данные для хранения в новом профиле
 :param password string: пароль для входа нового пользователя (должен быть не длиннее 72 символов)
+:param status string: начальный статус учетной записи: pending (ожидает активации) или active (пустая строка - active)
+:param author string: логин пользователя, создающего профиль (записывается в историю профиля, пустая строка - профиль создается сервисом)
 
-:return: возвращается ошибка, если профиль с логином login уже существует, неподходящий пароль или базу данных не удалось сохранить
+:return: возвращается ошибка, если профиль с логином login уже существует, начальный статус недопустим, атрибуты не соответствуют схеме атрибутов, неподходящий пароль или базу данных не удалось сохранить
 */
-func (db *myProfilesDB) AddProfile(login string, profileData models.ProfileData, password string) error {
-	// Проверка наличия профиля с логином login
-	_, ok := db.profilesDataTab[login]
+func (t *Tenant) AddProfile(login string, profileData models.ProfileData, password string, status string, author string) error {
+	// Генерация хэша и соли пароля (до транзакции)
+	passwordHashSalt, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	// Добавление профиля
+	return t.Update(func(tx *Tx) error {
+		_, err := tx.AddProfile(login, profileData, passwordHashSalt, status, author)
+		return err
+	})
+}
+
+/*
+Проверка начального статуса учетной записи нового профиля
+
+:param status string: начальный статус: pending (ожидает активации) или active (пустая строка - active)
+
+:return: начальный статус или ошибка, если статус недопустим для новой учетной записи
+*/
+func newAccountStatus(status string) (string, error) {
+	switch status {
+	case "":
+		return models.AccountStatusActive, nil
+	case models.AccountStatusPending, models.AccountStatusActive:
+		return status, nil
+	}
+	return "", fmt.Errorf("%w: new account can only be pending or active", invalidStatusTransitionErr)
+}
+
+/*
+Добавление нового профиля во все таблицы тенанта (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются)
+
+:param login string: логин нового профиля
+:param profileData models.ProfileData: данные для хранения в новом профиле
+:param passwordHashSalt string: зашифрованный пароль профиля (пустая строка - пароль не задан, профиль не может авторизоваться)
+:param status string: начальный статус учетной записи
+:param author string: логин пользователя, создающего профиль
+
+:return: идентификатор нового профиля или ошибка, если профиль с логином login уже существует, login - псевдоним другого профиля или атрибуты не соответствуют схеме атрибутов
+*/
+func (t *Tenant) addProfile(login string, profileData models.ProfileData, passwordHashSalt string, status string, author string) (string, error) {
+	// Проверка наличия профиля с логином login и псевдонима login
+	_, ok := t.loginsTab[login]
 	if ok {
-		return profileExistsErr
+		return "", profileExistsErr
+	}
+	if _, ok := t.activeLoginAlias(login); ok {
+		return "", loginIsAliasErr
 	}
 
-	// Добавление данных пользователя
-	db.profilesDataTab[login] = profileData
+	// Проверка дополнительных атрибутов по схеме атрибутов тенанта
+	id := newProfileID()
+	err := t.validateAttributes(id, profileData.Attributes)
+	if err != nil {
+		return "", err
+	}
 
 	// Добавление зашифрованного пароля
-	//	Генерация хэша и соли
-	passwordHashSalt, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return incorrectPasswordErr
+	if passwordHashSalt != "" {
+		setEntry(t.db.undo, t.profilesPasswordsTab, id, passwordHashSalt)
 	}
-	db.profilesPasswordsTab[login] = string(passwordHashSalt)
 
-	// Сохранение данных в файл
-	err = db.Dump()
-	if err != nil {
+	// Добавление данных пользователя (идентификатор и логин в данных профиля всегда совпадают с ключами таблиц, адрес электронной почты не подтвержден)
+	profileData.ID = id
+	profileData.Login = login
+	profileData.VerifiedAt = 0
+	profileData.PendingEmail = ""
+	setEntry(t.db.undo, t.profilesDataTab, id, profileData)
+	setEntry(t.db.undo, t.loginsTab, login, id)
+	setEntry(t.db.undo, t.accountsStatusTab, id, models.AccountStatus{Status: status, ChangedAt: time.Now().Unix()})
+	version := t.nextProfileVersion(id)
+	t.recordProfileHistory(id, author, models.HistoryActionCreate, version)
+
+	// Отправка токена подтверждения адреса электронной почты
+	if profileData.Email != "" {
+		t.issueVerification(id, profileData.Email)
+	}
+	return id, nil
+}
+
+/*
+Замена данных в профиле на новые; версия профиля проверяется и увеличивается атомарно с заменой данных
+
+:param login string: логин редактируемого профиля
+:param profileData models.ProfileData: новые данные для хранения в профиле (идентификатор и логин профиля не меняются)
+:param expectedVersion int64: ожидаемая текущая версия профиля (0 - версия не проверяется)
+:param author string: логин пользователя, редактирующего профиль (записывается в историю профиля)
+
+:return: новая версия профиля; возвращается ошибка, если профиль с логином login не существует, версия профиля не совпадает с ожидаемой, атрибуты не соответствуют схеме атрибутов или базу данных не удалось сохранить
+*/
+func (t *Tenant) EditProfile(login string, profileData models.ProfileData, expectedVersion int64, author string) (version int64, err error) {
+	err = t.Update(func(tx *Tx) error {
+		version, err = tx.EditProfile(login, profileData, expectedVersion, author)
 		return err
+	})
+	if err != nil {
+		return 0, err
 	}
-	return nil
+	return version, nil
 }
 
 /*
-Замена данных в профиле на новые
+Замена данных в профиле на новые (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются)
 
 :param login string: логин редактируемого профиля
-:param profileData models.ProfileData: новые данные для хранения в профиле
+:param profileData models.ProfileData: новые данные для хранения в профиле (идентификатор и логин профиля не меняются)
+:param expectedVersion int64: ожидаемая текущая версия профиля (0 - версия не проверяется)
+:param author string: логин пользователя, редактирующего профиль (записывается в историю профиля)
 
-:return: возвращается ошибка, если профиль с логином login не существует или базу данных не удалось сохранить
+:return: новая версия профиля; возвращается ошибка, если профиль с логином login не существует, версия профиля не совпадает с ожидаемой или атрибуты не соответствуют схеме атрибутов
 */
-func (db *myProfilesDB) EditProfile(login string, profileData models.ProfileData) error {
-	// Проверка наличия профиля с логином login
-	_, ok := db.profilesDataTab[login]
+func (t *Tenant) editProfile(login string, profileData models.ProfileData, expectedVersion int64, author string) (int64, error) {
+	// Проверка наличия профиля с логином login и его версии
+	id, ok := t.loginsTab[login]
 	if !ok {
-		return noProfileErr
+		return 0, noProfileErr
+	}
+	err := t.checkProfileVersion(id, expectedVersion)
+	if err != nil {
+		return 0, err
 	}
 
-	// Замена данных в профиле на новые
-	db.profilesDataTab[login] = profileData
-
-	// Сохранение данных в файл
-	err := db.Dump()
+	// Проверка дополнительных атрибутов по схеме атрибутов тенанта
+	err = t.validateAttributes(id, profileData.Attributes)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	return nil
+
+	// Замена данных в профиле на новые (идентификатор и логин в данных профиля всегда совпадают с ключами таблиц, новый адрес электронной почты ожидает подтверждения)
+	profileData.ID = id
+	profileData.Login = login
+	t.applyEmailChange(id, t.profilesDataTab[id], &profileData)
+	setEntry(t.db.undo, t.profilesDataTab, id, profileData)
+	version := t.nextProfileVersion(id)
+	t.recordProfileHistory(id, author, models.HistoryActionEdit, version)
+	return version, nil
 }
 
 /*
@@ -254,104 +674,155 @@ func (db *myProfilesDB) EditProfile(login string, profileData models.ProfileData
 
 :return: возвращается ошибка, если профиль с логином login не существует, неподходящий пароль или базу данных не удалось сохранить
 */
-func (db *myProfilesDB) ChangePassword(login string, newPassword string) error {
+func (t *Tenant) ChangePassword(login string, newPassword string) error {
+	// Генерация хэша и соли нового пароля (до транзакции)
+	newPasswordHashSalt, err := HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	return t.Update(func(tx *Tx) error {
+		return tx.ChangePassword(login, newPasswordHashSalt)
+	})
+}
+
+/*
+Запись зашифрованного пароля в профиль (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются)
+
+:param login string: логин, у которого меняется пароль
+:param passwordHashSalt string: новый зашифрованный пароль профиля (хэш+соль)
+
+:return: возвращается ошибка, если профиль с логином login не существует
+*/
+func (t *Tenant) changePassword(login string, passwordHashSalt string) error {
 	// Проверка наличия профиля с логином login
-	_, ok := db.profilesDataTab[login]
+	id, ok := t.loginsTab[login]
 	if !ok {
 		return noProfileErr
 	}
 
-	// Добавление зашифрованного пароля
-	//	Генерация хэша и соли
-	newPasswordHashSalt, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
-	if err != nil {
-		return incorrectPasswordErr
-	}
-	db.profilesPasswordsTab[login] = string(newPasswordHashSalt)
-
-	// Сохранение данных в файл
-	err = db.Dump()
-	if err != nil {
-		return err
-	}
+	// Замена зашифрованного пароля
+	setEntry(t.db.undo, t.profilesPasswordsTab, id, passwordHashSalt)
+	t.recordChange(models.ChangePasswordChanged, id, 0, "")
 	return nil
 }
 
 /*
-Удаление профиля
+Удаление профиля: профиль переносится в корзину, где хранится до восстановления или окончательного удаления по истечении времени хранения,
+или удаляется окончательно вместе с паролем (по требованию пользователя об удалении персональных данных); версия профиля проверяется атомарно с удалением
 
 :param login string: логин удаляемого профиля
+:param expectedVersion int64: ожидаемая текущая версия профиля (0 - версия не проверяется)
+:param hard bool: true - профиль удаляется окончательно вместе с историей изменений, минуя корзину
+:param author string: логин пользователя, удаляющего профиль (записывается в историю профиля)
 
-:return: возвращается ошибка, если профиль с логином login не существует или базу данных не удалось сохранить
+:return: возвращается ошибка, если профиль с логином login не существует, версия профиля не совпадает с ожидаемой или базу данных не удалось сохранить
 */
-func (db *myProfilesDB) RemoveProfile(login string) error {
-	// Проверка наличия профиля с логином login
-	_, ok := db.profilesDataTab[login]
+func (t *Tenant) RemoveProfile(login string, expectedVersion int64, hard bool, author string) error {
+	return t.Update(func(tx *Tx) error {
+		return tx.RemoveProfile(login, expectedVersion, hard, author)
+	})
+}
+
+/*
+Удаление профиля: профиль переносится в корзину, где хранится до восстановления или окончательного удаления по истечении времени хранения,
+или удаляется окончательно вместе с паролем (по требованию пользователя об удалении персональных данных); версия профиля проверяется атомарно с удалением (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются)
+
+:param login string: логин удаляемого профиля
+:param expectedVersion int64: ожидаемая текущая версия профиля (0 - версия не проверяется)
+:param hard bool: true - профиль удаляется окончательно вместе с историей изменений, минуя корзину
+:param author string: логин пользователя, удаляющего профиль (записывается в историю профиля)
+
+:return: возвращается ошибка, если профиль с логином login не существует или версия профиля не совпадает с ожидаемой
+*/
+func (t *Tenant) removeProfile(login string, expectedVersion int64, hard bool, author string) error {
+	// Проверка наличия профиля с логином login и его версии
+	id, ok := t.loginsTab[login]
 	if !ok {
 		return noProfileErr
 	}
-
-	// Удаление профиля из всех таблиц
-	if db.IsAdmin(login) {
-		delete(db.adminsTab, login)
-	}
-	delete(db.profilesDataTab, login)
-	delete(db.profilesPasswordsTab, login)
-
-	// Сохранение данных в файл
-	err := db.Dump()
+	err := t.checkProfileVersion(id, expectedVersion)
 	if err != nil {
 		return err
 	}
+
+	// Удаление профиля из всех таблиц и перенос в корзину или удаление пароля
+	deletedProfile := t.detachProfile(id, author)
+	if hard {
+		t.eraseProfile(id)
+	} else {
+		deletedProfile.DeletedAt = time.Now().Unix()
+		setEntry(t.db.undo, t.deletedProfilesTab, id, deletedProfile)
+	}
 	return nil
 }
 
 /*
-Добавление логина профиля в список админов
+Добавление профиля в список админов
 
 :param login string: логин добавляемого администратора
 
 :return: возвращается ошибка, если профиль с логином login не существует или базу данных не удалось сохранить
 */
-func (db *myProfilesDB) AddAdmin(login string) error {
+func (t *Tenant) AddAdmin(login string) error {
+	return t.Update(func(tx *Tx) error {
+		return tx.AddAdmin(login)
+	})
+}
+
+/*
+Добавление профиля в список админов (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются)
+
+:param login string: логин добавляемого администратора
+
+:return: возвращается ошибка, если профиль с логином login не существует
+*/
+func (t *Tenant) addAdmin(login string) error {
 	// Проверка наличия профиля с логином login
-	_, ok := db.profilesDataTab[login]
+	id, ok := t.loginsTab[login]
 	if !ok {
 		return noProfileErr
 	}
 
-	// Добавление логина login в список администраторов
-	db.adminsTab[login] = struct{}{}
-
-	// Сохранение данных в файл
-	err := db.Dump()
-	if err != nil {
-		return err
+	// Добавление профиля в список администраторов
+	if _, ok := t.adminsTab[id]; !ok {
+		setEntry(t.db.undo, t.adminsTab, id, struct{}{})
+		t.recordChange(models.ChangeAdminGranted, id, 0, "")
 	}
 	return nil
 }
 
 /*
-Удаление логина профиля из списка админов
+Удаление профиля из списка админов
 
 :param login string: логин профиля, удаляемого из списка администраторов
 
 :return: возвращается ошибка, если профиль с логином login не существует или базу данных не удалось сохранить
 */
-func (db *myProfilesDB) DropAdmin(login string) error {
+func (t *Tenant) DropAdmin(login string) error {
+	return t.Update(func(tx *Tx) error {
+		return tx.DropAdmin(login)
+	})
+}
+
+/*
+Удаление профиля из списка админов (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются)
+
+:param login string: логин профиля, удаляемого из списка администраторов
+
+:return: возвращается ошибка, если профиль с логином login не существует
+*/
+func (t *Tenant) dropAdmin(login string) error {
 	// Проверка наличия профиля с логином login
-	_, ok := db.profilesDataTab[login]
+	id, ok := t.loginsTab[login]
 	if !ok {
 		return noProfileErr
 	}
 
 	// Удаление профиля из списка администраторов
-	delete(db.adminsTab, login)
-
-	// Сохранение данных в файл
-	err := db.Dump()
-	if err != nil {
-		return err
+	if _, ok := t.adminsTab[id]; ok {
+		deleteEntry(t.db.undo, t.adminsTab, id)
+		t.recordChange(models.ChangeAdminRevoked, id, 0, "")
 	}
 	return nil
 }
@@ -0,0 +1,220 @@
+package myProfilesDB
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Получение ключа таблицы токенов по токену доступа: в БД хранится только sha256 от токена,
+чтобы дамп БД не позволял воспользоваться выданными токенами
+
+:param token string: токен доступа
+
+:return: ключ токена в таблице db.tokensTab
+*/
+func tokenKey(token string) string {
+	tokenHash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(tokenHash[:])
+}
+
+/*
+Генерация случайного токена (токенов доступа OAuth 2.0, токенов приглашений)
+
+:return: токен (32 случайных байта в шестнадцатеричной записи) или ошибка, если токен не удалось сгенерировать
+*/
+func generateToken() (string, error) {
+	tokenBytes := make([]byte, 32)
+	_, err := rand.Read(tokenBytes)
+	if err != nil {
+		return "", tokenGenerationFailErr
+	}
+	return hex.EncodeToString(tokenBytes), nil
+}
+
+/*
+Получить данные клиента OAuth 2.0 по идентификатору
+
+:param clientID string: идентификатор получаемого клиента
+
+:return: данные клиента с идентификатором clientID или ошибка, исли клиента с таким идентификатором нет
+*/
+func (db *myProfilesDB) GetClientData(clientID string) (clientData models.OAuthClientData, err error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	// Поиск данных клиента
+	clientData, ok := db.clientsDataTab[clientID]
+	if !ok {
+		err = noClientErr
+	}
+	return
+}
+
+/*
+Получение зашифрованного секрета клиента OAuth 2.0 по идентификатору
+
+:param clientID string: идентификатор клиента, для которого берется зашифрованный секрет
+
+:return: зашифрованный секрет клиента с идентификатором clientID или ошибка, исли клиента с таким идентификатором нет
+*/
+func (db *myProfilesDB) GetClientSecretHashSalt(clientID string) (secretHashSalt string, err error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	// Поиск секрета
+	secretHashSalt, ok := db.clientsSecretsTab[clientID]
+	if !ok {
+		err = noClientErr
+	}
+	return
+}
+
+/*
+Регистрация нового клиента OAuth 2.0 с секретом (в зашифрованном виде) и списком областей доступа
+
+:param clientData models.OAuthClientData: данные нового клиента
+:param secret string: секрет нового клиента (должен быть не длиннее 72 символов)
+
+:return: возвращается ошибка, если идентификатор клиента пуст, клиент с таким идентификатором уже существует, неподходящий секрет или базу данных не удалось сохранить
+*/
+func (db *myProfilesDB) AddClient(clientData models.OAuthClientData, secret string) error {
+	// Проверка идентификатора клиента
+	if strings.TrimSpace(clientData.ClientID) == "" {
+		return emptyClientIDErr
+	}
+
+	// Генерация хэша и соли секрета (до изменения: шифрование bcrypt занимает заметное время)
+	secretHashSalt, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return incorrectPasswordErr
+	}
+
+	return db.Update(func() error {
+		// Проверка наличия клиента с идентификатором clientData.ClientID
+		_, ok := db.clientsDataTab[clientData.ClientID]
+		if ok {
+			return clientExistsErr
+		}
+
+		// Запись клиента
+		setEntry(db.undo, db.clientsDataTab, clientData.ClientID, clientData)
+		setEntry(db.undo, db.clientsSecretsTab, clientData.ClientID, string(secretHashSalt))
+		return nil
+	})
+}
+
+/*
+Удаление клиента OAuth 2.0 и всех выданных ему токенов
+
+:param clientID string: идентификатор удаляемого клиента
+
+:return: возвращается ошибка, если клиента с идентификатором clientID не существует или базу данных не удалось сохранить
+*/
+func (db *myProfilesDB) RemoveClient(clientID string) error {
+	return db.Update(func() error {
+		// Проверка наличия клиента с идентификатором clientID
+		_, ok := db.clientsDataTab[clientID]
+		if !ok {
+			return noClientErr
+		}
+
+		// Удаление клиента и его токенов
+		deleteEntry(db.undo, db.clientsDataTab, clientID)
+		deleteEntry(db.undo, db.clientsSecretsTab, clientID)
+		for key, tokenData := range db.tokensTab {
+			if tokenData.ClientID == clientID {
+				deleteEntry(db.undo, db.tokensTab, key)
+			}
+		}
+		return nil
+	})
+}
+
+/*
+Выдача нового токена доступа клиенту OAuth 2.0; заодно из БД удаляются токены с истекшим сроком действия
+
+:param clientID string: идентификатор клиента, которому выдается токен
+:param scope string: области доступа токена, разделенные пробелами (должны быть проверены по данным клиента до вызова)
+
+:return: токен доступа и время его жизни в секундах или ошибка, если клиента не существует, токен не удалось сгенерировать или базу данных не удалось сохранить
+*/
+func (db *myProfilesDB) IssueToken(clientID string, scope string) (string, int64, error) {
+	// Генерация случайного токена
+	token, err := generateToken()
+	if err != nil {
+		return "", 0, err
+	}
+
+	err = db.Update(func() error {
+		// Проверка наличия клиента с идентификатором clientID
+		_, ok := db.clientsDataTab[clientID]
+		if !ok {
+			return noClientErr
+		}
+
+		// Удаление просроченных токенов
+		now := time.Now().Unix()
+		for key, tokenData := range db.tokensTab {
+			if tokenData.ExpiresAt <= now {
+				deleteEntry(db.undo, db.tokensTab, key)
+			}
+		}
+
+		// Запись токена
+		setEntry(db.undo, db.tokensTab, tokenKey(token), models.OAuthTokenData{
+			ClientID:  clientID,
+			Scope:     scope,
+			IssuedAt:  now,
+			ExpiresAt: now + db.accessTokenLifetime,
+		})
+		return nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	return token, db.accessTokenLifetime, nil
+}
+
+/*
+Получение данных действующего токена доступа
+
+:param token string: токен доступа
+
+:return: данные токена и true, если токен выдан и его срок действия не истек, иначе - false
+*/
+func (db *myProfilesDB) GetTokenData(token string) (models.OAuthTokenData, bool) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	tokenData, ok := db.tokensTab[tokenKey(token)]
+	if !ok || tokenData.ExpiresAt <= time.Now().Unix() {
+		return models.OAuthTokenData{}, false
+	}
+	return tokenData, true
+}
+
+/*
+Отзыв токена доступа
+
+:param token string: отзываемый токен доступа
+
+:return: возвращается ошибка, если базу данных не удалось сохранить; отзыв неизвестного токена ошибкой не является
+*/
+func (db *myProfilesDB) RevokeToken(token string) error {
+	return db.Update(func() error {
+		key := tokenKey(token)
+		_, ok := db.tokensTab[key]
+		if ok {
+			deleteEntry(db.undo, db.tokensTab, key)
+		}
+		return nil
+	})
+}
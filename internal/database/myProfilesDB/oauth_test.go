@@ -0,0 +1,49 @@
+package myProfilesDB
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Клиент с пустым идентификатором не регистрируется
+func TestAddClientEmptyID(t *testing.T) {
+	db, _ := openTestDB(t)
+	for _, clientID := range []string{"", "  \t"} {
+		err := db.AddClient(models.OAuthClientData{ClientID: clientID}, "secret")
+		if !errors.Is(err, emptyClientIDErr) {
+			t.Fatalf("client with id %q is added: %v", clientID, err)
+		}
+	}
+	if len(db.clientsDataTab) != 0 {
+		t.Fatalf("clients are added: %+v", db.clientsDataTab)
+	}
+}
+
+// Запись журнала изменений БД о выдаче токена содержит только общие таблицы, без данных тенантов
+func TestIssueTokenReplicatesSharedTables(t *testing.T) {
+	db, platform := openTestDB(t)
+	addTestProfile(t, platform, "alice")
+	err := db.AddClient(models.OAuthClientData{ClientID: "app"}, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	epoch, seq := db.ReplicationPosition()
+
+	_, _, err = db.IssueToken("app", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	replicationLog, _, err := db.ReplicationLog(epoch, seq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replicationLog.Entries) != 1 {
+		t.Fatalf("unexpected replication log entries: %+v", replicationLog.Entries)
+	}
+	entry := replicationLog.Entries[0]
+	if entry.Full || len(entry.Tenants) != 0 || len(entry.TokensTab) != 1 {
+		t.Fatalf("token entry is not limited to shared tables: %+v", entry)
+	}
+}
@@ -0,0 +1,165 @@
+package myProfilesDB
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Самостоятельная регистрация пользователя: создается профиль, который ожидает активации, пока не подтвержден адрес электронной почты
+и (или) заявку не одобрил администратор; если не требуется ни то, ни другое, учетная запись сразу активна. Токен подтверждения отправляется на адрес электронной почты
+
+:param registrationData models.RegistrationData: данные регистрации (пароль должен быть проверен по политике паролей до вызова)
+:param verifyEmail bool: для активации требуется подтверждение адреса электронной почты
+:param requireApproval bool: для активации требуется одобрение администратора
+
+:return: заявка на регистрацию или ошибка, если не заданы ни логин, ни адрес электронной почты, адрес не задан при необходимости подтверждения, профиль с таким логином уже существует,
+атрибуты не соответствуют схеме атрибутов, неподходящий пароль или базу данных не удалось сохранить
+*/
+func (t *Tenant) Register(registrationData models.RegistrationData, verifyEmail bool, requireApproval bool) (models.Registration, error) {
+	// Проверка данных регистрации
+	login := registrationData.Login
+	if login == "" {
+		login = registrationData.Email
+	}
+	if login == "" {
+		return models.Registration{}, emptyLoginErr
+	}
+	if verifyEmail && registrationData.Email == "" {
+		return models.Registration{}, emptyEmailErr
+	}
+
+	// Генерация хэша и соли пароля (до транзакции)
+	passwordHashSalt, err := HashPassword(registrationData.Password)
+	if err != nil {
+		return models.Registration{}, err
+	}
+
+	// Создание профиля (автором создания считается сам пользователь, токен подтверждения адреса электронной почты отправляется при создании)
+	registration := models.Registration{
+		Login:                     login,
+		Email:                     registrationData.Email,
+		RegisteredAt:              time.Now().Unix(),
+		EmailVerificationRequired: verifyEmail,
+		ApprovalRequired:          requireApproval,
+		Status:                    models.AccountStatusPending,
+	}
+	if !verifyEmail && !requireApproval {
+		registration.Status = models.AccountStatusActive
+	}
+	profileData := models.ProfileData{
+		FirstName:  registrationData.FirstName,
+		LastName:   registrationData.LastName,
+		Email:      registrationData.Email,
+		Attributes: registrationData.Attributes,
+	}
+	err = t.Update(func(tx *Tx) error {
+		registration.ID, err = tx.AddProfile(login, profileData, passwordHashSalt, registration.Status, login)
+		if err != nil {
+			return err
+		}
+
+		// Запись заявки в очередь
+		if registration.Status == models.AccountStatusPending {
+			setEntry(t.db.undo, t.registrationsTab, registration.ID, registration)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Registration{}, err
+	}
+	return registration, nil
+}
+
+/*
+Получить очередь заявок на регистрацию тенанта
+
+:return: список заявок, отсортированный по времени регистрации
+*/
+func (t *Tenant) GetRegistrations() []models.Registration {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	registrations := make([]models.Registration, 0, len(t.registrationsTab))
+	for id, registration := range t.registrationsTab {
+		registration.Login = t.profilesDataTab[id].Login
+		registrations = append(registrations, registration)
+	}
+	sort.Slice(registrations, func(i, j int) bool {
+		if registrations[i].RegisteredAt != registrations[j].RegisteredAt {
+			return registrations[i].RegisteredAt < registrations[j].RegisteredAt
+		}
+		return registrations[i].ID < registrations[j].ID
+	})
+	return registrations
+}
+
+/*
+Активация учетной записи по заявке на регистрацию и удаление заявки из очереди (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются);
+учетная запись, статус которой уже изменил администратор, не меняется
+
+:param id string: идентификатор профиля
+:param author string: логин пользователя, активировавшего учетную запись (администратора, одобрившего заявку, или самого пользователя, подтвердившего адрес)
+*/
+func (t *Tenant) activateRegistration(id string, author string) {
+	deleteEntry(t.db.undo, t.registrationsTab, id)
+	accountStatus := t.accountsStatusTab[id]
+	if accountStatus.Status == models.AccountStatusPending {
+		setEntry(t.db.undo, t.accountsStatusTab, id, models.AccountStatus{Status: models.AccountStatusActive, ChangedAt: time.Now().Unix(), ExpiresAt: accountStatus.ExpiresAt})
+		t.recordChange(models.ChangeAccountActivated, id, 0, author)
+	}
+}
+
+/*
+Одобрение заявки на регистрацию: учетная запись становится активной, заявка удаляется из очереди
+
+:param id string: идентификатор профиля зарегистрировавшегося пользователя
+:param author string: логин администратора, одобряющего заявку
+
+:return: возвращается ошибка, если заявки нет в очереди, адрес электронной почты не подтвержден или базу данных не удалось сохранить
+*/
+func (t *Tenant) ApproveRegistration(id string, author string) error {
+	return t.Update(func(tx *Tx) error {
+		// Проверка заявки
+		registration, ok := t.registrationsTab[id]
+		if !ok {
+			return noRegistrationErr
+		}
+		if registration.EmailVerificationRequired && !registration.EmailVerified {
+			return emailNotVerifiedErr
+		}
+
+		// Активация учетной записи
+		t.activateRegistration(id, author)
+
+		return nil
+	})
+}
+
+/*
+Отклонение заявки на регистрацию: заявка удаляется из очереди, а профиль, если он все еще ожидает активации, удаляется окончательно
+
+:param id string: идентификатор профиля зарегистрировавшегося пользователя
+:param author string: логин администратора, отклоняющего заявку
+
+:return: возвращается ошибка, если заявки нет в очереди или базу данных не удалось сохранить
+*/
+func (t *Tenant) RejectRegistration(id string, author string) error {
+	return t.Update(func(tx *Tx) error {
+		// Проверка заявки
+		if _, ok := t.registrationsTab[id]; !ok {
+			return noRegistrationErr
+		}
+
+		// Удаление заявки и профиля, ожидающего активации
+		deleteEntry(t.db.undo, t.registrationsTab, id)
+		if t.accountStatus(id).Status == models.AccountStatusPending {
+			t.detachProfile(id, author)
+			t.eraseProfile(id)
+		}
+
+		return nil
+	})
+}
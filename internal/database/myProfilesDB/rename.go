@@ -0,0 +1,90 @@
+package myProfilesDB
+
+import (
+	"time"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Поиск действующего псевдонима логина
+
+:param alias string: старый логин переименованного профиля
+
+:return: псевдоним и true, если псевдоним существует и не истек, иначе - false
+*/
+func (t *Tenant) activeLoginAlias(alias string) (models.LoginAlias, bool) {
+	loginAlias, ok := t.loginAliasesTab[alias]
+	if !ok || loginAlias.ExpiresAt <= time.Now().Unix() {
+		return models.LoginAlias{}, false
+	}
+	return loginAlias, true
+}
+
+/*
+Получение текущего логина профиля по логину, который может быть старым логином переименованного профиля
+
+:param login string: логин или действующий псевдоним логина
+
+:return: текущий логин профиля, на который указывает псевдоним, или login, если это не псевдоним
+*/
+func (t *Tenant) ResolveLogin(login string) string {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	if _, ok := t.loginsTab[login]; ok {
+		return login
+	}
+	if loginAlias, ok := t.activeLoginAlias(login); ok {
+		return t.profilesDataTab[loginAlias.ID].Login
+	}
+	return login
+}
+
+/*
+Переименование профиля: логин профиля атомарно заменяется на новый (пароль, права администратора и членство в группах привязаны к идентификатору профиля и не меняются),
+старый логин становится псевдонимом профиля на время, заданное в конфиге
+
+:param login string: текущий логин профиля
+:param newLogin string: новый логин профиля
+:param author string: логин пользователя, переименовывающего профиль (записывается в историю профиля)
+
+:return: возвращается ошибка, если профиль с логином login не существует, новый логин пустой, занят другим профилем или псевдонимом другого профиля или базу данных не удалось сохранить
+*/
+func (t *Tenant) RenameProfile(login string, newLogin string, author string) error {
+	return t.Update(func(tx *Tx) error {
+		// Проверка наличия профиля с логином login и нового логина
+		id, ok := t.loginsTab[login]
+		if !ok {
+			return noProfileErr
+		}
+		if newLogin == "" {
+			return emptyLoginErr
+		}
+		if _, ok := t.loginsTab[newLogin]; ok {
+			return profileExistsErr
+		}
+		if loginAlias, ok := t.activeLoginAlias(newLogin); ok && loginAlias.ID != id {
+			return loginIsAliasErr
+		}
+
+		// Замена логина в данных профиля и в индексе логинов
+		profileData := t.profilesDataTab[id]
+		profileData.Login = newLogin
+		setEntry(t.db.undo, t.profilesDataTab, id, profileData)
+		deleteEntry(t.db.undo, t.loginsTab, login)
+		setEntry(t.db.undo, t.loginsTab, newLogin, id)
+		t.recordProfileHistory(id, author, models.HistoryActionRename, t.nextProfileVersion(id))
+
+		// Псевдонимы: истекшие удаляются, старый логин становится псевдонимом профиля
+		now := time.Now().Unix()
+		for alias, loginAlias := range t.loginAliasesTab {
+			if loginAlias.ExpiresAt <= now || alias == newLogin {
+				deleteEntry(t.db.undo, t.loginAliasesTab, alias)
+			}
+		}
+		setEntry(t.db.undo, t.loginAliasesTab, login, models.LoginAlias{ID: id, ExpiresAt: now + t.db.loginAliasLifetime})
+
+		return nil
+	})
+}
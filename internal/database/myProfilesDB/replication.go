@@ -0,0 +1,265 @@
+package myProfilesDB
+
+import (
+	"encoding/json"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Поднятие базы данных ведомого экземпляра: строится пустой экземпляр БД, данные которого загружаются снимком и записями журнала изменений ведущего экземпляра;
+файл базы данных не читается и не сохраняется
+
+:return: ошибка, если конфиг базы данных не удалось прочитать
+*/
+func RaiseFollowerDB() error {
+	db, err := buildMyProfilesDB()
+	if err != nil {
+		return err
+	}
+	db.follower = true
+	db.replicationEpoch = ""
+	DB = db
+	return nil
+}
+
+/*
+Пометка тенантов, измененных до следующего сохранения БД (вызывается методами БД под блокировкой db.mu): в запись журнала изменений БД попадут только эти тенанты;
+без названий тенантов помечается изменение только общих данных БД (админов платформы, клиентов и токенов OAuth 2.0)
+
+:param names ...string: названия измененных тенантов
+*/
+func (db *myProfilesDB) markDirty(names ...string) {
+	if db.dirtyTenants == nil {
+		db.dirtyTenants = make(map[string]struct{}, len(names))
+	}
+	for _, name := range names {
+		db.dirtyTenants[name] = struct{}{}
+	}
+}
+
+/*
+Составление записи журнала изменений БД (вызывается методами БД под блокировкой db.mu)
+
+:param dirtyTenants map[string]struct{}: тенанты, измененные с последнего сохранения (nil - в запись попадают все тенанты)
+
+:return: запись журнала с данными измененных тенантов и общими данными БД (номер записи присваивается при добавлении в журнал)
+*/
+func (db *myProfilesDB) replicationEntry(dirtyTenants map[string]struct{}) models.ReplicationEntry {
+	entry := models.ReplicationEntry{
+		SchemaVersion:     schemaVersion,
+		Full:              dirtyTenants == nil,
+		Tenants:           make(map[string]json.RawMessage),
+		PlatformAdminsTab: make([]string, 0, len(db.platformAdminsTab)),
+		ClientsDataTab:    make(map[string]models.OAuthClientData, len(db.clientsDataTab)),
+		ClientsSecretsTab: make(map[string]string, len(db.clientsSecretsTab)),
+		TokensTab:         make(map[string]models.OAuthTokenData, len(db.tokensTab)),
+	}
+	// Данные измененных тенантов (удаленные тенанты записываются как null)
+	for name, t := range db.tenantsTab {
+		if _, ok := dirtyTenants[name]; ok || entry.Full {
+			entry.Tenants[name], _ = json.Marshal(t.fileData())
+		}
+	}
+	for name := range dirtyTenants {
+		if _, ok := db.tenantsTab[name]; !ok {
+			entry.Tenants[name] = json.RawMessage("null")
+		}
+	}
+	// Копии общих данных БД (таблицы БД меняются на месте после записи в журнал)
+	for id := range db.platformAdminsTab {
+		entry.PlatformAdminsTab = append(entry.PlatformAdminsTab, id)
+	}
+	for clientID, clientData := range db.clientsDataTab {
+		entry.ClientsDataTab[clientID] = clientData
+	}
+	for clientID, secret := range db.clientsSecretsTab {
+		entry.ClientsSecretsTab[clientID] = secret
+	}
+	for key, tokenData := range db.tokensTab {
+		entry.TokensTab[key] = tokenData
+	}
+	return entry
+}
+
+/*
+Добавление записи в журнал изменений БД после сохранения БД (вызывается методами БД под блокировкой db.mu); журнал хранит не больше replicationLogSize последних записей
+
+:param entry models.ReplicationEntry: запись журнала
+*/
+func (db *myProfilesDB) appendReplicationEntry(entry models.ReplicationEntry) {
+	// Запись в журнал и уведомление ожидающих запросов журнала
+	db.replicationSeq++
+	entry.Seq = db.replicationSeq
+	db.replicationLog = append(db.replicationLog, entry)
+	if extra := len(db.replicationLog) - db.replicationLogSize; extra > 0 {
+		db.replicationLog = append([]models.ReplicationEntry(nil), db.replicationLog[extra:]...)
+	}
+	close(db.replicated)
+	db.replicated = make(chan struct{})
+}
+
+/*
+Получить снимок БД для начальной загрузки ведомого экземпляра
+
+:return: снимок всех данных БД в формате файла базы данных (без шифрования) с эпохой и номером последней записи журнала изменений БД
+*/
+func (db *myProfilesDB) ReplicationSnapshot() models.ReplicationSnapshot {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return models.ReplicationSnapshot{Epoch: db.replicationEpoch, Seq: db.replicationSeq, Data: db.fileData()}
+}
+
+/*
+Получить записи журнала изменений БД после заданного номера и канал уведомления о новых записях
+
+:param epoch string: эпоха журнала, в которой получен номер since
+:param since int64: номер последней полученной записи
+
+:return: записи с номерами больше since и номер последней записи, канал, который закрывается при добавлении следующей записи;
+возвращается ошибка ReplicationLogTruncatedErr, если эпоха журнала сменилась (ведущий экземпляр перезапущен), записи после since уже удалены из журнала
+или since больше номера последней записи - ведомому экземпляру нужно заново загрузить снимок
+*/
+func (db *myProfilesDB) ReplicationLog(epoch string, since int64) (models.ReplicationLog, <-chan struct{}, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	replicationLog := models.ReplicationLog{Epoch: db.replicationEpoch, LastSeq: db.replicationSeq, Entries: []models.ReplicationEntry{}}
+	firstSeq := db.replicationSeq - int64(len(db.replicationLog)) + 1
+	if epoch != db.replicationEpoch || since > db.replicationSeq || since < firstSeq-1 {
+		return replicationLog, db.replicated, ReplicationLogTruncatedErr
+	}
+	replicationLog.Entries = append(replicationLog.Entries, db.replicationLog[since-firstSeq+1:]...)
+	return replicationLog, db.replicated, nil
+}
+
+/*
+Получить позицию БД в журнале изменений БД
+
+:return: эпоха журнала (для ведомого экземпляра пустая строка, пока не загружен снимок) и номер последней (для ведомого - последней примененной) записи
+*/
+func (db *myProfilesDB) ReplicationPosition() (string, int64) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.replicationEpoch, db.replicationSeq
+}
+
+/*
+Загрузка снимка БД ведущего экземпляра в БД ведомого экземпляра: все данные БД атомарно заменяются данными снимка
+
+:param snapshot models.ReplicationSnapshot: снимок БД ведущего экземпляра
+
+:return: ошибка, если БД не является БД ведомого экземпляра или снимок некорректен
+*/
+func (db *myProfilesDB) ApplyReplicationSnapshot(snapshot models.ReplicationSnapshot) error {
+	if !db.follower {
+		return notFollowerErr
+	}
+	restored, err := db.parseBackup(snapshot.Data)
+	if err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.replaceData(restored)
+	db.replicationEpoch = snapshot.Epoch
+	db.replicationSeq = snapshot.Seq
+	return nil
+}
+
+/*
+Применение записи журнала изменений ведущего экземпляра к БД ведомого экземпляра: данные тенантов из записи и общие данные БД атомарно заменяются
+
+:param epoch string: эпоха журнала, из которого получена запись
+:param entry models.ReplicationEntry: запись журнала
+
+:return: ошибка, если БД не является БД ведомого экземпляра, запись не следует за последней примененной записью, версия схемы записи не совпадает с текущей
+или данные тенанта в записи некорректны (БД при этом не меняется)
+*/
+func (db *myProfilesDB) ApplyReplicationEntry(epoch string, entry models.ReplicationEntry) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// Проверка порядка записей
+	if !db.follower {
+		return notFollowerErr
+	}
+	if epoch != db.replicationEpoch || entry.Seq != db.replicationSeq+1 {
+		return replicationGapErr
+	}
+	err := db.applyEntryData(entry)
+	if err != nil {
+		return err
+	}
+
+	db.replicationSeq = entry.Seq
+	db.notifyChanges()
+	return nil
+}
+
+/*
+Замена данных БД данными записи журнала изменений БД (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются):
+данные тенантов из записи и общие данные БД атомарно заменяются; данные тенантов заменяются в тех же экземплярах тенантов, поэтому ссылки на тенанты остаются действительными
+
+:param entry models.ReplicationEntry: запись журнала
+
+:return: ошибка, если версия схемы записи не совпадает с текущей или данные тенанта в записи некорректны (БД при этом не меняется)
+*/
+func (db *myProfilesDB) applyEntryData(entry models.ReplicationEntry) error {
+	if entry.SchemaVersion != schemaVersion {
+		return replicationSchemaVersionErr
+	}
+
+	// Чтение данных тенантов до изменения БД (некорректная запись не применяется частично)
+	tenantsData := make(map[string]*tenantFileData, len(entry.Tenants))
+	for name, rawData := range entry.Tenants {
+		var tenantData *tenantFileData
+		err := json.Unmarshal(rawData, &tenantData)
+		if err != nil {
+			return err
+		}
+		tenantsData[name] = tenantData
+	}
+
+	// Замена данных тенантов
+	if entry.Full {
+		for name := range db.tenantsTab {
+			if _, ok := tenantsData[name]; !ok {
+				delete(db.tenantsTab, name)
+			}
+		}
+	}
+	for name, tenantData := range tenantsData {
+		switch t, ok := db.tenantsTab[name]; {
+		case tenantData == nil:
+			delete(db.tenantsTab, name)
+		case ok:
+			*t = *newTenant(db, name, *tenantData)
+		default:
+			db.tenantsTab[name] = newTenant(db, name, *tenantData)
+		}
+	}
+
+	// Замена общих данных БД копиями данных записи (запись может храниться в журнале, а таблицы БД меняются на месте)
+	db.platformAdminsTab = make(map[string]struct{}, len(entry.PlatformAdminsTab))
+	for _, id := range entry.PlatformAdminsTab {
+		db.platformAdminsTab[id] = struct{}{}
+	}
+	db.clientsDataTab = make(map[string]models.OAuthClientData, len(entry.ClientsDataTab))
+	for clientID, clientData := range entry.ClientsDataTab {
+		db.clientsDataTab[clientID] = clientData
+	}
+	db.clientsSecretsTab = make(map[string]string, len(entry.ClientsSecretsTab))
+	for clientID, secret := range entry.ClientsSecretsTab {
+		db.clientsSecretsTab[clientID] = secret
+	}
+	db.tokensTab = make(map[string]models.OAuthTokenData, len(entry.TokensTab))
+	for key, tokenData := range entry.TokensTab {
+		db.tokensTab[key] = tokenData
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package myProfilesDB
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Получение действующего статуса учетной записи профиля: активная учетная запись с истекшим сроком действия считается истекшей
+
+:param id string: идентификатор профиля
+
+:return: статус учетной записи
+*/
+func (t *Tenant) accountStatus(id string) models.AccountStatus {
+	accountStatus := t.accountsStatusTab[id]
+	if accountStatus.Status == models.AccountStatusActive && accountStatus.ExpiresAt != 0 && accountStatus.ExpiresAt <= time.Now().Unix() {
+		accountStatus.Status = models.AccountStatusExpired
+	}
+	return accountStatus
+}
+
+/*
+Получить статус учетной записи профиля
+
+:param login string: логин профиля
+
+:return: статус учетной записи или ошибка, если профиля с логином login нет
+*/
+func (t *Tenant) GetAccountStatus(login string) (accountStatus models.AccountStatus, err error) {
+	err = t.View(func(tx *Tx) error {
+		accountStatus, err = tx.GetAccountStatus(login)
+		return err
+	})
+	return
+}
+
+/*
+Получить список учетных записей тенанта со статусами
+
+:param status string: статус, по которому отбираются учетные записи (пустая строка - выводятся все учетные записи)
+
+:return: список учетных записей, отсортированный по логинам, или ошибка, если статус неизвестен
+*/
+func (t *Tenant) GetAccounts(status string) ([]models.AccountData, error) {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	switch status {
+	case "", models.AccountStatusPending, models.AccountStatusActive, models.AccountStatusSuspended, models.AccountStatusExpired:
+	default:
+		return nil, fmt.Errorf("%w \"%s\"", unknownAccountStatusErr, status)
+	}
+	accounts := make([]models.AccountData, 0, len(t.loginsTab))
+	for login, id := range t.loginsTab {
+		accountStatus := t.accountStatus(id)
+		if status == "" || accountStatus.Status == status {
+			accounts = append(accounts, models.AccountData{ID: id, Login: login, AccountStatus: accountStatus})
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].Login < accounts[j].Login })
+	return accounts, nil
+}
+
+/*
+Активация учетной записи: ожидающая активации, приостановленная или истекшая учетная запись становится активной (истекший срок действия снимается)
+
+:param login string: логин профиля
+:param author string: логин пользователя, активирующего учетную запись
+
+:return: возвращается ошибка, если профиля с логином login нет, учетная запись уже активна или базу данных не удалось сохранить
+*/
+func (t *Tenant) ActivateAccount(login string, author string) error {
+	return t.Update(func(tx *Tx) error {
+		return tx.ActivateAccount(login, author)
+	})
+}
+
+/*
+Активация учетной записи: ожидающая активации, приостановленная или истекшая учетная запись становится активной (истекший срок действия снимается); вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются
+
+:param login string: логин профиля
+:param author string: логин пользователя, активирующего учетную запись
+
+:return: возвращается ошибка, если профиля с логином login нет или учетная запись уже активна
+*/
+func (t *Tenant) activateAccount(login string, author string) error {
+	// Проверка наличия профиля и статуса учетной записи
+	id, ok := t.loginsTab[login]
+	if !ok {
+		return noProfileErr
+	}
+	accountStatus := t.accountStatus(id)
+	if accountStatus.Status == models.AccountStatusActive {
+		return fmt.Errorf("%w: account is already active", invalidStatusTransitionErr)
+	}
+
+	// Активация учетной записи
+	now := time.Now().Unix()
+	if accountStatus.ExpiresAt <= now {
+		accountStatus.ExpiresAt = 0
+	}
+	setEntry(t.db.undo, t.accountsStatusTab, id, models.AccountStatus{Status: models.AccountStatusActive, ChangedAt: now, ExpiresAt: accountStatus.ExpiresAt})
+	t.recordChange(models.ChangeAccountActivated, id, 0, author)
+	return nil
+}
+
+/*
+Приостановка активной учетной записи с указанием причины (данные профиля сохраняются, авторизация запрещается до активации)
+
+:param login string: логин профиля
+:param reason string: причина приостановки
+:param author string: логин пользователя, приостанавливающего учетную запись
+
+:return: возвращается ошибка, если профиля с логином login нет, причина не указана, учетная запись не активна или базу данных не удалось сохранить
+*/
+func (t *Tenant) SuspendAccount(login string, reason string, author string) error {
+	return t.Update(func(tx *Tx) error {
+		return tx.SuspendAccount(login, reason, author)
+	})
+}
+
+/*
+Приостановка активной учетной записи с указанием причины (данные профиля сохраняются, авторизация запрещается до активации); вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются
+
+:param login string: логин профиля
+:param reason string: причина приостановки
+:param author string: логин пользователя, приостанавливающего учетную запись
+
+:return: возвращается ошибка, если профиля с логином login нет, причина не указана или учетная запись не активна
+*/
+func (t *Tenant) suspendAccount(login string, reason string, author string) error {
+	// Проверка наличия профиля, причины и статуса учетной записи
+	id, ok := t.loginsTab[login]
+	if !ok {
+		return noProfileErr
+	}
+	if reason == "" {
+		return emptySuspensionReasonErr
+	}
+	accountStatus := t.accountStatus(id)
+	if accountStatus.Status != models.AccountStatusActive {
+		return fmt.Errorf("%w: only active account can be suspended, account is %s", invalidStatusTransitionErr, accountStatus.Status)
+	}
+
+	// Приостановка учетной записи
+	setEntry(t.db.undo, t.accountsStatusTab, id, models.AccountStatus{
+		Status:    models.AccountStatusSuspended,
+		Reason:    reason,
+		ChangedAt: time.Now().Unix(),
+		ExpiresAt: accountStatus.ExpiresAt,
+	})
+	t.recordChange(models.ChangeAccountSuspended, id, 0, author)
+	return nil
+}
+
+/*
+Изменение срока действия учетной записи: по истечении срока активная учетная запись автоматически становится истекшей
+
+:param login string: логин профиля
+:param expiresAt int64: время истечения срока действия (unix-время в секундах, 0 - срок не ограничен)
+:param author string: логин пользователя, изменяющего срок действия
+
+:return: возвращается ошибка, если профиля с логином login нет, время истечения уже прошло или базу данных не удалось сохранить
+*/
+func (t *Tenant) SetAccountExpiry(login string, expiresAt int64, author string) error {
+	return t.Update(func(tx *Tx) error {
+		return tx.SetAccountExpiry(login, expiresAt, author)
+	})
+}
+
+/*
+Изменение срока действия учетной записи (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются)
+
+:param login string: логин профиля
+:param expiresAt int64: время истечения срока действия (unix-время в секундах, 0 - срок не ограничен)
+:param author string: логин пользователя, изменяющего срок действия
+
+:return: возвращается ошибка, если профиля с логином login нет или время истечения уже прошло
+*/
+func (t *Tenant) setAccountExpiry(login string, expiresAt int64, author string) error {
+	// Проверка наличия профиля и времени истечения
+	id, ok := t.loginsTab[login]
+	if !ok {
+		return noProfileErr
+	}
+	if expiresAt < 0 || (expiresAt != 0 && expiresAt <= time.Now().Unix()) {
+		return invalidAccountExpiryErr
+	}
+
+	// Изменение срока действия
+	accountStatus := t.accountsStatusTab[id]
+	accountStatus.ExpiresAt = expiresAt
+	setEntry(t.db.undo, t.accountsStatusTab, id, accountStatus)
+	t.recordChange(models.ChangeAccountExpiryChanged, id, 0, author)
+	return nil
+}
@@ -0,0 +1,70 @@
+package myProfilesDB
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Срок действия учетной записи должен быть неограниченным (0) или в будущем
+func TestAccountExpiryValidation(t *testing.T) {
+	_, platform := openTestDB(t)
+	addTestProfile(t, platform, "alice")
+	now := time.Now().Unix()
+
+	for _, expiresAt := range []int64{-1, now - 60, now} {
+		if err := platform.SetAccountExpiry("alice", expiresAt, "admin"); !errors.Is(err, invalidAccountExpiryErr) {
+			t.Fatalf("expiry %d is accepted: %v", expiresAt, err)
+		}
+	}
+	for _, expiresAt := range []int64{now + 3600, 0} {
+		if err := platform.SetAccountExpiry("alice", expiresAt, "admin"); err != nil {
+			t.Fatalf("expiry %d is rejected: %s", expiresAt, err)
+		}
+	}
+	accountStatus, err := platform.GetAccountStatus("alice")
+	if err != nil || accountStatus.Status != models.AccountStatusActive || accountStatus.ExpiresAt != 0 {
+		t.Fatalf("unexpected account status: %+v %v", accountStatus, err)
+	}
+}
+
+// Каждое изменение статуса и срока действия учетной записи записывается в журнал изменений профилей
+func TestAccountStatusChangeEvents(t *testing.T) {
+	_, platform := openTestDB(t)
+	addTestProfile(t, platform, "alice")
+	_, lastSeq, _, err := platform.WatchChanges(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = platform.SuspendAccount("alice", "test", "admin")
+	if err == nil {
+		err = platform.ActivateAccount("alice", "admin")
+	}
+	if err == nil {
+		err = platform.SetAccountExpiry("alice", time.Now().Unix()+3600, "admin")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Отклоненное изменение статуса событий не создает
+	if err = platform.ActivateAccount("alice", "admin"); err == nil {
+		t.Fatal("active account is activated")
+	}
+
+	events, _, _, err := platform.WatchChanges(lastSeq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedTypes := []string{models.ChangeAccountSuspended, models.ChangeAccountActivated, models.ChangeAccountExpiryChanged}
+	if len(events) != len(expectedTypes) {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	for i, event := range events {
+		if event.Type != expectedTypes[i] || event.Login != "alice" || event.Author != "admin" {
+			t.Fatalf("unexpected event %d: %+v", i, event)
+		}
+	}
+}
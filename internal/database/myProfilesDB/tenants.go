@@ -0,0 +1,342 @@
+package myProfilesDB
+
+import (
+	"regexp"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Допустимые названия тенантов: название может использоваться как поддомен, поэтому оно должно быть корректной DNS-меткой
+var tenantNameRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+/*
+Построение тенанта in memory БД по данным тенанта из файла
+
+:param db *myProfilesDB: база данных, в которую входит тенант
+:param name string: название тенанта
+:param tenantData tenantFileData: данные тенанта
+
+:return: указатель на тенант
+*/
+func newTenant(db *myProfilesDB, name string, tenantData tenantFileData) *Tenant {
+	t := Tenant{
+		name:                 name,
+		db:                   db,
+		profilesDataTab:      make(map[string]models.ProfileData, len(tenantData.ProfilesDataTab)),
+		loginsTab:            make(map[string]string, len(tenantData.ProfilesDataTab)),
+		profilesPasswordsTab: tenantData.ProfilesPasswordsTab,
+		adminsTab:            make(map[string]struct{}, len(tenantData.AdminsTab)),
+		groupsTab:            make(map[string]*group, len(tenantData.GroupsTab)),
+		adminGroupsTab:       make(map[string]struct{}, len(tenantData.AdminGroupsTab)),
+		attributesSchemaTab:  tenantData.AttributesSchemaTab,
+		fieldsVisibilityTab:  tenantData.FieldsVisibilityTab,
+		profilesVersionsTab:  tenantData.ProfilesVersionsTab,
+		lastProfileVersion:   tenantData.LastProfileVersion,
+		loginAliasesTab:      tenantData.LoginAliasesTab,
+		accountsStatusTab:    tenantData.AccountsStatusTab,
+		profilesHistoryTab:   tenantData.ProfilesHistoryTab,
+		invitationsTab:       tenantData.InvitationsTab,
+		registrationsTab:     tenantData.RegistrationsTab,
+		verificationsTab:     tenantData.VerificationsTab,
+		deletedProfilesTab:   tenantData.DeletedProfilesTab,
+		changeLog:            tenantData.ChangeLog,
+		lastChangeSeq:        tenantData.LastChangeSeq,
+		webhooksTab:          tenantData.WebhooksTab,
+		webhookDeliveriesTab: tenantData.WebhookDeliveriesTab,
+		webhookDeadLetters:   tenantData.WebhookDeadLetters,
+	}
+	if t.profilesPasswordsTab == nil {
+		t.profilesPasswordsTab = make(map[string]string)
+	}
+	if t.attributesSchemaTab == nil {
+		t.attributesSchemaTab = make(map[string]models.AttributeDefinition)
+	}
+	if t.fieldsVisibilityTab == nil {
+		t.fieldsVisibilityTab = make(map[string]models.FieldVisibility)
+	}
+	if t.profilesVersionsTab == nil {
+		t.profilesVersionsTab = make(map[string]int64)
+	}
+	if t.loginAliasesTab == nil {
+		t.loginAliasesTab = make(map[string]models.LoginAlias)
+	}
+	if t.accountsStatusTab == nil {
+		t.accountsStatusTab = make(map[string]models.AccountStatus)
+	}
+	if t.profilesHistoryTab == nil {
+		t.profilesHistoryTab = make(map[string][]models.ProfileHistoryEntry)
+	}
+	if t.invitationsTab == nil {
+		t.invitationsTab = make(map[string]models.Invitation)
+	}
+	if t.registrationsTab == nil {
+		t.registrationsTab = make(map[string]models.Registration)
+	}
+	if t.verificationsTab == nil {
+		t.verificationsTab = make(map[string]models.EmailVerification)
+	}
+	if t.deletedProfilesTab == nil {
+		t.deletedProfilesTab = make(map[string]models.DeletedProfileData)
+	}
+	if t.webhooksTab == nil {
+		t.webhooksTab = make(map[string]models.Webhook)
+	}
+	if t.webhookDeliveriesTab == nil {
+		t.webhookDeliveriesTab = make(map[string][]models.WebhookDelivery)
+	}
+	// Профили и индекс логинов: в файлах, сохраненных до появления идентификаторов, профили хранятся по логинам -
+	// им выдаются идентификаторы, а их пароли и версии переносятся на идентификаторы
+	for key, profileData := range tenantData.ProfilesDataTab {
+		if profileData.ID == "" {
+			profileData.ID = newProfileID()
+			profileData.Login = key
+			if passwordHashSalt, ok := t.profilesPasswordsTab[key]; ok {
+				delete(t.profilesPasswordsTab, key)
+				t.profilesPasswordsTab[profileData.ID] = passwordHashSalt
+			}
+			if version, ok := t.profilesVersionsTab[key]; ok {
+				delete(t.profilesVersionsTab, key)
+				t.profilesVersionsTab[profileData.ID] = version
+			}
+		}
+		t.profilesDataTab[profileData.ID] = profileData
+		t.loginsTab[profileData.Login] = profileData.ID
+	}
+	// Профилям из файлов, сохраненных до появления версий, выдаются новые версии, а история изменений профилей из файлов, сохраненных до появления истории,
+	// начинается с текущих данных профиля
+	for id := range t.profilesDataTab {
+		if _, ok := t.profilesVersionsTab[id]; !ok {
+			t.nextProfileVersion(id)
+		}
+		if _, ok := t.profilesHistoryTab[id]; !ok {
+			t.recordProfileHistory(id, "", models.HistoryActionImport, t.profilesVersionsTab[id])
+		}
+		// Учетные записи профилей из файлов, сохраненных до появления статусов, активны
+		if _, ok := t.accountsStatusTab[id]; !ok {
+			t.accountsStatusTab[id] = models.AccountStatus{Status: models.AccountStatusActive}
+		}
+	}
+	for _, adminRef := range tenantData.AdminsTab {
+		if id, ok := t.profileIDByRef(adminRef); ok {
+			t.adminsTab[id] = struct{}{}
+		}
+	}
+	for _, groupData := range tenantData.GroupsTab {
+		g := newGroup(models.GroupData{Subgroups: groupData.Subgroups})
+		for _, memberRef := range groupData.Members {
+			if id, ok := t.profileIDByRef(memberRef); ok {
+				g.members[id] = struct{}{}
+			}
+		}
+		t.groupsTab[groupData.Name] = g
+	}
+	for _, groupName := range tenantData.AdminGroupsTab {
+		t.adminGroupsTab[groupName] = struct{}{}
+	}
+	return &t
+}
+
+/*
+Составление данных тенанта для хранения в файле
+
+:return: данные тенанта
+*/
+func (t *Tenant) fileData() tenantFileData {
+	adminsList := make([]string, 0, len(t.adminsTab))
+	for adminLogin := range t.adminsTab {
+		adminsList = append(adminsList, adminLogin)
+	}
+	groupsList := make([]models.GroupData, 0, len(t.groupsTab))
+	for groupName, g := range t.groupsTab {
+		groupsList = append(groupsList, g.groupData(groupName))
+	}
+	adminGroupsList := make([]string, 0, len(t.adminGroupsTab))
+	for groupName := range t.adminGroupsTab {
+		adminGroupsList = append(adminGroupsList, groupName)
+	}
+	return tenantFileData{
+		ProfilesDataTab:      t.profilesDataTab,
+		ProfilesPasswordsTab: t.profilesPasswordsTab,
+		AdminsTab:            adminsList,
+		GroupsTab:            groupsList,
+		AdminGroupsTab:       adminGroupsList,
+		AttributesSchemaTab:  t.attributesSchemaTab,
+		FieldsVisibilityTab:  t.fieldsVisibilityTab,
+		ProfilesVersionsTab:  t.profilesVersionsTab,
+		LastProfileVersion:   t.lastProfileVersion,
+		LoginAliasesTab:      t.loginAliasesTab,
+		AccountsStatusTab:    t.accountsStatusTab,
+		ProfilesHistoryTab:   t.profilesHistoryTab,
+		InvitationsTab:       t.invitationsTab,
+		RegistrationsTab:     t.registrationsTab,
+		VerificationsTab:     t.verificationsTab,
+		DeletedProfilesTab:   t.deletedProfilesTab,
+		ChangeLog:            t.changeLog,
+		LastChangeSeq:        t.lastChangeSeq,
+		WebhooksTab:          t.webhooksTab,
+		WebhookDeliveriesTab: t.webhookDeliveriesTab,
+		WebhookDeadLetters:   t.webhookDeadLetters,
+	}
+}
+
+/*
+Получение названия тенанта
+
+:return: название тенанта
+*/
+func (t *Tenant) Name() string {
+	return t.name
+}
+
+/*
+Получение тенанта по названию
+
+:param name string: название тенанта
+
+:return: указатель на тенант или ошибка, если тенанта с таким названием нет
+*/
+func (db *myProfilesDB) GetTenant(name string) (*Tenant, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	t, ok := db.tenantsTab[name]
+	if !ok {
+		return nil, noTenantErr
+	}
+	return t, nil
+}
+
+/*
+Получение названия тенанта платформы
+
+:return: название тенанта платформы
+*/
+func (db *myProfilesDB) PlatformTenant() string {
+	return db.platformTenant
+}
+
+/*
+Получить список названий всех тенантов
+
+:return: список названий всех тенантов
+*/
+func (db *myProfilesDB) GetAllTenants() (names []string) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for name := range db.tenantsTab {
+		names = append(names, name)
+	}
+	return
+}
+
+/*
+Создание нового пустого тенанта
+
+:param name string: название нового тенанта (латинские строчные буквы, цифры и дефисы)
+
+:return: возвращается ошибка, если название некорректно, тенант уже существует или базу данных не удалось сохранить
+*/
+func (db *myProfilesDB) AddTenant(name string) error {
+	return db.Update(func() error {
+		// Проверка названия и наличия тенанта
+		if !tenantNameRegexp.MatchString(name) {
+			return invalidTenantNameErr
+		}
+		_, ok := db.tenantsTab[name]
+		if ok {
+			return tenantExistsErr
+		}
+
+		// Добавление тенанта
+		setEntry(db.undo, db.tenantsTab, name, newTenant(db, name, tenantFileData{}))
+		db.markDirty(name)
+		return nil
+	})
+}
+
+/*
+Удаление тенанта вместе со всеми его профилями и группами
+
+:param name string: название удаляемого тенанта
+
+:return: возвращается ошибка, если тенанта не существует, это тенант платформы или базу данных не удалось сохранить
+*/
+func (db *myProfilesDB) RemoveTenant(name string) error {
+	return db.Update(func() error {
+		// Проверка наличия тенанта
+		_, ok := db.tenantsTab[name]
+		if !ok {
+			return noTenantErr
+		}
+		if name == db.platformTenant {
+			return canNotRemovePlatformTenantErr
+		}
+
+		// Удаление тенанта
+		deleteEntry(db.undo, db.tenantsTab, name)
+		db.markDirty(name)
+		return nil
+	})
+}
+
+/*
+Проверка профиля тенанта платформы, входит ли он в список администраторов платформы
+
+:param login string: логин профиля тенанта платформы
+
+:return: возвращается true, если login в списке администраторов платформы, иначе - false
+*/
+func (db *myProfilesDB) IsPlatformAdmin(login string) bool {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	id, ok := db.tenantsTab[db.platformTenant].loginsTab[login]
+	if !ok {
+		return false
+	}
+	_, ok = db.platformAdminsTab[id]
+	return ok
+}
+
+/*
+Добавление профиля тенанта платформы в список администраторов платформы
+
+:param login string: логин профиля тенанта платформы
+
+:return: возвращается ошибка, если профиль с логином login не существует в тенанте платформы или базу данных не удалось сохранить
+*/
+func (db *myProfilesDB) AddPlatformAdmin(login string) error {
+	return db.Update(func() error {
+		// Проверка наличия профиля с логином login в тенанте платформы
+		id, ok := db.tenantsTab[db.platformTenant].loginsTab[login]
+		if !ok {
+			return noProfileErr
+		}
+
+		// Добавление профиля в список администраторов платформы
+		setEntry(db.undo, db.platformAdminsTab, id, struct{}{})
+		return nil
+	})
+}
+
+/*
+Удаление профиля тенанта платформы из списка администраторов платформы
+
+:param login string: логин профиля, удаляемого из списка администраторов платформы
+
+:return: возвращается ошибка, если профиль с логином login не существует в тенанте платформы или базу данных не удалось сохранить
+*/
+func (db *myProfilesDB) DropPlatformAdmin(login string) error {
+	return db.Update(func() error {
+		// Проверка наличия профиля с логином login в тенанте платформы
+		id, ok := db.tenantsTab[db.platformTenant].loginsTab[login]
+		if !ok {
+			return noProfileErr
+		}
+
+		// Удаление профиля из списка администраторов платформы
+		deleteEntry(db.undo, db.platformAdminsTab, id)
+		return nil
+	})
+}
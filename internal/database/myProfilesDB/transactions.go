@@ -0,0 +1,532 @@
+package myProfilesDB
+
+import (
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Транзакция над данными тенанта: методы транзакции выполняются под блокировкой БД, взятой на все время транзакции,
+// поэтому несколько шагов транзакции (чтение, проверка, запись) не пересекаются с другими запросами
+type Tx struct {
+	t        *Tenant // тенант, над данными которого выполняется транзакция
+	writable bool    // true - транзакция изменения данных (Update), false - транзакция чтения (View)
+	closed   bool    // true - транзакция завершена, ее методы больше не выполняются
+}
+
+/*
+Выполнение транзакции чтения данных тенанта: fn выполняется под блокировкой БД на чтение, данные не меняются
+(внутри fn нельзя вызывать методы тенанта - только методы транзакции)
+
+:param fn func(tx *Tx) error: шаги транзакции
+
+:return: ошибка, возвращенная fn
+*/
+func (t *Tenant) View(fn func(tx *Tx) error) error {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	tx := &Tx{t: t}
+	defer func() { tx.closed = true }()
+	return fn(tx)
+}
+
+/*
+Выполнение транзакции изменения данных тенанта: fn выполняется под блокировкой БД, после чего изменения сохраняются в файл один раз;
+если fn возвращает ошибку (или паникует) или базу данных не удалось сохранить, все изменения транзакции отменяются.
+Письма, отправляемые шагами транзакции, откладываются до ее фиксации и не отправляются, если транзакция отменена
+(внутри fn нельзя вызывать методы тенанта - только методы транзакции)
+
+:param fn func(tx *Tx) error: шаги транзакции
+
+:return: ошибка, возвращенная fn, или ошибка сохранения базы данных
+*/
+func (t *Tenant) Update(fn func(tx *Tx) error) error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	// Журнал отмены транзакции и очередь отложенных писем
+	undo := &undoLog{}
+	t.db.undo = undo
+	lastChangeSeq := t.lastChangeSeq
+	t.db.outbox = make([]func(), 0)
+	tx := &Tx{t: t, writable: true}
+	committed := false
+	defer func() {
+		tx.closed = true
+		outbox := t.db.outbox
+		t.db.outbox, t.db.undo = nil, nil
+		if !committed {
+			undo.rollback()
+			return
+		}
+		if t.lastChangeSeq != lastChangeSeq {
+			t.db.notifyChanges()
+		}
+		for _, send := range outbox {
+			send()
+		}
+	}()
+
+	// Выполнение шагов транзакции и сохранение данных в файл
+	err := fn(tx)
+	if err != nil {
+		return err
+	}
+	t.db.markDirty(t.name)
+	err = t.db.Dump()
+	if err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+/*
+Выполнение изменения данных БД, не относящихся к одному тенанту (тенанты, администраторы платформы, клиенты и токены OAuth 2.0): fn выполняется под блокировкой БД,
+после чего изменения сохраняются в файл один раз; если fn возвращает ошибку (или паникует) или базу данных не удалось сохранить, все изменения отменяются.
+Изменения в fn записываются через журнал отмены (setEntry, deleteEntry), добавленные и удаленные тенанты отмечаются markDirty; если fn ничего не изменила, файл не сохраняется
+
+:param fn func() error: шаги изменения
+
+:return: ошибка, возвращенная fn, или ошибка сохранения базы данных
+*/
+func (db *myProfilesDB) Update(fn func() error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// Журнал отмены изменения
+	undo := &undoLog{}
+	db.undo = undo
+	committed := false
+	defer func() {
+		db.undo = nil
+		if !committed {
+			undo.rollback()
+			db.dirtyTenants = nil
+		}
+	}()
+
+	// Выполнение шагов изменения и сохранение данных в файл (общие данные БД сохраняются вместе с отмеченными тенантами)
+	err := fn()
+	if err != nil {
+		return err
+	}
+	if len(undo.steps) != 0 {
+		db.markDirty()
+		err = db.Dump()
+		if err != nil {
+			return err
+		}
+	}
+	committed = true
+	return nil
+}
+
+/*
+Проверка, можно ли изменять данные в транзакции
+
+:return: ошибка, если транзакция завершена или является транзакцией чтения
+*/
+func (tx *Tx) checkWritable() error {
+	if tx.closed {
+		return closedTransactionErr
+	}
+	if !tx.writable {
+		return readOnlyTransactionErr
+	}
+	return nil
+}
+
+/*
+Отправка письма (вызывается методами БД под блокировкой db.mu): во время выполнения транзакции письмо откладывается до ее фиксации
+и не отправляется, если транзакция отменена
+
+:param send func(): отправка письма
+*/
+func (db *myProfilesDB) sendMail(send func()) {
+	if db.outbox != nil {
+		db.outbox = append(db.outbox, send)
+		return
+	}
+	send()
+}
+
+/*
+Шифрование пароля для записи в профиль (выполняется до транзакции: шифрование bcrypt занимает заметное время)
+
+:param password string: пароль (должен быть не длиннее 72 символов)
+
+:return: зашифрованный пароль (хэш+соль) или ошибка, если пароль неподходящий
+*/
+func HashPassword(password string) (string, error) {
+	passwordHashSalt, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", incorrectPasswordErr
+	}
+	return string(passwordHashSalt), nil
+}
+
+/*
+Получить данные о профиле по логину вместе с текущей версией профиля
+
+:param login string: логин получаемого профиля
+
+:return: данные о профиле с логином login (включая идентификатор профиля) и его версия или ошибка, исли профиля с таким логином нет
+*/
+func (tx *Tx) GetProfileData(login string) (models.ProfileData, int64, error) {
+	id, ok := tx.t.loginsTab[login]
+	if !ok {
+		return models.ProfileData{}, 0, noProfileErr
+	}
+	return tx.t.profilesDataTab[id], tx.t.profilesVersionsTab[id], nil
+}
+
+/*
+Составление данных профиля, которые может видеть пользователь: поля, скрытые от пользователя настройками видимости, удаляются
+
+:param profileData models.ProfileData: полные данные профиля
+:param viewerLogin string: логин пользователя тенанта, который просматривает профиль (пустая строка - пользователь не из тенанта)
+:param viewerIsAdmin bool: true, если пользователь является администратором тенанта
+
+:return: данные профиля, видимые пользователю
+*/
+func (tx *Tx) RedactProfileData(profileData models.ProfileData, viewerLogin string, viewerIsAdmin bool) models.ProfileData {
+	return tx.t.redactProfileData(profileData, viewerLogin, viewerIsAdmin)
+}
+
+/*
+Получить функцию, проверяющую, видит ли пользователь поле профиля (функцию можно вызывать только до завершения транзакции)
+
+:param ownerLogin string: логин просматриваемого профиля
+:param viewerLogin string: логин пользователя тенанта, который просматривает профиль (пустая строка - пользователь не из тенанта)
+:param viewerIsAdmin bool: true, если пользователь является администратором тенанта
+
+:return: функция, возвращающая true, если пользователь видит основное поле или дополнительный атрибут field
+*/
+func (tx *Tx) FieldViewer(ownerLogin string, viewerLogin string, viewerIsAdmin bool) func(field string) bool {
+	return tx.t.fieldViewer(ownerLogin, viewerLogin, viewerIsAdmin)
+}
+
+/*
+Получить список логинов всех зарегистрированных пользователей
+
+:return: список логинов всех зарегистрированных пользователей
+*/
+func (tx *Tx) GetAllLogins() (logins []string) {
+	for login := range tx.t.loginsTab {
+		logins = append(logins, login)
+	}
+	return
+}
+
+/*
+Проверка профиля, входит ли он в список администраторов непосредственно или через группу администраторов (с учетом вложенности групп)
+
+:param login string: логин профиля, проверяемого по списку администраторов
+
+:return: возвращается true, если login в списке администраторов или в группе администраторов, иначе - false
+*/
+func (tx *Tx) IsAdmin(login string) bool {
+	return tx.t.isAdmin(login, "", "")
+}
+
+/*
+Получить статус учетной записи профиля
+
+:param login string: логин профиля
+
+:return: статус учетной записи или ошибка, если профиля с логином login нет
+*/
+func (tx *Tx) GetAccountStatus(login string) (models.AccountStatus, error) {
+	id, ok := tx.t.loginsTab[login]
+	if !ok {
+		return models.AccountStatus{}, noProfileErr
+	}
+	return tx.t.accountStatus(id), nil
+}
+
+/*
+Добавление нового профиля; профилю выдается новый идентификатор
+
+:param login string: логин нового профиля
+:param profileData models.ProfileData: данные для хранения в новом профиле
+:param passwordHashSalt string: пароль, зашифрованный HashPassword (пустая строка - пароль не задан, профиль не может авторизоваться)
+:param status string: начальный статус учетной записи: pending (ожидает активации) или active (пустая строка - active)
+:param author string: логин пользователя, создающего профиль (пустая строка - профиль создается сервисом)
+
+:return: идентификатор нового профиля или ошибка, если транзакция только для чтения, профиль с логином login уже существует, login - псевдоним другого профиля,
+начальный статус недопустим или атрибуты не соответствуют схеме атрибутов
+*/
+func (tx *Tx) AddProfile(login string, profileData models.ProfileData, passwordHashSalt string, status string, author string) (string, error) {
+	err := tx.checkWritable()
+	if err != nil {
+		return "", err
+	}
+	status, err = newAccountStatus(status)
+	if err != nil {
+		return "", err
+	}
+	return tx.t.addProfile(login, profileData, passwordHashSalt, status, author)
+}
+
+/*
+Замена данных в профиле на новые; версия профиля проверяется и увеличивается атомарно с заменой данных
+
+:param login string: логин редактируемого профиля
+:param profileData models.ProfileData: новые данные для хранения в профиле (идентификатор и логин профиля не меняются)
+:param expectedVersion int64: ожидаемая текущая версия профиля (0 - версия не проверяется)
+:param author string: логин пользователя, редактирующего профиль (записывается в историю профиля)
+
+:return: новая версия профиля или ошибка, если транзакция только для чтения, профиль с логином login не существует, версия профиля не совпадает с ожидаемой
+или атрибуты не соответствуют схеме атрибутов
+*/
+func (tx *Tx) EditProfile(login string, profileData models.ProfileData, expectedVersion int64, author string) (int64, error) {
+	err := tx.checkWritable()
+	if err != nil {
+		return 0, err
+	}
+	return tx.t.editProfile(login, profileData, expectedVersion, author)
+}
+
+/*
+Изменение пароля в профиле
+
+:param login string: логин, у которого меняется пароль
+:param passwordHashSalt string: новый пароль, зашифрованный HashPassword
+
+:return: возвращается ошибка, если транзакция только для чтения, пароль пустой или профиль с логином login не существует
+*/
+func (tx *Tx) ChangePassword(login string, passwordHashSalt string) error {
+	err := tx.checkWritable()
+	if err != nil {
+		return err
+	}
+	if passwordHashSalt == "" {
+		return emptyPasswordErr
+	}
+	return tx.t.changePassword(login, passwordHashSalt)
+}
+
+/*
+Удаление профиля: профиль переносится в корзину или удаляется окончательно вместе с паролем; версия профиля проверяется атомарно с удалением
+
+:param login string: логин удаляемого профиля
+:param expectedVersion int64: ожидаемая текущая версия профиля (0 - версия не проверяется)
+:param hard bool: true - профиль удаляется окончательно вместе с историей изменений, минуя корзину
+:param author string: логин пользователя, удаляющего профиль (записывается в историю профиля)
+
+:return: возвращается ошибка, если транзакция только для чтения, профиль с логином login не существует или версия профиля не совпадает с ожидаемой
+*/
+func (tx *Tx) RemoveProfile(login string, expectedVersion int64, hard bool, author string) error {
+	err := tx.checkWritable()
+	if err != nil {
+		return err
+	}
+	return tx.t.removeProfile(login, expectedVersion, hard, author)
+}
+
+/*
+Добавление профиля в список админов
+
+:param login string: логин добавляемого администратора
+
+:return: возвращается ошибка, если транзакция только для чтения или профиль с логином login не существует
+*/
+func (tx *Tx) AddAdmin(login string) error {
+	err := tx.checkWritable()
+	if err != nil {
+		return err
+	}
+	return tx.t.addAdmin(login)
+}
+
+/*
+Удаление профиля из списка админов
+
+:param login string: логин профиля, удаляемого из списка администраторов
+
+:return: возвращается ошибка, если транзакция только для чтения или профиль с логином login не существует
+*/
+func (tx *Tx) DropAdmin(login string) error {
+	err := tx.checkWritable()
+	if err != nil {
+		return err
+	}
+	return tx.t.dropAdmin(login)
+}
+
+/*
+Добавление профиля в группу
+
+:param name string: название группы
+:param login string: логин добавляемого профиля
+
+:return: возвращается ошибка, если транзакция только для чтения, группы или профиля не существует
+*/
+func (tx *Tx) AddGroupMember(name string, login string) error {
+	err := tx.checkWritable()
+	if err != nil {
+		return err
+	}
+	return tx.t.addGroupMember(name, login)
+}
+
+/*
+Удаление профиля из группы
+
+:param name string: название группы
+:param login string: логин удаляемого профиля
+
+:return: возвращается ошибка, если транзакция только для чтения, группы не существует или профиль не входит в группу
+*/
+func (tx *Tx) RemoveGroupMember(name string, login string) error {
+	err := tx.checkWritable()
+	if err != nil {
+		return err
+	}
+	return tx.t.removeGroupMember(name, login)
+}
+
+/*
+Активация учетной записи: ожидающая активации, приостановленная или истекшая учетная запись становится активной (истекший срок действия снимается)
+
+:param login string: логин профиля
+:param author string: логин пользователя, активирующего учетную запись
+
+:return: возвращается ошибка, если транзакция только для чтения, профиля с логином login нет или учетная запись уже активна
+*/
+func (tx *Tx) ActivateAccount(login string, author string) error {
+	err := tx.checkWritable()
+	if err != nil {
+		return err
+	}
+	return tx.t.activateAccount(login, author)
+}
+
+/*
+Приостановка активной учетной записи с указанием причины
+
+:param login string: логин профиля
+:param reason string: причина приостановки
+:param author string: логин пользователя, приостанавливающего учетную запись
+
+:return: возвращается ошибка, если транзакция только для чтения, профиля с логином login нет, причина не указана или учетная запись не активна
+*/
+func (tx *Tx) SuspendAccount(login string, reason string, author string) error {
+	err := tx.checkWritable()
+	if err != nil {
+		return err
+	}
+	return tx.t.suspendAccount(login, reason, author)
+}
+
+/*
+Изменение срока действия учетной записи
+
+:param login string: логин профиля
+:param expiresAt int64: время истечения срока действия (unix-время в секундах, 0 - срок не ограничен)
+:param author string: логин пользователя, изменяющего срок действия
+
+:return: возвращается ошибка, если транзакция только для чтения, профиля с логином login нет или время истечения уже прошло
+*/
+func (tx *Tx) SetAccountExpiry(login string, expiresAt int64, author string) error {
+	err := tx.checkWritable()
+	if err != nil {
+		return err
+	}
+	return tx.t.setAccountExpiry(login, expiresAt, author)
+}
+
+/*
+Изменение видимости основного поля или дополнительного атрибута профилей тенанта
+
+:param fieldVisibility models.FieldVisibility: новая настройка видимости поля
+
+:return: возвращается ошибка, если транзакция только для чтения, поле неизвестно, уровень видимости неизвестен или одной из групп не существует
+*/
+func (tx *Tx) SetFieldVisibility(fieldVisibility models.FieldVisibility) error {
+	err := tx.checkWritable()
+	if err != nil {
+		return err
+	}
+	return tx.t.setFieldVisibility(fieldVisibility)
+}
+
+/*
+Восстановление профиля из корзины вместе с паролем, правами администраторов и членством в группах
+
+:param id string: идентификатор удаленного профиля
+:param author string: логин пользователя, восстанавливающего профиль
+
+:return: логин восстановленного профиля; возвращается ошибка, если транзакция только для чтения, профиля нет в корзине, его логин занят
+или атрибуты профиля не соответствуют текущей схеме атрибутов
+*/
+func (tx *Tx) RestoreProfile(id string, author string) (string, error) {
+	err := tx.checkWritable()
+	if err != nil {
+		return "", err
+	}
+	return tx.t.restoreProfile(id, author)
+}
+
+/*
+Регистрация вебхука
+
+:param webhookData models.WebhookData: адрес и типы событий вебхука
+:param author string: логин пользователя, регистрирующего вебхук
+
+:return: вебхук с секретом подписи; возвращается ошибка, если транзакция только для чтения, адрес некорректен или тип события неизвестен
+*/
+func (tx *Tx) AddWebhook(webhookData models.WebhookData, author string) (models.Webhook, error) {
+	err := tx.checkWritable()
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	return tx.t.addWebhook(webhookData, author)
+}
+
+/*
+Удаление вебхука вместе с историей доставок и недоставленными событиями вебхука
+
+:param id string: идентификатор вебхука
+
+:return: возвращается ошибка, если транзакция только для чтения или вебхука не существует
+*/
+func (tx *Tx) RemoveWebhook(id string) error {
+	err := tx.checkWritable()
+	if err != nil {
+		return err
+	}
+	return tx.t.removeWebhook(id)
+}
+
+/*
+Повторная доставка недоставленного события
+
+:param deliveryID string: идентификатор доставки
+
+:return: возвращается ошибка, если транзакция только для чтения, доставки нет в списке недоставленных событий или ее вебхук удален
+*/
+func (tx *Tx) RetryWebhookDelivery(deliveryID string) error {
+	err := tx.checkWritable()
+	if err != nil {
+		return err
+	}
+	return tx.t.retryWebhookDelivery(deliveryID)
+}
+
+/*
+Запись доставки тестового события в историю доставок вебхука
+
+:param delivery models.WebhookDelivery: доставка тестового события
+
+:return: возвращается ошибка, если транзакция только для чтения или вебхук удален
+*/
+func (tx *Tx) recordWebhookTest(delivery models.WebhookDelivery) error {
+	err := tx.checkWritable()
+	if err != nil {
+		return err
+	}
+	return tx.t.recordWebhookTest(delivery)
+}
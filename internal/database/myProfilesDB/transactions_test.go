@@ -0,0 +1,216 @@
+package myProfilesDB
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Составление данных БД для сравнения (списки, которые составляются из таблиц в произвольном порядке, сортируются)
+
+:param t *testing.T: тест
+:param db *myProfilesDB: БД
+
+:return: json-документ со всеми данными БД
+*/
+func dbState(t *testing.T, db *myProfilesDB) string {
+	t.Helper()
+	db.mu.RLock()
+	fileData := db.fileData()
+	db.mu.RUnlock()
+
+	var dbData myProfilesDBFileData
+	err := json.Unmarshal(fileData, &dbData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(dbData.PlatformAdminsTab)
+	for name, tenantData := range dbData.TenantsTab {
+		sort.Strings(tenantData.AdminsTab)
+		sort.Strings(tenantData.AdminGroupsTab)
+		for _, groupData := range tenantData.GroupsTab {
+			sort.Strings(groupData.Members)
+			sort.Strings(groupData.Subgroups)
+		}
+		sort.Slice(tenantData.GroupsTab, func(i, j int) bool { return tenantData.GroupsTab[i].Name < tenantData.GroupsTab[j].Name })
+		dbData.TenantsTab[name] = tenantData
+	}
+	state, _ := json.Marshal(dbData)
+	return string(state)
+}
+
+/*
+Заполнение тенанта данными, которые меняются тестируемыми транзакциями
+
+:param t *testing.T: тест
+:param tenant *Tenant: тенант
+:param receiver *webhookReceiver: адрес вебхука тенанта
+
+:return: идентификаторы профилей в корзине (восстанавливаемого и окончательно удаляемого) и идентификатор вебхука
+*/
+func fillTestTenant(t *testing.T, tenant *Tenant, receiver *webhookReceiver) (string, string, string) {
+	t.Helper()
+	err := tenant.SetAttributeDefinition(models.AttributeDefinition{Name: "dept", Type: models.AttributeTypeString})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, login := range []string{"alice", "bob", "carol", "dave"} {
+		addTestProfile(t, tenant, login)
+	}
+	_, err = tenant.EditProfile("alice", models.ProfileData{FirstName: "Alice", LastName: "User", Attributes: map[string]interface{}{"dept": "sales"}}, 0, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err = range []error{tenant.AddGroup("staff"), tenant.AddGroup("ops"), tenant.AddGroupMember("staff", "alice"), tenant.AddSubgroup("staff", "ops")} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	webhook, err := tenant.AddWebhook(models.WebhookData{URL: receiver.server.URL}, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return trashTestProfile(t, tenant, "carol"), trashTestProfile(t, tenant, "dave"), webhook.ID
+}
+
+// Все изменения транзакции, вернувшей ошибку, отменяются журналом отмены
+func TestUpdateRollback(t *testing.T) {
+	db, platform := openTestDB(t)
+	receiver := newWebhookReceiver(t, http.StatusOK)
+	restoredID, purgedID, webhookID := fillTestTenant(t, platform, receiver)
+	passwordHashSalt, err := HashPassword("Passw0rd!y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := dbState(t, db)
+
+	rollbackErr := errors.New("rollback")
+	err = platform.Update(func(tx *Tx) error {
+		_, err := tx.AddProfile("erin", models.ProfileData{Login: "erin", FirstName: "Erin", LastName: "User"}, passwordHashSalt, models.AccountStatusActive, "admin")
+		steps := []error{err}
+		_, err = tx.EditProfile("alice", models.ProfileData{FirstName: "Alicia", LastName: "User", Attributes: map[string]interface{}{"dept": "support"}}, 0, "admin")
+		steps = append(steps, err,
+			tx.ChangePassword("bob", passwordHashSalt),
+			tx.AddAdmin("bob"),
+			tx.AddGroupMember("staff", "bob"),
+			tx.RemoveGroupMember("staff", "alice"),
+			tx.SuspendAccount("bob", "test", "admin"),
+			tx.SetAccountExpiry("alice", time.Now().Unix()+3600, "admin"),
+			tx.SetFieldVisibility(models.FieldVisibility{Field: models.FieldEmail, Visibility: models.VisibilityAdmin, VisibleToGroups: []string{"staff"}}),
+		)
+		_, err = tx.RestoreProfile(restoredID, "admin")
+		steps = append(steps, err, tx.PurgeProfile(purgedID))
+		_, err = tx.AddWebhook(models.WebhookData{URL: receiver.server.URL + "/other"}, "admin")
+		steps = append(steps, err,
+			tx.RemoveWebhook(webhookID),
+			tx.RemoveProfile("alice", 0, false, "admin"),
+			tx.RemoveProfile("erin", 0, true, "admin"),
+		)
+		for i, err := range steps {
+			if err != nil {
+				t.Errorf("step %d: %s", i, err)
+			}
+		}
+		return rollbackErr
+	})
+	if !errors.Is(err, rollbackErr) {
+		t.Fatalf("unexpected transaction error: %v", err)
+	}
+	if after := dbState(t, db); after != before {
+		t.Fatalf("database is changed by rolled back transaction:\nbefore: %s\nafter:  %s", before, after)
+	}
+}
+
+// Если базу данных не удалось сохранить, изменения отменяются, в том числе изменения методов тенанта, меняющих таблицы без методов транзакции
+func TestUpdateRollbackOnDumpError(t *testing.T) {
+	db, platform := openTestDB(t)
+	fillTestTenant(t, platform, newWebhookReceiver(t, http.StatusOK))
+	before := dbState(t, db)
+
+	db.mu.Lock()
+	db.follower = true // сохранение БД ведомого экземпляра отклоняется
+	db.mu.Unlock()
+	steps := []error{
+		platform.RenameProfile("alice", "alicia", "admin"),
+		platform.RemoveAttributeDefinition("dept", "admin"),
+		platform.RenameGroup("ops", "devops"),
+		platform.RemoveGroup("staff"),
+		platform.AddAdminGroup("staff"),
+	}
+	db.mu.Lock()
+	db.follower = false
+	db.mu.Unlock()
+
+	for i, err := range steps {
+		if err == nil {
+			t.Fatalf("step %d succeeded without saving database", i)
+		}
+	}
+	if after := dbState(t, db); after != before {
+		t.Fatalf("database is changed by rolled back transactions:\nbefore: %s\nafter:  %s", before, after)
+	}
+}
+
+// Если базу данных не удалось сохранить, изменения тенантов, администраторов платформы, клиентов и токенов OAuth 2.0 отменяются
+func TestDBUpdateRollbackOnDumpError(t *testing.T) {
+	db, platform := openTestDB(t)
+	addTestProfile(t, platform, "bob")
+	addTestProfile(t, platform, "carol")
+	for _, err := range []error{
+		db.AddTenant("acme"),
+		db.AddPlatformAdmin("bob"),
+		db.AddClient(models.OAuthClientData{ClientID: "app", Scopes: []string{"profiles"}}, "secret"),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	token, _, err := db.IssueToken("app", "profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := dbState(t, db)
+
+	db.mu.Lock()
+	db.follower = true // сохранение БД ведомого экземпляра отклоняется
+	db.mu.Unlock()
+	_, _, issueErr := db.IssueToken("app", "profiles")
+	steps := []error{
+		db.AddTenant("globex"),
+		db.RemoveTenant("acme"),
+		db.AddPlatformAdmin("carol"),
+		db.DropPlatformAdmin("bob"),
+		db.AddClient(models.OAuthClientData{ClientID: "other"}, "secret"),
+		db.RemoveClient("app"),
+		issueErr,
+		db.RevokeToken(token),
+	}
+	unchangedErr := db.RevokeToken("unknown")
+	db.mu.Lock()
+	db.follower = false
+	db.mu.Unlock()
+
+	for i, err := range steps {
+		if !errors.Is(err, followerReadOnlyErr) {
+			t.Fatalf("step %d: expected %v, got %v", i, followerReadOnlyErr, err)
+		}
+	}
+	if unchangedErr != nil {
+		t.Fatalf("revoking unknown token saves database: %s", unchangedErr.Error())
+	}
+	if after := dbState(t, db); after != before {
+		t.Fatalf("database is changed by rolled back updates:\nbefore: %s\nafter:  %s", before, after)
+	}
+	if _, ok := db.GetTokenData(token); !ok {
+		t.Fatal("token is revoked by rolled back update")
+	}
+	if _, err := db.GetTenant("acme"); err != nil {
+		t.Fatal("tenant is removed by rolled back update")
+	}
+}
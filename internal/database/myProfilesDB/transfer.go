@@ -0,0 +1,248 @@
+package myProfilesDB
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Импорт профилей: для каждой записи создается новый профиль или (в режиме upsert) заменяются данные существующего профиля с тем же логином (пустые поля записи не меняются);
+записи с ошибками пропускаются и попадают в отчет, остальные записи импортируются, данные сохраняются в файл один раз. При проверке (dryRun) профили не изменяются,
+а записи проверяются по текущим данным тенанта (совпадения значений уникальных атрибутов между записями файла при проверке не обнаруживаются)
+
+:param records []models.ProfileRecord: записи профилей
+:param mode string: режим импорта: create (только новые профили) или upsert (новые и существующие профили)
+:param dryRun bool: true - записи только проверяются
+:param checkPassword func(string) error: проверка паролей записей по политике паролей (nil - пароли не проверяются)
+:param author string: логин пользователя, импортирующего профили (записывается в историю профилей)
+
+:return: отчет об импорте или ошибка, если режим импорта неизвестен или базу данных не удалось сохранить
+*/
+func (t *Tenant) ImportProfiles(records []models.ProfileRecord, mode string, dryRun bool, checkPassword func(string) error, author string) (models.ImportReport, error) {
+	report := models.ImportReport{DryRun: dryRun, Mode: mode, Total: len(records)}
+	if mode != models.ImportModeCreate && mode != models.ImportModeUpsert {
+		return report, fmt.Errorf("%w \"%s\"", unknownImportModeErr, mode)
+	}
+
+	// Проверка и шифрование паролей записей (до транзакции, параллельно: шифрование пароля bcrypt занимает заметное время)
+	passwordHashes := make([]string, len(records))
+	passwordErrs := make([]error, len(records))
+	runParallel(len(records), func(i int) {
+		passwordHashes[i], passwordErrs[i] = importPasswordHash(records[i], dryRun, checkPassword)
+	})
+
+	// Импорт записей в одной транзакции (пробный импорт не меняет данные и выполняется в транзакции чтения)
+	transaction := t.Update
+	if dryRun {
+		transaction = t.View
+	}
+	err := transaction(func(tx *Tx) error {
+		seenLogins := make(map[string]struct{}, len(records))
+		for i, record := range records {
+			err := passwordErrs[i]
+			if _, ok := seenLogins[record.Login]; ok && record.Login != "" {
+				err = duplicateImportLoginErr
+			}
+			var created bool
+			if err == nil {
+				created, err = t.importProfile(record, passwordHashes[i], mode, dryRun, author)
+			}
+			seenLogins[record.Login] = struct{}{}
+			switch {
+			case err != nil:
+				report.Failed++
+				report.Errors = append(report.Errors, models.ImportRowError{Line: record.Line, Login: record.Login, Error: err.Error()})
+			case created:
+				report.Created++
+			default:
+				report.Updated++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+/*
+Параллельное выполнение функции для индексов от 0 до n-1 (не больше одновременных вызовов, чем процессоров); используется для шифрования паролей bcrypt,
+которое занимает заметное время
+
+:param n int: количество индексов
+:param fn func(i int): функция, выполняемая для каждого индекса
+*/
+func runParallel(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, runtime.NumCPU())
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+/*
+Объединение данных профиля с новыми данными: пустые поля новых данных не меняются
+
+:param current models.ProfileData: текущие данные профиля
+:param update models.ProfileData: новые данные профиля
+
+:return: объединенные данные профиля
+*/
+func mergeProfileData(current models.ProfileData, update models.ProfileData) models.ProfileData {
+	if update.FirstName == "" {
+		update.FirstName = current.FirstName
+	}
+	if update.LastName == "" {
+		update.LastName = current.LastName
+	}
+	if update.Email == "" {
+		update.Email = current.Email
+	}
+	if update.Attributes == nil {
+		update.Attributes = current.Attributes
+	}
+	return update
+}
+
+/*
+Проверка и шифрование пароля записи профиля: задается либо пароль, который проверяется по политике паролей и шифруется, либо готовый хэш bcrypt
+
+:param record models.ProfileRecord: запись профиля
+:param dryRun bool: true - пароль только проверяется, без шифрования
+:param checkPassword func(string) error: проверка пароля по политике паролей (nil - пароль не проверяется)
+
+:return: зашифрованный пароль (пустая строка - пароль не задан или не шифровался) или ошибка, если заданы и пароль, и хэш, пароль не соответствует политике
+или хэш не является хэшем bcrypt
+*/
+func importPasswordHash(record models.ProfileRecord, dryRun bool, checkPassword func(string) error) (string, error) {
+	switch {
+	case record.Password != "" && record.PasswordHash != "":
+		return "", passwordAndHashErr
+	case record.PasswordHash != "":
+		_, err := bcrypt.Cost([]byte(record.PasswordHash))
+		if err != nil {
+			return "", invalidPasswordHashErr
+		}
+		return record.PasswordHash, nil
+	case record.Password != "":
+		if checkPassword != nil {
+			err := checkPassword(record.Password)
+			if err != nil {
+				return "", err
+			}
+		}
+		if len(record.Password) > 72 {
+			return "", incorrectPasswordErr
+		}
+		if dryRun {
+			return "", nil
+		}
+		return HashPassword(record.Password)
+	}
+	return "", nil
+}
+
+/*
+Импорт одной записи профиля (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются)
+
+:param record models.ProfileRecord: запись профиля
+:param passwordHashSalt string: зашифрованный пароль записи (пустая строка - пароль не меняется, новый профиль создается без пароля)
+:param mode string: режим импорта
+:param dryRun bool: true - запись только проверяется
+:param author string: логин пользователя, импортирующего профили
+
+:return: true, если профиль создается, false - если заменяются данные существующего профиля; ошибка, если логин пустой, профиль существует в режиме create,
+логин - псевдоним другого профиля, статус недопустим или атрибуты не соответствуют схеме атрибутов
+*/
+func (t *Tenant) importProfile(record models.ProfileRecord, passwordHashSalt string, mode string, dryRun bool, author string) (bool, error) {
+	if record.Login == "" {
+		return false, emptyLoginErr
+	}
+	profileData := models.ProfileData{FirstName: record.FirstName, LastName: record.LastName, Email: record.Email, Attributes: record.Attributes}
+
+	// Создание нового профиля
+	id, ok := t.loginsTab[record.Login]
+	if !ok {
+		status, err := newAccountStatus(record.Status)
+		if err != nil {
+			return true, err
+		}
+		if dryRun {
+			if _, ok := t.activeLoginAlias(record.Login); ok {
+				return true, loginIsAliasErr
+			}
+			return true, t.validateAttributes("", profileData.Attributes)
+		}
+		_, err = t.addProfile(record.Login, profileData, passwordHashSalt, status, author)
+		return true, err
+	}
+
+	// Замена данных существующего профиля (пустые поля записи не меняются)
+	if mode == models.ImportModeCreate {
+		return false, profileExistsErr
+	}
+	currentProfileData := t.profilesDataTab[id]
+	profileData = mergeProfileData(currentProfileData, profileData)
+	err := t.validateAttributes(id, profileData.Attributes)
+	if err != nil || dryRun {
+		return false, err
+	}
+	profileData.ID = id
+	profileData.Login = record.Login
+	t.applyEmailChange(id, currentProfileData, &profileData)
+	setEntry(t.db.undo, t.profilesDataTab, id, profileData)
+	t.recordProfileHistory(id, author, models.HistoryActionEdit, t.nextProfileVersion(id))
+	if passwordHashSalt != "" {
+		setEntry(t.db.undo, t.profilesPasswordsTab, id, passwordHashSalt)
+		t.recordChange(models.ChangePasswordChanged, id, 0, author)
+	}
+	return false, nil
+}
+
+/*
+Экспорт профилей тенанта (удаленные профили не экспортируются)
+
+:param withPasswordHashes bool: true - в записи добавляются зашифрованные пароли профилей
+
+:return: записи профилей, отсортированные по логинам
+*/
+func (t *Tenant) ExportProfiles(withPasswordHashes bool) []models.ProfileRecord {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	records := make([]models.ProfileRecord, 0, len(t.loginsTab))
+	for login, id := range t.loginsTab {
+		profileData := t.profilesDataTab[id]
+		record := models.ProfileRecord{
+			ID:         id,
+			Login:      login,
+			FirstName:  profileData.FirstName,
+			LastName:   profileData.LastName,
+			Email:      profileData.Email,
+			Status:     t.accountStatus(id).Status,
+			Attributes: profileData.Attributes,
+		}
+		if withPasswordHashes {
+			record.PasswordHash = t.profilesPasswordsTab[id]
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Login < records[j].Login })
+	return records
+}
@@ -0,0 +1,356 @@
+package myProfilesDB
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Отвязка профиля от всех таблиц тенанта (данные, индекс логинов, версия, права администраторов, группы, псевдонимы, заявка на регистрацию, подтверждения адреса электронной почты); пароль, статус учетной записи и история изменений профиля не удаляются,
+удаление записывается в историю профиля
+
+:param id string: идентификатор профиля
+:param author string: логин пользователя, удаляющего профиль
+
+:return: данные профиля в корзине (без времени удаления) с правами администраторов и группами, в которые входил профиль
+*/
+func (t *Tenant) detachProfile(id string, author string) models.DeletedProfileData {
+	t.recordProfileHistory(id, author, models.HistoryActionDelete, t.nextProfileVersion(id))
+	profileData := t.profilesDataTab[id]
+	deletedProfile := models.DeletedProfileData{Profile: profileData}
+
+	// Удаление профиля из списков администраторов (включая список админов платформы, если это тенант платформы)
+	if _, ok := t.adminsTab[id]; ok {
+		deletedProfile.Admin = true
+		deleteEntry(t.db.undo, t.adminsTab, id)
+	}
+	if t.name == t.db.platformTenant {
+		if _, ok := t.db.platformAdminsTab[id]; ok {
+			deletedProfile.PlatformAdmin = true
+			deleteEntry(t.db.undo, t.db.platformAdminsTab, id)
+		}
+	}
+	// Удаление профиля из групп
+	for groupName, g := range t.groupsTab {
+		if _, ok := g.members[id]; ok {
+			deletedProfile.Groups = append(deletedProfile.Groups, groupName)
+			deleteEntry(t.db.undo, g.members, id)
+		}
+	}
+	sort.Strings(deletedProfile.Groups)
+	// Удаление данных, версии, логина и псевдонимов старых логинов профиля
+	deleteEntry(t.db.undo, t.profilesDataTab, id)
+	deleteEntry(t.db.undo, t.profilesVersionsTab, id)
+	deleteEntry(t.db.undo, t.loginsTab, profileData.Login)
+	for alias, loginAlias := range t.loginAliasesTab {
+		if loginAlias.ID == id {
+			deleteEntry(t.db.undo, t.loginAliasesTab, alias)
+		}
+	}
+	// Удаление заявки на регистрацию и запросов на подтверждение адреса электронной почты профиля
+	deleteEntry(t.db.undo, t.registrationsTab, id)
+	for key, verification := range t.verificationsTab {
+		if verification.ProfileID == id {
+			deleteEntry(t.db.undo, t.verificationsTab, key)
+		}
+	}
+	return deletedProfile
+}
+
+/*
+Окончательное удаление данных профиля, которые хранятся после переноса профиля в корзину (пароль, статус учетной записи, история изменений);
+логин профиля удаляется из событий журнала изменений профилей и доставок событий вебхуков (события остаются без логина), а прежние записи журнала изменений БД
+с данными профиля удаляются при сохранении БД
+
+:param id string: идентификатор профиля
+*/
+func (t *Tenant) eraseProfile(id string) {
+	deleteEntry(t.db.undo, t.deletedProfilesTab, id)
+	deleteEntry(t.db.undo, t.profilesPasswordsTab, id)
+	deleteEntry(t.db.undo, t.accountsStatusTab, id)
+	deleteEntry(t.db.undo, t.profilesHistoryTab, id)
+
+	// Удаление логина профиля из событий (срезы событий не изменяются на месте - события без логина записываются в новые срезы)
+	if changeLog, ok := scrubChangeEvents(t.changeLog, id); ok {
+		setValue(t.db.undo, &t.changeLog, changeLog)
+	}
+	for webhookID, deliveries := range t.webhookDeliveriesTab {
+		if deliveries, ok := scrubWebhookDeliveries(deliveries, id); ok {
+			setEntry(t.db.undo, t.webhookDeliveriesTab, webhookID, deliveries)
+		}
+	}
+	if deadLetters, ok := scrubWebhookDeliveries(t.webhookDeadLetters, id); ok {
+		setValue(t.db.undo, &t.webhookDeadLetters, deadLetters)
+	}
+	setValue(t.db.undo, &t.db.erasedProfiles, true)
+}
+
+/*
+Удаление логина окончательно удаленного профиля из события изменения профиля (автор изменения удаляется, если это сам профиль)
+
+:param event models.ChangeEvent: событие окончательно удаленного профиля
+
+:return: событие без логина профиля
+*/
+func scrubChangeEvent(event models.ChangeEvent) models.ChangeEvent {
+	if event.Author == event.Login {
+		event.Author = ""
+	}
+	event.Login = ""
+	return event
+}
+
+/*
+Удаление логина окончательно удаленного профиля из событий журнала изменений профилей
+
+:param changeLog []models.ChangeEvent: события
+:param id string: идентификатор окончательно удаленного профиля
+
+:return: копия событий без логина профиля и true или исходные события и false, если событий профиля нет
+*/
+func scrubChangeEvents(changeLog []models.ChangeEvent, id string) ([]models.ChangeEvent, bool) {
+	scrubbed := false
+	for i, event := range changeLog {
+		if event.ProfileID != id {
+			continue
+		}
+		if !scrubbed {
+			changeLog = append([]models.ChangeEvent(nil), changeLog...)
+			scrubbed = true
+		}
+		changeLog[i] = scrubChangeEvent(event)
+	}
+	return changeLog, scrubbed
+}
+
+/*
+Удаление логина окончательно удаленного профиля из событий доставок вебхуков
+
+:param deliveries []models.WebhookDelivery: доставки
+:param id string: идентификатор окончательно удаленного профиля
+
+:return: копия доставок без логина профиля и true или исходные доставки и false, если доставок событий профиля нет
+*/
+func scrubWebhookDeliveries(deliveries []models.WebhookDelivery, id string) ([]models.WebhookDelivery, bool) {
+	scrubbed := false
+	for i, delivery := range deliveries {
+		if delivery.Event.ProfileID != id {
+			continue
+		}
+		if !scrubbed {
+			deliveries = append([]models.WebhookDelivery(nil), deliveries...)
+			scrubbed = true
+		}
+		deliveries[i].Event = scrubChangeEvent(delivery.Event)
+	}
+	return deliveries, scrubbed
+}
+
+/*
+Получить список профилей в корзине тенанта
+
+:return: список удаленных профилей, отсортированный по времени удаления
+*/
+func (t *Tenant) GetDeletedProfiles() []models.DeletedProfileData {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	deletedProfiles := make([]models.DeletedProfileData, 0, len(t.deletedProfilesTab))
+	for _, deletedProfile := range t.deletedProfilesTab {
+		deletedProfiles = append(deletedProfiles, deletedProfile)
+	}
+	sort.Slice(deletedProfiles, func(i, j int) bool {
+		if deletedProfiles[i].DeletedAt != deletedProfiles[j].DeletedAt {
+			return deletedProfiles[i].DeletedAt < deletedProfiles[j].DeletedAt
+		}
+		return deletedProfiles[i].Profile.ID < deletedProfiles[j].Profile.ID
+	})
+	return deletedProfiles
+}
+
+/*
+Восстановление профиля из корзины вместе с паролем, правами администраторов и членством в группах (группы, удаленные за время нахождения профиля в корзине, пропускаются)
+
+:param id string: идентификатор удаленного профиля
+:param author string: логин пользователя, восстанавливающего профиль (записывается в историю профиля)
+
+:return: логин восстановленного профиля; возвращается ошибка, если профиля нет в корзине, его логин занят другим профилем или псевдонимом,
+атрибуты профиля не соответствуют текущей схеме атрибутов или базу данных не удалось сохранить
+*/
+func (t *Tenant) RestoreProfile(id string, author string) (login string, err error) {
+	err = t.Update(func(tx *Tx) error {
+		login, err = tx.RestoreProfile(id, author)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return
+}
+
+/*
+Восстановление профиля из корзины (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются)
+
+:param id string: идентификатор удаленного профиля
+:param author string: логин пользователя, восстанавливающего профиль (записывается в историю профиля)
+
+:return: логин восстановленного профиля; возвращается ошибка, если профиля нет в корзине, его логин занят другим профилем или псевдонимом
+или атрибуты профиля не соответствуют текущей схеме атрибутов
+*/
+func (t *Tenant) restoreProfile(id string, author string) (string, error) {
+	// Проверка наличия профиля в корзине и его логина
+	deletedProfile, ok := t.deletedProfilesTab[id]
+	if !ok {
+		return "", noDeletedProfileErr
+	}
+	profileData := deletedProfile.Profile
+	if _, ok := t.loginsTab[profileData.Login]; ok {
+		return "", profileExistsErr
+	}
+	if _, ok := t.activeLoginAlias(profileData.Login); ok {
+		return "", loginIsAliasErr
+	}
+
+	// Проверка дополнительных атрибутов по схеме атрибутов тенанта (схема могла измениться за время нахождения профиля в корзине)
+	err := t.validateAttributes(id, profileData.Attributes)
+	if err != nil {
+		return "", err
+	}
+
+	// Возврат профиля в таблицы тенанта
+	setEntry(t.db.undo, t.profilesDataTab, id, profileData)
+	setEntry(t.db.undo, t.loginsTab, profileData.Login, id)
+	t.recordProfileHistory(id, author, models.HistoryActionRestore, t.nextProfileVersion(id))
+	admins := t.effectiveAdmins()
+	if deletedProfile.Admin {
+		setEntry(t.db.undo, t.adminsTab, id, struct{}{})
+	}
+	if deletedProfile.PlatformAdmin && t.name == t.db.platformTenant {
+		setEntry(t.db.undo, t.db.platformAdminsTab, id, struct{}{})
+	}
+	for _, groupName := range deletedProfile.Groups {
+		if g, ok := t.groupsTab[groupName]; ok {
+			setEntry(t.db.undo, g.members, id, struct{}{})
+		}
+	}
+	t.recordAdminChanges(admins, author)
+	deleteEntry(t.db.undo, t.deletedProfilesTab, id)
+	return profileData.Login, nil
+}
+
+/*
+Окончательное удаление профиля из корзины вместе с паролем, статусом учетной записи и историей изменений
+
+:param id string: идентификатор удаленного профиля
+
+:return: возвращается ошибка, если профиля нет в корзине или базу данных не удалось сохранить
+*/
+func (t *Tenant) PurgeProfile(id string) error {
+	return t.Update(func(tx *Tx) error {
+		return tx.PurgeProfile(id)
+	})
+}
+
+/*
+Окончательное удаление профиля из корзины вместе с паролем, статусом учетной записи и историей изменений
+
+:param id string: идентификатор удаленного профиля
+
+:return: возвращается ошибка, если транзакция только для чтения или профиля нет в корзине
+*/
+func (tx *Tx) PurgeProfile(id string) error {
+	err := tx.checkWritable()
+	if err != nil {
+		return err
+	}
+	if _, ok := tx.t.deletedProfilesTab[id]; !ok {
+		return noDeletedProfileErr
+	}
+	tx.t.eraseProfile(id)
+	return nil
+}
+
+/*
+Окончательное удаление профилей, удаленных в корзину не позже заданного времени
+
+:param expiredBefore int64: время удаления в корзину (unix-время в секундах), не позже которого профили удаляются окончательно
+
+:return: число удаленных профилей; возвращается ошибка, если транзакция только для чтения
+*/
+func (tx *Tx) PurgeDeletedProfiles(expiredBefore int64) (int, error) {
+	err := tx.checkWritable()
+	if err != nil {
+		return 0, err
+	}
+	purgedNumber := 0
+	for id, deletedProfile := range tx.t.deletedProfilesTab {
+		if deletedProfile.DeletedAt <= expiredBefore {
+			tx.t.eraseProfile(id)
+			purgedNumber++
+		}
+	}
+	return purgedNumber, nil
+}
+
+/*
+Окончательное удаление профилей, время хранения которых в корзине истекло, из всех тенантов: профили каждого тенанта удаляются отдельной транзакцией
+
+:return: число удаленных профилей; возвращается ошибка, если базу данных не удалось сохранить (профили тенанта, данные которого не удалось сохранить, не удаляются)
+*/
+func (db *myProfilesDB) PurgeDeletedProfiles() (int, error) {
+	// Тенанты, в корзинах которых есть профили с истекшим временем хранения
+	db.mu.RLock()
+	expiredBefore := time.Now().Unix() - db.deletedRetention
+	var tenants []*Tenant
+	for _, t := range db.tenantsTab {
+		for _, deletedProfile := range t.deletedProfilesTab {
+			if deletedProfile.DeletedAt <= expiredBefore {
+				tenants = append(tenants, t)
+				break
+			}
+		}
+	}
+	db.mu.RUnlock()
+
+	// Удаление профилей с истекшим временем хранения
+	purgedNumber := 0
+	var purgeErr error
+	for _, t := range tenants {
+		var tenantPurgedNumber int
+		err := t.Update(func(tx *Tx) error {
+			var err error
+			tenantPurgedNumber, err = tx.PurgeDeletedProfiles(expiredBefore)
+			return err
+		})
+		if err != nil {
+			purgeErr = err
+			continue
+		}
+		purgedNumber += tenantPurgedNumber
+	}
+	return purgedNumber, purgeErr
+}
+
+/*
+Фоновая очистка корзины: профили с истекшим временем хранения удаляются окончательно с периодом, заданным в конфиге (функция не возвращает управление)
+*/
+func (db *myProfilesDB) RunPurgeJob() {
+	ticker := time.NewTicker(time.Duration(db.purgeInterval) * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		if db.standby() {
+			continue
+		}
+		purgedNumber, err := db.PurgeDeletedProfiles()
+		if err != nil {
+			log.Printf("deleted profiles purge error: %s", err.Error())
+			continue
+		}
+		if purgedNumber > 0 {
+			log.Printf("%d deleted profiles purged", purgedNumber)
+		}
+	}
+}
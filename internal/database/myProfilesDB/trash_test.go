@@ -0,0 +1,130 @@
+package myProfilesDB
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Удаление профиля в корзину
+
+:param t *testing.T: тест
+:param tenant *Tenant: тенант
+:param login string: логин профиля
+
+:return: идентификатор удаленного профиля
+*/
+func trashTestProfile(t *testing.T, tenant *Tenant, login string) string {
+	t.Helper()
+	profileData, _, err := tenant.GetProfileData(login)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tenant.RemoveProfile(login, 0, false, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return profileData.ID
+}
+
+/*
+Проверка, что логин окончательно удаленного профиля не хранится в данных БД и журнале изменений БД
+
+:param t *testing.T: тест
+:param db *myProfilesDB: БД
+:param login string: логин окончательно удаленного профиля
+*/
+func checkLoginErased(t *testing.T, db *myProfilesDB, login string) {
+	t.Helper()
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if bytes.Contains(db.fileData(), []byte(`"`+login+`"`)) {
+		t.Fatalf("login %s is kept in database", login)
+	}
+	replicationLog, _ := json.Marshal(db.replicationLog)
+	if bytes.Contains(replicationLog, []byte(login)) {
+		t.Fatalf("login %s is kept in replication log", login)
+	}
+}
+
+// Окончательно удаленный профиль не остается в журнале изменений профилей, доставках событий вебхуков и журнале изменений БД
+func TestPurgeProfileErasesLogin(t *testing.T) {
+	db, platform := openTestDB(t)
+	receiver := newWebhookReceiver(t, http.StatusInternalServerError)
+	webhook, err := platform.AddWebhook(models.WebhookData{URL: receiver.server.URL}, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addTestProfile(t, platform, "erin")
+	id := trashTestProfile(t, platform, "erin")
+	_, seqBeforePurge := db.ReplicationPosition()
+
+	err = platform.PurgeProfile(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLoginErased(t, db, "erin")
+
+	// События профиля остаются в журнале без логина
+	events, _, _, err := platform.WatchChanges(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profileEvents := 0
+	for _, event := range events {
+		if event.ProfileID == id {
+			profileEvents++
+		}
+	}
+	if profileEvents != 2 {
+		t.Fatalf("events of purged profile are not kept: %+v", events)
+	}
+	if deliveries, err := platform.GetWebhookDeliveries(webhook.ID); err != nil || len(deliveries) != 2 {
+		t.Fatalf("deliveries of purged profile events are not kept: %v %+v", err, deliveries)
+	}
+
+	// Ведомый экземпляр, получивший записи с данными профиля, загружает снимок заново
+	epoch, _ := db.ReplicationPosition()
+	if _, _, err = db.ReplicationLog(epoch, seqBeforePurge-1); !errors.Is(err, ReplicationLogTruncatedErr) {
+		t.Fatalf("replication log entries with purged profile are kept: %v", err)
+	}
+	if replicationLog, _, err := db.ReplicationLog(epoch, seqBeforePurge); err != nil || len(replicationLog.Entries) != 1 {
+		t.Fatalf("replication log entry of purge is not kept: %v", err)
+	}
+}
+
+// Профили с истекшим временем хранения удаляются транзакцией тенанта: если БД не удалось сохранить, профили остаются в корзине
+func TestPurgeDeletedProfilesRollback(t *testing.T) {
+	db, platform := openTestDB(t)
+	addTestProfile(t, platform, "frank")
+	id := trashTestProfile(t, platform, "frank")
+	db.mu.Lock()
+	deletedProfile := platform.deletedProfilesTab[id]
+	deletedProfile.DeletedAt -= db.deletedRetention
+	platform.deletedProfilesTab[id] = deletedProfile
+	db.follower = true // сохранение БД ведомого экземпляра отклоняется
+	db.mu.Unlock()
+
+	purgedNumber, err := db.PurgeDeletedProfiles()
+	db.mu.Lock()
+	db.follower = false
+	db.mu.Unlock()
+	if err == nil || purgedNumber != 0 {
+		t.Fatalf("purge succeeded without saving database: %d", purgedNumber)
+	}
+	if deletedProfiles := platform.GetDeletedProfiles(); len(deletedProfiles) != 1 || deletedProfiles[0].Profile.Login != "frank" {
+		t.Fatalf("profile is not kept in trash: %+v", deletedProfiles)
+	}
+
+	purgedNumber, err = db.PurgeDeletedProfiles()
+	if err != nil || purgedNumber != 1 {
+		t.Fatalf("expired profile is not purged: %d %v", purgedNumber, err)
+	}
+	checkLoginErased(t, db, "frank")
+}
@@ -0,0 +1,91 @@
+package myProfilesDB
+
+// Журнал отмены транзакции: перед каждым изменением таблицы или значения БД в журнал записывается шаг, возвращающий прежнее значение;
+// при отмене транзакции шаги выполняются в обратном порядке, поэтому отмена занимает время, пропорциональное числу изменений, а не размеру тенанта
+type undoLog struct {
+	steps []func() // шаги отмены в порядке изменений
+}
+
+/*
+Запись шага отмены (вне транзакции журнала нет - изменения не записываются)
+
+:param step func(): шаг, возвращающий прежнее значение
+*/
+func (u *undoLog) record(step func()) {
+	if u == nil {
+		return
+	}
+	u.steps = append(u.steps, step)
+}
+
+/*
+Отмена всех записанных изменений в обратном порядке
+*/
+func (u *undoLog) rollback() {
+	for i := len(u.steps) - 1; i >= 0; i-- {
+		u.steps[i]()
+	}
+	u.steps = nil
+}
+
+/*
+Запись значения в таблицу с сохранением прежнего значения в журнале отмены (вызывается методами БД под блокировкой db.mu)
+
+:param u *undoLog: журнал отмены транзакции (nil - вне транзакции)
+:param tab map[K]V: таблица
+:param key K: ключ
+:param value V: новое значение
+*/
+func setEntry[K comparable, V any](u *undoLog, tab map[K]V, key K, value V) {
+	saveEntry(u, tab, key)
+	tab[key] = value
+}
+
+/*
+Удаление значения из таблицы с сохранением прежнего значения в журнале отмены (вызывается методами БД под блокировкой db.mu)
+
+:param u *undoLog: журнал отмены транзакции (nil - вне транзакции)
+:param tab map[K]V: таблица
+:param key K: ключ
+*/
+func deleteEntry[K comparable, V any](u *undoLog, tab map[K]V, key K) {
+	saveEntry(u, tab, key)
+	delete(tab, key)
+}
+
+/*
+Запись в журнал отмены шага, возвращающего текущее значение таблицы по ключу (или удаляющего ключ, если значения нет)
+
+:param u *undoLog: журнал отмены транзакции (nil - вне транзакции)
+:param tab map[K]V: таблица
+:param key K: ключ
+*/
+func saveEntry[K comparable, V any](u *undoLog, tab map[K]V, key K) {
+	if u == nil {
+		return
+	}
+	previous, ok := tab[key]
+	u.record(func() {
+		if ok {
+			tab[key] = previous
+		} else {
+			delete(tab, key)
+		}
+	})
+}
+
+/*
+Запись значения поля с сохранением прежнего значения в журнале отмены (вызывается методами БД под блокировкой db.mu);
+срез, записанный в поле, не должен изменяться на месте - изменения записываются новым срезом
+
+:param u *undoLog: журнал отмены транзакции (nil - вне транзакции)
+:param field *V: поле
+:param value V: новое значение
+*/
+func setValue[V any](u *undoLog, field *V, value V) {
+	if u != nil {
+		previous := *field
+		u.record(func() { *field = previous })
+	}
+	*field = value
+}
@@ -0,0 +1,158 @@
+package myProfilesDB
+
+import (
+	"log"
+	"time"
+
+	"github.com/ZotovSergey/authenticationservice/internal/mailer"
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Выдача токена подтверждения адреса электронной почты профиля и отправка его на подтверждаемый адрес (вызывается методами БД под блокировкой db.mu,
+данные в файл не сохраняются); заодно удаляются истекшие запросы на подтверждение. Если токен не удалось сгенерировать, ошибка записывается в лог,
+токен можно запросить повторно
+
+:param id string: идентификатор профиля
+:param email string: подтверждаемый адрес электронной почты
+*/
+func (t *Tenant) issueVerification(id string, email string) {
+	now := time.Now().Unix()
+	for key, verification := range t.verificationsTab {
+		if verification.ExpiresAt <= now {
+			deleteEntry(t.db.undo, t.verificationsTab, key)
+		}
+	}
+	token, err := generateToken()
+	if err != nil {
+		log.Printf("fail to issue email verification: %s", err.Error())
+		return
+	}
+	setEntry(t.db.undo, t.verificationsTab, tokenKey(token), models.EmailVerification{
+		ProfileID: id,
+		Email:     email,
+		ExpiresAt: now + t.db.verificationLifetime,
+	})
+	t.db.sendMail(func() { mailer.SendVerification(t.name, email, token) })
+}
+
+/*
+Применение изменения адреса электронной почты к новым данным профиля (вызывается методами БД под блокировкой db.mu перед записью данных профиля):
+состояние подтверждения задается только БД, новый адрес не заменяет текущий, а ожидает подтверждения, на него отправляется токен подтверждения;
+удаление адреса применяется сразу
+
+:param id string: идентификатор профиля
+:param currentProfileData models.ProfileData: текущие данные профиля
+:param profileData *models.ProfileData: новые данные профиля
+*/
+func (t *Tenant) applyEmailChange(id string, currentProfileData models.ProfileData, profileData *models.ProfileData) {
+	profileData.VerifiedAt = currentProfileData.VerifiedAt
+	profileData.PendingEmail = currentProfileData.PendingEmail
+	switch profileData.Email {
+	// Адрес не изменился
+	case currentProfileData.Email:
+	// Адрес удален: удаляются и подтверждение, и ожидающий подтверждения адрес
+	case "":
+		profileData.VerifiedAt = 0
+		profileData.PendingEmail = ""
+	// Адрес уже ожидает подтверждения
+	case currentProfileData.PendingEmail:
+		profileData.Email = currentProfileData.Email
+	// Новый адрес ожидает подтверждения
+	default:
+		profileData.PendingEmail = profileData.Email
+		profileData.Email = currentProfileData.Email
+		t.issueVerification(id, profileData.PendingEmail)
+	}
+}
+
+/*
+Подтверждение адреса электронной почты по одноразовому токену: ожидающий подтверждения адрес заменяет текущий (на старый адрес отправляется уведомление),
+в профиле записывается время подтверждения; если профиль ожидает подтверждения адреса в очереди заявок на регистрацию
+и одобрение администратора не требуется, учетная запись становится активной
+
+:param token string: токен подтверждения
+
+:return: логин профиля или ошибка, если запроса на подтверждение с таким токеном нет, он истек, адрес профиля изменился или базу данных не удалось сохранить
+*/
+func (t *Tenant) VerifyEmail(token string) (login string, err error) {
+	err = t.Update(func(tx *Tx) error {
+		// Поиск действующего запроса на подтверждение
+		key := tokenKey(token)
+		verification, ok := t.verificationsTab[key]
+		if !ok || verification.ExpiresAt <= time.Now().Unix() {
+			return noVerificationErr
+		}
+		id := verification.ProfileID
+		profileData, ok := t.profilesDataTab[id]
+		if !ok {
+			return noVerificationErr
+		}
+
+		// Подтверждение текущего адреса или замена текущего адреса ожидающим подтверждения
+		oldEmail := profileData.Email
+		switch verification.Email {
+		case profileData.PendingEmail:
+			profileData.Email = profileData.PendingEmail
+			profileData.PendingEmail = ""
+		case profileData.Email:
+		default:
+			return noVerificationErr
+		}
+		deleteEntry(t.db.undo, t.verificationsTab, key)
+		profileData.VerifiedAt = time.Now().Unix()
+		setEntry(t.db.undo, t.profilesDataTab, id, profileData)
+		t.recordProfileHistory(id, profileData.Login, models.HistoryActionVerify, t.nextProfileVersion(id))
+		if oldEmail != "" && oldEmail != profileData.Email {
+			t.db.sendMail(func() { mailer.SendEmailChangedNotice(profileData.Login, oldEmail, profileData.Email) })
+		}
+
+		// Продвижение заявки на регистрацию
+		if registration, ok := t.registrationsTab[id]; ok {
+			registration.EmailVerified = true
+			setEntry(t.db.undo, t.registrationsTab, id, registration)
+			if !registration.ApprovalRequired {
+				t.activateRegistration(id, profileData.Login)
+			}
+		}
+
+		login = profileData.Login
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return
+}
+
+/*
+Повторная отправка токена подтверждения на ожидающий подтверждения адрес электронной почты профиля или, если его нет, на неподтвержденный текущий адрес
+
+:param login string: логин профиля
+
+:return: возвращается ошибка, если профиля с логином login нет, у профиля нет адреса электронной почты или адрес уже подтвержден
+*/
+func (t *Tenant) ResendVerification(login string) error {
+	return t.Update(func(tx *Tx) error {
+		// Проверка наличия профиля и адреса, ожидающего подтверждения
+		id, ok := t.loginsTab[login]
+		if !ok {
+			return noProfileErr
+		}
+		profileData := t.profilesDataTab[id]
+		email := profileData.PendingEmail
+		if email == "" {
+			if profileData.Email == "" {
+				return emptyEmailErr
+			}
+			if profileData.VerifiedAt != 0 {
+				return emailAlreadyVerifiedErr
+			}
+			email = profileData.Email
+		}
+
+		// Выдача токена подтверждения (токены хранятся в файле, поэтому база данных сохраняется)
+		t.issueVerification(id, email)
+		return nil
+	})
+}
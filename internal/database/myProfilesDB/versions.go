@@ -0,0 +1,33 @@
+package myProfilesDB
+
+import (
+	"fmt"
+)
+
+/*
+Выдача профилю новой версии (при создании профиля и при каждом изменении его данных)
+
+:param id string: идентификатор профиля
+
+:return: новая версия профиля
+*/
+func (t *Tenant) nextProfileVersion(id string) int64 {
+	setValue(t.db.undo, &t.lastProfileVersion, t.lastProfileVersion+1)
+	setEntry(t.db.undo, t.profilesVersionsTab, id, t.lastProfileVersion)
+	return t.lastProfileVersion
+}
+
+/*
+Проверка текущей версии профиля перед его изменением (оптимистическая блокировка)
+
+:param id string: идентификатор профиля
+:param expectedVersion int64: ожидаемая текущая версия профиля (0 - версия не проверяется)
+
+:return: возвращается ошибка ProfileVersionMismatchErr, если текущая версия профиля не совпадает с ожидаемой
+*/
+func (t *Tenant) checkProfileVersion(id string, expectedVersion int64) error {
+	if expectedVersion != 0 && t.profilesVersionsTab[id] != expectedVersion {
+		return fmt.Errorf("%w: current version is %d", ProfileVersionMismatchErr, t.profilesVersionsTab[id])
+	}
+	return nil
+}
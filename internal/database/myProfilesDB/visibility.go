@@ -0,0 +1,254 @@
+package myProfilesDB
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Проверка уровня видимости поля
+
+:param visibility string: уровень видимости
+
+:return: возвращается ошибка, если уровень видимости неизвестен
+*/
+func validateVisibility(visibility string) error {
+	switch visibility {
+	case models.VisibilityPublic, models.VisibilitySelf, models.VisibilityAdmin:
+		return nil
+	}
+	return fmt.Errorf("%w \"%s\"", unknownVisibilityErr, visibility)
+}
+
+/*
+Проверка наличия групп тенанта, которым открывается видимость поля
+
+:param groups []string: названия групп
+
+:return: возвращается ошибка, если одной из групп не существует
+*/
+func (t *Tenant) validateVisibleToGroups(groups []string) error {
+	for _, groupName := range groups {
+		if _, ok := t.groupsTab[groupName]; !ok {
+			return fmt.Errorf("%w \"%s\"", noGroupErr, groupName)
+		}
+	}
+	return nil
+}
+
+/*
+Получение настройки видимости поля профиля
+
+:param field string: название основного поля или дополнительного атрибута
+
+:return: настройка видимости поля (если видимость не настроена, имя и фамилия видны всем, адрес электронной почты и атрибуты - владельцу профиля и администраторам)
+*/
+func (t *Tenant) fieldVisibility(field string) models.FieldVisibility {
+	if definition, ok := t.attributesSchemaTab[field]; ok {
+		visibility := definition.Visibility
+		if visibility == "" {
+			visibility = models.VisibilitySelf
+		}
+		return models.FieldVisibility{
+			Field:           field,
+			Visibility:      visibility,
+			VisibleToGroups: definition.VisibleToGroups,
+		}
+	}
+	if fieldVisibility, ok := t.fieldsVisibilityTab[field]; ok {
+		return fieldVisibility
+	}
+	switch field {
+	case models.FieldFirstName, models.FieldLastName:
+		return models.FieldVisibility{Field: field, Visibility: models.VisibilityPublic}
+	}
+	return models.FieldVisibility{Field: field, Visibility: models.VisibilitySelf}
+}
+
+/*
+Получить настройки видимости всех полей профилей тенанта
+
+:return: список настроек видимости основных полей и дополнительных атрибутов
+*/
+func (t *Tenant) GetFieldsVisibility() []models.FieldVisibility {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	fieldsVisibility := []models.FieldVisibility{
+		t.fieldVisibility(models.FieldFirstName),
+		t.fieldVisibility(models.FieldLastName),
+		t.fieldVisibility(models.FieldEmail),
+	}
+	attributesVisibility := make([]models.FieldVisibility, 0, len(t.attributesSchemaTab))
+	for name := range t.attributesSchemaTab {
+		attributesVisibility = append(attributesVisibility, t.fieldVisibility(name))
+	}
+	sort.Slice(attributesVisibility, func(i, j int) bool { return attributesVisibility[i].Field < attributesVisibility[j].Field })
+	return append(fieldsVisibility, attributesVisibility...)
+}
+
+/*
+Изменение видимости основного поля или дополнительного атрибута профилей тенанта
+
+:param fieldVisibility models.FieldVisibility: новая настройка видимости поля
+
+:return: возвращается ошибка, если поле неизвестно, уровень видимости неизвестен, одной из групп не существует или базу данных не удалось сохранить
+*/
+func (t *Tenant) SetFieldVisibility(fieldVisibility models.FieldVisibility) error {
+	return t.Update(func(tx *Tx) error {
+		return tx.SetFieldVisibility(fieldVisibility)
+	})
+}
+
+/*
+Изменение видимости основного поля или дополнительного атрибута профилей тенанта (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются)
+
+:param fieldVisibility models.FieldVisibility: новая настройка видимости поля
+
+:return: возвращается ошибка, если поле неизвестно, уровень видимости неизвестен или одной из групп не существует
+*/
+func (t *Tenant) setFieldVisibility(fieldVisibility models.FieldVisibility) error {
+	// Проверка настройки видимости
+	err := validateVisibility(fieldVisibility.Visibility)
+	if err != nil {
+		return err
+	}
+	err = t.validateVisibleToGroups(fieldVisibility.VisibleToGroups)
+	if err != nil {
+		return err
+	}
+
+	// Запись настройки видимости
+	switch fieldVisibility.Field {
+	case models.FieldFirstName, models.FieldLastName, models.FieldEmail:
+		setEntry(t.db.undo, t.fieldsVisibilityTab, fieldVisibility.Field, fieldVisibility)
+	default:
+		definition, ok := t.attributesSchemaTab[fieldVisibility.Field]
+		if !ok {
+			return fmt.Errorf("%w \"%s\"", unknownAttributeErr, fieldVisibility.Field)
+		}
+		definition.Visibility = fieldVisibility.Visibility
+		definition.VisibleToGroups = fieldVisibility.VisibleToGroups
+		setEntry(t.db.undo, t.attributesSchemaTab, fieldVisibility.Field, definition)
+	}
+	return nil
+}
+
+/*
+Замена или удаление названия группы в настройках видимости полей (при переименовании или удалении группы)
+
+:param name string: текущее название группы
+:param newName string: новое название группы (пустая строка - ссылки на группу удаляются)
+*/
+func (t *Tenant) renameVisibleToGroup(name string, newName string) {
+	rename := func(groups []string) []string {
+		renamedGroups := make([]string, 0, len(groups))
+		for _, groupName := range groups {
+			if groupName != name {
+				renamedGroups = append(renamedGroups, groupName)
+			} else if newName != "" {
+				renamedGroups = append(renamedGroups, newName)
+			}
+		}
+		return renamedGroups
+	}
+	for field, fieldVisibility := range t.fieldsVisibilityTab {
+		fieldVisibility.VisibleToGroups = rename(fieldVisibility.VisibleToGroups)
+		setEntry(t.db.undo, t.fieldsVisibilityTab, field, fieldVisibility)
+	}
+	for attributeName, definition := range t.attributesSchemaTab {
+		definition.VisibleToGroups = rename(definition.VisibleToGroups)
+		setEntry(t.db.undo, t.attributesSchemaTab, attributeName, definition)
+	}
+}
+
+/*
+Составление функции, проверяющей, видит ли пользователь поле профиля (вызывается методами БД под блокировкой db.mu; функцию можно вызывать, пока блокировка не снята)
+
+:param ownerLogin string: логин просматриваемого профиля
+:param viewerLogin string: логин пользователя тенанта, который просматривает профиль (пустая строка - пользователь не из тенанта)
+:param viewerIsAdmin bool: true, если пользователь является администратором тенанта
+
+:return: функция, возвращающая true, если пользователь видит основное поле или дополнительный атрибут field
+*/
+func (t *Tenant) fieldViewer(ownerLogin string, viewerLogin string, viewerIsAdmin bool) func(field string) bool {
+	isOwner := viewerLogin != "" && viewerLogin == ownerLogin
+	var viewerGroups map[string]struct{} // группы пользователя вычисляются только при необходимости
+	return func(field string) bool {
+		fieldVisibility := t.fieldVisibility(field)
+		switch {
+		case viewerIsAdmin, fieldVisibility.Visibility == models.VisibilityPublic:
+			return true
+		case isOwner && fieldVisibility.Visibility == models.VisibilitySelf:
+			return true
+		}
+		if len(fieldVisibility.VisibleToGroups) == 0 || viewerLogin == "" {
+			return false
+		}
+		if viewerGroups == nil {
+			viewerGroups = t.effectiveGroups(viewerLogin, "")
+		}
+		for _, groupName := range fieldVisibility.VisibleToGroups {
+			if _, ok := viewerGroups[groupName]; ok {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+/*
+Составление данных профиля, которые может видеть пользователь (вызывается методами БД под блокировкой db.mu)
+
+:param profileData models.ProfileData: полные данные профиля
+:param viewerLogin string: логин пользователя тенанта, который просматривает профиль (пустая строка - пользователь не из тенанта)
+:param viewerIsAdmin bool: true, если пользователь является администратором тенанта
+
+:return: данные профиля, видимые пользователю
+*/
+func (t *Tenant) redactProfileData(profileData models.ProfileData, viewerLogin string, viewerIsAdmin bool) models.ProfileData {
+	isOwner := viewerLogin != "" && viewerLogin == profileData.Login
+	canSee := t.fieldViewer(profileData.Login, viewerLogin, viewerIsAdmin)
+
+	visibleProfileData := models.ProfileData{ID: profileData.ID, Login: profileData.Login}
+	if canSee(models.FieldFirstName) {
+		visibleProfileData.FirstName = profileData.FirstName
+	}
+	if canSee(models.FieldLastName) {
+		visibleProfileData.LastName = profileData.LastName
+	}
+	if canSee(models.FieldEmail) {
+		visibleProfileData.Email = profileData.Email
+		visibleProfileData.VerifiedAt = profileData.VerifiedAt
+		if isOwner || viewerIsAdmin {
+			visibleProfileData.PendingEmail = profileData.PendingEmail
+		}
+	}
+	for name, value := range profileData.Attributes {
+		if canSee(name) {
+			if visibleProfileData.Attributes == nil {
+				visibleProfileData.Attributes = make(map[string]interface{})
+			}
+			visibleProfileData.Attributes[name] = value
+		}
+	}
+	return visibleProfileData
+}
+
+/*
+Составление данных профиля, которые может видеть пользователь: поля, скрытые от пользователя настройками видимости, удаляются
+
+:param profileData models.ProfileData: полные данные профиля
+:param viewerLogin string: логин пользователя тенанта, который просматривает профиль (пустая строка - пользователь не из тенанта)
+:param viewerIsAdmin bool: true, если пользователь является администратором тенанта
+
+:return: данные профиля, видимые пользователю
+*/
+func (t *Tenant) RedactProfileData(profileData models.ProfileData, viewerLogin string, viewerIsAdmin bool) models.ProfileData {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	return t.redactProfileData(profileData, viewerLogin, viewerIsAdmin)
+}
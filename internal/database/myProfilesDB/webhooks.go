@@ -0,0 +1,535 @@
+package myProfilesDB
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+	"github.com/ZotovSergey/authenticationservice/internal/webhooks"
+)
+
+// Доставка события, выбранная фоновой доставкой для очередной попытки
+type dueWebhookDelivery struct {
+	tenant   string                 // название тенанта
+	url      string                 // адрес вебхука
+	secret   string                 // секрет вебхука
+	delivery models.WebhookDelivery // доставка
+}
+
+// Результат попытки доставки события
+type webhookAttempt struct {
+	due            dueWebhookDelivery // доставка
+	responseStatus int                // статус ответа адреса вебхука (0 - ответ не получен)
+	err            error              // ошибка доставки (nil - событие доставлено)
+}
+
+// Копия историй доставок и недоставленных событий тенанта для отмены записи результатов попыток доставки
+type webhookState struct {
+	deliveries  map[string][]models.WebhookDelivery // истории доставок вебхуков, которые меняются результатами попыток
+	deadLetters []models.WebhookDelivery            // недоставленные события тенанта
+}
+
+/*
+Регистрация вебхука: на адрес вебхука отправляются события изменений профилей тенанта заданных типов, подписанные секретом вебхука
+
+:param webhookData models.WebhookData: адрес и типы событий вебхука
+:param author string: логин пользователя, регистрирующего вебхук
+
+:return: вебхук с секретом подписи (секрет выводится только при регистрации); возвращается ошибка, если адрес некорректен или указывает на внутренний адрес
+(петлевой, частный или локальный для канала), тип события неизвестен или базу данных не удалось сохранить
+*/
+func (t *Tenant) AddWebhook(webhookData models.WebhookData, author string) (webhook models.Webhook, err error) {
+	err = t.Update(func(tx *Tx) error {
+		webhook, err = tx.AddWebhook(webhookData, author)
+		return err
+	})
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	return
+}
+
+/*
+Регистрация вебхука (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются)
+
+:param webhookData models.WebhookData: адрес и типы событий вебхука
+:param author string: логин пользователя, регистрирующего вебхук
+
+:return: вебхук с секретом подписи; возвращается ошибка, если адрес некорректен или указывает на внутренний адрес или тип события неизвестен
+*/
+func (t *Tenant) addWebhook(webhookData models.WebhookData, author string) (models.Webhook, error) {
+	// Проверка адреса и типов событий
+	err := webhooks.CheckURL(webhookData.URL)
+	if err != nil {
+		return models.Webhook{}, fmt.Errorf("%w: %s", invalidWebhookURLErr, err.Error())
+	}
+	for _, eventType := range webhookData.Events {
+		known := false
+		for _, knownType := range models.ChangeEventTypes {
+			known = known || eventType == knownType
+		}
+		if !known {
+			return models.Webhook{}, unknownWebhookEventErr
+		}
+	}
+
+	// Генерация секрета подписи
+	secret, err := generateToken()
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	webhook := models.Webhook{
+		ID:        uuid.NewString(),
+		URL:       webhookData.URL,
+		Events:    webhookData.Events,
+		Secret:    secret,
+		CreatedBy: author,
+		CreatedAt: time.Now().Unix(),
+	}
+	setEntry(t.db.undo, t.webhooksTab, webhook.ID, webhook)
+	return webhook, nil
+}
+
+/*
+Получить список вебхуков тенанта (секреты не выводятся)
+
+:return: список вебхуков, отсортированный по времени регистрации
+*/
+func (t *Tenant) GetWebhooks() []models.Webhook {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	webhooksList := make([]models.Webhook, 0, len(t.webhooksTab))
+	for _, webhook := range t.webhooksTab {
+		webhook.Secret = ""
+		webhooksList = append(webhooksList, webhook)
+	}
+	sort.Slice(webhooksList, func(i, j int) bool {
+		return webhooksList[i].CreatedAt < webhooksList[j].CreatedAt
+	})
+	return webhooksList
+}
+
+/*
+Удаление вебхука вместе с историей доставок и недоставленными событиями вебхука (ожидающие доставки отменяются)
+
+:param id string: идентификатор вебхука
+
+:return: возвращается ошибка, если вебхука не существует или базу данных не удалось сохранить
+*/
+func (t *Tenant) RemoveWebhook(id string) error {
+	return t.Update(func(tx *Tx) error {
+		return tx.RemoveWebhook(id)
+	})
+}
+
+/*
+Удаление вебхука вместе с историей доставок и недоставленными событиями вебхука (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются)
+
+:param id string: идентификатор вебхука
+
+:return: возвращается ошибка, если вебхука не существует
+*/
+func (t *Tenant) removeWebhook(id string) error {
+	if _, ok := t.webhooksTab[id]; !ok {
+		return noWebhookErr
+	}
+	deleteEntry(t.db.undo, t.webhooksTab, id)
+	deleteEntry(t.db.undo, t.webhookDeliveriesTab, id)
+	deadLetters := make([]models.WebhookDelivery, 0, len(t.webhookDeadLetters))
+	for _, delivery := range t.webhookDeadLetters {
+		if delivery.WebhookID != id {
+			deadLetters = append(deadLetters, delivery)
+		}
+	}
+	setValue(t.db.undo, &t.webhookDeadLetters, deadLetters)
+	return nil
+}
+
+/*
+Получить историю доставок событий вебхука
+
+:param id string: идентификатор вебхука
+
+:return: ожидающие и последние завершенные доставки в порядке создания или ошибка, если вебхука не существует
+*/
+func (t *Tenant) GetWebhookDeliveries(id string) ([]models.WebhookDelivery, error) {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	if _, ok := t.webhooksTab[id]; !ok {
+		return nil, noWebhookErr
+	}
+	return append([]models.WebhookDelivery{}, t.webhookDeliveriesTab[id]...), nil
+}
+
+/*
+Получить список недоставленных событий тенанта - доставок, все попытки которых завершились ошибкой
+
+:return: недоставленные события в порядке исчерпания попыток
+*/
+func (t *Tenant) GetWebhookDeadLetters() []models.WebhookDelivery {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	return append([]models.WebhookDelivery{}, t.webhookDeadLetters...)
+}
+
+/*
+Повторная доставка недоставленного события: доставка убирается из списка недоставленных событий и снова ожидает доставки с полным набором попыток
+
+:param deliveryID string: идентификатор доставки
+
+:return: возвращается ошибка, если доставки нет в списке недоставленных событий, ее вебхук удален или базу данных не удалось сохранить
+*/
+func (t *Tenant) RetryWebhookDelivery(deliveryID string) error {
+	return t.Update(func(tx *Tx) error {
+		return tx.RetryWebhookDelivery(deliveryID)
+	})
+}
+
+/*
+Повторная доставка недоставленного события (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются)
+
+:param deliveryID string: идентификатор доставки
+
+:return: возвращается ошибка, если доставки нет в списке недоставленных событий или ее вебхук удален
+*/
+func (t *Tenant) retryWebhookDelivery(deliveryID string) error {
+	for i, delivery := range t.webhookDeadLetters {
+		if delivery.ID != deliveryID {
+			continue
+		}
+		if _, ok := t.webhooksTab[delivery.WebhookID]; !ok {
+			return noWebhookErr
+		}
+		setValue(t.db.undo, &t.webhookDeadLetters, append(t.webhookDeadLetters[:i:i], t.webhookDeadLetters[i+1:]...))
+
+		// Доставка снова ожидает доставки (запись о ней в истории вебхука заменяется)
+		delivery.Status = models.WebhookDeliveryPending
+		delivery.Attempts = 0
+		delivery.ResponseStatus = 0
+		delivery.Error = ""
+		delivery.NextAttemptAt = time.Now().Unix()
+		deliveries := t.webhookDeliveriesTab[delivery.WebhookID]
+		for j := range deliveries {
+			if deliveries[j].ID == deliveryID {
+				deliveries = append(deliveries[:j:j], deliveries[j+1:]...)
+				break
+			}
+		}
+		setEntry(t.db.undo, t.webhookDeliveriesTab, delivery.WebhookID, append(deliveries, delivery))
+		t.db.wakeWebhookJob()
+		return nil
+	}
+	return noWebhookDeadLetterErr
+}
+
+/*
+Отправка тестового события на адрес вебхука: выполняется одна попытка доставки (без повторов), результат записывается в историю доставок вебхука
+
+:param id string: идентификатор вебхука
+:param author string: логин пользователя, отправляющего тестовое событие
+
+:return: доставка тестового события со статусом delivered или failed; возвращается ошибка, если вебхука не существует или базу данных не удалось сохранить
+*/
+func (t *Tenant) TestWebhook(id string, author string) (models.WebhookDelivery, error) {
+	t.db.mu.RLock()
+	webhook, ok := t.webhooksTab[id]
+	t.db.mu.RUnlock()
+	if !ok {
+		return models.WebhookDelivery{}, noWebhookErr
+	}
+
+	// Попытка доставки (без блокировки БД: адрес может отвечать долго)
+	now := time.Now().Unix()
+	delivery := models.WebhookDelivery{
+		ID:            uuid.NewString(),
+		WebhookID:     id,
+		Event:         models.ChangeEvent{Type: models.WebhookEventTest, Author: author, At: now},
+		Status:        models.WebhookDeliveryDelivered,
+		Attempts:      1,
+		CreatedAt:     now,
+		LastAttemptAt: now,
+	}
+	responseStatus, err := webhooks.Deliver(webhook.URL, webhook.Secret, models.WebhookPayload{DeliveryID: delivery.ID, Tenant: t.name, Event: delivery.Event})
+	delivery.ResponseStatus = responseStatus
+	if err != nil {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.Error = err.Error()
+	}
+
+	// Запись результата в историю доставок вебхука
+	err = t.Update(func(tx *Tx) error {
+		return tx.recordWebhookTest(delivery)
+	})
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+/*
+Запись доставки тестового события в историю доставок вебхука (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются)
+
+:param delivery models.WebhookDelivery: доставка тестового события
+
+:return: возвращается ошибка, если вебхук удален за время доставки
+*/
+func (t *Tenant) recordWebhookTest(delivery models.WebhookDelivery) error {
+	if _, ok := t.webhooksTab[delivery.WebhookID]; !ok {
+		return noWebhookErr
+	}
+	setEntry(t.db.undo, t.webhookDeliveriesTab, delivery.WebhookID, append(t.webhookDeliveriesTab[delivery.WebhookID], delivery))
+	t.trimWebhookDeliveries(delivery.WebhookID)
+	return nil
+}
+
+/*
+Создание доставок события изменения профилей на адреса вебхуков тенанта, подписанных на события этого типа (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются);
+доставки сохраняются в файл вместе с данными тенанта, поэтому доставки событий отмененной транзакции не создаются
+
+:param event models.ChangeEvent: событие
+*/
+func (t *Tenant) enqueueWebhookDeliveries(event models.ChangeEvent) {
+	for id, webhook := range t.webhooksTab {
+		subscribed := len(webhook.Events) == 0
+		for _, eventType := range webhook.Events {
+			subscribed = subscribed || eventType == event.Type
+		}
+		if !subscribed {
+			continue
+		}
+		setEntry(t.db.undo, t.webhookDeliveriesTab, id, append(t.webhookDeliveriesTab[id], models.WebhookDelivery{
+			ID:            uuid.NewString(),
+			WebhookID:     id,
+			Event:         event,
+			Status:        models.WebhookDeliveryPending,
+			CreatedAt:     event.At,
+			NextAttemptAt: event.At,
+		}))
+		t.db.wakeWebhookJob()
+	}
+}
+
+/*
+Удаление самых старых завершенных доставок из истории вебхука сверх размера истории из конфига (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются);
+ожидающие доставки не удаляются
+
+:param id string: идентификатор вебхука
+*/
+func (t *Tenant) trimWebhookDeliveries(id string) {
+	deliveries := t.webhookDeliveriesTab[id]
+	extra := len(deliveries) - t.db.webhookHistorySize
+	if extra <= 0 {
+		return
+	}
+	trimmed := make([]models.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		if extra > 0 && delivery.Status != models.WebhookDeliveryPending {
+			extra--
+			continue
+		}
+		trimmed = append(trimmed, delivery)
+	}
+	setEntry(t.db.undo, t.webhookDeliveriesTab, id, trimmed)
+}
+
+/*
+Пробуждение фоновой доставки событий вебхуков (вызывается методами БД под блокировкой db.mu): доставка начинается после снятия блокировки,
+поэтому доставки отмененной транзакции не отправляются
+*/
+func (db *myProfilesDB) wakeWebhookJob() {
+	select {
+	case db.webhooksWake <- struct{}{}:
+	default:
+	}
+}
+
+/*
+Выбор доставок событий всех тенантов, время очередной попытки которых наступило (на узле кластера, не являющемся лидером, доставки не выбираются)
+
+:return: доставки для очередной попытки и ближайшее время следующей попытки остальных ожидающих доставок (unix-время в секундах, 0 - ожидающих доставок нет)
+*/
+func (db *myProfilesDB) dueWebhookDeliveries() ([]dueWebhookDelivery, int64) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.standby() {
+		return nil, 0
+	}
+
+	now := time.Now().Unix()
+	var due []dueWebhookDelivery
+	var next int64
+	for tenantName, t := range db.tenantsTab {
+		for id, deliveries := range t.webhookDeliveriesTab {
+			webhook := t.webhooksTab[id]
+			for _, delivery := range deliveries {
+				if delivery.Status != models.WebhookDeliveryPending {
+					continue
+				}
+				if delivery.NextAttemptAt <= now {
+					due = append(due, dueWebhookDelivery{tenant: tenantName, url: webhook.URL, secret: webhook.Secret, delivery: delivery})
+				} else if next == 0 || delivery.NextAttemptAt < next {
+					next = delivery.NextAttemptAt
+				}
+			}
+		}
+	}
+	return due, next
+}
+
+/*
+Запись результата попытки доставки в тенант (вызывается методами БД под блокировкой db.mu, данные в файл не сохраняются): доставка завершается, откладывается
+до следующей попытки или, если попытки исчерпаны, переносится в список недоставленных событий
+
+:param attempt webhookAttempt: попытка доставки
+
+:return: true - если доставка найдена и результат записан (тенант, вебхук или доставка могли быть удалены за время попытки)
+*/
+func (t *Tenant) recordWebhookAttempt(attempt webhookAttempt) bool {
+	deliveries := t.webhookDeliveriesTab[attempt.due.delivery.WebhookID]
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		if delivery.ID != attempt.due.delivery.ID || delivery.Status != models.WebhookDeliveryPending {
+			continue
+		}
+
+		now := time.Now().Unix()
+		delivery.Attempts++
+		delivery.LastAttemptAt = now
+		delivery.ResponseStatus = attempt.responseStatus
+		delivery.Error = ""
+		delivery.NextAttemptAt = 0
+		if attempt.err == nil {
+			delivery.Status = models.WebhookDeliveryDelivered
+		} else {
+			delivery.Error = attempt.err.Error()
+			if delay, ok := webhooks.RetryDelay(delivery.Attempts); ok {
+				delivery.NextAttemptAt = now + delay
+			} else {
+				delivery.Status = models.WebhookDeliveryDead
+				t.webhookDeadLetters = append(t.webhookDeadLetters, *delivery)
+				if extra := len(t.webhookDeadLetters) - t.db.webhookHistorySize; extra > 0 {
+					t.webhookDeadLetters = append([]models.WebhookDelivery(nil), t.webhookDeadLetters[extra:]...)
+				}
+			}
+			log.Printf("webhook delivery %s to \"%s\" failed (attempt %d): %s", delivery.ID, attempt.due.url, delivery.Attempts, attempt.err.Error())
+		}
+		t.trimWebhookDeliveries(attempt.due.delivery.WebhookID)
+		return true
+	}
+	return false
+}
+
+/*
+Запись результатов попыток доставки одного прохода фоновой доставки и сохранение их в файл одним сохранением БД; если базу данных не удалось сохранить,
+состояние доставок восстанавливается, и попытки повторяются следующим проходом
+
+:param attempts []webhookAttempt: попытки доставки
+*/
+func (db *myProfilesDB) completeWebhookDeliveries(attempts []webhookAttempt) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// Копии историй доставок и недоставленных событий, которые меняются результатами попыток
+	saved := make(map[*Tenant]*webhookState)
+	var tenantNames []string
+	for _, attempt := range attempts {
+		t, ok := db.tenantsTab[attempt.due.tenant]
+		if !ok {
+			continue
+		}
+		state, ok := saved[t]
+		if !ok {
+			state = &webhookState{deliveries: make(map[string][]models.WebhookDelivery), deadLetters: append([]models.WebhookDelivery(nil), t.webhookDeadLetters...)}
+			saved[t] = state
+			tenantNames = append(tenantNames, t.name)
+		}
+		webhookID := attempt.due.delivery.WebhookID
+		if _, ok := state.deliveries[webhookID]; !ok {
+			state.deliveries[webhookID] = append([]models.WebhookDelivery(nil), t.webhookDeliveriesTab[webhookID]...)
+		}
+		t.recordWebhookAttempt(attempt)
+	}
+	if len(tenantNames) == 0 {
+		return
+	}
+
+	// Сохранение данных в файл
+	db.markDirty(tenantNames...)
+	err := db.Dump()
+	if err != nil {
+		log.Printf("fail to save %d webhook delivery attempts: %s", len(attempts), err.Error())
+		for t, state := range saved {
+			for webhookID, deliveries := range state.deliveries {
+				if _, ok := t.webhooksTab[webhookID]; ok {
+					t.webhookDeliveriesTab[webhookID] = deliveries
+				}
+			}
+			t.webhookDeadLetters = state.deadLetters
+		}
+	}
+}
+
+/*
+Один проход фоновой доставки: доставки, время попытки которых наступило, отправляются параллельно, результаты попыток сохраняются в файл одним сохранением БД
+
+:return: количество выполненных попыток и ближайшее время следующей попытки остальных ожидающих доставок (unix-время в секундах, 0 - ожидающих доставок нет)
+*/
+func (db *myProfilesDB) deliverDueWebhooks() (int, int64) {
+	due, next := db.dueWebhookDeliveries()
+	if len(due) == 0 {
+		return 0, next
+	}
+	attempts := make([]webhookAttempt, len(due))
+	var wg sync.WaitGroup
+	for i, d := range due {
+		wg.Add(1)
+		go func(i int, d dueWebhookDelivery) {
+			defer wg.Done()
+			payload := models.WebhookPayload{DeliveryID: d.delivery.ID, Tenant: d.tenant, Event: d.delivery.Event}
+			responseStatus, err := webhooks.Deliver(d.url, d.secret, payload)
+			attempts[i] = webhookAttempt{due: d, responseStatus: responseStatus, err: err}
+		}(i, d)
+	}
+	wg.Wait()
+	db.completeWebhookDeliveries(attempts)
+	return len(attempts), next
+}
+
+/*
+Фоновая доставка событий на адреса вебхуков: доставки, время попытки которых наступило, отправляются проходами deliverDueWebhooks, повторные попытки выполняются
+с экспоненциальной задержкой из конфига "../../configs/webhooksConfig.json" (функция не возвращает управление)
+*/
+func (db *myProfilesDB) RunWebhookJob() {
+	for {
+		// Попытки доставки, время которых наступило
+		attempted, next := db.deliverDueWebhooks()
+		if attempted > 0 {
+			continue
+		}
+
+		// Ожидание новых доставок или времени следующей попытки
+		var nextAttempt <-chan time.Time
+		var timer *time.Timer
+		if next > 0 {
+			timer = time.NewTimer(time.Until(time.Unix(next, 0)))
+			nextAttempt = timer.C
+		}
+		select {
+		case <-db.webhooksWake:
+		case <-nextAttempt:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
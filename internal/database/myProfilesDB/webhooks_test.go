@@ -0,0 +1,280 @@
+package myProfilesDB
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+	"github.com/ZotovSergey/authenticationservice/internal/webhooks"
+)
+
+// Запрос, полученный httptest-получателем событий
+type receivedWebhook struct {
+	header  http.Header
+	body    []byte
+	payload models.WebhookPayload
+}
+
+// Получатель событий вебхуков: запоминает запросы и отвечает заданным статусом
+type webhookReceiver struct {
+	server   *httptest.Server
+	mu       sync.Mutex
+	status   int
+	received []receivedWebhook
+}
+
+/*
+Запуск получателя событий
+
+:param t *testing.T: тест
+:param status int: статус ответа
+
+:return: получатель событий (останавливается после теста)
+*/
+func newWebhookReceiver(t *testing.T, status int) *webhookReceiver {
+	receiver := &webhookReceiver{status: status}
+	receiver.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var payload models.WebhookPayload
+		json.Unmarshal(body, &payload)
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+		receiver.received = append(receiver.received, receivedWebhook{header: r.Header.Clone(), body: body, payload: payload})
+		w.WriteHeader(receiver.status)
+	}))
+	t.Cleanup(receiver.server.Close)
+	return receiver
+}
+
+// Изменение статуса ответа получателя
+func (r *webhookReceiver) setStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+// Запросы, полученные получателем
+func (r *webhookReceiver) requests() []receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedWebhook(nil), r.received...)
+}
+
+/*
+Получение единственной доставки вебхука
+
+:param t *testing.T: тест
+:param tenant *Tenant: тенант
+:param webhookID string: идентификатор вебхука
+
+:return: доставка
+*/
+func singleDelivery(t *testing.T, tenant *Tenant, webhookID string) models.WebhookDelivery {
+	t.Helper()
+	deliveries, err := tenant.GetWebhookDeliveries(webhookID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("expected 1 delivery, got %d: %+v", len(deliveries), deliveries)
+	}
+	return deliveries[0]
+}
+
+/*
+Перенос времени очередной попытки ожидающих доставок на текущее время (вместо ожидания задержки)
+
+:param db *myProfilesDB: БД
+:param tenant *Tenant: тенант
+*/
+func makeDeliveriesDue(db *myProfilesDB, tenant *Tenant) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, deliveries := range tenant.webhookDeliveriesTab {
+		for i := range deliveries {
+			if deliveries[i].Status == models.WebhookDeliveryPending {
+				deliveries[i].NextAttemptAt = 0
+			}
+		}
+	}
+}
+
+// Событие доставляется на адрес вебхука с подписью HMAC-SHA256 секретом вебхука
+func TestWebhookDeliverySignature(t *testing.T) {
+	db, platform := openTestDB(t)
+	receiver := newWebhookReceiver(t, http.StatusNoContent)
+	webhook, err := platform.AddWebhook(models.WebhookData{URL: receiver.server.URL}, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addTestProfile(t, platform, "alice")
+	if attempted, _ := db.deliverDueWebhooks(); attempted != 1 {
+		t.Fatalf("expected 1 attempt, got %d", attempted)
+	}
+
+	requests := receiver.requests()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	request := requests[0]
+	mac := hmac.New(sha256.New, []byte(webhook.Secret))
+	mac.Write([]byte(request.header.Get(webhooks.HeaderTimestamp) + "."))
+	mac.Write(request.body)
+	if expected := "sha256=" + hex.EncodeToString(mac.Sum(nil)); request.header.Get(webhooks.HeaderSignature) != expected {
+		t.Fatalf("signature %s does not match %s", request.header.Get(webhooks.HeaderSignature), expected)
+	}
+	delivery := singleDelivery(t, platform, webhook.ID)
+	if request.header.Get(webhooks.HeaderDeliveryID) != delivery.ID || request.header.Get(webhooks.HeaderEvent) != models.ChangeProfileCreated {
+		t.Fatalf("unexpected delivery headers %v", request.header)
+	}
+	if request.payload.Tenant != platform.Name() || request.payload.Event.Login != "alice" {
+		t.Fatalf("unexpected payload %s", request.body)
+	}
+	if delivery.Status != models.WebhookDeliveryDelivered || delivery.Attempts != 1 || delivery.ResponseStatus != http.StatusNoContent {
+		t.Fatalf("unexpected delivery %+v", delivery)
+	}
+}
+
+// Неудачные попытки повторяются с экспоненциальной задержкой, после исчерпания попыток доставка переносится в недоставленные события,
+// откуда ее можно отправить повторно
+func TestWebhookRetriesAndDeadLetters(t *testing.T) {
+	db, platform := openTestDB(t)
+	receiver := newWebhookReceiver(t, http.StatusInternalServerError)
+	webhook, err := platform.AddWebhook(models.WebhookData{URL: receiver.server.URL}, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addTestProfile(t, platform, "bob")
+
+	// Задержки по конфигу теста: 1 секунда, затем 2 секунды (не больше maxBackoff), после 3 попыток доставка недоставлена
+	for attempt, expectedDelay := range []int64{1, 2} {
+		db.deliverDueWebhooks()
+		delivery := singleDelivery(t, platform, webhook.ID)
+		if delivery.Status != models.WebhookDeliveryPending || delivery.Attempts != attempt+1 || delivery.ResponseStatus != http.StatusInternalServerError {
+			t.Fatalf("attempt %d: unexpected delivery %+v", attempt+1, delivery)
+		}
+		if delay := delivery.NextAttemptAt - delivery.LastAttemptAt; delay != expectedDelay {
+			t.Fatalf("attempt %d: expected delay %d, got %d", attempt+1, expectedDelay, delay)
+		}
+		if attempted, _ := db.deliverDueWebhooks(); attempted != 0 {
+			t.Fatalf("attempt %d: delivery is retried before its delay", attempt+1)
+		}
+		makeDeliveriesDue(db, platform)
+	}
+	db.deliverDueWebhooks()
+	delivery := singleDelivery(t, platform, webhook.ID)
+	deadLetters := platform.GetWebhookDeadLetters()
+	if delivery.Status != models.WebhookDeliveryDead || delivery.Attempts != 3 || len(deadLetters) != 1 || deadLetters[0].ID != delivery.ID {
+		t.Fatalf("delivery is not moved to dead letters: %+v, %+v", delivery, deadLetters)
+	}
+	if attempted, _ := db.deliverDueWebhooks(); attempted != 0 || len(receiver.requests()) != 3 {
+		t.Fatalf("dead delivery is retried")
+	}
+
+	// Повторная доставка недоставленного события
+	receiver.setStatus(http.StatusOK)
+	err = platform.RetryWebhookDelivery(delivery.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.deliverDueWebhooks()
+	delivery = singleDelivery(t, platform, webhook.ID)
+	if delivery.Status != models.WebhookDeliveryDelivered || delivery.Attempts != 1 || len(platform.GetWebhookDeadLetters()) != 0 {
+		t.Fatalf("retried delivery is not delivered: %+v", delivery)
+	}
+}
+
+// Если результаты попыток не удалось сохранить, состояние доставок восстанавливается
+func TestWebhookAttemptsRollback(t *testing.T) {
+	db, platform := openTestDB(t)
+	receiver := newWebhookReceiver(t, http.StatusOK)
+	webhook, err := platform.AddWebhook(models.WebhookData{URL: receiver.server.URL}, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addTestProfile(t, platform, "carol")
+
+	due, _ := db.dueWebhookDeliveries()
+	db.mu.Lock()
+	db.follower = true // сохранение БД ведомого экземпляра отклоняется
+	db.mu.Unlock()
+	db.completeWebhookDeliveries([]webhookAttempt{{due: due[0], responseStatus: http.StatusOK}})
+	db.mu.Lock()
+	db.follower = false
+	db.mu.Unlock()
+
+	delivery := singleDelivery(t, platform, webhook.ID)
+	if delivery.Status != models.WebhookDeliveryPending || delivery.Attempts != 0 {
+		t.Fatalf("delivery state is not restored: %+v", delivery)
+	}
+}
+
+// На адрес вебхука отправляются только события типов, на которые подписан вебхук
+func TestWebhookEventFiltering(t *testing.T) {
+	db, platform := openTestDB(t)
+	receiver := newWebhookReceiver(t, http.StatusOK)
+	webhook, err := platform.AddWebhook(models.WebhookData{URL: receiver.server.URL, Events: []string{models.ChangeProfileDeleted}}, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addTestProfile(t, platform, "dave")
+	if attempted, _ := db.deliverDueWebhooks(); attempted != 0 {
+		t.Fatalf("event of not subscribed type is delivered")
+	}
+	err = platform.RemoveProfile("dave", 0, false, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.deliverDueWebhooks()
+	requests := receiver.requests()
+	if len(requests) != 1 || requests[0].payload.Event.Type != models.ChangeProfileDeleted {
+		t.Fatalf("expected one profile.deleted event, got %d requests", len(requests))
+	}
+	if delivery := singleDelivery(t, platform, webhook.ID); delivery.Event.Type != models.ChangeProfileDeleted {
+		t.Fatalf("unexpected delivery %+v", delivery)
+	}
+}
+
+// Тестовое событие отправляется одной попыткой без повторов, результат записывается в историю доставок
+func TestWebhookTestEvent(t *testing.T) {
+	db, platform := openTestDB(t)
+	receiver := newWebhookReceiver(t, http.StatusOK)
+	webhook, err := platform.AddWebhook(models.WebhookData{URL: receiver.server.URL, Events: []string{models.ChangeProfileDeleted}}, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delivery, err := platform.TestWebhook(webhook.ID, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := receiver.requests()
+	if len(requests) != 1 || requests[0].header.Get(webhooks.HeaderEvent) != models.WebhookEventTest || requests[0].payload.DeliveryID != delivery.ID {
+		t.Fatalf("test event is not delivered: %+v", requests)
+	}
+	if delivery.Status != models.WebhookDeliveryDelivered || singleDelivery(t, platform, webhook.ID).ID != delivery.ID {
+		t.Fatalf("unexpected test delivery %+v", delivery)
+	}
+
+	// Недоставленное тестовое событие не повторяется
+	receiver.setStatus(http.StatusBadGateway)
+	delivery, err = platform.TestWebhook(webhook.ID, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != models.WebhookDeliveryFailed || delivery.ResponseStatus != http.StatusBadGateway || delivery.Attempts != 1 {
+		t.Fatalf("unexpected failed test delivery %+v", delivery)
+	}
+	if attempted, _ := db.deliverDueWebhooks(); attempted != 0 || len(platform.GetWebhookDeadLetters()) != 0 {
+		t.Fatalf("failed test event is retried")
+	}
+}
@@ -0,0 +1,14 @@
+package jsonpatch
+
+import (
+	"errors"
+)
+
+// Сообщения об ошибках при применении патчей
+var invalidPointerErr error = errors.New("invalid json pointer")
+var pathNotFoundErr error = errors.New("path not found")
+var unknownOperationErr error = errors.New("unknown patch operation")
+var missingValueErr error = errors.New("patch operation requires value")
+var moveIntoChildErr error = errors.New("location can not be moved into one of its children")
+var testFailedErr error = errors.New("test operation failed")
+var canNotRemoveRootErr error = errors.New("document root can not be removed")
@@ -0,0 +1,320 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Операция JSON Patch (RFC 6902)
+type Operation struct {
+	Op    string          `json:"op"`              // операция: add, remove, replace, move, copy или test
+	Path  string          `json:"path"`            // json pointer (RFC 6901) на изменяемое значение
+	From  string          `json:"from,omitempty"`  // json pointer на исходное значение (для move и copy)
+	Value json.RawMessage `json:"value,omitempty"` // значение (для add, replace и test; отсутствие значения отличается от null)
+}
+
+/*
+Применение JSON Patch (RFC 6902) к json-документу: операции применяются по порядку, при ошибке любой операции патч не применяется
+
+:param document interface{}: исходный документ (в виде, полученном из json)
+:param operations []Operation: операции патча
+
+:return: документ после применения патча (объекты исходного документа могут быть изменены) или ошибка, если одну из операций не удалось применить
+*/
+func ApplyPatch(document interface{}, operations []Operation) (interface{}, error) {
+	for i, operation := range operations {
+		var err error
+		document, err = applyOperation(document, operation)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s \"%s\"): %w", i, operation.Op, operation.Path, err)
+		}
+	}
+	return document, nil
+}
+
+/*
+Применение одной операции JSON Patch
+
+:param document interface{}: документ
+:param operation Operation: операция
+
+:return: документ после применения операции или ошибка, если операцию не удалось применить
+*/
+func applyOperation(document interface{}, operation Operation) (interface{}, error) {
+	path, err := parsePointer(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch operation.Op {
+	case "add":
+		value, err := operationValue(operation)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(document, path, value)
+	case "remove":
+		document, _, err = removeValue(document, path)
+		return document, err
+	case "replace":
+		value, err := operationValue(operation)
+		if err != nil {
+			return nil, err
+		}
+		document, _, err = removeValue(document, path)
+		if err != nil && len(path) > 0 {
+			return nil, err
+		}
+		return addValue(document, path, value)
+	case "move":
+		from, err := parsePointer(operation.From)
+		if err != nil {
+			return nil, err
+		}
+		if len(path) > len(from) && reflect.DeepEqual(path[:len(from)], from) {
+			return nil, moveIntoChildErr
+		}
+		document, value, err := removeValue(document, from)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(document, path, value)
+	case "copy":
+		from, err := parsePointer(operation.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := getValue(document, from)
+		if err != nil {
+			return nil, err
+		}
+		value, err = deepCopy(value)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(document, path, value)
+	case "test":
+		value, err := operationValue(operation)
+		if err != nil {
+			return nil, err
+		}
+		currentValue, err := getValue(document, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(currentValue, value) {
+			return nil, testFailedErr
+		}
+		return document, nil
+	}
+	return nil, unknownOperationErr
+}
+
+/*
+Чтение значения операции
+
+:param operation Operation: операция
+
+:return: значение операции (в виде, полученном из json) или ошибка, если значение не задано
+*/
+func operationValue(operation Operation) (interface{}, error) {
+	if len(operation.Value) == 0 {
+		return nil, missingValueErr
+	}
+	var value interface{}
+	err := json.Unmarshal(operation.Value, &value)
+	return value, err
+}
+
+/*
+Разбор json pointer (RFC 6901)
+
+:param pointer string: json pointer
+
+:return: список ссылочных токенов (пустой для корня документа) или ошибка, если pointer некорректен
+*/
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w \"%s\"", invalidPointerErr, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+/*
+Разбор индекса массива из ссылочного токена
+
+:param token string: ссылочный токен
+:param length int: максимально допустимый индекс
+
+:return: индекс или ошибка, если токен не является индексом от 0 до length
+*/
+func arrayIndex(token string, length int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w \"%s\"", pathNotFoundErr, token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > length {
+		return 0, fmt.Errorf("%w \"%s\"", pathNotFoundErr, token)
+	}
+	return index, nil
+}
+
+/*
+Получение значения по пути
+
+:param node interface{}: узел документа
+:param path []string: путь относительно узла
+
+:return: значение или ошибка, если значения по пути нет
+*/
+func getValue(node interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return node, nil
+	}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[path[0]]
+		if !ok {
+			return nil, fmt.Errorf("%w \"%s\"", pathNotFoundErr, path[0])
+		}
+		return getValue(child, path[1:])
+	case []interface{}:
+		index, err := arrayIndex(path[0], len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+		return getValue(n[index], path[1:])
+	}
+	return nil, fmt.Errorf("%w \"%s\"", pathNotFoundErr, path[0])
+}
+
+/*
+Добавление значения по пути: поле объекта добавляется или заменяется, в массив значение вставляется ("-" - в конец массива)
+
+:param node interface{}: узел документа
+:param path []string: путь относительно узла
+:param value interface{}: добавляемое значение
+
+:return: узел после добавления значения или ошибка, если родителя значения по пути нет
+*/
+func addValue(node interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			n[path[0]] = value
+			return n, nil
+		}
+		child, ok := n[path[0]]
+		if !ok {
+			return nil, fmt.Errorf("%w \"%s\"", pathNotFoundErr, path[0])
+		}
+		child, err := addValue(child, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[path[0]] = child
+		return n, nil
+	case []interface{}:
+		if len(path) == 1 {
+			index := len(n)
+			if path[0] != "-" {
+				var err error
+				index, err = arrayIndex(path[0], len(n))
+				if err != nil {
+					return nil, err
+				}
+			}
+			n = append(n, nil)
+			copy(n[index+1:], n[index:])
+			n[index] = value
+			return n, nil
+		}
+		index, err := arrayIndex(path[0], len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+		child, err := addValue(n[index], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[index] = child
+		return n, nil
+	}
+	return nil, fmt.Errorf("%w \"%s\"", pathNotFoundErr, path[0])
+}
+
+/*
+Удаление значения по пути
+
+:param node interface{}: узел документа
+:param path []string: путь относительно узла
+
+:return: узел после удаления значения и удаленное значение или ошибка, если значения по пути нет
+*/
+func removeValue(node interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, canNotRemoveRootErr
+	}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[path[0]]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w \"%s\"", pathNotFoundErr, path[0])
+		}
+		if len(path) == 1 {
+			delete(n, path[0])
+			return n, child, nil
+		}
+		child, removed, err := removeValue(child, path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		n[path[0]] = child
+		return n, removed, nil
+	case []interface{}:
+		index, err := arrayIndex(path[0], len(n)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(path) == 1 {
+			removed := n[index]
+			return append(n[:index], n[index+1:]...), removed, nil
+		}
+		child, removed, err := removeValue(n[index], path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		n[index] = child
+		return n, removed, nil
+	}
+	return nil, nil, fmt.Errorf("%w \"%s\"", pathNotFoundErr, path[0])
+}
+
+/*
+Глубокое копирование значения (для операции copy)
+
+:param value interface{}: значение (в виде, полученном из json)
+
+:return: копия значения
+*/
+func deepCopy(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var copied interface{}
+	err = json.Unmarshal(data, &copied)
+	return copied, err
+}
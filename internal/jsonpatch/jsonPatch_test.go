@@ -0,0 +1,245 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+/*
+Разбор json для теста
+
+:param t *testing.T: тест
+:param data string: json
+
+:return: значение в виде, полученном из json
+*/
+func decodeTestJSON(t *testing.T, data string) interface{} {
+	t.Helper()
+	var value interface{}
+	err := json.Unmarshal([]byte(data), &value)
+	if err != nil {
+		t.Fatalf("invalid test json %s: %s", data, err.Error())
+	}
+	return value
+}
+
+// Примеры приложения A RFC 6902 (кроме A.13 о повторяющихся полях операции, которые разбирает encoding/json), примеры указателей раздела 5 RFC 6901
+// и ошибки операций
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		patch    string
+		expected string // документ после применения патча (при ошибке не проверяется)
+		err      error  // ожидаемая ошибка (nil - патч применяется)
+	}{
+		{
+			name:     "A.1 adding an object member",
+			document: `{"foo":"bar"}`,
+			patch:    `[{"op":"add","path":"/baz","value":"qux"}]`,
+			expected: `{"baz":"qux","foo":"bar"}`,
+		},
+		{
+			name:     "A.2 adding an array element",
+			document: `{"foo":["bar","baz"]}`,
+			patch:    `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+			expected: `{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			name:     "A.3 removing an object member",
+			document: `{"baz":"qux","foo":"bar"}`,
+			patch:    `[{"op":"remove","path":"/baz"}]`,
+			expected: `{"foo":"bar"}`,
+		},
+		{
+			name:     "A.4 removing an array element",
+			document: `{"foo":["bar","qux","baz"]}`,
+			patch:    `[{"op":"remove","path":"/foo/1"}]`,
+			expected: `{"foo":["bar","baz"]}`,
+		},
+		{
+			name:     "A.5 replacing a value",
+			document: `{"baz":"qux","foo":"bar"}`,
+			patch:    `[{"op":"replace","path":"/baz","value":"boo"}]`,
+			expected: `{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			name:     "A.6 moving a value",
+			document: `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			patch:    `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			expected: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			name:     "A.7 moving an array element",
+			document: `{"foo":["all","grass","cows","eat"]}`,
+			patch:    `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			expected: `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			name:     "A.8 testing a value: success",
+			document: `{"baz":"qux","foo":["a",2,"c"]}`,
+			patch:    `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			expected: `{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{
+			name:     "A.9 testing a value: error",
+			document: `{"baz":"qux"}`,
+			patch:    `[{"op":"test","path":"/baz","value":"bar"}]`,
+			err:      testFailedErr,
+		},
+		{
+			name:     "A.10 adding a nested member object",
+			document: `{"foo":"bar"}`,
+			patch:    `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`,
+			expected: `{"foo":"bar","child":{"grandchild":{}}}`,
+		},
+		{
+			name:     "A.11 ignoring unrecognized elements",
+			document: `{"foo":"bar"}`,
+			patch:    `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`,
+			expected: `{"foo":"bar","baz":"qux"}`,
+		},
+		{
+			name:     "A.12 adding to a nonexistent target",
+			document: `{"foo":"bar"}`,
+			patch:    `[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+			err:      pathNotFoundErr,
+		},
+		{
+			name:     "A.14 ~ escape ordering",
+			document: `{"/":9,"~1":10}`,
+			patch:    `[{"op":"test","path":"/~01","value":10}]`,
+			expected: `{"/":9,"~1":10}`,
+		},
+		{
+			name:     "A.15 comparing strings and numbers",
+			document: `{"/":9,"~1":10}`,
+			patch:    `[{"op":"test","path":"/~01","value":"10"}]`,
+			err:      testFailedErr,
+		},
+		{
+			name:     "A.16 adding an array value",
+			document: `{"foo":["bar"]}`,
+			patch:    `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+			expected: `{"foo":["bar",["abc","def"]]}`,
+		},
+		{
+			name:     "RFC 6901 pointers",
+			document: `{"foo":["bar","baz"],"":0,"a/b":1,"c%d":2,"e^f":3,"g|h":4,"i\\j":5,"k\"l":6," ":7,"m~n":8}`,
+			patch: `[{"op":"test","path":"","value":{"foo":["bar","baz"],"":0,"a/b":1,"c%d":2,"e^f":3,"g|h":4,"i\\j":5,"k\"l":6," ":7,"m~n":8}},
+				{"op":"test","path":"/foo","value":["bar","baz"]},{"op":"test","path":"/foo/0","value":"bar"},{"op":"test","path":"/","value":0},
+				{"op":"test","path":"/a~1b","value":1},{"op":"test","path":"/c%d","value":2},{"op":"test","path":"/e^f","value":3},
+				{"op":"test","path":"/g|h","value":4},{"op":"test","path":"/i\\j","value":5},{"op":"test","path":"/k\"l","value":6},
+				{"op":"test","path":"/ ","value":7},{"op":"test","path":"/m~0n","value":8}]`,
+			expected: `{"foo":["bar","baz"],"":0,"a/b":1,"c%d":2,"e^f":3,"g|h":4,"i\\j":5,"k\"l":6," ":7,"m~n":8}`,
+		},
+		{
+			name:     "escaped member names",
+			document: `{"a/b":1,"m~n":2}`,
+			patch:    `[{"op":"add","path":"/c~1d","value":3},{"op":"replace","path":"/m~0n","value":4},{"op":"remove","path":"/a~1b"}]`,
+			expected: `{"c/d":3,"m~n":4}`,
+		},
+		{
+			name:     "copying a value",
+			document: `{"foo":{"bar":[1,2]},"baz":"qux"}`,
+			patch:    `[{"op":"copy","from":"/foo","path":"/copy"},{"op":"add","path":"/copy/bar/-","value":3},{"op":"copy","from":"/baz","path":"/foo/bar/0"}]`,
+			expected: `{"foo":{"bar":["qux",1,2]},"baz":"qux","copy":{"bar":[1,2,3]}}`,
+		},
+		{
+			name:     "copying from a nonexistent location",
+			document: `{"foo":1}`,
+			patch:    `[{"op":"copy","from":"/bar","path":"/baz"}]`,
+			err:      pathNotFoundErr,
+		},
+		{
+			name:     "moving a value into its child",
+			document: `{"foo":{"bar":1}}`,
+			patch:    `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`,
+			err:      moveIntoChildErr,
+		},
+		{
+			name:     "replacing the whole document",
+			document: `{"foo":1}`,
+			patch:    `[{"op":"replace","path":"","value":["bar"]}]`,
+			expected: `["bar"]`,
+		},
+		{
+			name:     "adding a value equal to null",
+			document: `{"foo":1}`,
+			patch:    `[{"op":"add","path":"/bar","value":null}]`,
+			expected: `{"foo":1,"bar":null}`,
+		},
+		{
+			name:     "adding without a value",
+			document: `{"foo":1}`,
+			patch:    `[{"op":"add","path":"/bar"}]`,
+			err:      missingValueErr,
+		},
+		{
+			name:     "removing the end of an array",
+			document: `{"foo":[1]}`,
+			patch:    `[{"op":"remove","path":"/foo/-"}]`,
+			err:      pathNotFoundErr,
+		},
+		{
+			name:     "adding past the end of an array",
+			document: `{"foo":[1]}`,
+			patch:    `[{"op":"add","path":"/foo/2","value":2}]`,
+			err:      pathNotFoundErr,
+		},
+		{
+			name:     "array index with a leading zero",
+			document: `{"foo":[1,2]}`,
+			patch:    `[{"op":"replace","path":"/foo/01","value":3}]`,
+			err:      pathNotFoundErr,
+		},
+		{
+			name:     "removing the document root",
+			document: `{"foo":1}`,
+			patch:    `[{"op":"remove","path":""}]`,
+			err:      canNotRemoveRootErr,
+		},
+		{
+			name:     "pointer without a leading slash",
+			document: `{"foo":1}`,
+			patch:    `[{"op":"remove","path":"foo"}]`,
+			err:      invalidPointerErr,
+		},
+		{
+			name:     "unknown operation",
+			document: `{"foo":1}`,
+			patch:    `[{"op":"merge","path":"/foo","value":2}]`,
+			err:      unknownOperationErr,
+		},
+		{
+			name:     "failed operation after applied operations",
+			document: `{"foo":1}`,
+			patch:    `[{"op":"add","path":"/bar","value":2},{"op":"test","path":"/bar","value":3}]`,
+			err:      testFailedErr,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var operations []Operation
+			err := json.Unmarshal([]byte(test.patch), &operations)
+			if err != nil {
+				t.Fatal(err)
+			}
+			document, err := ApplyPatch(decodeTestJSON(t, test.document), operations)
+			if test.err != nil {
+				if !errors.Is(err, test.err) || document != nil {
+					t.Fatalf("expected error %q, got %v (document %v)", test.err, err, document)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := decodeTestJSON(t, test.expected); !reflect.DeepEqual(document, expected) {
+				t.Fatalf("expected %v, got %v", expected, document)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package jsonpatch
+
+/*
+Применение JSON Merge Patch (RFC 7396) к json-документу: поля патча заменяют поля документа, поля со значением null удаляются,
+вложенные объекты объединяются рекурсивно, любое значение патча, не являющееся объектом, заменяет документ целиком
+
+:param document interface{}: исходный документ (в виде, полученном из json)
+:param patch interface{}: патч (в виде, полученном из json)
+
+:return: документ после применения патча (объекты исходного документа могут быть изменены)
+*/
+func MergePatch(document interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	documentObject, ok := document.(map[string]interface{})
+	if !ok {
+		documentObject = make(map[string]interface{}, len(patchObject))
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(documentObject, name)
+			continue
+		}
+		documentObject[name] = MergePatch(documentObject[name], value)
+	}
+	return documentObject
+}
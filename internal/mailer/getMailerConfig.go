@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+)
+
+// Структура конфигурации отправителя писем
+type mailerConfig struct {
+	Type     string `json:"type"`     // тип отправителя: log или smtp
+	SMTPHost string `json:"smtpHost"` // адрес SMTP-сервера
+	SMTPPort int    `json:"smtpPort"` // порт SMTP-сервера
+	Username string `json:"username"` // имя пользователя SMTP-сервера (пустая строка - без авторизации)
+	Password string `json:"password"` // пароль пользователя SMTP-сервера
+	From     string `json:"from"`     // адрес отправителя писем
+}
+
+/*
+Чтение конфигурации отправителя писем из конфига "../../configs/mailerConfig.json"
+
+:return: конфигурация отправителя писем или ошибка, если конфиг не удалось прочитать
+*/
+func getMailerConfig() (mailerConfig, error) {
+	configFilePath := "../../configs/mailerConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return mailerConfig{}, errors.New("fail to read mailer config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var config mailerConfig
+	json.Unmarshal(byteValue, &config)
+
+	return config, nil
+}
@@ -0,0 +1,92 @@
+package mailer
+
+import (
+	"errors"
+	"log"
+	"sync"
+)
+
+// Интерфейс отправки писем пользователям (токены подтверждения адресов электронной почты, уведомления)
+type Mailer interface {
+	/*
+		Отправка письма
+
+		:param to string: адрес электронной почты получателя
+		:param subject string: тема письма
+		:param body string: текст письма
+
+		:return: ошибка, если письмо не удалось отправить
+	*/
+	Send(to string, subject string, body string) error
+}
+
+// Отправитель писем сервиса (выбирается в конфиге функцией Configure, может быть заменен любой реализацией интерфейса Mailer)
+var Sender Mailer = logMailer{}
+
+// Письма, отправляемые в фоне (используется утилитами, которые должны дождаться отправки писем перед завершением)
+var pending sync.WaitGroup
+
+/*
+Выбор отправителя писем по конфигу "../../configs/mailerConfig.json": "log" - письма записываются в лог сервиса, "smtp" - письма отправляются через SMTP-сервер
+
+:return: ошибка, если конфиг не удалось прочитать или тип отправителя неизвестен
+*/
+func Configure() error {
+	config, err := getMailerConfig()
+	if err != nil {
+		return err
+	}
+	switch config.Type {
+	case "", "log":
+		Sender = logMailer{}
+	case "smtp":
+		Sender = smtpMailer{host: config.SMTPHost, port: config.SMTPPort, username: config.Username, password: config.Password, from: config.From}
+	default:
+		return errors.New("unknown mailer type \"" + config.Type + "\"")
+	}
+	log.Printf("mailer \"%s\" configured", config.Type)
+	return nil
+}
+
+/*
+Отправка письма в фоне: письмо отправляется, не задерживая вызывающий код (в том числе методы БД под блокировкой), ошибка отправки записывается в лог
+
+:param to string: адрес электронной почты получателя
+:param subject string: тема письма
+:param body string: текст письма
+*/
+func sendAsync(to string, subject string, body string) {
+	sender := Sender
+	pending.Add(1)
+	go func() {
+		defer pending.Done()
+		err := sender.Send(to, subject, body)
+		if err != nil {
+			log.Printf("fail to send mail to \"%s\": %s", to, err.Error())
+		}
+	}()
+}
+
+/*
+Ожидание отправки всех писем, отправляемых в фоне
+*/
+func Wait() {
+	pending.Wait()
+}
+
+// Отправитель, который не отправляет письма, а записывает их в лог сервиса (для разработки и развертываний без почтового сервера)
+type logMailer struct{}
+
+/*
+Запись письма в лог сервиса
+
+:param to string: адрес электронной почты получателя
+:param subject string: тема письма
+:param body string: текст письма
+
+:return: nil
+*/
+func (logMailer) Send(to string, subject string, body string) error {
+	log.Printf("mail to \"%s\" (%s): %s", to, subject, body)
+	return nil
+}
@@ -0,0 +1,28 @@
+package mailer
+
+import (
+	"net/url"
+)
+
+/*
+Отправка письма с токеном подтверждения адреса электронной почты (в фоне)
+
+:param tenantName string: название тенанта профиля
+:param email string: подтверждаемый адрес электронной почты
+:param token string: токен подтверждения
+*/
+func SendVerification(tenantName string, email string, token string) {
+	verifyPath := "/tenants/" + url.PathEscape(tenantName) + "/email/verify/" + token
+	sendAsync(email, "Email verification", "To confirm your email address, send POST request "+verifyPath)
+}
+
+/*
+Отправка уведомления на старый адрес электронной почты о том, что он заменен новым подтвержденным адресом (в фоне)
+
+:param login string: логин профиля
+:param oldEmail string: старый адрес электронной почты
+:param newEmail string: новый адрес электронной почты
+*/
+func SendEmailChangedNotice(login string, oldEmail string, newEmail string) {
+	sendAsync(oldEmail, "Email changed", "Email address of profile \""+login+"\" has been changed to "+newEmail+". If you did not request this change, contact your administrator")
+}
@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+// Отправитель писем через SMTP-сервер
+type smtpMailer struct {
+	host     string // адрес SMTP-сервера
+	port     int    // порт SMTP-сервера
+	username string // имя пользователя SMTP-сервера (пустая строка - без авторизации)
+	password string // пароль пользователя SMTP-сервера
+	from     string // адрес отправителя писем
+}
+
+/*
+Отправка письма через SMTP-сервер
+
+:param to string: адрес электронной почты получателя
+:param subject string: тема письма
+:param body string: текст письма
+
+:return: ошибка, если письмо не удалось отправить
+*/
+func (m smtpMailer) Send(to string, subject string, body string) error {
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+	message := strings.Join([]string{
+		"From: " + m.from,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+	return smtp.SendMail(net.JoinHostPort(m.host, strconv.Itoa(m.port)), auth, m.from, []string{to}, []byte(message))
+}
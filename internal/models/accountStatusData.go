@@ -0,0 +1,34 @@
+package models
+
+// Статусы учетных записей профилей
+const (
+	AccountStatusPending   = "pending"   // учетная запись ожидает активации, авторизация запрещена
+	AccountStatusActive    = "active"    // учетная запись активна, авторизация разрешена
+	AccountStatusSuspended = "suspended" // учетная запись приостановлена администратором, авторизация запрещена
+	AccountStatusExpired   = "expired"   // срок действия учетной записи истек, авторизация запрещена
+)
+
+// Структура статуса учетной записи профиля
+type AccountStatus struct {
+	Status    string `json:"status"`              // статус учетной записи
+	Reason    string `json:"reason,omitempty"`    // причина приостановки учетной записи
+	ChangedAt int64  `json:"changedAt"`           // время последнего изменения статуса (unix-время в секундах)
+	ExpiresAt int64  `json:"expiresAt,omitempty"` // время истечения срока действия учетной записи (unix-время в секундах, 0 - срок не ограничен)
+}
+
+// Структура данных профиля в списке учетных записей
+type AccountData struct {
+	ID    string `json:"id"`    // идентификатор профиля
+	Login string `json:"login"` // логин профиля
+	AccountStatus
+}
+
+// Структура данных для приостановки учетной записи
+type SuspensionData struct {
+	Reason string `json:"reason"` // причина приостановки учетной записи (обязательна)
+}
+
+// Структура данных для изменения срока действия учетной записи
+type ExpiryData struct {
+	ExpiresAt int64 `json:"expiresAt"` // время истечения срока действия учетной записи (unix-время в секундах, 0 - срок не ограничен)
+}
@@ -0,0 +1,49 @@
+package models
+
+// Типы дополнительных атрибутов профиля
+const (
+	AttributeTypeString = "string" // строка
+	AttributeTypeInt    = "int"    // целое число
+	AttributeTypeBool   = "bool"   // логическое значение
+	AttributeTypeDate   = "date"   // дата в формате YYYY-MM-DD
+	AttributeTypeEnum   = "enum"   // одно из значений списка enumValues
+	AttributeTypeList   = "list"   // список строк
+)
+
+// Уровни видимости полей и дополнительных атрибутов профиля (независимо от уровня поле видят участники групп visibleToGroups)
+const (
+	VisibilityPublic = "public" // поле видят все пользователи тенанта
+	VisibilitySelf   = "self"   // поле видят владелец профиля и администраторы
+	VisibilityAdmin  = "admin"  // поле видят только администраторы
+)
+
+// Названия основных полей профиля, видимость которых можно настроить (логин виден всегда)
+const (
+	FieldFirstName = "firstName" // имя пользователя
+	FieldLastName  = "lastName"  // фамилия пользователя
+	FieldEmail     = "email"     // адрес электронной почты пользователя
+)
+
+// Структура описания дополнительного атрибута профиля в схеме атрибутов тенанта
+type AttributeDefinition struct {
+	Name            string   `json:"name"`                      // название атрибута, является первичным ключом схемы атрибутов, должно быть уникальным
+	Type            string   `json:"type"`                      // тип атрибута: string, int, bool, date, enum или list
+	Required        bool     `json:"required"`                  // true, если атрибут обязателен для всех профилей
+	Unique          bool     `json:"unique"`                    // true, если значения атрибута не должны повторяться у разных профилей (не поддерживается для bool и list)
+	Regex           string   `json:"regex,omitempty"`           // регулярное выражение для значений типов string и list
+	EnumValues      []string `json:"enumValues,omitempty"`      // допустимые значения атрибута типа enum
+	Visibility      string   `json:"visibility"`                // видимость атрибута: public, self или admin (по умолчанию self)
+	VisibleToGroups []string `json:"visibleToGroups,omitempty"` // группы (роли), участники которых видят атрибут независимо от уровня видимости
+}
+
+// Структура настройки видимости поля профиля (основного поля или дополнительного атрибута)
+type FieldVisibility struct {
+	Field           string   `json:"field"`                     // название основного поля (firstName, lastName, email) или дополнительного атрибута
+	Visibility      string   `json:"visibility"`                // видимость поля: public, self или admin (по умолчанию имя и фамилия - public, адрес электронной почты - self)
+	VisibleToGroups []string `json:"visibleToGroups,omitempty"` // группы (роли), участники которых видят поле независимо от уровня видимости
+}
+
+// Структура данных, содержащая только название атрибута
+type AttributeNameData struct {
+	Name string `json:"name"` // название атрибута
+}
@@ -0,0 +1,62 @@
+package models
+
+// Операции пакета
+const (
+	BatchOpCreateProfile     = "createProfile"     // создание профиля (login, firstName, lastName, email, attributes, password, status)
+	BatchOpEditProfile       = "editProfile"       // изменение данных профиля, пустые поля не меняются (login, firstName, lastName, email, attributes, version)
+	BatchOpSetPassword       = "setPassword"       // изменение пароля профиля (login, password)
+	BatchOpRemoveProfile     = "removeProfile"     // удаление профиля в корзину или окончательно (login, hard, version)
+	BatchOpAddAdmin          = "addAdmin"          // добавление профиля в список администраторов (login)
+	BatchOpDropAdmin         = "dropAdmin"         // удаление профиля из списка администраторов (login)
+	BatchOpAddGroupMember    = "addGroupMember"    // добавление профиля в группу (login, group)
+	BatchOpRemoveGroupMember = "removeGroupMember" // удаление профиля из группы (login, group)
+	BatchOpActivateAccount   = "activateAccount"   // активация учетной записи (login)
+	BatchOpSuspendAccount    = "suspendAccount"    // приостановка учетной записи (login, reason)
+)
+
+// Результаты операций пакета
+const (
+	BatchStatusOK         = "ok"         // операция выполнена и зафиксирована
+	BatchStatusFailed     = "failed"     // операция не выполнена, пакет отменен
+	BatchStatusRolledBack = "rolledBack" // операция выполнена, но отменена вместе с пакетом
+	BatchStatusSkipped    = "skipped"    // операция не выполнялась, так как пакет отменен
+)
+
+// Структура операции пакета (используемые поля зависят от операции)
+type BatchOperation struct {
+	Op         string                 `json:"op"`                   // операция
+	Login      string                 `json:"login"`                // логин профиля
+	FirstName  string                 `json:"firstName,omitempty"`  // имя пользователя
+	LastName   string                 `json:"lastName,omitempty"`   // фамилия пользователя
+	Email      string                 `json:"email,omitempty"`      // адрес электронной почты пользователя
+	Attributes map[string]interface{} `json:"attributes,omitempty"` // дополнительные атрибуты профиля
+	Password   string                 `json:"password,omitempty"`   // пароль (проверяется по политике паролей)
+	Status     string                 `json:"status,omitempty"`     // начальный статус учетной записи: pending или active (по-умолчанию)
+	Group      string                 `json:"group,omitempty"`      // название группы
+	Reason     string                 `json:"reason,omitempty"`     // причина приостановки учетной записи
+	Hard       bool                   `json:"hard,omitempty"`       // профиль удаляется окончательно, минуя корзину
+	Version    int64                  `json:"version,omitempty"`    // ожидаемая текущая версия профиля (0 - версия не проверяется)
+}
+
+// Структура пакета операций
+type BatchData struct {
+	Operations []BatchOperation `json:"operations"` // операции, выполняемые по порядку
+}
+
+// Структура результата операции пакета
+type BatchOperationResult struct {
+	Index   int    `json:"index"`             // номер операции в пакете (с 0)
+	Op      string `json:"op"`                // операция
+	Login   string `json:"login"`             // логин профиля
+	Status  string `json:"status"`            // результат операции
+	ID      string `json:"id,omitempty"`      // идентификатор созданного профиля
+	Version int64  `json:"version,omitempty"` // новая версия созданного или измененного профиля
+	Error   string `json:"error,omitempty"`   // ошибка операции
+}
+
+// Структура результата пакета операций
+type BatchResult struct {
+	Committed bool                   `json:"committed"`       // true - все операции выполнены и сохранены, false - пакет отменен, данные не изменены
+	Error     string                 `json:"error,omitempty"` // причина отмены пакета
+	Results   []BatchOperationResult `json:"results"`         // результаты операций
+}
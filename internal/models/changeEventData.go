@@ -0,0 +1,29 @@
+package models
+
+// Типы событий изменений профилей тенанта
+const (
+	ChangeProfileCreated       = "profile.created"       // создание профиля (в том числе восстановление из корзины)
+	ChangeProfileUpdated       = "profile.updated"       // изменение данных или логина профиля
+	ChangeProfileDeleted       = "profile.deleted"       // удаление профиля (перенос в корзину или окончательное удаление)
+	ChangeAdminGranted         = "admin.granted"         // добавление профиля в список администраторов тенанта или получение прав администратора через группу администраторов
+	ChangeAdminRevoked         = "admin.revoked"         // удаление профиля из списка администраторов тенанта или потеря прав администратора, полученных через группу администраторов
+	ChangePasswordChanged      = "password.changed"      // изменение пароля профиля (пароль и его хэш в событие не попадают)
+	ChangeAccountActivated     = "account.activated"     // активация учетной записи (администратором, по приглашению или по заявке на регистрацию)
+	ChangeAccountSuspended     = "account.suspended"     // приостановка учетной записи
+	ChangeAccountExpiryChanged = "account.expiryChanged" // изменение срока действия учетной записи
+)
+
+// Список всех типов событий изменений профилей
+var ChangeEventTypes = []string{ChangeProfileCreated, ChangeProfileUpdated, ChangeProfileDeleted, ChangeAdminGranted, ChangeAdminRevoked, ChangePasswordChanged,
+	ChangeAccountActivated, ChangeAccountSuspended, ChangeAccountExpiryChanged}
+
+// Структура события изменения профиля в журнале изменений тенанта
+type ChangeEvent struct {
+	Seq       int64  `json:"seq"`               // порядковый номер события в тенанте (номера возрастают и не повторяются)
+	Type      string `json:"type"`              // тип события
+	ProfileID string `json:"profileId"`         // идентификатор профиля
+	Login     string `json:"login"`             // логин профиля на момент события
+	Version   int64  `json:"version,omitempty"` // версия профиля после изменения его данных (для событий создания, изменения и удаления профиля)
+	Author    string `json:"author,omitempty"`  // логин пользователя, выполнившего изменение (пустая строка - не известен или изменение выполнено сервисом)
+	At        int64  `json:"at"`                // время события (unix-время в секундах)
+}
@@ -0,0 +1,23 @@
+package models
+
+// Структура узла кластера в конфигурации Raft
+type ClusterServer struct {
+	ID       string `json:"id"`               // идентификатор узла
+	RaftAddr string `json:"raftAddr"`         // адрес транспорта Raft узла
+	APIURL   string `json:"apiUrl,omitempty"` // адрес API узла (из конфига кластера)
+	Voter    bool   `json:"voter"`            // true - узел участвует в голосовании
+}
+
+// Структура состояния узла кластера (выводится запросом готовности экземпляра и запросом состояния кластера)
+type ClusterStatus struct {
+	NodeID       string          `json:"nodeId"`                 // идентификатор узла
+	State        string          `json:"state"`                  // состояние узла в Raft: Leader, Follower, Candidate или Shutdown
+	Ready        bool            `json:"ready"`                  // узел получил данные БД из журнала Raft и лидер кластера известен
+	Writable     bool            `json:"writable"`               // узел является лидером и принимает изменения
+	Leader       string          `json:"leader,omitempty"`       // идентификатор лидера кластера
+	LeaderAPIURL string          `json:"leaderApiUrl,omitempty"` // адрес API лидера кластера, которому перенаправляются изменяющие запросы
+	Term         uint64          `json:"term"`                   // текущий срок Raft
+	LastIndex    uint64          `json:"lastIndex"`              // номер последней команды в журнале Raft узла
+	AppliedIndex uint64          `json:"appliedIndex"`           // номер последней примененной к БД команды
+	Servers      []ClusterServer `json:"servers"`                // узлы кластера в конфигурации Raft
+}
@@ -0,0 +1,8 @@
+package models
+
+// Структура запроса на подтверждение адреса электронной почты в БД (токен подтверждения не хранится)
+type EmailVerification struct {
+	ProfileID string `json:"profileId"` // идентификатор профиля, адрес электронной почты которого подтверждается
+	Email     string `json:"email"`     // подтверждаемый адрес электронной почты
+	ExpiresAt int64  `json:"expiresAt"` // время истечения токена подтверждения (unix-время в секундах)
+}
@@ -0,0 +1,31 @@
+package models
+
+// Структура данных группы, содержащихся в БД, для хранения и вывода
+type GroupData struct {
+	Name      string   `json:"name"`      // название группы, является первичным ключом для таблицы групп, должно быть уникальным
+	Members   []string `json:"members"`   // логины профилей, непосредственно входящих в группу
+	Subgroups []string `json:"subgroups"` // названия групп, вложенных в группу
+}
+
+// Структура данных, содержащая только название группы
+type GroupNameData struct {
+	Name string `json:"name"` // название группы
+}
+
+// Структура данных, содержащая текущее и новое название группы, используется для переименования группы
+type GroupRenameData struct {
+	Name    string `json:"name"`    // текущее название группы
+	NewName string `json:"newName"` // новое название группы
+}
+
+// Структура данных, содержащая название группы и логин профиля, используется для изменения состава группы
+type GroupMemberData struct {
+	Name  string `json:"name"`  // название группы
+	Login string `json:"login"` // логин профиля, добавляемого в группу или удаляемого из нее
+}
+
+// Структура данных, содержащая название группы и название вложенной группы, используется для изменения вложенности групп
+type SubgroupData struct {
+	Name     string `json:"name"`     // название родительской группы
+	Subgroup string `json:"subgroup"` // название вложенной группы
+}
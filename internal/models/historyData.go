@@ -0,0 +1,37 @@
+package models
+
+// Действия с профилем, записываемые в историю изменений профиля
+const (
+	HistoryActionCreate  = "create"  // создание профиля
+	HistoryActionEdit    = "edit"    // редактирование данных профиля
+	HistoryActionRename  = "rename"  // изменение логина профиля
+	HistoryActionSchema  = "schema"  // удаление атрибута профиля вместе с описанием атрибута из схемы
+	HistoryActionRevert  = "revert"  // возврат данных профиля к одной из предыдущих версий
+	HistoryActionVerify  = "verify"  // подтверждение адреса электронной почты профиля
+	HistoryActionDelete  = "delete"  // перенос профиля в корзину
+	HistoryActionRestore = "restore" // восстановление профиля из корзины
+	HistoryActionImport  = "import"  // начальная запись истории профиля, созданного до появления истории изменений
+)
+
+// Структура записи истории изменений профиля
+type ProfileHistoryEntry struct {
+	Version   int64         `json:"version"`           // версия профиля после изменения
+	ChangedAt int64         `json:"changedAt"`         // время изменения (unix-время в секундах)
+	ChangedBy string        `json:"changedBy"`         // логин пользователя, выполнившего изменение (пустая строка - изменение выполнено сервисом)
+	Action    string        `json:"action"`            // действие с профилем
+	Profile   *ProfileData  `json:"profile,omitempty"` // данные профиля после изменения (хранятся в БД, при выводе истории заменяются списком изменений)
+	Changes   []FieldChange `json:"changes,omitempty"` // изменения полей профиля относительно предыдущей записи истории
+}
+
+// Структура изменения поля профиля
+type FieldChange struct {
+	Field    string      `json:"field"`              // название поля (дополнительные атрибуты - attributes.<название>)
+	OldValue interface{} `json:"oldValue,omitempty"` // значение поля до изменения (отсутствует, если поле было пустым)
+	NewValue interface{} `json:"newValue,omitempty"` // значение поля после изменения (отсутствует, если поле очищено)
+}
+
+// Структура данных для возврата профиля к одной из предыдущих версий: задается версия или время (профиль возвращается к последней версии на это время)
+type ProfileRevertData struct {
+	Version   int64 `json:"version"`   // версия профиля из истории изменений
+	Timestamp int64 `json:"timestamp"` // время (unix-время в секундах), используется, если версия не задана
+}
@@ -0,0 +1,43 @@
+package models
+
+// Структура данных для создания приглашения: приглашение создает профиль, ожидающий активации, с заданными правами,
+// приглашенный пользователь сам задает пароль по одноразовому токену
+type InvitationData struct {
+	Login     string   `json:"login"`     // логин профиля приглашенного пользователя (если не задан, логином становится адрес электронной почты)
+	Email     string   `json:"email"`     // адрес электронной почты приглашенного пользователя
+	Admin     bool     `json:"admin"`     // приглашенный пользователь становится администратором тенанта
+	Groups    []string `json:"groups"`    // группы, в которые входит профиль приглашенного пользователя
+	ExpiresAt int64    `json:"expiresAt"` // время истечения приглашения (unix-время в секундах, 0 - время жизни приглашения из конфига)
+}
+
+// Структура приглашения в БД
+type Invitation struct {
+	ID        string   `json:"id"`               // идентификатор приглашения (используется для отзыва приглашения, токен не хранится)
+	ProfileID string   `json:"profileId"`        // идентификатор профиля приглашенного пользователя
+	Login     string   `json:"login"`            // логин профиля приглашенного пользователя на момент создания приглашения
+	Email     string   `json:"email,omitempty"`  // адрес электронной почты приглашенного пользователя
+	Admin     bool     `json:"admin,omitempty"`  // приглашенный пользователь становится администратором тенанта
+	Groups    []string `json:"groups,omitempty"` // группы профиля приглашенного пользователя
+	CreatedBy string   `json:"createdBy"`        // логин пользователя, создавшего приглашение
+	CreatedAt int64    `json:"createdAt"`        // время создания приглашения (unix-время в секундах)
+	ExpiresAt int64    `json:"expiresAt"`        // время истечения приглашения (unix-время в секундах)
+}
+
+// Структура ответа на создание приглашения
+type InvitationResponse struct {
+	Invitation
+	Token      string `json:"token"`      // одноразовый токен приглашения (выдается только при создании приглашения)
+	AcceptPath string `json:"acceptPath"` // путь запроса для принятия приглашения
+}
+
+// Структура данных для принятия приглашения
+type AcceptInvitationData struct {
+	Password  string `json:"password"`            // пароль, задаваемый приглашенным пользователем (должен соответствовать политике паролей)
+	FirstName string `json:"firstName,omitempty"` // имя пользователя
+	LastName  string `json:"lastName,omitempty"`  // фамилия пользователя
+}
+
+// Структура данных для отзыва приглашения
+type InvitationIDData struct {
+	ID string `json:"id"` // идентификатор приглашения
+}
@@ -0,0 +1,51 @@
+package models
+
+// Структура данных клиента OAuth 2.0, содержащихся в БД, для хранения и вывода
+type OAuthClientData struct {
+	ClientID string   `json:"clientId"` // идентификатор клиента, является первичным ключом для таблицы клиентов, должен быть уникальным
+	Scopes   []string `json:"scopes"`   // список областей доступа (scopes), которые могут быть выданы клиенту
+}
+
+// Структура данных клиента OAuth 2.0, включающая секрет клиента, используется при регистрации клиента
+type FullOAuthClientData struct {
+	ClientID     string   `json:"clientId"`     // идентификатор клиента, является первичным ключом для таблицы клиентов, должен быть уникальным
+	ClientSecret string   `json:"clientSecret"` // секрет клиента (должен быть не длиннее 72 символов)
+	Scopes       []string `json:"scopes"`       // список областей доступа (scopes), которые могут быть выданы клиенту
+}
+
+// Структура данных, содержащая только идентификатор клиента OAuth 2.0
+type OAuthClientIDData struct {
+	ClientID string `json:"clientId"` // идентификатор клиента
+}
+
+// Структура данных выданного токена доступа, содержащихся в БД
+type OAuthTokenData struct {
+	ClientID  string `json:"clientId"`  // идентификатор клиента, которому выдан токен
+	Scope     string `json:"scope"`     // области доступа токена, разделенные пробелами
+	IssuedAt  int64  `json:"issuedAt"`  // время выдачи токена (unix time)
+	ExpiresAt int64  `json:"expiresAt"` // время истечения срока действия токена (unix time)
+}
+
+// Структура ответа на запрос выдачи токена (RFC 6749, раздел 5.1)
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`    // токен доступа
+	TokenType   string `json:"token_type"`      // тип токена, всегда "Bearer"
+	ExpiresIn   int64  `json:"expires_in"`      // время жизни токена в секундах
+	Scope       string `json:"scope,omitempty"` // области доступа токена, разделенные пробелами
+}
+
+// Структура ответа на запрос интроспекции токена (RFC 7662, раздел 2.2)
+type OAuthIntrospectionResponse struct {
+	Active    bool   `json:"active"`               // true, если токен действителен
+	Scope     string `json:"scope,omitempty"`      // области доступа токена, разделенные пробелами
+	ClientID  string `json:"client_id,omitempty"`  // идентификатор клиента, которому выдан токен
+	TokenType string `json:"token_type,omitempty"` // тип токена
+	Exp       int64  `json:"exp,omitempty"`        // время истечения срока действия токена (unix time)
+	Iat       int64  `json:"iat,omitempty"`        // время выдачи токена (unix time)
+}
+
+// Структура ответа с ошибкой OAuth 2.0 (RFC 6749, раздел 5.2)
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`                       // код ошибки
+	ErrorDescription string `json:"error_description,omitempty"` // описание ошибки
+}
@@ -2,26 +2,67 @@ package models
 
 // Структура данных профилей, содержащихся в БД, для хранения и вывода
 type ProfileData struct {
-	Login     string `json:"login"`     // логин профиля, используется при авторизации, является первичным ключом для БД, должен быть уникальным
-	FirstName string `json:"firstName"` // имя пользователя
-	LastName  string `json:"lastName"`  // фамилия пользователя
+	ID           string                 `json:"id,omitempty"`           // неизменяемый идентификатор профиля, генерируется при создании профиля, является первичным ключом для БД
+	Login        string                 `json:"login"`                  // логин профиля, используется при авторизации, должен быть уникальным в пределах тенанта, может быть изменен
+	FirstName    string                 `json:"firstName,omitempty"`    // имя пользователя (не выводится, если скрыто настройками видимости)
+	LastName     string                 `json:"lastName,omitempty"`     // фамилия пользователя (не выводится, если скрыто настройками видимости)
+	Email        string                 `json:"email,omitempty"`        // адрес электронной почты пользователя (не выводится, если скрыто настройками видимости)
+	VerifiedAt   int64                  `json:"verifiedAt,omitempty"`   // время подтверждения адреса электронной почты (unix-время в секундах, 0 - адрес не подтвержден), задается только БД
+	PendingEmail string                 `json:"pendingEmail,omitempty"` // новый адрес электронной почты, ожидающий подтверждения (выводится только владельцу профиля и администраторам), задается только БД
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`   // дополнительные атрибуты профиля, описанные в схеме атрибутов тенанта
 }
 
-// Структура данных, содержащая только логин
+// Структура данных, содержащая логин или идентификатор профиля
 type LoginData struct {
-	Login string `json:"login"` // логин профиля, используется при авторизации, является первичным ключом для БД, должен быть уникальным
+	Login string `json:"login"`        // логин профиля, используется при авторизации, должен быть уникальным в пределах тенанта
+	ID    string `json:"id,omitempty"` // идентификатор профиля (если задан, профиль ищется по идентификатору, а не по логину)
 }
 
 // Структура данных профилей, включающая все данные пользователя, включая пароль
 type FullProfileData struct {
-	Login     string `json:"login"`     // логин профиля, используется при авторизации, является первичным ключом для БД, должен быть уникальным
-	FirstName string `json:"firstName"` // имя пользователя
-	LastName  string `json:"lastName"`  // фамилия пользователя
-	Password  string `json:"password"`  // пароль для входа нового пользователя (должен быть не длиннее 72 символов)
+	Login      string                 `json:"login"`                // логин профиля, используется при авторизации, является первичным ключом для БД, должен быть уникальным
+	FirstName  string                 `json:"firstName"`            // имя пользователя
+	LastName   string                 `json:"lastName"`             // фамилия пользователя
+	Email      string                 `json:"email,omitempty"`      // адрес электронной почты пользователя
+	Attributes map[string]interface{} `json:"attributes,omitempty"` // дополнительные атрибуты профиля, описанные в схеме атрибутов тенанта
+	Password   string                 `json:"password"`             // пароль для входа нового пользователя (должен быть не длиннее 72 символов)
+	Status     string                 `json:"status,omitempty"`     // начальный статус учетной записи: pending (ожидает активации) или active (по-умолчанию)
 }
 
 // Структура данных, содержащая пару логин-пароль, используется для смены пароля пользователя
 type NewPasswordForProfile struct {
-	Login       string `json:"login"`       // логин профиля, используется при авторизации, является первичным ключом для БД, должен быть уникальным
-	NewPassword string `json:"newPassword"` // новый пароль для входа пользователя (должен быть не длиннее 72 символов)
+	Login       string `json:"login"`        // логин профиля, используется при авторизации, является первичным ключом для БД, должен быть уникальным
+	ID          string `json:"id,omitempty"` // идентификатор профиля (если задан, профиль ищется по идентификатору, а не по логину)
+	NewPassword string `json:"newPassword"`  // новый пароль для входа пользователя (должен быть не длиннее 72 символов)
+}
+
+// Структура данных для переименования профиля (изменения логина)
+type LoginRenameData struct {
+	Login    string `json:"login"`    // текущий логин профиля
+	NewLogin string `json:"newLogin"` // новый логин профиля (не должен совпадать с логинами и псевдонимами других профилей)
+}
+
+// Структура псевдонима логина: старый логин переименованного профиля, который указывает на профиль в течение льготного периода
+type LoginAlias struct {
+	ID        string `json:"id"`        // идентификатор профиля, на который указывает псевдоним
+	ExpiresAt int64  `json:"expiresAt"` // время истечения псевдонима (unix-время в секундах)
+}
+
+// Структура удаленного профиля в корзине: профиль не может авторизоваться и не выводится в списках до восстановления или окончательного удаления
+type DeletedProfileData struct {
+	Profile       ProfileData `json:"profile"`                 // данные удаленного профиля
+	DeletedAt     int64       `json:"deletedAt"`               // время удаления (unix-время в секундах)
+	Admin         bool        `json:"admin,omitempty"`         // профиль был администратором тенанта (права восстанавливаются вместе с профилем)
+	PlatformAdmin bool        `json:"platformAdmin,omitempty"` // профиль был администратором платформы
+	Groups        []string    `json:"groups,omitempty"`        // группы, в которые непосредственно входил профиль
+}
+
+// Структура данных, содержащая только идентификатор профиля
+type ProfileIDData struct {
+	ID string `json:"id"` // идентификатор профиля
+}
+
+// Структура данных, содержащая только название тенанта
+type TenantData struct {
+	Name string `json:"name"` // название тенанта (латинские строчные буквы, цифры и дефисы), является первичным ключом для таблицы тенантов, должно быть уникальным
 }
@@ -0,0 +1,23 @@
+package models
+
+// Структура данных для самостоятельной регистрации пользователя
+type RegistrationData struct {
+	Login      string                 `json:"login"`                // логин нового профиля (если не задан, логином становится адрес электронной почты)
+	Password   string                 `json:"password"`             // пароль нового пользователя (должен соответствовать политике паролей)
+	FirstName  string                 `json:"firstName"`            // имя пользователя
+	LastName   string                 `json:"lastName"`             // фамилия пользователя
+	Email      string                 `json:"email"`                // адрес электронной почты пользователя (обязателен, если требуется подтверждение адреса)
+	Attributes map[string]interface{} `json:"attributes,omitempty"` // дополнительные атрибуты профиля, описанные в схеме атрибутов тенанта
+}
+
+// Структура заявки на регистрацию в очереди: профиль ожидает активации, пока не подтвержден адрес электронной почты и (или) заявку не одобрил администратор
+type Registration struct {
+	ID                        string `json:"id"`                                  // идентификатор профиля зарегистрировавшегося пользователя
+	Login                     string `json:"login"`                               // логин профиля на момент регистрации
+	Email                     string `json:"email,omitempty"`                     // адрес электронной почты пользователя
+	RegisteredAt              int64  `json:"registeredAt"`                        // время регистрации (unix-время в секундах)
+	EmailVerificationRequired bool   `json:"emailVerificationRequired,omitempty"` // для активации требуется подтверждение адреса электронной почты
+	EmailVerified             bool   `json:"emailVerified,omitempty"`             // адрес электронной почты подтвержден
+	ApprovalRequired          bool   `json:"approvalRequired,omitempty"`          // для активации требуется одобрение администратора
+	Status                    string `json:"status"`                              // статус учетной записи после регистрации (pending или active)
+}
@@ -0,0 +1,47 @@
+package models
+
+import "encoding/json"
+
+// Роли экземпляра сервиса в репликации
+const (
+	ReplicationRoleLeader   = "leader"   // ведущий экземпляр: принимает изменения и отдает журнал изменений БД ведомым
+	ReplicationRoleFollower = "follower" // ведомый экземпляр: только читает данные, полученные от ведущего
+)
+
+// Структура снимка БД ведущего экземпляра для начальной загрузки ведомого экземпляра
+type ReplicationSnapshot struct {
+	Epoch string          `json:"epoch"`                     // эпоха журнала изменений БД (меняется при каждом запуске ведущего экземпляра)
+	Seq   int64           `json:"seq"`                       // номер последней записи журнала, вошедшей в снимок
+	Data  json.RawMessage `json:"data" swaggertype:"object"` // данные БД в формате файла базы данных (без шифрования)
+}
+
+// Структура записи журнала изменений БД: одна зафиксированная транзакция или другое изменение, сохраненное в файл базы данных
+type ReplicationEntry struct {
+	Seq               int64                      `json:"seq"`                                    // номер записи (номера идут подряд в пределах эпохи)
+	SchemaVersion     int                        `json:"schemaVersion"`                          // версия схемы данных тенантов
+	Full              bool                       `json:"full,omitempty"`                         // true - в записи все тенанты БД (тенанты, которых нет в записи, удалены)
+	Tenants           map[string]json.RawMessage `json:"tenants,omitempty" swaggertype:"object"` // данные измененных тенантов в формате файла базы данных (null - тенант удален)
+	PlatformAdminsTab []string                   `json:"platformAdminsTab"`                      // таблица админов платформы
+	ClientsDataTab    map[string]OAuthClientData `json:"clientsDataTab"`                         // таблица данных клиентов OAuth 2.0
+	ClientsSecretsTab map[string]string          `json:"clientsSecretsTab"`                      // таблица зашифрованных секретов клиентов OAuth 2.0
+	TokensTab         map[string]OAuthTokenData  `json:"tokensTab"`                              // таблица выданных токенов доступа
+}
+
+// Структура ответа на запрос журнала изменений БД
+type ReplicationLog struct {
+	Epoch   string             `json:"epoch"`   // эпоха журнала изменений БД
+	LastSeq int64              `json:"lastSeq"` // номер последней записи журнала ведущего экземпляра
+	Entries []ReplicationEntry `json:"entries"` // записи журнала с номерами больше запрошенного
+}
+
+// Структура состояния репликации (выводится запросом готовности экземпляра)
+type ReplicationStatus struct {
+	Role       string `json:"role"`                // роль экземпляра: leader или follower
+	Ready      bool   `json:"ready"`               // экземпляр готов обслуживать запросы (ведомый загрузил снимок и отстает от ведущего не больше допустимого)
+	Epoch      string `json:"epoch,omitempty"`     // эпоха журнала изменений БД
+	Seq        int64  `json:"seq"`                 // номер последней записи журнала (для ведомого - последней примененной записи)
+	LeaderSeq  int64  `json:"leaderSeq,omitempty"` // номер последней записи журнала ведущего экземпляра по последнему ответу ведущего
+	LagEntries int64  `json:"lagEntries"`          // отставание ведомого экземпляра в записях журнала
+	LagSeconds int64  `json:"lagSeconds"`          // отставание ведомого экземпляра в секундах (время с момента, когда ведомый в последний раз догнал ведущего)
+	LastError  string `json:"lastError,omitempty"` // ошибка последнего обращения к ведущему экземпляру
+}
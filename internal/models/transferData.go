@@ -0,0 +1,45 @@
+package models
+
+// Форматы файлов импорта и экспорта профилей
+const (
+	TransferFormatCSV   = "csv"   // CSV с заголовком, атрибуты передаются json-объектом в колонке attributes
+	TransferFormatJSONL = "jsonl" // JSON Lines: один json-объект профиля на строку
+)
+
+// Режимы импорта профилей
+const (
+	ImportModeCreate = "create" // создаются только новые профили, существующие логины считаются ошибкой
+	ImportModeUpsert = "upsert" // новые профили создаются, данные существующих заменяются
+)
+
+// Структура записи профиля в файлах импорта и экспорта
+type ProfileRecord struct {
+	ID           string                 `json:"id,omitempty"`           // идентификатор профиля (выводится при экспорте, при импорте не используется - профили ищутся по логину)
+	Login        string                 `json:"login"`                  // логин профиля
+	FirstName    string                 `json:"firstName,omitempty"`    // имя пользователя
+	LastName     string                 `json:"lastName,omitempty"`     // фамилия пользователя
+	Email        string                 `json:"email,omitempty"`        // адрес электронной почты пользователя
+	Status       string                 `json:"status,omitempty"`       // статус учетной записи (при импорте задается только для новых профилей: pending или active)
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`   // дополнительные атрибуты профиля
+	Password     string                 `json:"password,omitempty"`     // пароль (только при импорте, проверяется по политике паролей)
+	PasswordHash string                 `json:"passwordHash,omitempty"` // зашифрованный пароль bcrypt (при импорте - вместо пароля, при экспорте - только по запросу)
+	Line         int                    `json:"-"`                      // номер строки записи в файле импорта (для отчета об ошибках)
+}
+
+// Структура ошибки импорта записи профиля
+type ImportRowError struct {
+	Line  int    `json:"line"`            // номер строки записи в файле
+	Login string `json:"login,omitempty"` // логин профиля записи (если удалось прочитать)
+	Error string `json:"error"`           // описание ошибки
+}
+
+// Структура отчета об импорте профилей
+type ImportReport struct {
+	DryRun  bool             `json:"dryRun"`           // true - записи только проверены, профили не изменены
+	Mode    string           `json:"mode"`             // режим импорта
+	Total   int              `json:"total"`            // количество записей в файле
+	Created int              `json:"created"`          // количество созданных (при проверке - создаваемых) профилей
+	Updated int              `json:"updated"`          // количество измененных (при проверке - изменяемых) профилей
+	Failed  int              `json:"failed"`           // количество записей с ошибками (они не импортируются)
+	Errors  []ImportRowError `json:"errors,omitempty"` // ошибки записей
+}
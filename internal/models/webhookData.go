@@ -0,0 +1,49 @@
+package models
+
+// Тип тестового события, отправляемого на адрес вебхука по запросу администратора
+const WebhookEventTest = "webhook.test"
+
+// Статусы доставки события на адрес вебхука
+const (
+	WebhookDeliveryPending   = "pending"   // доставка ожидает очередной попытки
+	WebhookDeliveryDelivered = "delivered" // событие доставлено (адрес ответил статусом 2xx)
+	WebhookDeliveryFailed    = "failed"    // тестовое событие не доставлено (тестовые события не отправляются повторно)
+	WebhookDeliveryDead      = "dead"      // событие не доставлено за все попытки, доставка перенесена в список недоставленных событий
+)
+
+// Структура данных для регистрации вебхука
+type WebhookData struct {
+	URL    string   `json:"url"`    // адрес, на который отправляются события (http или https)
+	Events []string `json:"events"` // типы событий, отправляемых на адрес (пустой список - все события)
+}
+
+// Структура вебхука в БД
+type Webhook struct {
+	ID        string   `json:"id"`               // идентификатор вебхука
+	URL       string   `json:"url"`              // адрес, на который отправляются события
+	Events    []string `json:"events,omitempty"` // типы событий, отправляемых на адрес (пустой список - все события)
+	Secret    string   `json:"secret,omitempty"` // секрет подписи событий HMAC-SHA256 (выводится только при регистрации вебхука)
+	CreatedBy string   `json:"createdBy"`        // логин пользователя, зарегистрировавшего вебхук
+	CreatedAt int64    `json:"createdAt"`        // время регистрации вебхука (unix-время в секундах)
+}
+
+// Структура доставки события на адрес вебхука
+type WebhookDelivery struct {
+	ID             string      `json:"id"`                       // идентификатор доставки (не меняется при повторных попытках, по нему получатель отбрасывает повторы)
+	WebhookID      string      `json:"webhookId"`                // идентификатор вебхука
+	Event          ChangeEvent `json:"event"`                    // доставляемое событие
+	Status         string      `json:"status"`                   // статус доставки
+	Attempts       int         `json:"attempts"`                 // количество выполненных попыток доставки
+	ResponseStatus int         `json:"responseStatus,omitempty"` // статус ответа адреса на последнюю попытку (0 - ответ не получен)
+	Error          string      `json:"error,omitempty"`          // ошибка последней попытки доставки
+	CreatedAt      int64       `json:"createdAt"`                // время создания доставки (unix-время в секундах)
+	LastAttemptAt  int64       `json:"lastAttemptAt,omitempty"`  // время последней попытки доставки (unix-время в секундах)
+	NextAttemptAt  int64       `json:"nextAttemptAt,omitempty"`  // время следующей попытки доставки (unix-время в секундах, только для ожидающих доставок)
+}
+
+// Структура тела запроса, отправляемого на адрес вебхука
+type WebhookPayload struct {
+	DeliveryID string      `json:"deliveryId"` // идентификатор доставки
+	Tenant     string      `json:"tenant"`     // название тенанта, в котором произошло событие
+	Event      ChangeEvent `json:"event"`      // событие
+}
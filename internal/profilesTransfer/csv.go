@@ -0,0 +1,167 @@
+package profilesTransfer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Колонки CSV: в файле импорта должна быть колонка login, остальные колонки необязательны и могут идти в любом порядке
+var csvColumns = []string{"id", "login", "firstName", "lastName", "email", "status", "attributes", "password", "passwordHash"}
+
+/*
+Чтение записей профилей из CSV с заголовком
+
+:param r io.Reader: содержимое файла
+
+:return: прочитанные записи, ошибки чтения записей или ошибка, если заголовок не удалось прочитать или он содержит неизвестные колонки
+*/
+func readCSV(r io.Reader) ([]models.ProfileRecord, []models.ImportRowError, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+	hasLogin := false
+	for _, column := range header {
+		if !isCSVColumn(column) {
+			return nil, nil, fmt.Errorf("%w \"%s\"", unknownColumnErr, column)
+		}
+		hasLogin = hasLogin || column == "login"
+	}
+	if !hasLogin {
+		return nil, nil, noLoginColumnErr
+	}
+
+	var records []models.ProfileRecord
+	var readErrors []models.ImportRowError
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			readErrors = append(readErrors, models.ImportRowError{Line: parseErr.StartLine, Error: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		// Заполнение записи по колонкам заголовка
+		record := models.ProfileRecord{Line: line}
+		validAttributes := true // атрибуты не заданы или заданы json-объектом
+		for i, column := range header {
+			value := row[i]
+			switch column {
+			case "login":
+				record.Login = value
+			case "firstName":
+				record.FirstName = value
+			case "lastName":
+				record.LastName = value
+			case "email":
+				record.Email = value
+			case "status":
+				record.Status = value
+			case "password":
+				record.Password = value
+			case "passwordHash":
+				record.PasswordHash = value
+			case "attributes":
+				if value != "" {
+					err = json.Unmarshal([]byte(value), &record.Attributes)
+					validAttributes = err == nil && record.Attributes != nil
+				}
+			}
+		}
+		if !validAttributes {
+			readErrors = append(readErrors, models.ImportRowError{Line: line, Login: record.Login, Error: invalidAttributesErr.Error()})
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, readErrors, nil
+}
+
+/*
+Проверка, является ли название колонкой CSV
+
+:param column string: название колонки
+
+:return: true, если колонка известна
+*/
+func isCSVColumn(column string) bool {
+	for _, known := range csvColumns {
+		if column == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Запись профилей в CSV
+type csvWriter struct {
+	writer             *csv.Writer // запись CSV
+	withPasswordHashes bool        // в файл записываются зашифрованные пароли
+}
+
+/*
+Создание записи профилей в CSV: сразу записывается заголовок
+
+:param w io.Writer: место записи файла
+:param withPasswordHashes bool: true - в файл записываются зашифрованные пароли (колонка passwordHash)
+
+:return: запись профилей или ошибка, если заголовок не удалось записать
+*/
+func newCSVWriter(w io.Writer, withPasswordHashes bool) (*csvWriter, error) {
+	cw := csvWriter{writer: csv.NewWriter(w), withPasswordHashes: withPasswordHashes}
+	header := []string{"id", "login", "firstName", "lastName", "email", "status", "attributes"}
+	if withPasswordHashes {
+		header = append(header, "passwordHash")
+	}
+	err := cw.writer.Write(header)
+	if err != nil {
+		return nil, err
+	}
+	return &cw, nil
+}
+
+/*
+Запись профиля в CSV (атрибуты записываются json-объектом)
+
+:param record models.ProfileRecord: запись профиля
+
+:return: ошибка, если строку не удалось записать
+*/
+func (cw *csvWriter) Write(record models.ProfileRecord) error {
+	var attributes string
+	if len(record.Attributes) != 0 {
+		attributesJSON, err := json.Marshal(record.Attributes)
+		if err != nil {
+			return err
+		}
+		attributes = string(attributesJSON)
+	}
+	row := []string{record.ID, record.Login, record.FirstName, record.LastName, record.Email, record.Status, attributes}
+	if cw.withPasswordHashes {
+		row = append(row, record.PasswordHash)
+	}
+	return cw.writer.Write(row)
+}
+
+/*
+Завершение записи CSV
+
+:return: ошибка, если буферизованные строки не удалось записать
+*/
+func (cw *csvWriter) Flush() error {
+	cw.writer.Flush()
+	return cw.writer.Error()
+}
@@ -0,0 +1,10 @@
+package profilesTransfer
+
+import "errors"
+
+// Ошибки импорта и экспорта профилей
+var UnknownFormatErr error = errors.New("unknown profiles file format")
+var InvalidFileErr error = errors.New("invalid profiles file")
+var unknownColumnErr error = errors.New("unknown column in csv header")
+var noLoginColumnErr error = errors.New("csv header has no login column")
+var invalidAttributesErr error = errors.New("attributes must be a json object")
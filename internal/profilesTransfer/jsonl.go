@@ -0,0 +1,84 @@
+package profilesTransfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Максимальная длина строки JSON Lines в байтах
+const maxJSONLLineBytes = 1 << 20
+
+/*
+Чтение записей профилей из JSON Lines (пустые строки пропускаются, неизвестные поля считаются ошибкой записи)
+
+:param r io.Reader: содержимое файла
+
+:return: прочитанные записи, ошибки чтения записей или ошибка, если файл не удалось прочитать (например, слишком длинная строка)
+*/
+func readJSONL(r io.Reader) ([]models.ProfileRecord, []models.ImportRowError, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLLineBytes)
+
+	var records []models.ProfileRecord
+	var readErrors []models.ImportRowError
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		decoder.DisallowUnknownFields()
+		record := models.ProfileRecord{Line: line}
+		err := decoder.Decode(&record)
+		if err != nil {
+			readErrors = append(readErrors, models.ImportRowError{Line: line, Login: record.Login, Error: err.Error()})
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return records, readErrors, nil
+}
+
+// Запись профилей в JSON Lines
+type jsonlWriter struct {
+	encoder *json.Encoder // запись json-объектов (каждый объект завершается переводом строки)
+}
+
+/*
+Создание записи профилей в JSON Lines
+
+:param w io.Writer: место записи файла
+
+:return: запись профилей
+*/
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	return &jsonlWriter{encoder: json.NewEncoder(w)}
+}
+
+/*
+Запись профиля в JSON Lines
+
+:param record models.ProfileRecord: запись профиля
+
+:return: ошибка, если строку не удалось записать
+*/
+func (jw *jsonlWriter) Write(record models.ProfileRecord) error {
+	return jw.encoder.Encode(record)
+}
+
+/*
+Завершение записи JSON Lines (записи не буферизуются)
+
+:return: всегда nil
+*/
+func (jw *jsonlWriter) Flush() error {
+	return nil
+}
@@ -0,0 +1,135 @@
+package profilesTransfer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Запись профилей в файл экспорта
+type Writer interface {
+	Write(record models.ProfileRecord) error // запись профиля
+	Flush() error                            // завершение записи (дописывание буферизованных данных)
+}
+
+/*
+Проверка формата файла профилей
+
+:param format string: формат файла (csv или jsonl)
+
+:return: ошибка UnknownFormatErr, если формат неизвестен
+*/
+func CheckFormat(format string) error {
+	switch format {
+	case models.TransferFormatCSV, models.TransferFormatJSONL:
+		return nil
+	}
+	return fmt.Errorf("%w \"%s\"", UnknownFormatErr, format)
+}
+
+/*
+Получение типа содержимого файла профилей
+
+:param format string: формат файла (csv или jsonl)
+
+:return: тип содержимого (MIME)
+*/
+func ContentType(format string) string {
+	if format == models.TransferFormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+/*
+Чтение записей профилей из файла импорта: записи, которые не удалось прочитать, попадают в список ошибок, остальные записи читаются дальше
+
+:param r io.Reader: содержимое файла
+:param format string: формат файла (csv или jsonl)
+
+:return: прочитанные записи, ошибки чтения записей или ошибка, если формат неизвестен или файл не удалось прочитать (например, некорректный заголовок CSV)
+*/
+func ReadRecords(r io.Reader, format string) ([]models.ProfileRecord, []models.ImportRowError, error) {
+	switch format {
+	case models.TransferFormatCSV:
+		return readCSV(r)
+	case models.TransferFormatJSONL:
+		return readJSONL(r)
+	}
+	return nil, nil, fmt.Errorf("%w \"%s\"", UnknownFormatErr, format)
+}
+
+/*
+Создание записи профилей в файл экспорта
+
+:param w io.Writer: место записи файла
+:param format string: формат файла (csv или jsonl)
+:param withPasswordHashes bool: true - в файл записываются зашифрованные пароли
+
+:return: запись профилей или ошибка, если формат неизвестен или заголовок CSV не удалось записать
+*/
+func NewWriter(w io.Writer, format string, withPasswordHashes bool) (Writer, error) {
+	switch format {
+	case models.TransferFormatCSV:
+		return newCSVWriter(w, withPasswordHashes)
+	case models.TransferFormatJSONL:
+		return newJSONLWriter(w), nil
+	}
+	return nil, fmt.Errorf("%w \"%s\"", UnknownFormatErr, format)
+}
+
+/*
+Импорт профилей из файла в тенант: ошибки чтения записей добавляются в отчет об импорте
+
+:param t *myProfilesDB.Tenant: тенант, в который импортируются профили
+:param r io.Reader: содержимое файла
+:param format string: формат файла (csv или jsonl)
+:param mode string: режим импорта (create или upsert)
+:param dryRun bool: true - записи только проверяются
+:param checkPassword func(string) error: проверка паролей по политике паролей (nil - пароли не проверяются)
+:param author string: логин пользователя, импортирующего профили
+
+:return: отчет об импорте (ошибки записей отсортированы по номерам строк) или ошибка InvalidFileErr, если файл не удалось прочитать; ошибка, если режим импорта неизвестен или базу данных не удалось сохранить
+*/
+func Import(t *myProfilesDB.Tenant, r io.Reader, format string, mode string, dryRun bool, checkPassword func(string) error, author string) (models.ImportReport, error) {
+	records, readErrors, err := ReadRecords(r, format)
+	if err != nil {
+		return models.ImportReport{}, fmt.Errorf("%w: %s", InvalidFileErr, err.Error())
+	}
+	report, err := t.ImportProfiles(records, mode, dryRun, checkPassword, author)
+	if err != nil {
+		return report, err
+	}
+	report.Total += len(readErrors)
+	report.Failed += len(readErrors)
+	report.Errors = append(readErrors, report.Errors...)
+	sort.SliceStable(report.Errors, func(i, j int) bool { return report.Errors[i].Line < report.Errors[j].Line })
+	return report, nil
+}
+
+/*
+Экспорт профилей тенанта в файл (записи выводятся по мере форматирования)
+
+:param t *myProfilesDB.Tenant: тенант, профили которого экспортируются
+:param w io.Writer: место записи файла
+:param format string: формат файла (csv или jsonl)
+:param withPasswordHashes bool: true - в файл записываются зашифрованные пароли
+
+:return: ошибка, если формат неизвестен или файл не удалось записать
+*/
+func Export(t *myProfilesDB.Tenant, w io.Writer, format string, withPasswordHashes bool) error {
+	writer, err := NewWriter(w, format, withPasswordHashes)
+	if err != nil {
+		return err
+	}
+	for _, record := range t.ExportProfiles(withPasswordHashes) {
+		err = writer.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
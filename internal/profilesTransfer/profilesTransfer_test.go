@@ -0,0 +1,279 @@
+package profilesTransfer
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+	"github.com/ZotovSergey/authenticationservice/internal/testutil"
+)
+
+// Тесты выполняются из временного каталога с конфигами репозитория
+func TestMain(m *testing.M) {
+	os.Exit(testutil.RunWithConfigs(m, "../../configs", nil, nil))
+}
+
+// Ошибка политики паролей тестов (политика паролей API проверяется тестами cmd/dbtool)
+var testWeakPasswordErr error = errors.New("weak password")
+
+/*
+Проверка пароля по политике паролей тестов
+
+:param password string: пароль
+
+:return: ошибка testWeakPasswordErr, если пароль короче 8 символов
+*/
+func testPasswordPolicy(password string) error {
+	if len(password) < 8 {
+		return testWeakPasswordErr
+	}
+	return nil
+}
+
+/*
+Поднятие БД с временным файлом базы данных и атрибутом department в схеме атрибутов тенанта платформы
+
+:param t *testing.T: тест
+
+:return: тенант платформы и путь к файлу базы данных
+*/
+func openTestTenant(t *testing.T) (*myProfilesDB.Tenant, string) {
+	t.Helper()
+	dumpPath := filepath.Join(t.TempDir(), "db.json")
+	db, err := myProfilesDB.OpenMyProfilesDB(dumpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	platform, err := db.GetTenant(db.PlatformTenant())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = platform.SetAttributeDefinition(models.AttributeDefinition{Name: "department", Type: "string"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return platform, dumpPath
+}
+
+/*
+Импорт файла профилей в тенант
+
+:param t *testing.T: тест
+:param tenant *myProfilesDB.Tenant: тенант
+:param file string: содержимое файла
+:param format string: формат файла
+:param mode string: режим импорта
+:param dryRun bool: true - записи только проверяются
+
+:return: отчет об импорте
+*/
+func importTestFile(t *testing.T, tenant *myProfilesDB.Tenant, file string, format string, mode string, dryRun bool) models.ImportReport {
+	t.Helper()
+	report, err := Import(tenant, strings.NewReader(file), format, mode, dryRun, testPasswordPolicy, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+/*
+Экспорт профилей тенанта в файл
+
+:param t *testing.T: тест
+:param tenant *myProfilesDB.Tenant: тенант
+:param format string: формат файла
+:param withPasswordHashes bool: true - в файл записываются зашифрованные пароли
+
+:return: содержимое файла
+*/
+func exportTestFile(t *testing.T, tenant *myProfilesDB.Tenant, format string, withPasswordHashes bool) string {
+	t.Helper()
+	var file bytes.Buffer
+	err := Export(tenant, &file, format, withPasswordHashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file.String()
+}
+
+/*
+Записи профилей тенанта без идентификаторов (идентификаторы профилей при импорте не переносятся)
+
+:param tenant *myProfilesDB.Tenant: тенант
+
+:return: записи профилей с зашифрованными паролями
+*/
+func recordsWithoutIDs(tenant *myProfilesDB.Tenant) []models.ProfileRecord {
+	records := tenant.ExportProfiles(true)
+	for i := range records {
+		records[i].ID = ""
+	}
+	return records
+}
+
+// Профили, экспортированные вместе с паролями, импортируются в другую БД без изменений
+func TestExportImportRoundTrip(t *testing.T) {
+	for _, format := range []string{models.TransferFormatCSV, models.TransferFormatJSONL} {
+		t.Run(format, func(t *testing.T) {
+			source, _ := openTestTenant(t)
+			file := "login,firstName,lastName,email,status,attributes,password\n" +
+				"alice,Alice,Smith,alice@example.com,active,\"{\"\"department\"\":\"\"sales\"\"}\",Passw0rd!a\n" +
+				"bob,Bob,\"Jones, Jr\",,pending,,\n"
+			report := importTestFile(t, source, file, models.TransferFormatCSV, models.ImportModeCreate, false)
+			if report.Created != 2 || report.Failed != 0 {
+				t.Fatalf("unexpected import report: %+v", report)
+			}
+
+			// Администратор есть в обеих БД, поэтому профили импортируются в режиме upsert
+			target, _ := openTestTenant(t)
+			report = importTestFile(t, target, exportTestFile(t, source, format, true), format, models.ImportModeUpsert, false)
+			if report.Total != 3 || report.Created != 2 || report.Updated != 1 || report.Failed != 0 {
+				t.Fatalf("unexpected import report: %+v", report)
+			}
+			sourceRecords, targetRecords := recordsWithoutIDs(source), recordsWithoutIDs(target)
+			if !reflect.DeepEqual(targetRecords, sourceRecords) {
+				t.Fatalf("imported profiles differ from exported:\nexported: %+v\nimported: %+v", sourceRecords, targetRecords)
+			}
+		})
+	}
+}
+
+// По умолчанию зашифрованные пароли не экспортируются
+func TestExportOmitsPasswordHashes(t *testing.T) {
+	tenant, _ := openTestTenant(t)
+	passwordHash, err := tenant.GetPasswordHashSalt("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []string{models.TransferFormatCSV, models.TransferFormatJSONL} {
+		file := exportTestFile(t, tenant, format, false)
+		if !strings.Contains(file, "admin") || strings.Contains(file, "passwordHash") || strings.Contains(file, passwordHash) {
+			t.Fatalf("%s export without password hashes: %s", format, file)
+		}
+		if file = exportTestFile(t, tenant, format, true); !strings.Contains(file, passwordHash) {
+			t.Fatalf("%s export with password hashes has no admin password hash: %s", format, file)
+		}
+	}
+}
+
+// Пробный импорт проверяет записи, но не меняет профили и файл базы данных
+func TestImportDryRunLeavesStoreUnchanged(t *testing.T) {
+	tenant, dumpPath := openTestTenant(t)
+	before := recordsWithoutIDs(tenant)
+	dumpBefore, err := ioutil.ReadFile(dumpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := `{"login":"alice","firstName":"Alice","password":"Passw0rd!a"}
+{"login":"admin","lastName":"Root","attributes":{"department":"it"}}
+{"login":"carol","password":"short"}
+`
+	report := importTestFile(t, tenant, file, models.TransferFormatJSONL, models.ImportModeUpsert, true)
+	expected := models.ImportReport{DryRun: true, Mode: models.ImportModeUpsert, Total: 3, Created: 1, Updated: 1, Failed: 1,
+		Errors: []models.ImportRowError{{Line: 3, Login: "carol", Error: testWeakPasswordErr.Error()}}}
+	if !reflect.DeepEqual(report, expected) {
+		t.Fatalf("unexpected dry run report:\nexpected: %+v\ngot:      %+v", expected, report)
+	}
+
+	if after := recordsWithoutIDs(tenant); !reflect.DeepEqual(after, before) {
+		t.Fatalf("profiles are changed by dry run:\nbefore: %+v\nafter:  %+v", before, after)
+	}
+	dumpAfter, err := ioutil.ReadFile(dumpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dumpAfter, dumpBefore) {
+		t.Fatal("database dump is rewritten by dry run")
+	}
+}
+
+// Записи с ошибками попадают в отчет с номерами строк файла, остальные записи импортируются; режим create не меняет существующие профили, режим upsert меняет
+func TestImportRowErrors(t *testing.T) {
+	tests := []struct {
+		format string
+		file   string
+		errors []models.ImportRowError
+	}{
+		{
+			format: models.TransferFormatCSV,
+			file: "login,firstName,attributes,password,passwordHash\n" +
+				"alice,Alice,,Passw0rd!a,\n" +
+				"bob,Bob,,short,\n" +
+				"alice,Alice,,,\n" +
+				"carol,Carol,[1],,\n" +
+				"dave,Dave,,Passw0rd!d,$2a$10$notahash\n" +
+				"erin,Erin,\"{\"\"team\"\":\"\"x\"\"}\",,\n" +
+				"admin,Root,,,\n" +
+				",Nobody,,,\n",
+			errors: []models.ImportRowError{
+				{Line: 3, Login: "bob", Error: testWeakPasswordErr.Error()},
+				{Line: 4, Login: "alice", Error: "login is repeated in import"},
+				{Line: 5, Login: "carol", Error: invalidAttributesErr.Error()},
+				{Line: 6, Login: "dave", Error: "only one of password and password hash can be set"},
+				{Line: 7, Login: "erin", Error: "unknown attribute \"team\""},
+				{Line: 8, Login: "admin", Error: "such profile is already exists"},
+				{Line: 9, Error: "login must not be empty"},
+			},
+		},
+		{
+			format: models.TransferFormatJSONL,
+			file: `{"login":"alice","firstName":"Alice","password":"Passw0rd!a"}
+{"login":"bob","password":"short"}
+
+{"login":"carol","role":"admin"}
+{"login":"dave","passwordHash":"$2a$10$notahash"}
+not json
+{"login":"admin","firstName":"Root"}
+`,
+			errors: []models.ImportRowError{
+				{Line: 2, Login: "bob", Error: testWeakPasswordErr.Error()},
+				{Line: 4, Login: "carol", Error: "json: unknown field \"role\""},
+				{Line: 5, Login: "dave", Error: "password hash is not a bcrypt hash"},
+				{Line: 6, Error: "invalid character 'o' in literal null (expecting 'u')"},
+				{Line: 7, Login: "admin", Error: "such profile is already exists"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			tenant, _ := openTestTenant(t)
+			report := importTestFile(t, tenant, test.file, test.format, models.ImportModeCreate, false)
+			if report.Created != 1 || report.Updated != 0 || report.Failed != len(test.errors) || report.Total != len(test.errors)+1 {
+				t.Fatalf("unexpected import report: %+v", report)
+			}
+			if len(report.Errors) != len(test.errors) {
+				t.Fatalf("unexpected import errors: %+v", report.Errors)
+			}
+			for i, expected := range test.errors {
+				got := report.Errors[i]
+				if got.Line != expected.Line || got.Login != expected.Login || !strings.Contains(got.Error, expected.Error) {
+					t.Fatalf("import error %d:\nexpected: %+v\ngot:      %+v", i, expected, got)
+				}
+			}
+			if _, _, err := tenant.GetProfileData("alice"); err != nil {
+				t.Fatalf("valid record is not imported: %s", err.Error())
+			}
+			if profileData, _, _ := tenant.GetProfileData("admin"); profileData.FirstName == "Root" {
+				t.Fatal("existing profile is changed in create mode")
+			}
+
+			// В режиме upsert данные существующего профиля заменяются, пустые поля записи не меняются
+			report = importTestFile(t, tenant, "login,firstName\nadmin,Root\n", models.TransferFormatCSV, models.ImportModeUpsert, false)
+			if report.Updated != 1 || report.Failed != 0 {
+				t.Fatalf("unexpected upsert report: %+v", report)
+			}
+			profileData, _, err := tenant.GetProfileData("admin")
+			if err != nil || profileData.FirstName != "Root" || profileData.LastName != "admin" {
+				t.Fatalf("existing profile is not updated in upsert mode: %+v %v", profileData, err)
+			}
+		})
+	}
+}
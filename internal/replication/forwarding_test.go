@@ -0,0 +1,60 @@
+package replication_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ZotovSergey/authenticationservice/internal/replication"
+	"github.com/ZotovSergey/authenticationservice/internal/rest/handlers"
+	"github.com/ZotovSergey/authenticationservice/internal/testutil"
+)
+
+// Ведомый экземпляр до загрузки снимка отклоняет запросы, после загрузки обслуживает чтение локально и перенаправляет изменяющие запросы ведущему
+func TestWriteForwarding(t *testing.T) {
+	leader := replication.StartTestLeader(t, true)
+
+	// До загрузки снимка запросы отклоняются, кроме проверки готовности
+	if status, _ := testutil.GuardedRequest(t, handlers.FollowerGuard, http.MethodGet, "/logins", nil); status != http.StatusServiceUnavailable {
+		t.Fatalf("request is served before snapshot is loaded: %d", status)
+	}
+	if status, _ := testutil.GuardedRequest(t, handlers.FollowerGuard, http.MethodGet, "/ready", nil); status != http.StatusOK {
+		t.Fatalf("readiness request is rejected: %d", status)
+	}
+	if err := replication.Replicate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Чтение обслуживается локально, изменяющий запрос перенаправляется ведущему с тенантом из заголовка в пути
+	if status, body := testutil.GuardedRequest(t, handlers.FollowerGuard, http.MethodGet, "/logins", nil); status != http.StatusOK || body != "local" {
+		t.Fatalf("read is not served locally: %d %s", status, body)
+	}
+	status, body := testutil.GuardedRequest(t, handlers.FollowerGuard, http.MethodPost, "/profile?x=1", map[string]string{"X-Tenant": "acme"})
+	if status != http.StatusOK || body != "leader" {
+		t.Fatalf("write is not forwarded to leader: %d %s", status, body)
+	}
+	forwarded := leader.Forwarded()
+	if len(forwarded) != 1 || forwarded[0].Method != http.MethodPost || forwarded[0].URL.String() != "/tenants/acme/profile?x=1" || forwarded[0].Header.Get("X-Forwarded-Write") == "" {
+		t.Fatalf("unexpected forwarded requests %v", forwarded)
+	}
+	// Запрос, уже перенаправленный другим экземпляром, повторно не перенаправляется
+	if status, _ := testutil.GuardedRequest(t, handlers.FollowerGuard, http.MethodPost, "/profile", map[string]string{"X-Forwarded-Write": "1"}); status != http.StatusServiceUnavailable {
+		t.Fatalf("forwarded write is forwarded again: %d", status)
+	}
+}
+
+// Если перенаправление выключено, изменяющие запросы к ведомому экземпляру отклоняются
+func TestWriteRejection(t *testing.T) {
+	leader := replication.StartTestLeader(t, false)
+	if err := replication.Replicate(); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := testutil.GuardedRequest(t, handlers.FollowerGuard, http.MethodPost, "/profile", nil); status != http.StatusServiceUnavailable {
+		t.Fatalf("write is accepted by follower without forwarding: %d", status)
+	}
+	if status, body := testutil.GuardedRequest(t, handlers.FollowerGuard, http.MethodGet, "/logins", nil); status != http.StatusOK || body != "local" {
+		t.Fatalf("read is not served locally: %d %s", status, body)
+	}
+	if len(leader.Forwarded()) != 0 {
+		t.Fatal("write is forwarded to leader")
+	}
+}
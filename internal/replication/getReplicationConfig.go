@@ -0,0 +1,53 @@
+package replication
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Структура конфигурации репликации
+type replicationConfig struct {
+	Role          string `json:"role"`          // роль экземпляра: leader - ведущий, follower - ведомый (только чтение)
+	LeaderURL     string `json:"leaderUrl"`     // адрес API ведущего экземпляра (для ведомого экземпляра)
+	Username      string `json:"username"`      // логин администратора платформы ведущего экземпляра, которым читается журнал изменений
+	Password      string `json:"password"`      // пароль администратора платформы ведущего экземпляра
+	ForwardWrites bool   `json:"forwardWrites"` // true - изменяющие запросы к ведомому экземпляру перенаправляются ведущему, false - отклоняются
+	PollTimeout   int64  `json:"pollTimeout"`   // наибольшее время ожидания новых записей журнала изменений в одном запросе в секундах
+	RetryInterval int64  `json:"retryInterval"` // задержка перед повтором после ошибки запроса к ведущему экземпляру в секундах
+	MaxLag        int64  `json:"maxLag"`        // наибольшее отставание от ведущего экземпляра в секундах, при котором ведомый экземпляр готов обслуживать запросы
+}
+
+/*
+Чтение конфигурации репликации из конфига "../../configs/replicationConfig.json"
+
+:return: конфигурация репликации или ошибка, если конфиг не удалось прочитать, роль неизвестна, для ведомого экземпляра не задан адрес ведущего
+или значения времени не положительны
+*/
+func getReplicationConfig() (replicationConfig, error) {
+	configFilePath := "../../configs/replicationConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return replicationConfig{}, errors.New("fail to read replication config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var config replicationConfig
+	json.Unmarshal(byteValue, &config)
+	if config.Role != models.ReplicationRoleLeader && config.Role != models.ReplicationRoleFollower {
+		return replicationConfig{}, errors.New("role must be leader or follower in replication config " + configFilePath)
+	}
+	if config.Role == models.ReplicationRoleFollower && config.LeaderURL == "" {
+		return replicationConfig{}, errors.New("leader url is required for follower in replication config " + configFilePath)
+	}
+	if config.PollTimeout <= 0 || config.RetryInterval <= 0 || config.MaxLag <= 0 {
+		return replicationConfig{}, errors.New("poll timeout, retry interval and max lag must be positive in replication config " + configFilePath)
+	}
+	config.LeaderURL = strings.TrimSuffix(config.LeaderURL, "/")
+
+	return config, nil
+}
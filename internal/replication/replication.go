@@ -0,0 +1,266 @@
+package replication
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Конфигурация репликации (заменяется конфигом функцией Configure; по умолчанию экземпляр является ведущим)
+var config = replicationConfig{Role: models.ReplicationRoleLeader, PollTimeout: 30, RetryInterval: 5, MaxLag: 60}
+
+// HTTP-клиент, которым ведомый экземпляр обращается к ведущему (время ожидания ответа больше времени ожидания записей журнала)
+var Client = &http.Client{Timeout: time.Duration(config.PollTimeout+10) * time.Second}
+
+// Ошибка ответа ведущего экземпляра: журнал изменений после последней примененной записи недоступен, нужно заново загрузить снимок
+var logTruncatedErr error = errors.New("leader replication log is truncated or leader was restarted")
+
+// Состояние ведомого экземпляра
+var state struct {
+	mu         sync.Mutex
+	leaderSeq  int64     // номер последней записи журнала ведущего экземпляра по последнему ответу ведущего
+	caughtUpAt time.Time // время, когда ведомый экземпляр в последний раз догнал ведущего
+	waiting    bool      // ведомый экземпляр догнал ведущего и ожидает новых записей журнала
+	lastError  string    // ошибка последнего обращения к ведущему экземпляру
+}
+
+/*
+Чтение конфигурации репликации из конфига "../../configs/replicationConfig.json"
+
+:return: ошибка, если конфиг не удалось прочитать
+*/
+func Configure() error {
+	var err error
+	config, err = getReplicationConfig()
+	if err != nil {
+		return err
+	}
+	Client.Timeout = time.Duration(config.PollTimeout+10) * time.Second
+	if IsFollower() {
+		log.Printf("replication configured: follower of \"%s\", writes are forwarded: %t", config.LeaderURL, config.ForwardWrites)
+	} else {
+		log.Println("replication configured: leader")
+	}
+	return nil
+}
+
+/*
+Проверка, является ли экземпляр ведомым
+
+:return: true - если экземпляр ведомый (только чтение), иначе - false
+*/
+func IsFollower() bool {
+	return config.Role == models.ReplicationRoleFollower
+}
+
+/*
+Получить адрес API ведущего экземпляра
+
+:return: адрес API ведущего экземпляра без завершающего "/"
+*/
+func LeaderURL() string {
+	return config.LeaderURL
+}
+
+/*
+Проверка, перенаправляются ли изменяющие запросы к ведомому экземпляру ведущему
+
+:return: true - если запросы перенаправляются, false - если отклоняются
+*/
+func ForwardWrites() bool {
+	return config.ForwardWrites
+}
+
+/*
+Проверка, загрузил ли ведомый экземпляр снимок БД ведущего (ведущий экземпляр всегда готов)
+
+:return: true - если данные БД можно читать, иначе - false
+*/
+func Bootstrapped() bool {
+	epoch, _ := myProfilesDB.DB.ReplicationPosition()
+	return !IsFollower() || epoch != ""
+}
+
+/*
+Получить состояние репликации
+
+:return: роль экземпляра, позиция в журнале изменений БД, отставание ведомого экземпляра от ведущего и готовность экземпляра обслуживать запросы
+*/
+func Status() models.ReplicationStatus {
+	epoch, seq := myProfilesDB.DB.ReplicationPosition()
+	status := models.ReplicationStatus{Role: config.Role, Ready: true, Epoch: epoch, Seq: seq}
+	if !IsFollower() {
+		return status
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	status.LeaderSeq = state.leaderSeq
+	status.LastError = state.lastError
+	if status.LagEntries = state.leaderSeq - seq; status.LagEntries < 0 {
+		status.LagEntries = 0
+	}
+	if !state.waiting && !state.caughtUpAt.IsZero() {
+		status.LagSeconds = int64(time.Since(state.caughtUpAt).Seconds())
+	}
+	status.Ready = epoch != "" && status.LagSeconds <= config.MaxLag
+	return status
+}
+
+/*
+Запуск репликации ведомого экземпляра: загрузка снимка БД ведущего, затем получение и применение записей журнала изменений ведущего долгими запросами;
+после ошибки запрос повторяется через retryInterval, если журнал ведущего недоступен (ведущий перезапущен или записи удалены), снимок загружается заново
+*/
+func Run() {
+	for {
+		err := replicate()
+		if err != nil {
+			log.Printf("replication error: %s", err.Error())
+			setError(err)
+			time.Sleep(time.Duration(config.RetryInterval) * time.Second)
+		}
+	}
+}
+
+/*
+Шаг репликации: загрузка снимка БД ведущего, если он еще не загружен, иначе получение и применение записей журнала изменений ведущего;
+если журнал ведущего недоступен, снимок загружается заново
+
+:return: ошибка запроса к ведущему экземпляру, применения снимка или записи журнала
+*/
+func replicate() error {
+	if !Bootstrapped() {
+		return bootstrap()
+	}
+	err := pollLog()
+	if errors.Is(err, logTruncatedErr) {
+		log.Printf("replication: %s, reloading snapshot", err.Error())
+		return bootstrap()
+	}
+	return err
+}
+
+/*
+Загрузка снимка БД ведущего экземпляра
+
+:return: ошибка, если снимок не удалось получить или применить
+*/
+func bootstrap() error {
+	var snapshot models.ReplicationSnapshot
+	err := getFromLeader("/v1/replication/snapshot", &snapshot)
+	if err != nil {
+		return err
+	}
+	err = myProfilesDB.DB.ApplyReplicationSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	log.Printf("replication: snapshot loaded (epoch %s, seq %d)", snapshot.Epoch, snapshot.Seq)
+	setPosition(snapshot.Seq, snapshot.Seq)
+	return nil
+}
+
+/*
+Получение и применение записей журнала изменений ведущего экземпляра после последней примененной записи (запрос ждет новых записей не дольше pollTimeout)
+
+:return: ошибка запроса к ведущему экземпляру или применения записи (logTruncatedErr - нужно заново загрузить снимок)
+*/
+func pollLog() error {
+	epoch, seq := myProfilesDB.DB.ReplicationPosition()
+
+	// Ожидание новых записей журнала
+	query := url.Values{}
+	query.Set("epoch", epoch)
+	query.Set("since", strconv.FormatInt(seq, 10))
+	query.Set("wait", strconv.FormatInt(config.PollTimeout, 10))
+	var replicationLog models.ReplicationLog
+	err := getFromLeader("/v1/replication/log?"+query.Encode(), &replicationLog)
+	if err != nil {
+		return err
+	}
+
+	// Применение записей
+	for _, entry := range replicationLog.Entries {
+		err = myProfilesDB.DB.ApplyReplicationEntry(replicationLog.Epoch, entry)
+		if err != nil {
+			return err
+		}
+		seq = entry.Seq
+	}
+	setPosition(seq, replicationLog.LastSeq)
+	return nil
+}
+
+/*
+GET-запрос к API ведущего экземпляра от имени администратора платформы из конфига
+
+:param path string: путь запроса с параметрами
+:param result interface{}: значение, в которое читается JSON-ответ
+
+:return: ошибка, если запрос не выполнен или ответ некорректен (logTruncatedErr - ведущий ответил статусом 410)
+*/
+func getFromLeader(path string, result interface{}) error {
+	request, err := http.NewRequest(http.MethodGet, config.LeaderURL+path, nil)
+	if err != nil {
+		return err
+	}
+	request.SetBasicAuth(config.Username, config.Password)
+	response, err := Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode == http.StatusGone {
+		return logTruncatedErr
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("leader responded with status %d: %s", response.StatusCode, body)
+	}
+	return json.Unmarshal(body, result)
+}
+
+/*
+Обновление состояния ведомого экземпляра после успешного ответа ведущего
+
+:param seq int64: номер последней примененной записи журнала
+:param leaderSeq int64: номер последней записи журнала ведущего экземпляра
+*/
+func setPosition(seq int64, leaderSeq int64) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.leaderSeq = leaderSeq
+	state.lastError = ""
+	state.waiting = seq >= leaderSeq
+	if state.waiting {
+		state.caughtUpAt = time.Now()
+	}
+}
+
+/*
+Запись ошибки обращения к ведущему экземпляру в состояние ведомого экземпляра (отставание считается с момента, когда ведомый в последний раз догнал ведущего)
+
+:param err error: ошибка
+*/
+func setError(err error) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.lastError = err.Error()
+	state.waiting = false
+}
@@ -0,0 +1,296 @@
+package replication
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+	"github.com/ZotovSergey/authenticationservice/internal/testutil"
+)
+
+// Изменения конфигов репозитория для тестов: короткий журнал изменений БД, чтобы ведомый экземпляр отставал от ведущего за несколько изменений
+var testConfigs = map[string]map[string]interface{}{
+	"dbConfig.json": {
+		"replicationLogSize": 2,
+	},
+}
+
+// Тесты выполняются из временного каталога с конфигами репозитория, измененными testConfigs
+func TestMain(m *testing.M) {
+	os.Exit(testutil.RunWithConfigs(m, "../../configs", testConfigs, nil))
+}
+
+// БД ведущего экземпляра в тесте
+type leaderStore interface {
+	ReplicationSnapshot() models.ReplicationSnapshot
+	ReplicationLog(epoch string, since int64) (models.ReplicationLog, <-chan struct{}, error)
+	GetTenant(name string) (*myProfilesDB.Tenant, error)
+	PlatformTenant() string
+}
+
+// Ведущий экземпляр в тесте: API репликации над своей БД, остальные запросы запоминаются, на них отвечается "leader"
+type TestLeader struct {
+	server    *httptest.Server
+	store     leaderStore
+	mu        sync.Mutex
+	forwarded []*http.Request
+}
+
+/*
+Запуск ведущего экземпляра с временной БД и подъем пустой БД ведомого экземпляра этого ведущего (по глобальному адресу myProfilesDB.DB);
+конфигурация репликации, состояние ведомого экземпляра и БД восстанавливаются после теста
+
+:param t *testing.T: тест
+:param forwardWrites bool: перенаправляются ли изменяющие запросы к ведомому экземпляру ведущему
+
+:return: ведущий экземпляр
+*/
+func StartTestLeader(t *testing.T, forwardWrites bool) *TestLeader {
+	t.Helper()
+	store, err := myProfilesDB.OpenMyProfilesDB(filepath.Join(t.TempDir(), "leader.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	leader := &TestLeader{store: store}
+	leader.server = httptest.NewServer(http.HandlerFunc(leader.serve))
+	t.Cleanup(leader.server.Close)
+
+	previousConfig, previousDB := config, myProfilesDB.DB
+	t.Cleanup(func() {
+		config, myProfilesDB.DB = previousConfig, previousDB
+		resetState()
+	})
+	config = replicationConfig{Role: models.ReplicationRoleFollower, LeaderURL: leader.server.URL, ForwardWrites: forwardWrites, PollTimeout: 1, RetryInterval: 1, MaxLag: 1}
+	resetState()
+	err = myProfilesDB.RaiseFollowerDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leader
+}
+
+/*
+Обработка запроса к ведущему экземпляру, как его обрабатывают запросы журнала и снимка БД API
+
+:param w http.ResponseWriter: ответ
+:param r *http.Request: запрос
+*/
+func (l *TestLeader) serve(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/v1/replication/snapshot":
+		json.NewEncoder(w).Encode(l.store.ReplicationSnapshot())
+	case "/v1/replication/log":
+		epoch := r.URL.Query().Get("epoch")
+		since, _ := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+		wait, _ := strconv.ParseInt(r.URL.Query().Get("wait"), 10, 64)
+		replicationLog, replicated, err := l.store.ReplicationLog(epoch, since)
+		if err == nil && len(replicationLog.Entries) == 0 {
+			select {
+			case <-replicated:
+				replicationLog, _, err = l.store.ReplicationLog(epoch, since)
+			case <-time.After(time.Duration(wait) * time.Second):
+			}
+		}
+		if errors.Is(err, myProfilesDB.ReplicationLogTruncatedErr) {
+			http.Error(w, err.Error(), http.StatusGone)
+			return
+		}
+		json.NewEncoder(w).Encode(replicationLog)
+	default:
+		l.mu.Lock()
+		l.forwarded = append(l.forwarded, r)
+		l.mu.Unlock()
+		w.Write([]byte("leader"))
+	}
+}
+
+/*
+Получить запросы, перенаправленные ведущему экземпляру
+
+:return: запросы, кроме запросов журнала и снимка БД
+*/
+func (l *TestLeader) Forwarded() []*http.Request {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]*http.Request(nil), l.forwarded...)
+}
+
+/*
+Добавление профиля в тенант платформы ведущего экземпляра
+
+:param t *testing.T: тест
+:param login string: логин профиля
+*/
+func (l *TestLeader) AddProfile(t *testing.T, login string) {
+	t.Helper()
+	platform, err := l.store.GetTenant(l.store.PlatformTenant())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = platform.AddProfile(login, models.ProfileData{Login: login, FirstName: "Test", LastName: "User"}, "Passw0rd!x", models.AccountStatusActive, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+/*
+Шаг репликации ведомого экземпляра, как его выполняет Run (после ошибки состояние ведомого экземпляра обновляется, но повтор не ждет retryInterval)
+
+:return: ошибка шага репликации
+*/
+func Replicate() error {
+	err := replicate()
+	if err != nil {
+		setError(err)
+	}
+	return err
+}
+
+// Сброс состояния ведомого экземпляра
+func resetState() {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.leaderSeq, state.caughtUpAt, state.waiting, state.lastError = 0, time.Time{}, false, ""
+}
+
+/*
+Проверка, что профиль есть в тенанте платформы ведомого экземпляра
+
+:param t *testing.T: тест
+:param login string: логин профиля
+*/
+func checkFollowerProfile(t *testing.T, login string) {
+	t.Helper()
+	platform, err := myProfilesDB.DB.GetTenant(myProfilesDB.DB.PlatformTenant())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = platform.GetProfileData(login); err != nil {
+		t.Fatalf("profile %s is not replicated: %s", login, err)
+	}
+}
+
+// Ведомый экземпляр загружает снимок БД ведущего, затем применяет записи журнала изменений; изменения на ведомом экземпляре отклоняются
+func TestBootstrapAndLogTailing(t *testing.T) {
+	leader := StartTestLeader(t, true)
+	leader.AddProfile(t, "user1")
+
+	// Начальная загрузка снимка
+	if Bootstrapped() || Status().Ready {
+		t.Fatal("follower is ready before snapshot is loaded")
+	}
+	if err := Replicate(); err != nil {
+		t.Fatal(err)
+	}
+	if !Bootstrapped() {
+		t.Fatal("follower is not bootstrapped after snapshot")
+	}
+	checkFollowerProfile(t, "user1")
+	leaderEpoch, leaderSeq := leader.store.ReplicationSnapshot().Epoch, leader.store.ReplicationSnapshot().Seq
+	if epoch, seq := myProfilesDB.DB.ReplicationPosition(); epoch != leaderEpoch || seq != leaderSeq {
+		t.Fatalf("follower position %s/%d, leader position %s/%d", epoch, seq, leaderEpoch, leaderSeq)
+	}
+
+	// Применение новых записей журнала
+	leader.AddProfile(t, "user2")
+	if err := Replicate(); err != nil {
+		t.Fatal(err)
+	}
+	checkFollowerProfile(t, "user2")
+	if _, seq := myProfilesDB.DB.ReplicationPosition(); seq != leaderSeq+1 {
+		t.Fatalf("follower seq %d after one leader change, expected %d", seq, leaderSeq+1)
+	}
+	if status := Status(); !status.Ready || status.LagEntries != 0 || status.LagSeconds != 0 {
+		t.Fatalf("caught up follower status %+v", status)
+	}
+
+	// Изменения на ведомом экземпляре отклоняются и не применяются
+	platform, err := myProfilesDB.DB.GetTenant(myProfilesDB.DB.PlatformTenant())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = platform.AddProfile("user3", models.ProfileData{Login: "user3", FirstName: "Test", LastName: "User"}, "Passw0rd!x", models.AccountStatusActive, "admin")
+	if err == nil {
+		t.Fatal("follower accepted a write")
+	}
+	if _, _, err = platform.GetProfileData("user3"); err == nil {
+		t.Fatal("rejected write is kept in follower database")
+	}
+}
+
+// Если записи журнала после позиции ведомого экземпляра уже удалены, ведущий отвечает 410 и ведомый заново загружает снимок
+func TestTruncatedLogReloadsSnapshot(t *testing.T) {
+	leader := StartTestLeader(t, true)
+	if err := Replicate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Изменений больше, чем хранит журнал ведущего (replicationLogSize)
+	for _, login := range []string{"user1", "user2", "user3"} {
+		leader.AddProfile(t, login)
+	}
+	epoch, seq := myProfilesDB.DB.ReplicationPosition()
+	if _, _, err := leader.store.ReplicationLog(epoch, seq); !errors.Is(err, myProfilesDB.ReplicationLogTruncatedErr) {
+		t.Fatalf("leader log is not truncated: %v", err)
+	}
+	if err := Replicate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, login := range []string{"user1", "user2", "user3"} {
+		checkFollowerProfile(t, login)
+	}
+	if _, seq = myProfilesDB.DB.ReplicationPosition(); seq != leader.store.ReplicationSnapshot().Seq {
+		t.Fatalf("follower seq %d after snapshot reload, leader seq %d", seq, leader.store.ReplicationSnapshot().Seq)
+	}
+
+	// Ведущий перезапущен (новая эпоха журнала): ведомый также заново загружает снимок
+	restarted, err := myProfilesDB.OpenMyProfilesDB(filepath.Join(t.TempDir(), "leader.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	leader.store = restarted
+	if err := Replicate(); err != nil {
+		t.Fatal(err)
+	}
+	if epoch, _ = myProfilesDB.DB.ReplicationPosition(); epoch != restarted.ReplicationSnapshot().Epoch {
+		t.Fatal("follower did not reload snapshot of restarted leader")
+	}
+}
+
+// Ведомый экземпляр перестает быть готовым, если не догонял ведущего дольше maxLag
+func TestReadinessLag(t *testing.T) {
+	leader := StartTestLeader(t, true)
+	if err := Replicate(); err != nil {
+		t.Fatal(err)
+	}
+	if status := Status(); !status.Ready {
+		t.Fatalf("caught up follower is not ready: %+v", status)
+	}
+
+	// Ведущий недоступен: отставание считается с момента, когда ведомый в последний раз догнал ведущего
+	leader.server.Close()
+	if err := Replicate(); err == nil {
+		t.Fatal("replication succeeded with stopped leader")
+	}
+	status := Status()
+	if !status.Ready || status.LastError == "" {
+		t.Fatalf("follower lagging less than maxLag is not ready or has no error: %+v", status)
+	}
+	state.mu.Lock()
+	state.caughtUpAt = time.Now().Add(-time.Duration(config.MaxLag+1) * time.Second)
+	state.mu.Unlock()
+	if status = Status(); status.Ready || status.LagSeconds <= config.MaxLag {
+		t.Fatalf("follower lagging more than maxLag is ready: %+v", status)
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Получение тенанта, к которому относится запрос (определяется middleware ResolveTenant)
+
+:param ctx *fiber.Ctx: контекст запроса
+
+:return: указатель на тенант запроса
+*/
+func requestTenant(ctx *fiber.Ctx) *myProfilesDB.Tenant {
+	return ctx.Locals("tenant").(*myProfilesDB.Tenant)
+}
+
+/*
+Получение логина авторизованного пользователя
+
+:param ctx *fiber.Ctx: контекст запроса
+
+:return: логин авторизованного пользователя
+*/
+func authorizedLogin(ctx *fiber.Ctx) string {
+	return ctx.Locals("login").(string)
+}
+
+/*
+Проверка, является ли авторизованный пользователь администратором платформы
+
+:param ctx *fiber.Ctx: контекст запроса
+
+:return: true, если пользователь авторизован в тенанте платформы и входит в список администраторов платформы, иначе - false
+*/
+func isPlatformAdmin(ctx *fiber.Ctx) bool {
+	return ctx.Locals("authTenant").(string) == myProfilesDB.DB.PlatformTenant() &&
+		myProfilesDB.DB.IsPlatformAdmin(authorizedLogin(ctx))
+}
+
+/*
+Проверка, является ли авторизованный пользователь администратором тенанта запроса (администраторы платформы являются администраторами всех тенантов)
+
+:param ctx *fiber.Ctx: контекст запроса
+
+:return: true, если пользователь является администратором тенанта запроса или администратором платформы, иначе - false
+*/
+func isTenantAdmin(ctx *fiber.Ctx) bool {
+	if isPlatformAdmin(ctx) {
+		return true
+	}
+	tenant := requestTenant(ctx)
+	return ctx.Locals("authTenant").(string) == tenant.Name() && tenant.IsAdmin(authorizedLogin(ctx))
+}
+
+/*
+Проверка, обращается ли авторизованный пользователь к своему профилю
+
+:param ctx *fiber.Ctx: контекст запроса
+:param login string: логин профиля в тенанте запроса
+
+:return: true, если пользователь авторизован в тенанте запроса под логином login, иначе - false
+*/
+func isSelf(ctx *fiber.Ctx, login string) bool {
+	return ctx.Locals("authTenant").(string) == requestTenant(ctx).Name() && authorizedLogin(ctx) == login
+}
+
+/*
+Составление данных профиля, видимых авторизованному пользователю, по настройкам видимости полей тенанта запроса
+
+:param ctx *fiber.Ctx: контекст запроса
+:param profileData models.ProfileData: полные данные профиля тенанта запроса
+
+:return: данные профиля без полей, скрытых от пользователя
+*/
+func visibleProfileData(ctx *fiber.Ctx, profileData models.ProfileData) models.ProfileData {
+	return requestTenant(ctx).RedactProfileData(profileData, viewerLogin(ctx), isTenantAdmin(ctx))
+}
+
+/*
+Получение логина авторизованного пользователя в тенанте запроса (для проверки видимости полей профилей)
+
+:param ctx *fiber.Ctx: контекст запроса
+
+:return: логин пользователя или пустая строка, если пользователь авторизован в другом тенанте (администратор платформы)
+*/
+func viewerLogin(ctx *fiber.Ctx) string {
+	if ctx.Locals("authTenant").(string) == requestTenant(ctx).Name() {
+		return authorizedLogin(ctx)
+	}
+	return ""
+}
+
+/*
+Получение логина профиля тенанта запроса по логину или идентификатору профиля
+
+:param ctx *fiber.Ctx: контекст запроса
+:param loginData models.LoginData: логин или идентификатор профиля (если задан идентификатор, логин не учитывается)
+
+:return: текущий логин профиля (старый логин переименованного профиля заменяется новым) или ошибка, если профиля с заданным идентификатором нет
+*/
+func profileLogin(ctx *fiber.Ctx, loginData models.LoginData) (string, error) {
+	if loginData.ID != "" {
+		return requestTenant(ctx).GetLoginByID(loginData.ID)
+	}
+	return requestTenant(ctx).ResolveLogin(loginData.Login), nil
+}
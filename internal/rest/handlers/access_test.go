@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Пользователь видит все поля своего профиля, а в чужом профиле - только поля, видимые всем: адрес электронной почты и атрибуты без настройки видимости скрыты
+func TestGetProfileRedactsFields(t *testing.T) {
+	platform := raiseTestDB(t)
+	for _, definition := range []models.AttributeDefinition{
+		{Name: "dept", Type: models.AttributeTypeString},
+		{Name: "team", Type: models.AttributeTypeString, Visibility: models.VisibilityPublic},
+	} {
+		err := platform.SetAttributeDefinition(definition)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	alice := models.ProfileData{Login: "alice", FirstName: "Alice", LastName: "User", Email: "alice@example.com", Attributes: map[string]interface{}{"dept": "sales", "team": "red"}}
+	err := platform.AddProfile("alice", alice, "Passw0rd!1", models.AccountStatusActive, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addTestProfile(t, platform, "bob", "Passw0rd!2")
+	app := newTestAPI(t)
+	app.Get("/profile", GetProfileDataRequest)
+
+	viewers := []struct {
+		login      string
+		password   string
+		email      string
+		attributes map[string]interface{}
+	}{
+		{"bob", "Passw0rd!2", "", map[string]interface{}{"team": "red"}},
+		{"alice", "Passw0rd!1", alice.Email, alice.Attributes},
+		{"admin", "admin", alice.Email, alice.Attributes},
+	}
+	for _, viewer := range viewers {
+		status, body := sendTestRequest(t, app, http.MethodGet, "/profile", viewer.login, viewer.password, `{"login":"alice"}`)
+		if status != http.StatusOK {
+			t.Fatalf("profile is not received by %s: %d %s", viewer.login, status, body)
+		}
+		var profileData models.ProfileData
+		err = json.Unmarshal([]byte(body), &profileData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if profileData.FirstName != alice.FirstName || profileData.LastName != alice.LastName || profileData.Email != viewer.email ||
+			!reflect.DeepEqual(profileData.Attributes, viewer.attributes) {
+			t.Fatalf("unexpected profile seen by %s: %s", viewer.login, body)
+		}
+	}
+}
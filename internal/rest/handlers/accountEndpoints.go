@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// @Summary Get accounts
+// @Security BasicAuth
+// @Description Запрос на вывод учетных записей тенанта со статусами (pending, active, suspended, expired), причинами приостановки и сроками действия, доступно только администраторам
+// @Produce json
+// @Param status query string false "статус, по которому отбираются учетные записи"
+// @Success      200  {json}  json	model.AccountData
+// @Failure      404  {string}  string	"unknown account status"
+// @Router /v1/profiles [get]
+func GetAccountsRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "get accounts")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Получение списка учетных записей из БД
+	accounts, err := requestTenant(ctx).GetAccounts(ctx.Query("status"))
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(accounts)
+}
+
+// @Summary Activate account
+// @Security BasicAuth
+// @Description Запрос на активацию ожидающей активации, приостановленной или истекшей учетной записи (истекший срок действия снимается), доступно только администраторам
+// @Param login path string true "логин профиля"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"account status transition is not allowed"
+// @Router /v1/profiles/{login}/activate [post]
+func ActivateAccountRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "activate account")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+	login, err := pathProfileLogin(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Активация учетной записи
+	err = requestTenant(ctx).ActivateAccount(login, authorizedLogin(ctx))
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Suspend account
+// @Security BasicAuth
+// @Description Запрос на приостановку активной учетной записи с указанием причины (данные профиля сохраняются, авторизация запрещается до активации), доступно только администраторам, нельзя приостанавливать свою учетную запись
+// @Accept json
+// @Param login path string true "логин профиля"
+// @Param input body models.SuspensionData true "причина приостановки"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"account status transition is not allowed"
+// @Router /v1/profiles/{login}/suspend [post]
+func SuspendAccountRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "suspend account")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.SuspensionData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return badRequest(err)
+	}
+	login, err := pathProfileLogin(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Проверка профиля: нельзя приостанавливать свою учетную запись
+	if isSelf(ctx, login) {
+		log.Println(canNotChangeOwnAccountStatusErr.Error())
+		return canNotChangeOwnAccountStatusErr
+	}
+
+	// Приостановка учетной записи
+	err = requestTenant(ctx).SuspendAccount(login, body.Reason, authorizedLogin(ctx))
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Set account expiry
+// @Security BasicAuth
+// @Description Запрос на изменение срока действия учетной записи: по истечении срока активная учетная запись автоматически становится истекшей, срок должен быть 0 или в будущем, доступно только администраторам, нельзя менять срок действия своей учетной записи
+// @Accept json
+// @Param login path string true "логин профиля"
+// @Param input body models.ExpiryData true "время истечения срока действия (unix-время в секундах, 0 - срок не ограничен)"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such profile"
+// @Failure      404  {string}  string	"account expiry time must be 0 or in the future"
+// @Router /v1/profiles/{login}/expiry [put]
+func SetAccountExpiryRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "set account expiry")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.ExpiryData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return badRequest(err)
+	}
+	login, err := pathProfileLogin(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Проверка профиля: нельзя менять срок действия своей учетной записи
+	if isSelf(ctx, login) {
+		log.Println(canNotChangeOwnAccountStatusErr.Error())
+		return canNotChangeOwnAccountStatusErr
+	}
+
+	// Изменение срока действия учетной записи
+	err = requestTenant(ctx).SetAccountExpiry(login, body.ExpiresAt, authorizedLogin(ctx))
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+)
+
+// @Summary Backup database
+// @Security BasicAuth
+// @Description Запрос на получение резервной копии БД без остановки сервиса: согласованный снимок всех тенантов, администраторов платформы, клиентов и токенов OAuth 2.0 в формате файла базы данных (если заданы мастер-ключи, копия зашифрована), доступно только администраторам платформы
+// @Produce octet-stream
+// @Success      200  {file}  file	"резервная копия БД"
+// @Failure      404  {string}  string	"user is not platform admin"
+// @Router /v1/admin/backup [get]
+func BackupRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "backup database")
+
+	// Проверка, является ли авторизованный пользователь администратором платформы
+	if !isPlatformAdmin(ctx) {
+		log.Println(userIsNotPlatformAdminErr.Error())
+		return userIsNotPlatformAdminErr
+	}
+
+	// Создание резервной копии
+	backup, err := myProfilesDB.DB.Backup()
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	ctx.Attachment("db-" + time.Now().UTC().Format("20060102-150405") + ".bak")
+	ctx.Set(fiber.HeaderContentType, fiber.MIMEOctetStream)
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendStream(bytes.NewReader(backup), len(backup))
+}
+
+// @Summary Restore database
+// @Security BasicAuth
+// @Description Запрос на восстановление БД из резервной копии без остановки сервиса: копия проверяется (целостность, ключ шифрования, наличие тенанта и администраторов платформы), после чего все данные БД атомарно заменяются данными копии (если БД не удалось сохранить, данные не меняются); журналы изменений профилей очищаются, номера событий не уменьшаются, доступно только администраторам платформы
+// @Accept octet-stream
+// @Param input body string true "резервная копия БД, полученная запросом /v1/admin/backup [get]"
+// @Success      200  {string}  string	"request completed"
+// @Failure      400  {string}  string	"invalid backup"
+// @Failure      404  {string}  string	"user is not platform admin"
+// @Router /v1/admin/restore [post]
+func RestoreRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "restore database")
+
+	// Проверка, является ли авторизованный пользователь администратором платформы
+	if !isPlatformAdmin(ctx) {
+		log.Println(userIsNotPlatformAdminErr.Error())
+		return userIsNotPlatformAdminErr
+	}
+
+	// Восстановление БД из резервной копии из тела запроса
+	err := myProfilesDB.DB.Restore(ctx.Body())
+	if errors.Is(err, myProfilesDB.InvalidBackupErr) {
+		return badRequest(err)
+	}
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
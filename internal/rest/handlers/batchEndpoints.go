@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Максимальное количество операций в пакете
+const maxBatchOperations = 1000
+
+// @Summary Batch operations
+// @Security BasicAuth
+// @Description Запрос на выполнение пакета операций над профилями тенанта по принципу "все или ничего": операции (createProfile, editProfile, setPassword, removeProfile, addAdmin, dropAdmin, addGroupMember, removeGroupMember, activateAccount, suspendAccount) выполняются по порядку, данные сохраняются один раз; если одна из операций не выполнена, все операции отменяются. Доступно только администраторам; пароли проверяются по политике паролей, нельзя удалять и приостанавливать свой профиль и лишать себя прав администратора
+// @Accept json
+// @Produce json
+// @Param input body models.BatchData true "операции пакета"
+// @Success      200  {json}  json	model.BatchResult
+// @Failure      400  {string}  string	"password does not satisfy password policy"
+// @Failure      422  {json}  json	model.BatchResult
+// @Router /v1/batch [post]
+func BatchRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "batch operations")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.BatchData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return badRequest(err)
+	}
+	if len(body.Operations) == 0 || len(body.Operations) > maxBatchOperations {
+		return badRequest(fmt.Errorf("%w: from 1 to %d operations are allowed", invalidBatchErr, maxBatchOperations))
+	}
+
+	// Проверка операций: пароли по политике паролей, операции со своим профилем
+	for i, operation := range body.Operations {
+		switch operation.Op {
+		case models.BatchOpCreateProfile, models.BatchOpSetPassword:
+			err = PasswordPolicyError(operation.Password)
+			if err != nil {
+				return badRequest(fmt.Errorf("operation %d (%s): %w", i, operation.Op, err))
+			}
+		case models.BatchOpRemoveProfile:
+			if isSelf(ctx, operation.Login) {
+				log.Println(canNotRemoveOwnProfileErr.Error())
+				return canNotRemoveOwnProfileErr
+			}
+		case models.BatchOpSuspendAccount:
+			if isSelf(ctx, operation.Login) {
+				log.Println(canNotChangeOwnAccountStatusErr.Error())
+				return canNotChangeOwnAccountStatusErr
+			}
+		}
+	}
+
+	// Выполнение пакета (администратор тенанта не может лишить себя прав администратора ни одной из операций пакета)
+	var keepAdmin string
+	if !isPlatformAdmin(ctx) {
+		keepAdmin = authorizedLogin(ctx)
+	}
+	result, err := requestTenant(ctx).Batch(body.Operations, keepAdmin, authorizedLogin(ctx))
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	if !result.Committed {
+		log.Printf("request error (status %d): %s", fiber.StatusUnprocessableEntity, result.Error)
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(result)
+	}
+	log.Printf("request completed (status %d): %d operations", fiber.StatusOK, len(result.Results))
+	return ctx.JSON(result)
+}
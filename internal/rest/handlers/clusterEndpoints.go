@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/cluster"
+)
+
+// @Summary Cluster status
+// @Security BasicAuth
+// @Description Запрос на получение состояния узла кластера: состояние узла в Raft, лидер кластера и адрес его API, позиция в журнале Raft и узлы кластера, доступно только администраторам платформы
+// @Produce json
+// @Success      200  {object}  models.ClusterStatus
+// @Failure      404  {string}  string	"user is not platform admin"
+// @Failure      503  {string}  string	"cluster is disabled"
+// @Router /v1/cluster [get]
+func ClusterStatusRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "cluster status")
+
+	// Проверка, является ли авторизованный пользователь администратором платформы
+	if !isPlatformAdmin(ctx) {
+		log.Println(userIsNotPlatformAdminErr.Error())
+		return userIsNotPlatformAdminErr
+	}
+	// Экземпляр не входит в кластер
+	if !cluster.Enabled() {
+		log.Printf("request error (status %d): %s", fiber.StatusServiceUnavailable, clusterDisabledErr.Error())
+		return fiber.NewError(fiber.StatusServiceUnavailable, clusterDisabledErr.Error())
+	}
+
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(cluster.Status())
+}
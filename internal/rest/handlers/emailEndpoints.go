@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// @Summary Verify email
+// @Description Запрос на подтверждение адреса электронной почты по одноразовому токену из письма (без авторизации): новый адрес, ожидающий подтверждения, заменяет текущий (на старый адрес отправляется уведомление), в профиле записывается время подтверждения; если заявка на регистрацию не требует одобрения администратора, учетная запись становится активной
+// @Param token path string true "токен подтверждения"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such email verification or verification expired"
+// @Router /email/verify/{token} [post]
+func VerifyEmailRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "verify email")
+
+	// Подтверждение адреса электронной почты
+	login, err := requestTenant(ctx).VerifyEmail(ctx.Params("token"))
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("email of \"%s\" verified", login)
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Resend email verification
+// @Security BasicAuth
+// @Description Запрос на повторную отправку токена подтверждения на ожидающий подтверждения адрес электронной почты профиля или на неподтвержденный текущий адрес, доступно всем пользователям для своих профилей и администраторам для всех профилей
+// @Accept json
+// @Param input body models.LoginData true "логин или идентификатор профиля"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"email is already verified"
+// @Router /email/verification [post]
+func ResendVerificationRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "resend email verification")
+
+	// Чтение тела запроса
+	var body models.LoginData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return badRequest(err)
+	}
+	login, err := profileLogin(ctx, body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+
+	// Проверка прав доступа (является ли авторизованный пользователь администратором или пользователь запрашивает подтверждение своего адреса)
+	if !isTenantAdmin(ctx) && !isSelf(ctx, login) {
+		log.Println(canNotEditProfileErr.Error())
+		return canNotEditProfileErr
+	}
+
+	// Повторная отправка токена подтверждения
+	err = requestTenant(ctx).ResendVerification(login)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
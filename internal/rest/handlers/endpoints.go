@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,11 +12,12 @@ import (
 
 // @Summary Get profile data
 // @Security BasicAuth
-// @Description Запрос на вывод данных о профиле по логину, доступно всем пользователям (запрос не работает со страницы swagger из браузера, но работает через postman или insomnia)
+// @Description Запрос на вывод данных о профиле по логину, доступно всем пользователям; поля, скрытые от пользователя настройками видимости тенанта, не выводятся (запрос не работает со страницы swagger из браузера, но работает через postman или insomnia)
 // @Accept json
 // @Produce json
-// @Param input body models.LoginData true "логин получаемого профиля"
+// @Param input body models.LoginData true "логин или идентификатор получаемого профиля"
 // @Success      200  {json}	json	model.ProfileData
+// @Header       200  {string}  ETag	"версия профиля"
 // @Failure      404  {string}  string	"no such profile"
 // @Router /profile [get]
 func GetProfileDataRequest(ctx *fiber.Ctx) error {
@@ -27,14 +29,20 @@ func GetProfileDataRequest(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	// Получение профиля из БД
-	profileData, err := myProfilesDB.DB.GetProfileData(body.Login)
+	// Получение профиля из БД по логину или идентификатору (по старому логину переименованного профиля выводится профиль с новым логином)
+	login, err := profileLogin(ctx, body)
 	if err != nil {
-		log.Printf("request completed (status %d) with error: %s", fiber.StatusOK, err.Error())
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	profileData, version, err := requestTenant(ctx).GetProfileData(login)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
 		return err
 	}
 	log.Printf("request completed (status %d)", fiber.StatusOK)
-	return ctx.JSON(profileData)
+	ctx.Set(fiber.HeaderETag, profileETag(version))
+	return ctx.JSON(visibleProfileData(ctx, profileData))
 }
 
 // @Summary Get all logins
@@ -47,7 +55,7 @@ func GetAllLoginsRequest(ctx *fiber.Ctx) error {
 	log.Printf("\"%s\" request received", "get all logins")
 
 	// Получение списка логинов из БД
-	loginsList := myProfilesDB.DB.GetAllLogins()
+	loginsList := requestTenant(ctx).GetAllLogins()
 
 	log.Printf("request completed (status %d)", fiber.StatusOK)
 	return ctx.JSON(loginsList)
@@ -55,18 +63,17 @@ func GetAllLoginsRequest(ctx *fiber.Ctx) error {
 
 // @Summary Add profile
 // @Security BasicAuth
-// @Description Запрос на регистрацию нового пользователя, доступно только администраторам
+// @Description Запрос на регистрацию нового пользователя, доступно только администраторам; учетная запись создается активной или ожидающей активации
 // @Accept json
-// @Param input body models.FullProfileData true "данные нового профиля, включающие логин нового профиля, данные для хранения и пароль"
+// @Param input body models.FullProfileData true "данные нового профиля, включающие логин нового профиля, данные для хранения, пароль и начальный статус учетной записи (pending или active)"
 // @Success      200  {string}  string	"request completed"
 // @Failure      404  {string}  string	"no such profile"
 // @Router /profile [post]
 func AddProfileRequest(ctx *fiber.Ctx) error {
 	log.Printf("\"%s\" request received", "add profile")
 
-	// Проверка, является ли авторизованный пользователь администратором
-	authorizedUserLogin := ctx.Locals("login").(string)
-	if !myProfilesDB.DB.IsAdmin(authorizedUserLogin) {
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
 		log.Println(userIsNotAdminErr.Error())
 		return userIsNotAdminErr
 	}
@@ -79,18 +86,28 @@ func AddProfileRequest(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
+	// Проверка пароля по политике паролей
+	err = checkPasswordPolicy(body.Password)
+	if err != nil {
+		return err
+	}
+
 	// Добавление пользователя
-	err = myProfilesDB.DB.AddProfile(
+	err = requestTenant(ctx).AddProfile(
 		body.Login,
 		models.ProfileData{
-			Login:     body.Login,
-			FirstName: body.FirstName,
-			LastName:  body.LastName,
+			Login:      body.Login,
+			FirstName:  body.FirstName,
+			LastName:   body.LastName,
+			Email:      body.Email,
+			Attributes: body.Attributes,
 		},
 		body.Password,
+		body.Status,
+		authorizedLogin(ctx),
 	)
 	if err != nil {
-		log.Printf("request completed (status %d) with error: %s", fiber.StatusOK, err.Error())
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
 		return err
 	}
 	log.Printf("request completed (status %d)", fiber.StatusOK)
@@ -99,68 +116,90 @@ func AddProfileRequest(ctx *fiber.Ctx) error {
 
 // @Summary Edit profile
 // @Security BasicAuth
-// @Description Запрос на редактирование данных профиля, доступно всем пользователям при редактировании своих профилей и доступно редактирование всех профилей администраторам
+// @Description Запрос на редактирование данных профиля, доступно всем пользователям при редактировании своих профилей и доступно редактирование всех профилей администраторам.
+// @Description Формат изменений определяется заголовком Content-Type: application/json - новые данные профиля (пустые поля не меняются, атрибуты со значением null удаляются),
+// @Description application/merge-patch+json - JSON Merge Patch (RFC 7396, поля со значением null очищаются), application/json-patch+json - JSON Patch (RFC 6902).
+// @Description Данные профиля после применения изменений проверяются, логин профиля изменять нельзя.
+// @Description Изменения применяются к данным, видимым пользователю: изменения и ссылки на скрытые от пользователя поля отклоняются (422), скрытые поля не меняются
 // @Accept json
-// @Param input body models.ProfileData true "логин редактируемого профиля и новые данные для редактирования (если данные не добавлениы, то они не меняются)"
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Param input body models.ProfileData true "логин редактируемого профиля и новые данные для редактирования (если данные не добавлениы, то они не меняются, атрибуты со значением null удаляются) или патч"
+// @Param login query string false "логин редактируемого профиля (для JSON Patch обязателен логин или идентификатор)"
+// @Param id query string false "идентификатор редактируемого профиля"
+// @Param If-Match header string false "ETag профиля, полученный запросом /profile [get]: профиль редактируется, только если он не был изменен"
 // @Success      200  {string}  string	"request completed"
+// @Header       200  {string}  ETag	"новая версия профиля"
 // @Failure      404  {string}  string	"no such profile"
+// @Failure      412  {string}  string	"profile version does not match"
+// @Failure      415  {string}  string	"unsupported media type"
+// @Failure      422  {string}  string	"patched profile is invalid"
 // @Router /profile [patch]
 func EditProfileRequest(ctx *fiber.Ctx) error {
 	log.Printf("\"%s\" request received", "edit profile")
 
-	// Чтение тела запроса
-	var body models.ProfileData
-	err := ctx.BodyParser(&body)
+	// Разбор тела запроса по типу содержимого
+	login, id, patch, err := parseProfilePatch(ctx)
 	if err != nil {
-		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
-		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		return err
+	}
+	if login == "" && id == "" {
+		return badRequest(noPatchedLoginErr)
+	}
+	// Профиль ищется по логину или идентификатору (по старому логину переименованного профиля редактируется профиль с новым логином)
+	login, err = profileLogin(ctx, models.LoginData{Login: login, ID: id})
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
 	}
 
 	// Проверка прав достува (является ли авторизованный пользователь администратором или пользователь редактирует свой профиль)
-	authorizedUserLogin := ctx.Locals("login").(string)
-	if !myProfilesDB.DB.IsAdmin(authorizedUserLogin) && authorizedUserLogin != body.Login {
-		if !myProfilesDB.DB.IsAdmin(authorizedUserLogin) {
+	if !isTenantAdmin(ctx) && !isSelf(ctx, login) {
+		if !isTenantAdmin(ctx) {
 			log.Println(userIsNotAdminErr.Error())
 			return userIsNotAdminErr
 		}
-		if authorizedUserLogin != body.Login {
+		if !isSelf(ctx, login) {
 			log.Println(canNotEditProfileErr.Error())
 			return canNotEditProfileErr
 		}
 	}
 
-	// Получение текущих данных профиля
-	currentProfileData, err := myProfilesDB.DB.GetProfileData(body.Login)
-	if err != nil {
-		log.Printf("request completed (status %d) with error: %s", fiber.StatusOK, err.Error())
-		return err
-	}
-	// Составление новой структуры данных профиля
-	var newProfileFirstName string // новое имя пользовате (остается прежним, если не было задано)
-	if body.FirstName != "" {
-		newProfileFirstName = body.FirstName
-	} else {
-		newProfileFirstName = currentProfileData.FirstName
-	}
-	var newProfileLastName string // новое имя пользовате (остается прежним, если не было задано)
-	if body.LastName != "" {
-		newProfileLastName = body.LastName
-	} else {
-		newProfileLastName = currentProfileData.LastName
-	}
+	// Редактирование данных профиля в одной транзакции: текущие данные читаются, проверяются по условию If-Match, изменения применяются к видимым пользователю данным
+	// и записываются вместе со скрытыми от пользователя полями, пока другие запросы не могут изменить профиль
+	viewer, viewerIsAdmin := viewerLogin(ctx), isTenantAdmin(ctx)
+	var newVersion int64
+	err = requestTenant(ctx).Update(func(tx *myProfilesDB.Tx) error {
+		// Получение текущих данных профиля
+		currentProfileData, currentVersion, err := tx.GetProfileData(login)
+		if err != nil {
+			return err
+		}
+		// Проверка условия If-Match
+		if !ifMatchProfile(ctx, currentVersion) {
+			return preconditionFailed(fmt.Errorf("%w: current version is %d", myProfilesDB.ProfileVersionMismatchErr, currentVersion))
+		}
+		// Составление новых данных профиля (патч применяется к данным, видимым пользователю, скрытые поля нельзя добавить или изменить, они переносятся из текущих данных)
+		canSee := tx.FieldViewer(login, viewer, viewerIsAdmin)
+		visibleProfileData := tx.RedactProfileData(currentProfileData, viewer, viewerIsAdmin)
+		newProfileData, err := patch(visibleProfileData, canSee)
+		if err == nil {
+			newProfileData, err = mergeHiddenFields(newProfileData, visibleProfileData, currentProfileData, canSee)
+		}
+		if err != nil {
+			log.Printf("request error (status %d): %s", fiber.StatusUnprocessableEntity, err.Error())
+			return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+		}
 
-	// Редактирование данных профиля
-	err = myProfilesDB.DB.EditProfile(
-		body.Login,
-		models.ProfileData{
-			Login:     body.Login,
-			FirstName: newProfileFirstName,
-			LastName:  newProfileLastName,
-		})
+		// Запись новых данных профиля
+		newVersion, err = tx.EditProfile(login, newProfileData, currentVersion, authorizedLogin(ctx))
+		return err
+	})
 	if err != nil {
-		log.Printf("request completed (status %d) with error: %s", fiber.StatusOK, err.Error())
+		logTransactionError(err)
 		return err
 	}
+	ctx.Set(fiber.HeaderETag, profileETag(newVersion))
 	log.Printf("request completed (status %d)", fiber.StatusOK)
 	return ctx.SendString("request completed")
 }
@@ -169,7 +208,7 @@ func EditProfileRequest(ctx *fiber.Ctx) error {
 // @Security BasicAuth
 // @Description Запрос на изменение пароля пользователя, доступно всем пользователям при редактировании своих профилей и доступно редактирование всех профилей администраторам
 // @Accept json
-// @Param input body models.NewPasswordForProfile true "логин профиля, для каторого меняется пароль и новый пароль"
+// @Param input body models.NewPasswordForProfile true "логин или идентификатор профиля, для каторого меняется пароль, и новый пароль"
 // @Success      200  {string}  string	"request completed"
 // @Failure      404  {string}  string	"no such profile"
 // @Router /password [patch]
@@ -183,27 +222,73 @@ func ChangePasswordRequest(ctx *fiber.Ctx) error {
 		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
+	// Профиль ищется по логину или идентификатору (по старому логину переименованного профиля меняется пароль профиля с новым логином)
+	body.Login, err = profileLogin(ctx, models.LoginData{Login: body.Login, ID: body.ID})
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
 
 	// Проверка прав достува (является ли авторизованный пользователь администратором или пользователь редактирует свой профиль)
-	authorizedUserLogin := ctx.Locals("login").(string)
-	if !myProfilesDB.DB.IsAdmin(authorizedUserLogin) && authorizedUserLogin != body.Login {
-		if !myProfilesDB.DB.IsAdmin(authorizedUserLogin) {
+	if !isTenantAdmin(ctx) && !isSelf(ctx, body.Login) {
+		if !isTenantAdmin(ctx) {
 			log.Println(userIsNotAdminErr.Error())
 			return userIsNotAdminErr
 		}
-		if authorizedUserLogin != body.Login {
+		if !isSelf(ctx, body.Login) {
 			log.Println(canNotEditProfileErr.Error())
 			return canNotEditPasswordErr
 		}
 	}
 
+	// Проверка пароля по политике паролей
+	err = checkPasswordPolicy(body.NewPassword)
+	if err != nil {
+		return err
+	}
+
 	// Изменение пароля пользователя
-	err = myProfilesDB.DB.ChangePassword(
+	err = requestTenant(ctx).ChangePassword(
 		body.Login,
 		body.NewPassword,
 	)
 	if err != nil {
-		log.Printf("request completed (status %d) with error: %s", fiber.StatusOK, err.Error())
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Rename profile
+// @Security BasicAuth
+// @Description Запрос на изменение логина профиля, доступно всем пользователям для своих профилей и администраторам для всех профилей. Права администратора и членство в группах переносятся на новый логин, старый логин остается псевдонимом нового на время, заданное в конфиге
+// @Accept json
+// @Param input body models.LoginRenameData true "текущий и новый логины профиля"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such profile"
+// @Router /profile/login [patch]
+func RenameProfileRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "rename profile")
+
+	// Чтение тела запроса
+	var body models.LoginRenameData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Проверка прав достува (является ли авторизованный пользователь администратором или пользователь переименовывает свой профиль)
+	if !isTenantAdmin(ctx) && !isSelf(ctx, body.Login) {
+		log.Println(canNotEditProfileErr.Error())
+		return canNotEditProfileErr
+	}
+
+	// Переименование профиля
+	err = requestTenant(ctx).RenameProfile(body.Login, body.NewLogin, authorizedLogin(ctx))
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
 		return err
 	}
 	log.Printf("request completed (status %d)", fiber.StatusOK)
@@ -212,18 +297,20 @@ func ChangePasswordRequest(ctx *fiber.Ctx) error {
 
 // @Summary Remove profile
 // @Security BasicAuth
-// @Description Запрос удаление профиля, доступно только администраторам, нельзя удалять свой профиль
+// @Description Запрос удаление профиля, доступно только администраторам, нельзя удалять свой профиль; удаленный профиль переносится в корзину, откуда его можно восстановить до истечения времени хранения, с параметром hard=true профиль удаляется окончательно
 // @Accept json
-// @Param input body models.LoginData true "логин удаляемого профиля"
+// @Param input body models.LoginData true "логин или идентификатор удаляемого профиля"
+// @Param hard query bool false "true - профиль удаляется окончательно вместе с паролем, минуя корзину"
+// @Param If-Match header string false "ETag профиля, полученный запросом /profile [get]: профиль удаляется, только если он не был изменен"
 // @Success      200  {string}  string	"request completed"
 // @Failure      404  {string}  string	"no such profile"
+// @Failure      412  {string}  string	"profile version does not match"
 // @Router /profile [delete]
 func RemoveProfileRequest(ctx *fiber.Ctx) error {
 	log.Printf("\"%s\" request received", "remove profile")
 
-	// Проверка, является ли авторизованный пользователь администратором
-	authorizedUserLogin := ctx.Locals("login").(string)
-	if !myProfilesDB.DB.IsAdmin(authorizedUserLogin) {
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
 		log.Println(userIsNotAdminErr.Error())
 		return userIsNotAdminErr
 	}
@@ -235,19 +322,32 @@ func RemoveProfileRequest(ctx *fiber.Ctx) error {
 		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
+	// Профиль ищется по логину или идентификатору
+	body.Login, err = profileLogin(ctx, body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
 
 	// Проверка профиля: нельзя удалять свой профиль
-	if authorizedUserLogin == body.Login {
+	if isSelf(ctx, body.Login) {
 		log.Println(canNotRemoveOwnProfileErr.Error())
 		return canNotRemoveOwnProfileErr
 	}
 
-	// Удаление профиля
-	err = myProfilesDB.DB.RemoveProfile(
-		body.Login,
-	)
+	// Удаление профиля (перенос в корзину или окончательное удаление) в одной транзакции с проверкой условия If-Match
+	err = requestTenant(ctx).Update(func(tx *myProfilesDB.Tx) error {
+		_, currentVersion, err := tx.GetProfileData(body.Login)
+		if err != nil {
+			return err
+		}
+		if !ifMatchProfile(ctx, currentVersion) {
+			return preconditionFailed(fmt.Errorf("%w: current version is %d", myProfilesDB.ProfileVersionMismatchErr, currentVersion))
+		}
+		return tx.RemoveProfile(body.Login, currentVersion, ctx.QueryBool("hard"), authorizedLogin(ctx))
+	})
 	if err != nil {
-		log.Printf("request completed (status %d) with error: %s", fiber.StatusOK, err.Error())
+		logTransactionError(err)
 		return err
 	}
 	log.Printf("request completed (status %d)", fiber.StatusOK)
@@ -265,8 +365,7 @@ func RemoveProfileRequest(ctx *fiber.Ctx) error {
 func AddAdminRequest(ctx *fiber.Ctx) error {
 	log.Printf("\"%s\" request received", "add admin")
 
-	authorizedUserLogin := ctx.Locals("login").(string)
-	if !myProfilesDB.DB.IsAdmin(authorizedUserLogin) {
+	if !isTenantAdmin(ctx) {
 		log.Println(userIsNotAdminErr.Error())
 		return userIsNotAdminErr
 	}
@@ -280,11 +379,11 @@ func AddAdminRequest(ctx *fiber.Ctx) error {
 	}
 
 	// Добавление администратора
-	err = myProfilesDB.DB.AddAdmin(
+	err = requestTenant(ctx).AddAdmin(
 		body.Login,
 	)
 	if err != nil {
-		log.Printf("request completed (status %d) with error: %s", fiber.StatusOK, err.Error())
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
 		return err
 	}
 	log.Printf("request completed (status %d)", fiber.StatusOK)
@@ -302,8 +401,7 @@ func AddAdminRequest(ctx *fiber.Ctx) error {
 func DropAdminRequest(ctx *fiber.Ctx) error {
 	log.Printf("\"%s\" request received", "drop admin")
 
-	authorizedUserLogin := ctx.Locals("login").(string)
-	if !myProfilesDB.DB.IsAdmin(authorizedUserLogin) {
+	if !isTenantAdmin(ctx) {
 		log.Println(userIsNotAdminErr.Error())
 		return userIsNotAdminErr
 	}
@@ -317,17 +415,17 @@ func DropAdminRequest(ctx *fiber.Ctx) error {
 	}
 
 	// Проверка профиля: нельзя удалять свой профиль из администраторов
-	if authorizedUserLogin == body.Login {
+	if isSelf(ctx, body.Login) {
 		log.Println(canNotRemoveOwnProfileFromAdminsErr.Error())
 		return canNotRemoveOwnProfileFromAdminsErr
 	}
 
 	// Добавление администратора
-	err = myProfilesDB.DB.DropAdmin(
+	err = requestTenant(ctx).DropAdmin(
 		body.Login,
 	)
 	if err != nil {
-		log.Printf("request completed (status %d) with error: %s", fiber.StatusOK, err.Error())
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
 		return err
 	}
 	log.Printf("request completed (status %d)", fiber.StatusOK)
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ZotovSergey/authenticationservice/internal/authorizers"
+)
+
+// Пароль меняется по идентификатору профиля и по старому логину переименованного профиля, пользователь может менять свой пароль по старому логину
+func TestChangePasswordResolvesProfile(t *testing.T) {
+	platform := raiseTestDB(t)
+	id := addTestProfile(t, platform, "bob", "Passw0rd!1")
+	err := platform.RenameProfile("bob", "robert", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := newTestAPI(t)
+	app.Patch("/password", ChangePasswordRequest)
+
+	requests := []struct {
+		login       string
+		password    string
+		body        string
+		newPassword string
+	}{
+		{"admin", "admin", `{"id":"` + id + `","newPassword":"Passw0rd!2"}`, "Passw0rd!2"},
+		{"admin", "admin", `{"login":"bob","newPassword":"Passw0rd!3"}`, "Passw0rd!3"},
+		{"robert", "Passw0rd!3", `{"login":"bob","newPassword":"Passw0rd!4"}`, "Passw0rd!4"},
+	}
+	for _, request := range requests {
+		status, body := sendTestRequest(t, app, http.MethodPatch, "/password", request.login, request.password, request.body)
+		if status != http.StatusOK || body != "request completed" {
+			t.Fatalf("password is not changed by %s: %d %s", request.body, status, body)
+		}
+		if !authorizers.CommonUsersAuthorizer(platform.Name(), "robert", request.newPassword) {
+			t.Fatalf("new password is not set by %s", request.body)
+		}
+	}
+
+	// Пользователь не может менять пароль другого профиля, указанного идентификатором
+	adminData, _, err := platform.GetProfileData("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, _ := sendTestRequest(t, app, http.MethodPatch, "/password", "robert", "Passw0rd!4", `{"id":"`+adminData.ID+`","newPassword":"Passw0rd!5"}`)
+	if status == http.StatusOK || authorizers.CommonUsersAuthorizer(platform.Name(), "admin", "Passw0rd!5") {
+		t.Fatalf("user changed password of another profile: %d", status)
+	}
+}
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"errors"
+
+	"github.com/gofiber/fiber/v2"
 )
 
 // Сообщения об ошибках при работе с
@@ -11,3 +13,42 @@ var canNotEditPasswordErr error = errors.New("access error: authorized user can
 var canNotRemoveOwnProfileErr error = errors.New("access error: user tried to remove own profile")
 var canNotRemoveOwnProfileFromAdminsErr error = errors.New("access error: user tried to remove own profile from admins list")
 var unauthorizedRequestErr error = errors.New("access denied: attempt to authorize unauthorized user")
+var canNotLoseOwnAdminRightsErr error = errors.New("access error: user tried to remove own admin rights granted by group")
+var userIsNotPlatformAdminErr error = errors.New("access error: authorized user is not a platform admin")
+var canNotChangeOwnAccountStatusErr error = errors.New("access error: user tried to change status of own account")
+var canNotRemoveOwnProfileFromPlatformAdminsErr error = errors.New("access error: user tried to remove own profile from platform admins list")
+var unsupportedMediaTypeErr error = errors.New("unsupported media type: application/json, application/merge-patch+json or application/json-patch+json expected")
+var noPatchedLoginErr error = errors.New("login of edited profile is not specified")
+var canNotPatchLoginErr error = errors.New("login and id of profile can not be changed by patch")
+var canNotPatchEmailVerificationErr error = errors.New("verifiedAt and pendingEmail of profile can not be changed by patch")
+var invalidPatchedProfileErr error = errors.New("patched profile is invalid")
+var hiddenFieldPatchErr error = errors.New("field is hidden from user and can not be patched")
+var registrationDisabledErr error = errors.New("self-registration is disabled")
+var registrationRateLimitErr error = errors.New("too many registrations from this address, try again later")
+var weakPasswordErr error = errors.New("password does not satisfy password policy")
+var invalidHistoryPointErr error = errors.New("positive version or timestamp of profile history is required")
+var unknownImportModeErr error = errors.New("import mode must be create or upsert")
+var invalidBatchErr error = errors.New("invalid batch")
+var invalidEventSeqErr error = errors.New("event sequence number must be a non-negative integer")
+var invalidReplicationPositionErr error = errors.New("replication log position must be a non-negative integer")
+var replicationNotLeaderErr error = errors.New("replication data is served only by the leader")
+var followerNotReadyErr error = errors.New("follower has not loaded leader snapshot yet")
+var followerReadOnlyErr error = errors.New("follower is read-only, send writes to the leader")
+var clusterNotReadyErr error = errors.New("cluster node has not received data from the cluster log yet")
+var clusterNoLeaderErr error = errors.New("leader is unknown, try again after leader election")
+var clusterDisabledErr error = errors.New("cluster is disabled")
+
+/*
+Статус ответа на запрос, завершенный ошибкой: статус ошибки fiber или 500 (статус, который обработчик ошибок fiber по умолчанию отдает для остальных ошибок)
+
+:param err error: ошибка обработчика запроса
+
+:return: статус ответа
+*/
+func errorStatus(err error) int {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return fiber.StatusInternalServerError
+}
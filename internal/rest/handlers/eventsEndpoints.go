@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Период отправки комментариев в поток событий, пока новых событий нет (не дает закрыть простаивающее соединение)
+const eventsKeepAlivePeriod = 15 * time.Second
+
+/*
+Чтение номера последнего полученного события из параметра запроса since или заголовка Last-Event-ID (переподключение клиента Server-Sent Events)
+
+:param ctx *fiber.Ctx: контекст запроса
+
+:return: номер последнего полученного события (-1 - номер не задан, выводятся только новые события) или ошибка, если номер некорректен
+*/
+func eventsSinceQuery(ctx *fiber.Ctx) (int64, error) {
+	since := ctx.Query("since")
+	if since == "" {
+		since = ctx.Get("Last-Event-ID")
+	}
+	if since == "" {
+		return -1, nil
+	}
+	seq, err := strconv.ParseInt(since, 10, 64)
+	if err != nil || seq < 0 {
+		return 0, badRequest(invalidEventSeqErr)
+	}
+	return seq, nil
+}
+
+/*
+Вывод события изменения профиля в поток в формате Server-Sent Events
+
+:param w *bufio.Writer: поток ответа
+:param event models.ChangeEvent: событие
+*/
+func writeChangeEvent(w *bufio.Writer, event models.ChangeEvent) {
+	data, _ := json.Marshal(event)
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data)
+}
+
+// @Summary Watch profile changes
+// @Security BasicAuth
+// @Description Запрос на получение потока событий изменений профилей тенанта (Server-Sent Events): создание, изменение и удаление профилей, выдача и отзыв прав администратора, смена пароля (без пароля и хэша), доступно только администраторам.
+// @Description Сначала выводятся хранимые события с номерами больше since (или Last-Event-ID), затем новые события по мере фиксации изменений; без номера выводятся только новые события.
+// @Description Если события после заданного номера уже не хранятся, возвращается 410 - клиенту нужно заново прочитать профили и подключиться без номера
+// @Produce text/event-stream
+// @Param since query int false "номер последнего полученного события"
+// @Param Last-Event-ID header int false "номер последнего полученного события (используется, если since не задан)"
+// @Success      200  {string}  string	"поток событий"
+// @Failure      400  {string}  string	"event sequence number must be a non-negative integer"
+// @Failure      410  {string}  string	"change events are no longer retained"
+// @Router /v1/events [get]
+func EventsRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "watch profile changes")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение номера последнего полученного события и проверка, хранятся ли следующие события
+	since, err := eventsSinceQuery(ctx)
+	if err != nil {
+		return err
+	}
+	tenantName := requestTenant(ctx).Name()
+	events, lastSeq, changed, err := requestTenant(ctx).WatchChanges(since)
+	if errors.Is(err, myProfilesDB.ChangeLogTruncatedErr) {
+		return fiber.NewError(fiber.StatusGone, err.Error())
+	}
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+
+	// Вывод событий потоком, пока клиент не отключится
+	ctx.Set(fiber.HeaderContentType, "text/event-stream")
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	ctx.Set(fiber.HeaderConnection, "keep-alive")
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		keepAlive := time.NewTicker(eventsKeepAlivePeriod)
+		defer keepAlive.Stop()
+		for {
+			for _, event := range events {
+				writeChangeEvent(w, event)
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+
+			// Ожидание фиксации следующих изменений
+			select {
+			case <-changed:
+			case <-keepAlive.C:
+				fmt.Fprint(w, ": keepalive\n\n")
+				events = nil
+				continue
+			}
+
+			// Чтение новых событий (тенант мог быть удален или заменен при восстановлении из резервной копии)
+			tenant, err := myProfilesDB.DB.GetTenant(tenantName)
+			if err != nil {
+				return
+			}
+			events, lastSeq, changed, err = tenant.WatchChanges(lastSeq)
+			if err != nil {
+				fmt.Fprintf(w, "event: error\ndata: %s\n\n", err.Error())
+				w.Flush()
+				return
+			}
+		}
+	})
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return nil
+}
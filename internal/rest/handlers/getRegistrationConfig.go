@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+)
+
+// Структура конфигурации самостоятельной регистрации пользователей
+type registrationConfig struct {
+	Enabled                  bool  `json:"enabled"`                  // самостоятельная регистрация разрешена
+	RequireEmailVerification bool  `json:"requireEmailVerification"` // для активации учетной записи требуется подтверждение адреса электронной почты
+	RequireApproval          bool  `json:"requireApproval"`          // для активации учетной записи требуется одобрение администратора
+	RateLimit                int   `json:"rateLimit"`                // максимальное число запросов на регистрацию с одного IP-адреса за период (0 - не ограничено)
+	RateLimitWindow          int64 `json:"rateLimitWindow"`          // период ограничения числа запросов на регистрацию в секундах
+}
+
+// Конфигурация самостоятельной регистрации (читается из конфига при развертывании API)
+var registrationSettings registrationConfig
+
+/*
+Чтение конфигурации самостоятельной регистрации из конфига "../../configs/registrationConfig.json"
+
+:return: ошибка, если конфиг не удалось прочитать или период ограничения числа запросов не положителен
+*/
+func LoadRegistrationConfig() error {
+	configFilePath := "../../configs/registrationConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return errors.New("fail to read registration config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	json.Unmarshal(byteValue, &registrationSettings)
+	if registrationSettings.RateLimit > 0 && registrationSettings.RateLimitWindow <= 0 {
+		return errors.New("registration rate limit window must be positive in registration config " + configFilePath)
+	}
+
+	return nil
+}
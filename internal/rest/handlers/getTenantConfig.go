@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+)
+
+// Структура конфигурации определения тенанта запроса
+type tenantConfig struct {
+	Header     string `json:"header"`     // заголовок, в котором передается название тенанта (пустая строка - не используется)
+	BaseDomain string `json:"baseDomain"` // базовый домен, поддомен которого является названием тенанта (пустая строка - не используется)
+}
+
+/*
+Чтение конфигурации определения тенанта из конфига "../../configs/tenantConfig.json"
+
+:return: конфигурация определения тенанта или ошибка, если конфиг не удалось прочитать
+*/
+func getTenantConfig() (tenantConfig, error) {
+	configFilePath := "../../configs/tenantConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return tenantConfig{}, errors.New("fail to read tenant config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var config tenantConfig
+	json.Unmarshal(byteValue, &config)
+
+	return config, nil
+}
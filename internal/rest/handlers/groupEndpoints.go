@@ -0,0 +1,416 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// @Summary Get group data
+// @Security BasicAuth
+// @Description Запрос на вывод участников и вложенных групп группы по названию, доступно всем пользователям (запрос не работает со страницы swagger из браузера, но работает через postman или insomnia)
+// @Accept json
+// @Produce json
+// @Param input body models.GroupNameData true "название получаемой группы"
+// @Success      200  {json}	json	model.GroupData
+// @Failure      404  {string}  string	"no such group"
+// @Router /group [get]
+func GetGroupDataRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "get group data")
+
+	// Чтение тела запроса
+	var body models.GroupNameData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Получение группы из БД
+	groupData, err := requestTenant(ctx).GetGroupData(body.Name)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(groupData)
+}
+
+// @Summary Get all groups
+// @Security BasicAuth
+// @Description Запрос на вывод списка названий всех групп, доступно всем пользователям
+// @Produce json
+// @Success      200  {json}  json	"groups"
+// @Router /groups [get]
+func GetAllGroupsRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "get all groups")
+
+	// Получение списка групп из БД
+	groupsList := requestTenant(ctx).GetAllGroups()
+
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(groupsList)
+}
+
+// @Summary Get effective groups
+// @Security BasicAuth
+// @Description Запрос на вывод всех групп, в которые профиль входит непосредственно или через вложенные группы, доступно всем пользователям (запрос не работает со страницы swagger из браузера, но работает через postman или insomnia)
+// @Accept json
+// @Produce json
+// @Param input body models.LoginData true "логин профиля"
+// @Success      200  {json}  json	"groups"
+// @Failure      404  {string}  string	"no such profile"
+// @Router /membership [get]
+func GetEffectiveGroupsRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "get effective groups")
+
+	// Чтение тела запроса
+	var body models.LoginData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Проверка наличия профиля
+	_, _, err = requestTenant(ctx).GetProfileData(body.Login)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+
+	// Получение групп профиля из БД
+	groupsList := requestTenant(ctx).GetEffectiveGroups(body.Login)
+
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(groupsList)
+}
+
+// @Summary Add group
+// @Security BasicAuth
+// @Description Запрос на создание новой пустой группы, доступно только администраторам
+// @Accept json
+// @Param input body models.GroupNameData true "название новой группы"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"such group is already exists"
+// @Router /group [post]
+func AddGroupRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "add group")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.GroupNameData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Создание группы
+	err = requestTenant(ctx).AddGroup(body.Name)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Rename group
+// @Security BasicAuth
+// @Description Запрос на переименование группы, доступно только администраторам
+// @Accept json
+// @Param input body models.GroupRenameData true "текущее и новое название группы"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such group"
+// @Router /group [patch]
+func RenameGroupRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "rename group")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.GroupRenameData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Переименование группы
+	err = requestTenant(ctx).RenameGroup(body.Name, body.NewName)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Remove group
+// @Security BasicAuth
+// @Description Запрос на удаление группы (вложенные группы и профили не удаляются), доступно только администраторам, нельзя удалять группу, через которую пользователь получил права администратора
+// @Accept json
+// @Param input body models.GroupNameData true "название удаляемой группы"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such group"
+// @Router /group [delete]
+func RemoveGroupRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "remove group")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.GroupNameData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Проверка группы: нельзя лишать себя прав администратора
+	if !isPlatformAdmin(ctx) && !requestTenant(ctx).IsAdminWithoutGroup(authorizedLogin(ctx), body.Name) {
+		log.Println(canNotLoseOwnAdminRightsErr.Error())
+		return canNotLoseOwnAdminRightsErr
+	}
+
+	// Удаление группы
+	err = requestTenant(ctx).RemoveGroup(body.Name)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Add group member
+// @Security BasicAuth
+// @Description Запрос на добавление профиля в группу, доступно только администраторам
+// @Accept json
+// @Param input body models.GroupMemberData true "название группы и логин добавляемого профиля"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such group"
+// @Router /group/member [post]
+func AddGroupMemberRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "add group member")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.GroupMemberData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Добавление профиля в группу
+	err = requestTenant(ctx).AddGroupMember(body.Name, body.Login)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Remove group member
+// @Security BasicAuth
+// @Description Запрос на удаление профиля из группы, доступно только администраторам
+// @Accept json
+// @Param input body models.GroupMemberData true "название группы и логин удаляемого из нее профиля"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such group"
+// @Router /group/member [delete]
+func RemoveGroupMemberRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "remove group member")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.GroupMemberData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Удаление профиля из группы
+	err = requestTenant(ctx).RemoveGroupMember(body.Name, body.Login)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Add subgroup
+// @Security BasicAuth
+// @Description Запрос на вложение группы в другую группу, доступно только администраторам, вложение не должно образовывать цикл
+// @Accept json
+// @Param input body models.SubgroupData true "название родительской группы и вкладываемой группы"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"nesting groups would create a cycle"
+// @Router /group/subgroup [post]
+func AddSubgroupRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "add subgroup")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.SubgroupData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Вложение группы
+	err = requestTenant(ctx).AddSubgroup(body.Name, body.Subgroup)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Remove subgroup
+// @Security BasicAuth
+// @Description Запрос на удаление вложенной группы из родительской группы, доступно только администраторам
+// @Accept json
+// @Param input body models.SubgroupData true "название родительской группы и вложенной группы"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such group"
+// @Router /group/subgroup [delete]
+func RemoveSubgroupRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "remove subgroup")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.SubgroupData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Удаление вложенной группы
+	err = requestTenant(ctx).RemoveSubgroup(body.Name, body.Subgroup)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Add admin group
+// @Security BasicAuth
+// @Description Запрос на добавление группы в список групп администраторов (все участники группы и вложенных групп получают права администратора), доступно только администраторам
+// @Accept json
+// @Param input body models.GroupNameData true "название группы, добавляемой к списку групп администраторов"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such group"
+// @Router /admin/group [post]
+func AddAdminGroupRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "add admin group")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.GroupNameData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Добавление группы администраторов
+	err = requestTenant(ctx).AddAdminGroup(body.Name)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Drop admin group
+// @Security BasicAuth
+// @Description Запрос на удаление группы из списка групп администраторов, доступно только администраторам, нельзя удалять группу, через которую пользователь получил права администратора
+// @Accept json
+// @Param input body models.GroupNameData true "название группы, удаляемой из списка групп администраторов"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such group"
+// @Router /admin/group [delete]
+func DropAdminGroupRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "drop admin group")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.GroupNameData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Проверка группы: нельзя лишать себя прав администратора
+	if !isPlatformAdmin(ctx) && !requestTenant(ctx).IsAdminWithoutAdminGroup(authorizedLogin(ctx), body.Name) {
+		log.Println(canNotLoseOwnAdminRightsErr.Error())
+		return canNotLoseOwnAdminRightsErr
+	}
+
+	// Удаление группы администраторов
+	err = requestTenant(ctx).DropAdminGroup(body.Name)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
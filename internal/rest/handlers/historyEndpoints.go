@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Получение логина профиля из пути запроса /v1/profiles/{login}/...
+
+:param ctx *fiber.Ctx: контекст запроса
+
+:return: текущий логин профиля (старый логин переименованного профиля заменяется новым) или ошибка, если логин в пути закодирован некорректно
+*/
+func pathProfileLogin(ctx *fiber.Ctx) (string, error) {
+	login, err := url.PathUnescape(ctx.Params("login"))
+	if err != nil {
+		return "", badRequest(err)
+	}
+	return requestTenant(ctx).ResolveLogin(login), nil
+}
+
+/*
+Чтение версии профиля или времени из параметров запроса version и timestamp
+
+:param ctx *fiber.Ctx: контекст запроса
+
+:return: версия профиля и время (unix-время в секундах) или ошибка, если параметры некорректны или ни один из них не задан
+*/
+func historyPointQuery(ctx *fiber.Ctx) (models.ProfileRevertData, error) {
+	var point models.ProfileRevertData
+	var err error
+	if version := ctx.Query("version"); version != "" {
+		point.Version, err = strconv.ParseInt(version, 10, 64)
+		if err != nil || point.Version <= 0 {
+			return point, badRequest(invalidHistoryPointErr)
+		}
+	}
+	if timestamp := ctx.Query("timestamp"); timestamp != "" {
+		point.Timestamp, err = strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return point, badRequest(invalidHistoryPointErr)
+		}
+	}
+	if point.Version == 0 && point.Timestamp == 0 {
+		return point, badRequest(invalidHistoryPointErr)
+	}
+	return point, nil
+}
+
+// @Summary Get profile history
+// @Security BasicAuth
+// @Description Запрос на вывод истории изменений профиля: версия, время, автор, действие и изменения полей относительно предыдущей записи, доступно всем пользователям; изменения полей, скрытых от пользователя настройками видимости тенанта, не выводятся
+// @Produce json
+// @Param login path string true "логин профиля"
+// @Success      200  {json}  json	model.ProfileHistoryEntry
+// @Failure      404  {string}  string	"no such profile"
+// @Router /v1/profiles/{login}/history [get]
+func GetProfileHistoryRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "get profile history")
+
+	// Получение истории профиля из БД
+	login, err := pathProfileLogin(ctx)
+	if err != nil {
+		return err
+	}
+	history, err := requestTenant(ctx).GetProfileHistory(login)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+
+	// Замена данных профиля в записях истории изменениями видимых пользователю полей
+	var previousProfileData models.ProfileData
+	for i, entry := range history {
+		profileData := visibleProfileData(ctx, *entry.Profile)
+		history[i].Changes = myProfilesDB.ProfileChanges(previousProfileData, profileData)
+		history[i].Profile = nil
+		previousProfileData = profileData
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(history)
+}
+
+// @Summary Get profile as of version
+// @Security BasicAuth
+// @Description Запрос на вывод данных профиля на момент одной из предыдущих версий или на заданное время, доступно всем пользователям; поля, скрытые от пользователя настройками видимости тенанта, не выводятся
+// @Produce json
+// @Param login path string true "логин профиля"
+// @Param version query int false "версия профиля из истории изменений"
+// @Param timestamp query int false "время (unix-время в секундах), используется, если версия не задана"
+// @Success      200  {json}  json	model.ProfileHistoryEntry
+// @Failure      404  {string}  string	"no such version in profile history"
+// @Router /v1/profiles/{login}/history/as-of [get]
+func GetProfileAsOfRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "get profile as of version")
+
+	// Чтение версии или времени
+	point, err := historyPointQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Получение записи истории профиля из БД
+	login, err := pathProfileLogin(ctx)
+	if err != nil {
+		return err
+	}
+	entry, err := requestTenant(ctx).GetProfileAsOf(login, point.Version, point.Timestamp)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	profileData := visibleProfileData(ctx, *entry.Profile)
+	entry.Profile = &profileData
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(entry)
+}
+
+// @Summary Revert profile
+// @Security BasicAuth
+// @Description Запрос на возврат данных профиля (имени, фамилии, адреса электронной почты и атрибутов) к одной из предыдущих версий или к данным на заданное время, доступно всем пользователям для своих профилей и администраторам для всех профилей; логин профиля не меняется, возврат записывается в историю как новое изменение
+// @Accept json
+// @Param login path string true "логин профиля"
+// @Param input body models.ProfileRevertData true "версия профиля или время (unix-время в секундах)"
+// @Param If-Match header string false "ETag профиля, полученный запросом /profile [get]: профиль изменяется, только если он не был изменен"
+// @Success      200  {string}  string	"request completed"
+// @Header       200  {string}  ETag	"новая версия профиля"
+// @Failure      404  {string}  string	"no such version in profile history"
+// @Failure      412  {string}  string	"profile version does not match"
+// @Router /v1/profiles/{login}/revert [post]
+func RevertProfileRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "revert profile")
+
+	// Чтение тела запроса
+	var body models.ProfileRevertData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return badRequest(err)
+	}
+	if body.Version < 0 || (body.Version == 0 && body.Timestamp == 0) {
+		return badRequest(invalidHistoryPointErr)
+	}
+	login, err := pathProfileLogin(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Проверка прав доступа (является ли авторизованный пользователь администратором или пользователь изменяет свой профиль)
+	if !isTenantAdmin(ctx) && !isSelf(ctx, login) {
+		log.Println(canNotEditProfileErr.Error())
+		return canNotEditProfileErr
+	}
+
+	// Возврат данных профиля в одной транзакции с проверкой условия If-Match
+	var newVersion int64
+	err = requestTenant(ctx).Update(func(tx *myProfilesDB.Tx) error {
+		_, currentVersion, err := tx.GetProfileData(login)
+		if err != nil {
+			return err
+		}
+		if !ifMatchProfile(ctx, currentVersion) {
+			return preconditionFailed(fmt.Errorf("%w: current version is %d", myProfilesDB.ProfileVersionMismatchErr, currentVersion))
+		}
+		newVersion, err = tx.RevertProfile(login, body.Version, body.Timestamp, authorizedLogin(ctx), currentVersion)
+		return err
+	})
+	if err != nil {
+		logTransactionError(err)
+		return err
+	}
+	ctx.Set(fiber.HeaderETag, profileETag(newVersion))
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"log"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// @Summary Create invitation
+// @Security BasicAuth
+// @Description Запрос на создание приглашения: создается профиль, ожидающий активации, без пароля с правами администратора и членством в группах из приглашения, в ответе возвращается одноразовый токен, по которому приглашенный пользователь задает пароль (токен выдается только один раз), доступно только администраторам
+// @Accept json
+// @Produce json
+// @Param input body models.InvitationData true "логин, адрес электронной почты, права администратора, группы и время истечения приглашения"
+// @Success      200  {json}  json	model.InvitationResponse
+// @Failure      404  {string}  string	"profile with such login already exists"
+// @Router /invitation [post]
+func CreateInvitationRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "create invitation")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.InvitationData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	// Создание приглашения
+	tenant := requestTenant(ctx)
+	token, invitation, err := tenant.CreateInvitation(body, authorizedLogin(ctx))
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(models.InvitationResponse{
+		Invitation: invitation,
+		Token:      token,
+		AcceptPath: tenantPathPrefix + url.PathEscape(tenant.Name()) + "/invitations/" + token + "/accept",
+	})
+}
+
+// @Summary Get invitations
+// @Security BasicAuth
+// @Description Запрос на вывод приглашений тенанта, которые еще не приняты (токены не выводятся), доступно только администраторам
+// @Produce json
+// @Success      200  {json}  json	model.Invitation
+// @Router /invitations [get]
+func GetInvitationsRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "get invitations")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Получение списка приглашений из БД
+	invitations := requestTenant(ctx).GetInvitations()
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(invitations)
+}
+
+// @Summary Revoke invitation
+// @Security BasicAuth
+// @Description Запрос на отзыв приглашения: токен приглашения перестает действовать, профиль приглашенного пользователя, если он еще не активирован, удаляется, доступно только администраторам
+// @Accept json
+// @Param input body models.InvitationIDData true "идентификатор приглашения"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such invitation or invitation expired"
+// @Router /invitation [delete]
+func RevokeInvitationRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "revoke invitation")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.InvitationIDData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	// Отзыв приглашения
+	err = requestTenant(ctx).RevokeInvitation(body.ID)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Accept invitation
+// @Description Запрос на принятие приглашения по одноразовому токену (без авторизации): приглашенный пользователь задает пароль, соответствующий политике паролей, и, при необходимости, имя и фамилию, учетная запись становится активной, токен перестает действовать
+// @Accept json
+// @Param token path string true "токен приглашения"
+// @Param input body models.AcceptInvitationData true "пароль, имя и фамилия"
+// @Success      200  {string}  string	"request completed"
+// @Failure      400  {string}  string	"password does not satisfy password policy"
+// @Failure      404  {string}  string	"no such invitation or invitation expired"
+// @Router /invitations/{token}/accept [post]
+func AcceptInvitationRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "accept invitation")
+
+	// Чтение тела запроса
+	var body models.AcceptInvitationData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	// Проверка пароля по политике паролей
+	err = checkPasswordPolicy(body.Password)
+	if err != nil {
+		return err
+	}
+
+	// Принятие приглашения
+	login, err := requestTenant(ctx).AcceptInvitation(ctx.Params("token"), body.Password, body.FirstName, body.LastName)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("invitation accepted by \"%s\"", login)
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+	"github.com/ZotovSergey/authenticationservice/internal/testutil"
+)
+
+// Тесты выполняются из временного каталога с конфигами репозитория
+func TestMain(m *testing.M) {
+	os.Exit(testutil.RunWithConfigs(m, "../../../configs", nil, LoadPasswordPolicy))
+}
+
+/*
+Поднятие БД с временным файлом базы данных по глобальному адресу DB (в БД есть тенант платформы с администратором admin/admin)
+
+:param t *testing.T: тест
+
+:return: тенант платформы
+*/
+func raiseTestDB(t *testing.T) *myProfilesDB.Tenant {
+	t.Helper()
+	db, err := myProfilesDB.OpenMyProfilesDB(filepath.Join(t.TempDir(), "db.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	myProfilesDB.DB = db
+	platform, err := db.GetTenant(db.PlatformTenant())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return platform
+}
+
+/*
+Добавление активного профиля в тенант
+
+:param t *testing.T: тест
+:param tenant *myProfilesDB.Tenant: тенант
+:param login string: логин профиля
+:param password string: пароль профиля
+
+:return: идентификатор профиля
+*/
+func addTestProfile(t *testing.T, tenant *myProfilesDB.Tenant, login string, password string) string {
+	t.Helper()
+	err := tenant.AddProfile(login, models.ProfileData{Login: login, FirstName: "Test", LastName: "User"}, password, models.AccountStatusActive, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	profileData, _, err := tenant.GetProfileData(login)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return profileData.ID
+}
+
+/*
+Построение API для теста: запросы проходят определение тенанта и basic auth, как в httprouter
+
+:param t *testing.T: тест
+
+:return: приложение, в котором тест регистрирует проверяемые обработчики
+*/
+func newTestAPI(t *testing.T) *fiber.App {
+	t.Helper()
+	tenantResolver, err := ResolveTenant()
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := fiber.New()
+	app.Use(tenantResolver)
+	app.Use(BasicAuth())
+	return app
+}
+
+/*
+Запрос к API от имени пользователя
+
+:param t *testing.T: тест
+:param app *fiber.App: API
+:param method string: метод запроса
+:param path string: путь запроса
+:param login string: логин пользователя
+:param password string: пароль пользователя
+:param body string: тело запроса в формате json (пустая строка - запрос без тела)
+
+:return: статус и тело ответа
+*/
+func sendTestRequest(t *testing.T, app *fiber.App, method string, path string, login string, password string, body string) (int, string) {
+	t.Helper()
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	request.SetBasicAuth(login, password)
+	response, err := app.Test(request, 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responseBody, _ := ioutil.ReadAll(response.Body)
+	return response.StatusCode, string(responseBody)
+}
@@ -2,25 +2,173 @@ package handlers
 
 import (
 	"log"
+	"net"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/basicauth"
+	"github.com/gofiber/fiber/v2/middleware/proxy"
 
 	"github.com/ZotovSergey/authenticationservice/internal/authorizers"
+	"github.com/ZotovSergey/authenticationservice/internal/cluster"
 	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/replication"
 )
 
+// Префикс пути запросов, в котором задается тенант: /tenants/{tenant}/...
+const tenantPathPrefix = "/tenants/"
+
+/*
+Функция возвращает функцию для middleware, определяющего тенант запроса; тенант берется (по убыванию приоритета)
+из пути запроса /tenants/{tenant}/..., из заголовка, заданного в конфиге, или из поддомена базового домена из конфига,
+если тенант не задан, запрос относится к тенанту платформы
+
+:return: функция middleware или ошибка, если конфиг тенантов не удалось прочитать
+*/
+func ResolveTenant() (func(*fiber.Ctx) error, error) {
+	config, err := getTenantConfig()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx *fiber.Ctx) error {
+		tenantName := resolveTenantName(ctx, config)
+		tenant, err := myProfilesDB.DB.GetTenant(tenantName)
+		if err != nil {
+			log.Printf("request error (status %d): %s \"%s\"", fiber.StatusNotFound, err.Error(), tenantName)
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		ctx.Locals("tenant", tenant)
+		return ctx.Next()
+	}, nil
+}
+
+// Заголовок, которым помечается запрос, перенаправленный лидеру (перенаправленный запрос повторно не перенаправляется)
+const forwardedWriteHeader = "X-Forwarded-Write"
+
+/*
+Функция возвращает функцию для middleware ведомого экземпляра и узла кластера, не являющегося лидером: пока данные БД не загружены с ведущего экземпляра
+или из журнала Raft, запросы отклоняются (кроме проверки готовности и документации); запросы чтения и интроспекция токенов обслуживаются локально,
+изменяющие запросы перенаправляются ведущему экземпляру (лидеру кластера) или отклоняются, если перенаправление выключено в конфиге или лидер неизвестен;
+при перенаправлении тенант, заданный заголовком или поддоменом, переносится в путь запроса /tenants/{tenant}/...
+
+:return: функция middleware или ошибка, если конфиг тенантов не удалось прочитать
+*/
+func FollowerGuard() (func(*fiber.Ctx) error, error) {
+	config, err := getTenantConfig()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx *fiber.Ctx) error {
+		if !replication.IsFollower() && !cluster.Enabled() {
+			return ctx.Next()
+		}
+		path := ctx.Path()
+		if path == "/ready" || strings.HasPrefix(path, "/swagger/") {
+			return ctx.Next()
+		}
+		// Данные экземпляра еще не загружены
+		if replication.IsFollower() && !replication.Bootstrapped() {
+			log.Printf("request error (status %d): %s", fiber.StatusServiceUnavailable, followerNotReadyErr.Error())
+			return fiber.NewError(fiber.StatusServiceUnavailable, followerNotReadyErr.Error())
+		}
+		if cluster.Enabled() && !cluster.Ready() {
+			log.Printf("request error (status %d): %s", fiber.StatusServiceUnavailable, clusterNotReadyErr.Error())
+			return fiber.NewError(fiber.StatusServiceUnavailable, clusterNotReadyErr.Error())
+		}
+		// Запросы, не изменяющие данные, и изменяющие запросы к лидеру кластера
+		switch ctx.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+			return ctx.Next()
+		}
+		if path == "/oauth/introspect" || cluster.IsLeader() {
+			return ctx.Next()
+		}
+		// Изменяющие запросы
+		leaderURL, forwardWrites := replication.LeaderURL(), replication.ForwardWrites()
+		if cluster.Enabled() {
+			leaderURL, forwardWrites = cluster.LeaderAPIURL(), cluster.ForwardWrites()
+		}
+		if !forwardWrites {
+			log.Printf("request error (status %d): %s", fiber.StatusServiceUnavailable, followerReadOnlyErr.Error())
+			return fiber.NewError(fiber.StatusServiceUnavailable, followerReadOnlyErr.Error())
+		}
+		if leaderURL == "" || ctx.Get(forwardedWriteHeader) != "" {
+			log.Printf("request error (status %d): %s", fiber.StatusServiceUnavailable, clusterNoLeaderErr.Error())
+			return fiber.NewError(fiber.StatusServiceUnavailable, clusterNoLeaderErr.Error())
+		}
+		target := ctx.OriginalURL()
+		if !strings.HasPrefix(path, tenantPathPrefix) && !strings.HasPrefix(path, "/oauth/") {
+			if tenantName := resolveTenantName(ctx, config); tenantName != myProfilesDB.DB.PlatformTenant() {
+				target = tenantPathPrefix + tenantName + target
+			}
+		}
+		log.Printf("write request %s %s is forwarded to leader", ctx.Method(), path)
+		ctx.Request().Header.Set(forwardedWriteHeader, "1")
+		return proxy.Do(ctx, leaderURL+target)
+	}, nil
+}
+
+/*
+Определение названия тенанта запроса
+
+:param ctx *fiber.Ctx: контекст запроса
+:param config tenantConfig: конфигурация определения тенанта
+
+:return: название тенанта запроса
+*/
+func resolveTenantName(ctx *fiber.Ctx, config tenantConfig) string {
+	// Тенант из пути запроса
+	path := ctx.Path()
+	if strings.HasPrefix(path, tenantPathPrefix) {
+		tenantName, _, _ := strings.Cut(strings.TrimPrefix(path, tenantPathPrefix), "/")
+		if tenantName != "" {
+			return tenantName
+		}
+	}
+	// Тенант из заголовка
+	if config.Header != "" {
+		if tenantName := ctx.Get(config.Header); tenantName != "" {
+			return tenantName
+		}
+	}
+	// Тенант из поддомена
+	if config.BaseDomain != "" {
+		host := ctx.Hostname()
+		if hostWithoutPort, _, err := net.SplitHostPort(host); err == nil {
+			host = hostWithoutPort
+		}
+		if subdomain := strings.TrimSuffix(host, "."+config.BaseDomain); subdomain != host && !strings.Contains(subdomain, ".") {
+			return subdomain
+		}
+	}
+	return myProfilesDB.DB.PlatformTenant()
+}
+
 /*
-Функция возвращает функцию для middleware basic auth с кастомным config
+Функция возвращает функцию для middleware basic auth с кастомным config; пользователь авторизуется в тенанте запроса,
+администраторы платформы могут авторизоваться в любом тенанте логином и паролем тенанта платформы
 */
 func BasicAuth() func(*fiber.Ctx) error {
-	return basicauth.New(basicauth.Config{
-		Users:      myProfilesDB.DB.GetPasswordsTab(),
-		Authorizer: authorizers.CommonUsersAuthorizer,
-		Unauthorized: func(ctx *fiber.Ctx) error {
-			log.Println(unauthorizedRequestErr.Error())
-			return ctx.SendString(unauthorizedRequestErr.Error())
-		},
-		ContextUsername: "login",
-	})
+	return func(ctx *fiber.Ctx) error {
+		tenantName := requestTenant(ctx).Name()
+		platformTenant := myProfilesDB.DB.PlatformTenant()
+		return basicauth.New(basicauth.Config{
+			Authorizer: func(login, password string) bool {
+				if authorizers.CommonUsersAuthorizer(tenantName, login, password) {
+					ctx.Locals("authTenant", tenantName)
+					return true
+				}
+				if tenantName != platformTenant && authorizers.PlatformAdminsAuthorizer(login, password) {
+					ctx.Locals("authTenant", platformTenant)
+					return true
+				}
+				return false
+			},
+			Unauthorized: func(ctx *fiber.Ctx) error {
+				log.Println(unauthorizedRequestErr.Error())
+				return ctx.SendString(unauthorizedRequestErr.Error())
+			},
+			ContextUsername: "login",
+		})(ctx)
+	}
 }
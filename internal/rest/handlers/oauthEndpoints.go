@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+
+	"github.com/ZotovSergey/authenticationservice/internal/authorizers"
+	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Отправка ответа с ошибкой OAuth 2.0 (RFC 6749, раздел 5.2)
+
+:param ctx *fiber.Ctx: контекст запроса
+:param status int: HTTP статус ответа
+:param code string: код ошибки OAuth 2.0
+:param description string: описание ошибки
+
+:return: ошибка отправки ответа
+*/
+func oauthError(ctx *fiber.Ctx, status int, code string, description string) error {
+	log.Printf("request error (status %d): %s: %s", status, code, description)
+	if status == fiber.StatusUnauthorized {
+		ctx.Set(fiber.HeaderWWWAuthenticate, "Basic realm=\"oauth\"")
+	}
+	return ctx.Status(status).JSON(models.OAuthErrorResponse{
+		Error:            code,
+		ErrorDescription: description,
+	})
+}
+
+/*
+Получение идентификатора и секрета клиента OAuth 2.0 из заголовка Authorization (client_secret_basic)
+или из тела запроса (client_secret_post) и авторизация клиента
+
+:param ctx *fiber.Ctx: контекст запроса
+
+:return: идентификатор клиента и true, если клиент авторизован, иначе - false
+*/
+func authorizeClient(ctx *fiber.Ctx) (string, bool) {
+	var clientID, secret string
+	authHeader := ctx.Get(fiber.HeaderAuthorization)
+	if strings.HasPrefix(authHeader, "Basic ") {
+		// Идентификатор и секрет в заголовке закодированы в application/x-www-form-urlencoded (RFC 6749, раздел 2.3.1)
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(authHeader, "Basic "))
+		if err != nil {
+			return "", false
+		}
+		encodedClientID, encodedSecret, ok := strings.Cut(string(raw), ":")
+		if !ok {
+			return "", false
+		}
+		clientID, err = url.QueryUnescape(encodedClientID)
+		if err != nil {
+			return "", false
+		}
+		secret, err = url.QueryUnescape(encodedSecret)
+		if err != nil {
+			return "", false
+		}
+	} else {
+		clientID = ctx.FormValue("client_id")
+		secret = ctx.FormValue("client_secret")
+	}
+	if clientID == "" {
+		return "", false
+	}
+	return clientID, authorizers.ClientsAuthorizer(clientID, secret)
+}
+
+// @Summary Issue access token
+// @Description Запрос на выдачу токена доступа по client_credentials grant (RFC 6749, раздел 4.4), клиент авторизуется через basic auth или параметрами client_id и client_secret
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "тип гранта, поддерживается только client_credentials"
+// @Param scope formData string false "запрашиваемые области доступа, разделенные пробелами (по умолчанию - все области клиента)"
+// @Success      200  {object}  models.OAuthTokenResponse
+// @Failure      400  {object}  models.OAuthErrorResponse
+// @Failure      401  {object}  models.OAuthErrorResponse
+// @Router /oauth/token [post]
+func TokenRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "oauth token")
+
+	// Ответы с токенами не должны кэшироваться (RFC 6749, раздел 5.1)
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	ctx.Set(fiber.HeaderPragma, "no-cache")
+
+	// Проверка типа гранта
+	grantType := ctx.FormValue("grant_type")
+	if grantType == "" {
+		return oauthError(ctx, fiber.StatusBadRequest, "invalid_request", "grant_type is required")
+	}
+	if grantType != "client_credentials" {
+		return oauthError(ctx, fiber.StatusBadRequest, "unsupported_grant_type", "only client_credentials grant is supported")
+	}
+
+	// Авторизация клиента
+	clientID, ok := authorizeClient(ctx)
+	if !ok {
+		return oauthError(ctx, fiber.StatusUnauthorized, "invalid_client", "client authentication failed")
+	}
+	clientData, err := myProfilesDB.DB.GetClientData(clientID)
+	if err != nil {
+		return oauthError(ctx, fiber.StatusUnauthorized, "invalid_client", err.Error())
+	}
+
+	// Проверка запрошенных областей доступа: все они должны быть зарегистрированы для клиента
+	requestedScopes := strings.Fields(ctx.FormValue("scope"))
+	if len(requestedScopes) == 0 {
+		requestedScopes = clientData.Scopes
+	}
+	allowedScopes := make(map[string]struct{}, len(clientData.Scopes))
+	for _, scope := range clientData.Scopes {
+		allowedScopes[scope] = struct{}{}
+	}
+	for _, scope := range requestedScopes {
+		if _, ok := allowedScopes[scope]; !ok {
+			return oauthError(ctx, fiber.StatusBadRequest, "invalid_scope", "scope \""+scope+"\" is not allowed for client")
+		}
+	}
+	// Значения из запроса fiber ссылаются на переиспользуемый буфер, поэтому перед записью в БД они копируются
+	scope := utils.CopyString(strings.Join(requestedScopes, " "))
+	clientID = utils.CopyString(clientID)
+
+	// Выдача токена
+	token, expiresIn, err := myProfilesDB.DB.IssueToken(clientID, scope)
+	if err != nil {
+		return oauthError(ctx, fiber.StatusInternalServerError, "server_error", err.Error())
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(models.OAuthTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   expiresIn,
+		Scope:       scope,
+	})
+}
+
+// @Summary Introspect access token
+// @Description Запрос на интроспекцию токена доступа (RFC 7662), доступно зарегистрированным клиентам OAuth 2.0
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "проверяемый токен"
+// @Param token_type_hint formData string false "тип токена, поддерживается только access_token"
+// @Success      200  {object}  models.OAuthIntrospectionResponse
+// @Failure      400  {object}  models.OAuthErrorResponse
+// @Failure      401  {object}  models.OAuthErrorResponse
+// @Router /oauth/introspect [post]
+func IntrospectRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "oauth introspect")
+
+	// Авторизация клиента
+	_, ok := authorizeClient(ctx)
+	if !ok {
+		return oauthError(ctx, fiber.StatusUnauthorized, "invalid_client", "client authentication failed")
+	}
+
+	token := ctx.FormValue("token")
+	if token == "" {
+		return oauthError(ctx, fiber.StatusBadRequest, "invalid_request", "token is required")
+	}
+
+	// Неизвестные, отозванные и просроченные токены неактивны (RFC 7662, раздел 2.2)
+	tokenData, ok := myProfilesDB.DB.GetTokenData(token)
+	if !ok {
+		log.Printf("request completed (status %d)", fiber.StatusOK)
+		return ctx.JSON(models.OAuthIntrospectionResponse{Active: false})
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(models.OAuthIntrospectionResponse{
+		Active:    true,
+		Scope:     tokenData.Scope,
+		ClientID:  tokenData.ClientID,
+		TokenType: "Bearer",
+		Exp:       tokenData.ExpiresAt,
+		Iat:       tokenData.IssuedAt,
+	})
+}
+
+// @Summary Revoke access token
+// @Description Запрос на отзыв токена доступа (RFC 7009), клиент может отозвать только выданные ему токены
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "отзываемый токен"
+// @Param token_type_hint formData string false "тип токена, поддерживается только access_token"
+// @Success      200  {string}  string	"request completed"
+// @Failure      400  {object}  models.OAuthErrorResponse
+// @Failure      401  {object}  models.OAuthErrorResponse
+// @Router /oauth/revoke [post]
+func RevokeRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "oauth revoke")
+
+	// Авторизация клиента
+	clientID, ok := authorizeClient(ctx)
+	if !ok {
+		return oauthError(ctx, fiber.StatusUnauthorized, "invalid_client", "client authentication failed")
+	}
+
+	token := ctx.FormValue("token")
+	if token == "" {
+		return oauthError(ctx, fiber.StatusBadRequest, "invalid_request", "token is required")
+	}
+
+	// Неизвестный токен не является ошибкой (RFC 7009, раздел 2.2)
+	tokenData, ok := myProfilesDB.DB.GetTokenData(token)
+	if ok && tokenData.ClientID != clientID {
+		return oauthError(ctx, fiber.StatusBadRequest, "unauthorized_client", "token was issued to another client")
+	}
+	err := myProfilesDB.DB.RevokeToken(token)
+	if err != nil {
+		return oauthError(ctx, fiber.StatusInternalServerError, "server_error", err.Error())
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Add oauth client
+// @Security BasicAuth
+// @Description Запрос на регистрацию нового клиента OAuth 2.0, доступно только администраторам платформы
+// @Accept json
+// @Param input body models.FullOAuthClientData true "идентификатор, секрет и области доступа нового клиента"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"such oauth client is already exists"
+// @Failure      404  {string}  string	"oauth client id must not be empty"
+// @Router /oauth/client [post]
+func AddClientRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "add oauth client")
+
+	// Проверка, является ли авторизованный пользователь администратором платформы (клиенты OAuth 2.0 общие для всех тенантов)
+	if !isPlatformAdmin(ctx) {
+		log.Println(userIsNotPlatformAdminErr.Error())
+		return userIsNotPlatformAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.FullOAuthClientData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Регистрация клиента
+	err = myProfilesDB.DB.AddClient(
+		models.OAuthClientData{
+			ClientID: body.ClientID,
+			Scopes:   body.Scopes,
+		},
+		body.ClientSecret,
+	)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Remove oauth client
+// @Security BasicAuth
+// @Description Запрос на удаление клиента OAuth 2.0 вместе с выданными ему токенами, доступно только администраторам платформы
+// @Accept json
+// @Param input body models.OAuthClientIDData true "идентификатор удаляемого клиента"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such oauth client"
+// @Router /oauth/client [delete]
+func RemoveClientRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "remove oauth client")
+
+	// Проверка, является ли авторизованный пользователь администратором платформы (клиенты OAuth 2.0 общие для всех тенантов)
+	if !isPlatformAdmin(ctx) {
+		log.Println(userIsNotPlatformAdminErr.Error())
+		return userIsNotPlatformAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.OAuthClientIDData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Удаление клиента
+	err = myProfilesDB.DB.RemoveClient(body.ClientID)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
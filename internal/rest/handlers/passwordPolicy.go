@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"unicode"
+)
+
+// Максимальная длина пароля в байтах (ограничение bcrypt)
+const maxPasswordBytes = 72
+
+// Структура политики паролей
+type passwordPolicyConfig struct {
+	MinLength     int  `json:"minLength"`     // минимальная длина пароля в символах
+	RequireLetter bool `json:"requireLetter"` // пароль должен содержать хотя бы одну букву
+	RequireDigit  bool `json:"requireDigit"`  // пароль должен содержать хотя бы одну цифру
+}
+
+// Политика паролей, задаваемых пользователями (читается из конфига при развертывании API)
+var passwordPolicy passwordPolicyConfig
+
+/*
+Чтение политики паролей из конфига "../../configs/passwordPolicyConfig.json"
+
+:return: ошибка, если конфиг не удалось прочитать
+*/
+func LoadPasswordPolicy() error {
+	configFilePath := "../../configs/passwordPolicyConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return errors.New("fail to read password policy config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	json.Unmarshal(byteValue, &passwordPolicy)
+
+	return nil
+}
+
+/*
+Проверка пароля по политике паролей
+
+:param password string: проверяемый пароль
+
+:return: ошибка со статусом 400, если пароль не соответствует политике паролей
+*/
+func checkPasswordPolicy(password string) error {
+	err := PasswordPolicyError(password)
+	if err != nil {
+		return badRequest(err)
+	}
+	return nil
+}
+
+/*
+Получение нарушения политики паролей (используется также для проверки паролей при импорте профилей запросом и утилитой cmd/dbtool, где нарушение относится к записи, а не к запросу)
+
+:param password string: проверяемый пароль
+
+:return: ошибка weakPasswordErr с описанием нарушения или nil, если пароль соответствует политике паролей
+*/
+func PasswordPolicyError(password string) error {
+	if len([]rune(password)) < passwordPolicy.MinLength {
+		return fmt.Errorf("%w: password must be at least %d characters long", weakPasswordErr, passwordPolicy.MinLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("%w: password must be at most %d bytes long", weakPasswordErr, maxPasswordBytes)
+	}
+	hasLetter, hasDigit := false, false
+	for _, r := range password {
+		hasLetter = hasLetter || unicode.IsLetter(r)
+		hasDigit = hasDigit || unicode.IsDigit(r)
+	}
+	if passwordPolicy.RequireLetter && !hasLetter {
+		return fmt.Errorf("%w: password must contain a letter", weakPasswordErr)
+	}
+	if passwordPolicy.RequireDigit && !hasDigit {
+		return fmt.Errorf("%w: password must contain a digit", weakPasswordErr)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+)
+
+/*
+Составление ETag профиля по его версии
+
+:param version int64: версия профиля
+
+:return: строгий ETag профиля (версия в кавычках)
+*/
+func profileETag(version int64) string {
+	return "\"" + strconv.FormatInt(version, 10) + "\""
+}
+
+/*
+Проверка условия If-Match запроса по текущей версии профиля (ETag сравниваются строго, слабые ETag не совпадают)
+
+:param ctx *fiber.Ctx: контекст запроса
+:param version int64: текущая версия профиля
+
+:return: true, если заголовок If-Match не задан, равен "*" или содержит ETag текущей версии профиля, иначе - false
+*/
+func ifMatchProfile(ctx *fiber.Ctx, version int64) bool {
+	ifMatch := ctx.Get(fiber.HeaderIfMatch)
+	if ifMatch == "" {
+		return true
+	}
+	etag := profileETag(version)
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Ответ на запрос, условие If-Match которого не выполнено
+
+:param err error: ошибка несовпадения версии профиля
+
+:return: ошибка со статусом 412
+*/
+func preconditionFailed(err error) error {
+	log.Printf("request error (status %d): %s", fiber.StatusPreconditionFailed, err.Error())
+	return fiber.NewError(fiber.StatusPreconditionFailed, err.Error())
+}
+
+/*
+Проверка, что ошибка БД вызвана изменением профиля другим запросом (версия профиля не совпала с ожидаемой)
+
+:param err error: ошибка БД
+
+:return: true, если версия профиля не совпала с ожидаемой, иначе - false
+*/
+func isProfileVersionMismatch(err error) bool {
+	return errors.Is(err, myProfilesDB.ProfileVersionMismatchErr)
+}
+
+/*
+Запись в лог ошибки транзакции БД, выполненной обработчиком запроса: ответы со статусом, составленные внутри транзакции (412, 422), уже записаны в лог
+
+:param err error: ошибка транзакции
+*/
+func logTransactionError(err error) {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return
+	}
+	log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+}
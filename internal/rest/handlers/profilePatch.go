@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"reflect"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+
+	"github.com/ZotovSergey/authenticationservice/internal/jsonpatch"
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Типы содержимого запроса на редактирование профиля
+const (
+	mimeApplicationJSON       = "application/json"             // данные профиля: пустые поля не меняются, атрибуты со значением null удаляются
+	mimeApplicationMergePatch = "application/merge-patch+json" // JSON Merge Patch (RFC 7396): поля со значением null очищаются
+	mimeApplicationJSONPatch  = "application/json-patch+json"  // JSON Patch (RFC 6902)
+)
+
+// Функция, составляющая новые данные профиля по текущим данным профиля, видимым пользователю (canSee - проверка, видит ли пользователь поле профиля);
+// изменение поля, скрытого от пользователя, отклоняется
+type profilePatch func(visibleProfileData models.ProfileData, canSee func(field string) bool) (models.ProfileData, error)
+
+/*
+Разбор тела запроса на редактирование профиля по типу содержимого (Content-Type)
+
+:param ctx *fiber.Ctx: контекст запроса
+
+:return: логин и идентификатор редактируемого профиля (задается один из них) и функция, применяющая изменения к текущим данным профиля,
+или ошибка со статусом 415, если тип содержимого не поддерживается, или 400, если тело запроса некорректно
+*/
+func parseProfilePatch(ctx *fiber.Ctx) (string, string, profilePatch, error) {
+	mediaType, _, _ := mime.ParseMediaType(ctx.Get(fiber.HeaderContentType))
+	// Логин или идентификатор редактируемого профиля может быть задан параметром запроса (для JSON Patch - обязательно)
+	login := utils.CopyString(ctx.Query("login"))
+	id := utils.CopyString(ctx.Query("id"))
+
+	switch mediaType {
+	// Данные профиля: пустые имя, фамилия и адрес электронной почты не меняются, заданные атрибуты заменяются, атрибуты со значением null удаляются
+	case mimeApplicationJSON:
+		var body models.ProfileData
+		err := json.Unmarshal(ctx.Body(), &body)
+		if err != nil {
+			return "", "", nil, badRequest(err)
+		}
+		if login == "" && id == "" {
+			login = body.Login
+		}
+		return login, id, func(currentProfileData models.ProfileData, canSee func(field string) bool) (models.ProfileData, error) {
+			err := checkPatchedFields(body, canSee)
+			if err != nil {
+				return models.ProfileData{}, err
+			}
+			newProfileData := currentProfileData
+			if body.FirstName != "" {
+				newProfileData.FirstName = body.FirstName
+			}
+			if body.LastName != "" {
+				newProfileData.LastName = body.LastName
+			}
+			if body.Email != "" {
+				newProfileData.Email = body.Email
+			}
+			newProfileData.Attributes = make(map[string]interface{}, len(currentProfileData.Attributes)+len(body.Attributes))
+			for name, value := range currentProfileData.Attributes {
+				newProfileData.Attributes[name] = value
+			}
+			for name, value := range body.Attributes {
+				newProfileData.Attributes[name] = value
+			}
+			return newProfileData, nil
+		}, nil
+
+	// JSON Merge Patch: документ профиля объединяется с патчем, поля со значением null очищаются
+	case mimeApplicationMergePatch:
+		var patch interface{}
+		err := json.Unmarshal(ctx.Body(), &patch)
+		if err != nil {
+			return "", "", nil, badRequest(err)
+		}
+		// логин в патче определяет редактируемый профиль и в документ профиля не переносится
+		if patchObject, ok := patch.(map[string]interface{}); ok {
+			if login == "" && id == "" {
+				login, _ = patchObject["login"].(string)
+			}
+			delete(patchObject, "login")
+		}
+		return login, id, func(currentProfileData models.ProfileData, canSee func(field string) bool) (models.ProfileData, error) {
+			err := checkMergePatchFields(patch, canSee)
+			if err != nil {
+				return models.ProfileData{}, err
+			}
+			document, err := profileDocument(currentProfileData)
+			if err != nil {
+				return models.ProfileData{}, err
+			}
+			return profileFromDocument(jsonpatch.MergePatch(document, patch), currentProfileData)
+		}, nil
+
+	// JSON Patch: операции применяются к документу профиля по порядку
+	case mimeApplicationJSONPatch:
+		decoder := json.NewDecoder(bytes.NewReader(ctx.Body()))
+		decoder.DisallowUnknownFields()
+		var operations []jsonpatch.Operation
+		err := decoder.Decode(&operations)
+		if err != nil {
+			return "", "", nil, badRequest(err)
+		}
+		return login, id, func(currentProfileData models.ProfileData, canSee func(field string) bool) (models.ProfileData, error) {
+			for _, operation := range operations {
+				for _, pointer := range []string{operation.Path, operation.From} {
+					if field := pointerField(pointer); field != "" && !canSee(field) {
+						return models.ProfileData{}, fmt.Errorf("%w \"%s\"", hiddenFieldPatchErr, field)
+					}
+				}
+			}
+			document, err := profileDocument(currentProfileData)
+			if err != nil {
+				return models.ProfileData{}, err
+			}
+			document, err = jsonpatch.ApplyPatch(document, operations)
+			if err != nil {
+				return models.ProfileData{}, err
+			}
+			return profileFromDocument(document, currentProfileData)
+		}, nil
+	}
+
+	log.Printf("request error (status %d): %s", fiber.StatusUnsupportedMediaType, unsupportedMediaTypeErr.Error())
+	return "", "", nil, fiber.NewError(fiber.StatusUnsupportedMediaType, unsupportedMediaTypeErr.Error())
+}
+
+/*
+Проверка, что данные профиля в теле запроса не изменяют полей, скрытых от пользователя
+
+:param body models.ProfileData: данные профиля из тела запроса
+:param canSee func(field string) bool: проверка, видит ли пользователь поле профиля
+
+:return: ошибка, если задано поле или атрибут, скрытый от пользователя
+*/
+func checkPatchedFields(body models.ProfileData, canSee func(field string) bool) error {
+	fields := map[string]bool{
+		models.FieldFirstName: body.FirstName != "",
+		models.FieldLastName:  body.LastName != "",
+		models.FieldEmail:     body.Email != "",
+	}
+	for name := range body.Attributes {
+		fields[name] = true
+	}
+	for field, patched := range fields {
+		if patched && !canSee(field) {
+			return fmt.Errorf("%w \"%s\"", hiddenFieldPatchErr, field)
+		}
+	}
+	return nil
+}
+
+/*
+Проверка, что JSON Merge Patch не изменяет полей, скрытых от пользователя
+
+:param patch interface{}: патч
+:param canSee func(field string) bool: проверка, видит ли пользователь поле профиля
+
+:return: ошибка, если патч изменяет поле или атрибут, скрытый от пользователя
+*/
+func checkMergePatchFields(patch interface{}, canSee func(field string) bool) error {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for key, value := range patchObject {
+		if key == "attributes" {
+			attributes, _ := value.(map[string]interface{})
+			for name := range attributes {
+				if !canSee(name) {
+					return fmt.Errorf("%w \"%s\"", hiddenFieldPatchErr, name)
+				}
+			}
+			continue
+		}
+		if field := pointerField("/" + key); field != "" && !canSee(field) {
+			return fmt.Errorf("%w \"%s\"", hiddenFieldPatchErr, field)
+		}
+	}
+	return nil
+}
+
+/*
+Определение поля профиля, на которое указывает json pointer в документе профиля
+
+:param pointer string: json pointer (RFC 6901)
+
+:return: название основного поля или дополнительного атрибута (пустая строка - pointer указывает на корень документа, на все атрибуты
+или на поле, видимость которого не настраивается)
+*/
+func pointerField(pointer string) string {
+	if !strings.HasPrefix(pointer, "/") {
+		return ""
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	switch tokens[0] {
+	case models.FieldFirstName, models.FieldLastName, models.FieldEmail:
+		return tokens[0]
+	case "verifiedAt", "pendingEmail":
+		return models.FieldEmail
+	case "attributes":
+		if len(tokens) > 1 {
+			return tokens[1]
+		}
+	}
+	return ""
+}
+
+/*
+Проверка, что новые данные профиля, составленные по видимым пользователю данным, не добавляют и не изменяют скрытых от пользователя полей и атрибутов
+(патч, заменяющий все атрибуты или весь документ профиля, проверкой путей не отклоняется), и перенос скрытых полей из текущих данных профиля в новые данные профиля
+
+:param profileData models.ProfileData: новые данные профиля, составленные по видимым пользователю данным
+:param visibleProfileData models.ProfileData: текущие данные профиля, видимые пользователю (к ним применялся патч)
+:param currentProfileData models.ProfileData: текущие полные данные профиля
+:param canSee func(field string) bool: проверка, видит ли пользователь поле профиля
+
+:return: новые полные данные профиля (скрытые поля и атрибуты - из текущих данных профиля) или ошибка, если скрытое поле или атрибут добавлен или изменен
+*/
+func mergeHiddenFields(profileData models.ProfileData, visibleProfileData models.ProfileData, currentProfileData models.ProfileData, canSee func(field string) bool) (models.ProfileData, error) {
+	// Проверка основных полей
+	fields := map[string]bool{
+		models.FieldFirstName: profileData.FirstName != visibleProfileData.FirstName,
+		models.FieldLastName:  profileData.LastName != visibleProfileData.LastName,
+		models.FieldEmail: profileData.Email != visibleProfileData.Email || profileData.VerifiedAt != visibleProfileData.VerifiedAt ||
+			profileData.PendingEmail != visibleProfileData.PendingEmail,
+	}
+	for field, changed := range fields {
+		if changed && !canSee(field) {
+			return models.ProfileData{}, fmt.Errorf("%w \"%s\"", hiddenFieldPatchErr, field)
+		}
+	}
+	if !canSee(models.FieldFirstName) {
+		profileData.FirstName = currentProfileData.FirstName
+	}
+	if !canSee(models.FieldLastName) {
+		profileData.LastName = currentProfileData.LastName
+	}
+	if !canSee(models.FieldEmail) {
+		profileData.Email = currentProfileData.Email
+		profileData.VerifiedAt = currentProfileData.VerifiedAt
+		profileData.PendingEmail = currentProfileData.PendingEmail
+	}
+
+	// Проверка атрибутов: скрытые атрибуты новых данных профиля заменяются скрытыми атрибутами текущих данных профиля
+	attributes := make(map[string]interface{}, len(profileData.Attributes)+len(currentProfileData.Attributes))
+	for name, value := range profileData.Attributes {
+		if canSee(name) {
+			attributes[name] = value
+			continue
+		}
+		visibleValue, ok := visibleProfileData.Attributes[name]
+		if !ok || !reflect.DeepEqual(value, visibleValue) {
+			return models.ProfileData{}, fmt.Errorf("%w \"%s\"", hiddenFieldPatchErr, name)
+		}
+	}
+	for name, value := range currentProfileData.Attributes {
+		if !canSee(name) {
+			attributes[name] = value
+		}
+	}
+	profileData.Attributes = nil
+	if len(attributes) > 0 {
+		profileData.Attributes = attributes
+	}
+	return profileData, nil
+}
+
+/*
+Ответ на запрос с некорректным телом
+
+:param err error: ошибка разбора тела запроса
+
+:return: ошибка со статусом 400
+*/
+func badRequest(err error) error {
+	log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+	return fiber.NewError(fiber.StatusBadRequest, err.Error())
+}
+
+/*
+Составление json-документа профиля, к которому применяются патчи
+
+:param profileData models.ProfileData: данные профиля
+
+:return: документ профиля (в виде, полученном из json; пустые поля отсутствуют)
+*/
+func profileDocument(profileData models.ProfileData) (interface{}, error) {
+	data, err := json.Marshal(profileData)
+	if err != nil {
+		return nil, err
+	}
+	var document interface{}
+	err = json.Unmarshal(data, &document)
+	return document, err
+}
+
+/*
+Проверка json-документа профиля после применения патча и составление по нему данных профиля
+
+:param document interface{}: документ профиля после применения патча
+:param currentProfileData models.ProfileData: текущие данные редактируемого профиля
+
+:return: новые данные профиля или ошибка, если документ не является корректным профилем (неизвестные поля, неверные типы полей) или в нем изменены логин, идентификатор
+или состояние подтверждения адреса электронной почты
+*/
+func profileFromDocument(document interface{}, currentProfileData models.ProfileData) (models.ProfileData, error) {
+	if _, ok := document.(map[string]interface{}); !ok {
+		return models.ProfileData{}, fmt.Errorf("%w: profile must be a json object", invalidPatchedProfileErr)
+	}
+	data, err := json.Marshal(document)
+	if err != nil {
+		return models.ProfileData{}, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var profileData models.ProfileData
+	err = decoder.Decode(&profileData)
+	if err != nil {
+		return models.ProfileData{}, fmt.Errorf("%w: %s", invalidPatchedProfileErr, err.Error())
+	}
+	if profileData.Login == "" {
+		profileData.Login = currentProfileData.Login
+	}
+	if profileData.ID == "" {
+		profileData.ID = currentProfileData.ID
+	}
+	if profileData.Login != currentProfileData.Login || profileData.ID != currentProfileData.ID {
+		return models.ProfileData{}, canNotPatchLoginErr
+	}
+	if profileData.VerifiedAt != currentProfileData.VerifiedAt || profileData.PendingEmail != currentProfileData.PendingEmail {
+		return models.ProfileData{}, canNotPatchEmailVerificationErr
+	}
+	return profileData, nil
+}
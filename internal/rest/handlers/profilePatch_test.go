@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Текущие данные профиля: атрибут secret скрыт от владельца профиля (скрытый атрибут internalNote не задан)
+var patchedProfile = models.ProfileData{
+	ID:         "id1",
+	Login:      "user",
+	FirstName:  "Ivan",
+	LastName:   "Ivanov",
+	Attributes: map[string]interface{}{"secret": "s3cr3t", "city": "Moscow"},
+}
+
+// Проверка видимости полей владельцем профиля
+func ownerCanSee(field string) bool {
+	return field != "secret" && field != "internalNote"
+}
+
+/*
+Применение тела запроса на редактирование профиля к данным, видимым владельцу, так же, как запрос на редактирование профиля
+
+:param t *testing.T: тест
+:param contentType string: тип содержимого
+:param body string: тело запроса
+
+:return: новые полные данные профиля или ошибка патча
+*/
+func applyTestPatch(t *testing.T, contentType string, body string) (models.ProfileData, error) {
+	var result models.ProfileData
+	var patchErr error
+	app := fiber.New()
+	app.Patch("/profile", func(ctx *fiber.Ctx) error {
+		_, _, patch, err := parseProfilePatch(ctx)
+		if err != nil {
+			return err
+		}
+		visible := patchedProfile
+		visible.Attributes = map[string]interface{}{"city": patchedProfile.Attributes["city"]}
+		result, patchErr = patch(visible, ownerCanSee)
+		if patchErr == nil {
+			result, patchErr = mergeHiddenFields(result, visible, patchedProfile, ownerCanSee)
+		}
+		return nil
+	})
+	request := httptest.NewRequest(http.MethodPatch, "/profile?login=user", strings.NewReader(body))
+	request.Header.Set(fiber.HeaderContentType, contentType)
+	response, err := app.Test(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != fiber.StatusOK {
+		message, _ := ioutil.ReadAll(response.Body)
+		t.Fatalf("unexpected status %d: %s", response.StatusCode, message)
+	}
+	return result, patchErr
+}
+
+// Операции JSON Patch, читающие или изменяющие скрытое поле, отклоняются
+func TestJSONPatchRejectsHiddenFields(t *testing.T) {
+	patches := []string{
+		`[{"op":"copy","from":"/attributes/secret","path":"/firstName"}]`,
+		`[{"op":"move","from":"/attributes/secret","path":"/attributes/city"}]`,
+		`[{"op":"test","path":"/attributes/secret","value":"s3cr3t"}]`,
+		`[{"op":"replace","path":"/attributes/secret","value":"x"}]`,
+		`[{"op":"remove","path":"/attributes/secret"}]`,
+	}
+	for _, patch := range patches {
+		_, err := applyTestPatch(t, mimeApplicationJSONPatch, patch)
+		if !errors.Is(err, hiddenFieldPatchErr) {
+			t.Errorf("patch %s: expected hidden field error, got %v", patch, err)
+		}
+	}
+}
+
+// Патч, заменяющий все атрибуты или весь документ профиля, не может добавить или изменить скрытый атрибут
+func TestPatchRejectsHiddenAttributesOfWholeDocument(t *testing.T) {
+	patches := []struct {
+		contentType string
+		body        string
+	}{
+		{mimeApplicationJSONPatch, `[{"op":"add","path":"/attributes","value":{"internalNote":"x"}}]`},
+		{mimeApplicationJSONPatch, `[{"op":"replace","path":"/attributes","value":{"city":"Moscow","secret":"x"}}]`},
+		{mimeApplicationJSONPatch, `[{"op":"replace","path":"","value":{"login":"user","firstName":"Ivan","lastName":"Ivanov","attributes":{"internalNote":"x"}}}]`},
+		{mimeApplicationJSONPatch, `[{"op":"add","path":"","value":{"login":"user","firstName":"Ivan","lastName":"Ivanov","attributes":{"secret":"s3cr3t"}}}]`},
+		{mimeApplicationMergePatch, `{"attributes":{"internalNote":"x"}}`},
+	}
+	for _, patch := range patches {
+		_, err := applyTestPatch(t, patch.contentType, patch.body)
+		if !errors.Is(err, hiddenFieldPatchErr) {
+			t.Errorf("patch %s: expected hidden field error, got %v", patch.body, err)
+		}
+	}
+
+	// Замена всего документа профиля без скрытых атрибутов изменяет видимые поля, скрытые атрибуты сохраняются
+	result, err := applyTestPatch(t, mimeApplicationJSONPatch, `[{"op":"replace","path":"","value":{"login":"user","firstName":"Petr","lastName":"Ivanov"}}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FirstName != "Petr" || !reflect.DeepEqual(result.Attributes, map[string]interface{}{"secret": "s3cr3t"}) {
+		t.Fatalf("unexpected profile %+v", result)
+	}
+}
+
+// Видимые поля изменяются JSON Patch, скрытые поля сохраняются
+func TestJSONPatchKeepsHiddenFields(t *testing.T) {
+	result, err := applyTestPatch(t, mimeApplicationJSONPatch, `[{"op":"replace","path":"/firstName","value":"Petr"},{"op":"remove","path":"/attributes"}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := patchedProfile
+	expected.FirstName = "Petr"
+	expected.Attributes = map[string]interface{}{"secret": "s3cr3t"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected profile %+v", result)
+	}
+}
+
+// JSON Merge Patch не может изменить скрытый атрибут, очистка всех атрибутов не затрагивает скрытые атрибуты
+func TestMergePatchHiddenFields(t *testing.T) {
+	_, err := applyTestPatch(t, mimeApplicationMergePatch, `{"attributes":{"secret":null}}`)
+	if !errors.Is(err, hiddenFieldPatchErr) {
+		t.Fatalf("expected hidden field error, got %v", err)
+	}
+	result, err := applyTestPatch(t, mimeApplicationMergePatch, `{"lastName":"Petrov","attributes":null}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(result)
+	if result.LastName != "Petrov" || !reflect.DeepEqual(result.Attributes, map[string]interface{}{"secret": "s3cr3t"}) {
+		t.Fatalf("unexpected profile %s", data)
+	}
+}
+
+// Данные профиля в формате application/json не могут задать скрытый атрибут
+func TestJSONBodyHiddenFields(t *testing.T) {
+	_, err := applyTestPatch(t, mimeApplicationJSON, `{"attributes":{"secret":"x"}}`)
+	if !errors.Is(err, hiddenFieldPatchErr) {
+		t.Fatalf("expected hidden field error, got %v", err)
+	}
+	result, err := applyTestPatch(t, mimeApplicationJSON, `{"attributes":{"city":"Kazan"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Attributes, map[string]interface{}{"secret": "s3cr3t", "city": "Kazan"}) {
+		t.Fatalf("unexpected attributes %v", result.Attributes)
+	}
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Функция возвращает функцию для middleware, ограничивающего число запросов на регистрацию с одного IP-адреса за период из конфига регистрации
+
+:return: функция middleware
+*/
+func RegistrationLimiter() func(*fiber.Ctx) error {
+	if registrationSettings.RateLimit <= 0 {
+		return func(ctx *fiber.Ctx) error {
+			return ctx.Next()
+		}
+	}
+	return limiter.New(limiter.Config{
+		Max:        registrationSettings.RateLimit,
+		Expiration: time.Duration(registrationSettings.RateLimitWindow) * time.Second,
+		KeyGenerator: func(ctx *fiber.Ctx) string {
+			return ctx.IP()
+		},
+		LimitReached: func(ctx *fiber.Ctx) error {
+			log.Printf("request error (status %d): %s \"%s\"", fiber.StatusTooManyRequests, registrationRateLimitErr.Error(), ctx.IP())
+			return fiber.NewError(fiber.StatusTooManyRequests, registrationRateLimitErr.Error())
+		},
+	})
+}
+
+// @Summary Register
+// @Description Запрос на самостоятельную регистрацию пользователя (без авторизации, если разрешена в конфиге регистрации): создается профиль, ожидающий подтверждения адреса электронной почты и (или) одобрения администратора; токен подтверждения отправляется на адрес электронной почты. Число запросов с одного IP-адреса ограничено
+// @Accept json
+// @Produce json
+// @Param input body models.RegistrationData true "логин, пароль, имя, фамилия, адрес электронной почты и атрибуты"
+// @Success      200  {json}  json	model.Registration
+// @Failure      400  {string}  string	"password does not satisfy password policy"
+// @Failure      404  {string}  string	"self-registration is disabled"
+// @Failure      429  {string}  string	"too many registrations from this address, try again later"
+// @Router /register [post]
+func RegisterRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "register")
+
+	// Проверка, разрешена ли самостоятельная регистрация
+	if !registrationSettings.Enabled {
+		log.Printf("request error (status %d): %s", fiber.StatusNotFound, registrationDisabledErr.Error())
+		return fiber.NewError(fiber.StatusNotFound, registrationDisabledErr.Error())
+	}
+
+	// Чтение тела запроса
+	var body models.RegistrationData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	// Проверка пароля по политике паролей
+	err = checkPasswordPolicy(body.Password)
+	if err != nil {
+		return err
+	}
+
+	// Регистрация пользователя
+	registration, err := requestTenant(ctx).Register(body, registrationSettings.RequireEmailVerification, registrationSettings.RequireApproval)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("user \"%s\" registered, account is %s", registration.Login, registration.Status)
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(registration)
+}
+
+// @Summary Get registrations
+// @Security BasicAuth
+// @Description Запрос на вывод очереди заявок на регистрацию, ожидающих подтверждения адреса электронной почты или одобрения администратора, доступно только администраторам
+// @Produce json
+// @Success      200  {json}  json	model.Registration
+// @Router /registrations [get]
+func GetRegistrationsRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "get registrations")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Получение очереди заявок из БД
+	registrations := requestTenant(ctx).GetRegistrations()
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(registrations)
+}
+
+// @Summary Approve registration
+// @Security BasicAuth
+// @Description Запрос на одобрение заявки на регистрацию по идентификатору профиля: учетная запись становится активной (адрес электронной почты должен быть подтвержден, если это требуется), доступно только администраторам
+// @Accept json
+// @Param input body models.ProfileIDData true "идентификатор профиля зарегистрировавшегося пользователя"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such registration"
+// @Router /registration/approve [post]
+func ApproveRegistrationRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "approve registration")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.ProfileIDData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	// Одобрение заявки
+	err = requestTenant(ctx).ApproveRegistration(body.ID, authorizedLogin(ctx))
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Reject registration
+// @Security BasicAuth
+// @Description Запрос на отклонение заявки на регистрацию по идентификатору профиля: заявка удаляется из очереди, профиль, ожидающий активации, удаляется окончательно, доступно только администраторам
+// @Accept json
+// @Param input body models.ProfileIDData true "идентификатор профиля зарегистрировавшегося пользователя"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such registration"
+// @Router /registration/reject [post]
+func RejectRegistrationRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "reject registration")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.ProfileIDData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	// Отклонение заявки
+	err = requestTenant(ctx).RejectRegistration(body.ID, authorizedLogin(ctx))
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/cluster"
+	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/replication"
+)
+
+// Наибольшее время ожидания новых записей журнала изменений БД в одном запросе
+const replicationMaxWait = 60 * time.Second
+
+// @Summary Replication snapshot
+// @Security BasicAuth
+// @Description Запрос на получение снимка БД для начальной загрузки ведомого экземпляра: все данные БД в формате файла базы данных (без шифрования) с эпохой и номером последней записи журнала изменений БД, доступно только администраторам платформы ведущего экземпляра
+// @Produce json
+// @Success      200  {object}  models.ReplicationSnapshot
+// @Failure      404  {string}  string	"user is not platform admin"
+// @Failure      503  {string}  string	"replication data is served only by the leader"
+// @Router /v1/replication/snapshot [get]
+func ReplicationSnapshotRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "replication snapshot")
+
+	// Проверка, является ли авторизованный пользователь администратором платформы
+	if !isPlatformAdmin(ctx) {
+		log.Println(userIsNotPlatformAdminErr.Error())
+		return userIsNotPlatformAdminErr
+	}
+	// Снимок отдает только ведущий экземпляр
+	if replication.IsFollower() {
+		log.Printf("request error (status %d): %s", fiber.StatusServiceUnavailable, replicationNotLeaderErr.Error())
+		return fiber.NewError(fiber.StatusServiceUnavailable, replicationNotLeaderErr.Error())
+	}
+
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(myProfilesDB.DB.ReplicationSnapshot())
+}
+
+// @Summary Replication log
+// @Security BasicAuth
+// @Description Запрос на получение записей журнала изменений БД после заданного номера для ведомого экземпляра, доступно только администраторам платформы ведущего экземпляра.
+// @Description Если новых записей нет, запрос ждет их не дольше wait секунд (не больше 60). Если эпоха журнала сменилась (ведущий перезапущен) или записи после since уже не хранятся, возвращается 410 - ведомому нужно заново загрузить снимок
+// @Produce json
+// @Param epoch query string true "эпоха журнала из снимка или предыдущего ответа"
+// @Param since query int true "номер последней примененной записи"
+// @Param wait query int false "время ожидания новых записей в секундах"
+// @Success      200  {object}  models.ReplicationLog
+// @Failure      400  {string}  string	"replication log position must be a non-negative integer"
+// @Failure      404  {string}  string	"user is not platform admin"
+// @Failure      410  {string}  string	"replication log entries are no longer retained, snapshot is required"
+// @Failure      503  {string}  string	"replication data is served only by the leader"
+// @Router /v1/replication/log [get]
+func ReplicationLogRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "replication log")
+
+	// Проверка, является ли авторизованный пользователь администратором платформы
+	if !isPlatformAdmin(ctx) {
+		log.Println(userIsNotPlatformAdminErr.Error())
+		return userIsNotPlatformAdminErr
+	}
+	// Журнал отдает только ведущий экземпляр
+	if replication.IsFollower() {
+		log.Printf("request error (status %d): %s", fiber.StatusServiceUnavailable, replicationNotLeaderErr.Error())
+		return fiber.NewError(fiber.StatusServiceUnavailable, replicationNotLeaderErr.Error())
+	}
+
+	// Чтение позиции в журнале и времени ожидания
+	since, err := strconv.ParseInt(ctx.Query("since"), 10, 64)
+	if err != nil || since < 0 {
+		return badRequest(invalidReplicationPositionErr)
+	}
+	wait := time.Duration(ctx.QueryInt("wait")) * time.Second
+	if wait > replicationMaxWait {
+		wait = replicationMaxWait
+	}
+
+	// Ожидание новых записей журнала, если их нет
+	epoch := ctx.Query("epoch")
+	replicationLog, replicated, err := myProfilesDB.DB.ReplicationLog(epoch, since)
+	if err == nil && len(replicationLog.Entries) == 0 && wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-replicated:
+			replicationLog, _, err = myProfilesDB.DB.ReplicationLog(epoch, since)
+		case <-ctx.Context().Done():
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+	if errors.Is(err, myProfilesDB.ReplicationLogTruncatedErr) {
+		log.Printf("request error (status %d): %s", fiber.StatusGone, err.Error())
+		return fiber.NewError(fiber.StatusGone, err.Error())
+	}
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(replicationLog)
+}
+
+// @Summary Readiness
+// @Description Запрос на проверку готовности экземпляра обслуживать запросы (без авторизации): роль экземпляра в репликации и позиция в журнале изменений БД,
+// @Description для ведомого экземпляра - отставание от ведущего; ведомый готов, если загрузил снимок ведущего и отстает не больше допустимого в конфиге.
+// @Description Для узла кластера возвращается состояние узла (models.ClusterStatus); узел готов, если получил данные БД из журнала Raft и лидер кластера известен
+// @Produce json
+// @Success      200  {object}  models.ReplicationStatus
+// @Failure      503  {object}  models.ReplicationStatus
+// @Router /ready [get]
+func ReadinessRequest(ctx *fiber.Ctx) error {
+	if cluster.Enabled() {
+		status := cluster.Status()
+		if !status.Ready {
+			ctx.Status(fiber.StatusServiceUnavailable)
+		}
+		return ctx.JSON(status)
+	}
+	status := replication.Status()
+	if !status.Ready {
+		ctx.Status(fiber.StatusServiceUnavailable)
+	}
+	return ctx.JSON(status)
+}
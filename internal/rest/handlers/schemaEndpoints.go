@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// @Summary Get attributes schema
+// @Security BasicAuth
+// @Description Запрос на вывод схемы дополнительных атрибутов профилей тенанта, доступно всем пользователям
+// @Produce json
+// @Success      200  {json}  json	model.AttributeDefinition
+// @Router /schema [get]
+func GetAttributesSchemaRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "get attributes schema")
+
+	// Получение схемы атрибутов из БД
+	schema := requestTenant(ctx).GetAttributesSchema()
+
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(schema)
+}
+
+// @Summary Set attribute definition
+// @Security BasicAuth
+// @Description Запрос на добавление или замену описания дополнительного атрибута профилей в схеме атрибутов тенанта, доступно только администраторам, новое описание должно выполняться для всех существующих профилей
+// @Accept json
+// @Param input body models.AttributeDefinition true "описание атрибута: название, тип (string, int, bool, date, enum, list), обязательность, уникальность, регулярное выражение, допустимые значения enum и видимость (public, self, admin)"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"invalid attribute definition"
+// @Router /schema/attribute [put]
+func SetAttributeDefinitionRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "set attribute definition")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.AttributeDefinition
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Запись описания атрибута
+	err = requestTenant(ctx).SetAttributeDefinition(body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Remove attribute definition
+// @Security BasicAuth
+// @Description Запрос на удаление описания дополнительного атрибута из схемы атрибутов тенанта вместе со значениями атрибута во всех профилях, доступно только администраторам
+// @Accept json
+// @Param input body models.AttributeNameData true "название удаляемого атрибута"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"unknown attribute"
+// @Router /schema/attribute [delete]
+func RemoveAttributeDefinitionRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "remove attribute definition")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.AttributeNameData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Удаление описания атрибута
+	err = requestTenant(ctx).RemoveAttributeDefinition(body.Name, authorizedLogin(ctx))
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Get fields visibility
+// @Security BasicAuth
+// @Description Запрос на вывод настроек видимости основных полей и дополнительных атрибутов профилей тенанта, доступно всем пользователям
+// @Produce json
+// @Success      200  {json}  json	model.FieldVisibility
+// @Router /schema/visibility [get]
+func GetFieldsVisibilityRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "get fields visibility")
+
+	// Получение настроек видимости из БД
+	fieldsVisibility := requestTenant(ctx).GetFieldsVisibility()
+
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(fieldsVisibility)
+}
+
+// @Summary Set field visibility
+// @Security BasicAuth
+// @Description Запрос на изменение видимости основного поля (firstName, lastName, email) или дополнительного атрибута профилей тенанта, доступно только администраторам
+// @Accept json
+// @Param input body models.FieldVisibility true "название поля, уровень видимости (public, self, admin) и группы, участникам которых поле видно всегда"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"unknown visibility"
+// @Router /schema/visibility [put]
+func SetFieldVisibilityRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "set field visibility")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.FieldVisibility
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Запись настройки видимости
+	err = requestTenant(ctx).SetFieldVisibility(body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/database/myProfilesDB"
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// @Summary Get all tenants
+// @Security BasicAuth
+// @Description Запрос на вывод списка названий всех тенантов, доступно только администраторам платформы
+// @Produce json
+// @Success      200  {json}  json	"tenants"
+// @Router /tenants [get]
+func GetAllTenantsRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "get all tenants")
+
+	// Проверка, является ли авторизованный пользователь администратором платформы
+	if !isPlatformAdmin(ctx) {
+		log.Println(userIsNotPlatformAdminErr.Error())
+		return userIsNotPlatformAdminErr
+	}
+
+	// Получение списка тенантов из БД
+	tenantsList := myProfilesDB.DB.GetAllTenants()
+
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(tenantsList)
+}
+
+// @Summary Add tenant
+// @Security BasicAuth
+// @Description Запрос на создание нового пустого тенанта, доступно только администраторам платформы (профили и администраторы тенанта добавляются запросами к тенанту)
+// @Accept json
+// @Param input body models.TenantData true "название нового тенанта"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"such tenant is already exists"
+// @Router /tenant [post]
+func AddTenantRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "add tenant")
+
+	// Проверка, является ли авторизованный пользователь администратором платформы
+	if !isPlatformAdmin(ctx) {
+		log.Println(userIsNotPlatformAdminErr.Error())
+		return userIsNotPlatformAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.TenantData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Создание тенанта
+	err = myProfilesDB.DB.AddTenant(body.Name)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Remove tenant
+// @Security BasicAuth
+// @Description Запрос на удаление тенанта со всеми его профилями и группами, доступно только администраторам платформы, нельзя удалять тенант платформы
+// @Accept json
+// @Param input body models.TenantData true "название удаляемого тенанта"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such tenant"
+// @Router /tenant [delete]
+func RemoveTenantRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "remove tenant")
+
+	// Проверка, является ли авторизованный пользователь администратором платформы
+	if !isPlatformAdmin(ctx) {
+		log.Println(userIsNotPlatformAdminErr.Error())
+		return userIsNotPlatformAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.TenantData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Удаление тенанта
+	err = myProfilesDB.DB.RemoveTenant(body.Name)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Add platform admin
+// @Security BasicAuth
+// @Description Запрос на добавление профиля тенанта платформы в список администраторов платформы, доступно только администраторам платформы
+// @Accept json
+// @Param input body models.LoginData true "логин профиля тенанта платформы"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such profile"
+// @Router /platformAdmin [post]
+func AddPlatformAdminRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "add platform admin")
+
+	// Проверка, является ли авторизованный пользователь администратором платформы
+	if !isPlatformAdmin(ctx) {
+		log.Println(userIsNotPlatformAdminErr.Error())
+		return userIsNotPlatformAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.LoginData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Добавление администратора платформы
+	err = myProfilesDB.DB.AddPlatformAdmin(body.Login)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Drop platform admin
+// @Security BasicAuth
+// @Description Запрос на удаление профиля из списка администраторов платформы, доступно только администраторам платформы, нельзя удалять из списка свой профиль
+// @Accept json
+// @Param input body models.LoginData true "логин профиля тенанта платформы"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such profile"
+// @Router /platformAdmin [delete]
+func DropPlatformAdminRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "drop platform admin")
+
+	// Проверка, является ли авторизованный пользователь администратором платформы
+	if !isPlatformAdmin(ctx) {
+		log.Println(userIsNotPlatformAdminErr.Error())
+		return userIsNotPlatformAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.LoginData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Проверка профиля: нельзя удалять свой профиль из администраторов платформы
+	if authorizedLogin(ctx) == body.Login {
+		log.Println(canNotRemoveOwnProfileFromPlatformAdminsErr.Error())
+		return canNotRemoveOwnProfileFromPlatformAdminsErr
+	}
+
+	// Удаление администратора платформы
+	err = myProfilesDB.DB.DropPlatformAdmin(body.Login)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+	"github.com/ZotovSergey/authenticationservice/internal/profilesTransfer"
+)
+
+/*
+Получение формата файла профилей из параметра запроса format или, если он не задан, из заголовка Content-Type
+
+:param ctx *fiber.Ctx: контекст запроса
+:param defaultFormat string: формат, если он не задан ни параметром, ни заголовком
+
+:return: формат файла или ошибка со статусом 400, если формат неизвестен
+*/
+func transferFormat(ctx *fiber.Ctx, defaultFormat string) (string, error) {
+	format := ctx.Query("format")
+	if format == "" {
+		switch strings.TrimSpace(strings.Split(ctx.Get(fiber.HeaderContentType), ";")[0]) {
+		case "text/csv":
+			format = models.TransferFormatCSV
+		case "application/x-ndjson", "application/jsonl":
+			format = models.TransferFormatJSONL
+		default:
+			format = defaultFormat
+		}
+	}
+	err := profilesTransfer.CheckFormat(format)
+	if err != nil {
+		return "", badRequest(err)
+	}
+	return format, nil
+}
+
+// @Summary Import profiles
+// @Security BasicAuth
+// @Description Запрос на импорт профилей из CSV (с заголовком, колонка login обязательна, атрибуты - json-объект в колонке attributes) или JSON Lines, доступно только администраторам. В режиме create создаются только новые профили, в режиме upsert данные существующих профилей заменяются. Пароли проверяются по политике паролей, вместо пароля можно передать хэш bcrypt (passwordHash). Записи с ошибками пропускаются и попадают в отчет, при проверке (dryRun) профили не изменяются
+// @Accept plain
+// @Produce json
+// @Param format query string false "формат файла: csv или jsonl (по-умолчанию определяется по Content-Type: text/csv или application/x-ndjson)"
+// @Param mode query string false "режим импорта: create (по-умолчанию) или upsert"
+// @Param dryRun query bool false "только проверить записи"
+// @Param input body string true "файл профилей"
+// @Success      200  {json}  json	model.ImportReport
+// @Failure      400  {string}  string	"invalid profiles file"
+// @Router /v1/profiles/import [post]
+func ImportProfilesRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "import profiles")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение параметров импорта
+	format, err := transferFormat(ctx, "")
+	if err != nil {
+		return err
+	}
+	mode := ctx.Query("mode", models.ImportModeCreate)
+	if mode != models.ImportModeCreate && mode != models.ImportModeUpsert {
+		return badRequest(unknownImportModeErr)
+	}
+
+	// Импорт профилей
+	report, err := profilesTransfer.Import(requestTenant(ctx), bytes.NewReader(ctx.Body()), format, mode, ctx.QueryBool("dryRun"), PasswordPolicyError, authorizedLogin(ctx))
+	if errors.Is(err, profilesTransfer.InvalidFileErr) {
+		return badRequest(err)
+	}
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d): %d created, %d updated, %d failed", fiber.StatusOK, report.Created, report.Updated, report.Failed)
+	return ctx.JSON(report)
+}
+
+// @Summary Export profiles
+// @Security BasicAuth
+// @Description Запрос на экспорт профилей тенанта в CSV или JSON Lines (файл выводится потоком по мере форматирования), доступно только администраторам; зашифрованные пароли выводятся только по запросу
+// @Produce plain
+// @Param format query string false "формат файла: csv или jsonl (по-умолчанию)"
+// @Param passwordHashes query bool false "выводить зашифрованные пароли (хэши bcrypt)"
+// @Success      200  {file}  file	"файл профилей"
+// @Failure      400  {string}  string	"unknown profiles file format"
+// @Router /v1/profiles/export [get]
+func ExportProfilesRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "export profiles")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение параметров экспорта
+	format, err := transferFormat(ctx, models.TransferFormatJSONL)
+	if err != nil {
+		return err
+	}
+	withPasswordHashes := ctx.QueryBool("passwordHashes")
+	if withPasswordHashes {
+		log.Printf("password hashes are exported by \"%s\"", authorizedLogin(ctx))
+	}
+
+	// Вывод файла профилей потоком
+	tenant := requestTenant(ctx)
+	ctx.Attachment("profiles." + format)
+	ctx.Set(fiber.HeaderContentType, profilesTransfer.ContentType(format))
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		err := profilesTransfer.Export(tenant, w, format, withPasswordHashes)
+		if err != nil {
+			log.Printf("profiles export error: %s", err.Error())
+		}
+	})
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return nil
+}
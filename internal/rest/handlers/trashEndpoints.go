@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// @Summary Get deleted profiles
+// @Security BasicAuth
+// @Description Запрос на вывод профилей в корзине тенанта (удаленных, но еще не удаленных окончательно) с временем удаления, доступно только администраторам
+// @Produce json
+// @Success      200  {json}  json	model.DeletedProfileData
+// @Router /trash [get]
+func GetDeletedProfilesRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "get deleted profiles")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Получение профилей в корзине из БД
+	deletedProfiles := requestTenant(ctx).GetDeletedProfiles()
+
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(deletedProfiles)
+}
+
+// @Summary Restore deleted profile
+// @Security BasicAuth
+// @Description Запрос на восстановление профиля из корзины вместе с паролем, правами администратора и членством в группах, доступно только администраторам; логин профиля не должен быть занят
+// @Accept json
+// @Param input body models.ProfileIDData true "идентификатор удаленного профиля"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such deleted profile"
+// @Router /trash/restore [post]
+func RestoreProfileRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "restore profile")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.ProfileIDData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Восстановление профиля
+	login, err := requestTenant(ctx).RestoreProfile(body.ID, authorizedLogin(ctx))
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("profile \"%s\" restored", login)
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Purge deleted profile
+// @Security BasicAuth
+// @Description Запрос на окончательное удаление профиля из корзины вместе с паролем, не дожидаясь истечения времени хранения, доступно только администраторам
+// @Accept json
+// @Param input body models.ProfileIDData true "идентификатор удаленного профиля"
+// @Success      200  {string}  string	"request completed"
+// @Failure      404  {string}  string	"no such deleted profile"
+// @Router /trash [delete]
+func PurgeProfileRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "purge profile")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.ProfileIDData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		log.Printf("request error (status %d): %s", fiber.StatusBadRequest, err.Error())
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Окончательное удаление профиля
+	err = requestTenant(ctx).PurgeProfile(body.ID)
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// @Summary Add webhook
+// @Security BasicAuth
+// @Description Запрос на регистрацию вебхука: на адрес отправляются события изменений профилей тенанта заданных типов (пустой список - все события) запросами POST, подписанными HMAC-SHA256 секретом вебхука (заголовок X-Webhook-Signature: "sha256=<hex>" от строки "<X-Webhook-Timestamp>.<тело запроса>"); в ответе возвращается секрет (выдается только один раз), доступно только администраторам
+// @Accept json
+// @Produce json
+// @Param input body models.WebhookData true "адрес и типы событий вебхука"
+// @Success      200  {json}  json	model.Webhook
+// @Failure      500  {string}  string	"webhook url must be an absolute http or https url"
+// @Router /v1/webhooks [post]
+func AddWebhookRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "add webhook")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Чтение тела запроса
+	var body models.WebhookData
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	// Регистрация вебхука
+	webhook, err := requestTenant(ctx).AddWebhook(body, authorizedLogin(ctx))
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(webhook)
+}
+
+// @Summary Get webhooks
+// @Security BasicAuth
+// @Description Запрос на вывод вебхуков тенанта (секреты не выводятся), доступно только администраторам
+// @Produce json
+// @Success      200  {json}  json	model.Webhook
+// @Router /v1/webhooks [get]
+func GetWebhooksRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "get webhooks")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Получение списка вебхуков из БД
+	webhooks := requestTenant(ctx).GetWebhooks()
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(webhooks)
+}
+
+// @Summary Remove webhook
+// @Security BasicAuth
+// @Description Запрос на удаление вебхука вместе с историей доставок и недоставленными событиями вебхука (ожидающие доставки отменяются), доступно только администраторам
+// @Param id path string true "идентификатор вебхука"
+// @Success      200  {string}  string	"request completed"
+// @Failure      500  {string}  string	"no such webhook"
+// @Router /v1/webhooks/{id} [delete]
+func RemoveWebhookRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "remove webhook")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Удаление вебхука
+	err := requestTenant(ctx).RemoveWebhook(ctx.Params("id"))
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
+
+// @Summary Get webhook deliveries
+// @Security BasicAuth
+// @Description Запрос на вывод истории доставок событий вебхука: ожидающие доставки и последние завершенные доставки с количеством попыток, статусом ответа и ошибкой последней попытки, доступно только администраторам
+// @Produce json
+// @Param id path string true "идентификатор вебхука"
+// @Success      200  {json}  json	model.WebhookDelivery
+// @Failure      500  {string}  string	"no such webhook"
+// @Router /v1/webhooks/{id}/deliveries [get]
+func GetWebhookDeliveriesRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "get webhook deliveries")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Получение истории доставок из БД
+	deliveries, err := requestTenant(ctx).GetWebhookDeliveries(ctx.Params("id"))
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(deliveries)
+}
+
+// @Summary Test webhook
+// @Security BasicAuth
+// @Description Запрос на отправку тестового события (тип webhook.test) на адрес вебхука: выполняется одна попытка доставки без повторов, результат записывается в историю доставок и возвращается в ответе, доступно только администраторам
+// @Produce json
+// @Param id path string true "идентификатор вебхука"
+// @Success      200  {json}  json	model.WebhookDelivery
+// @Failure      500  {string}  string	"no such webhook"
+// @Router /v1/webhooks/{id}/test [post]
+func TestWebhookRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "test webhook")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Отправка тестового события
+	delivery, err := requestTenant(ctx).TestWebhook(ctx.Params("id"), authorizedLogin(ctx))
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d): test event %s", fiber.StatusOK, delivery.Status)
+	return ctx.JSON(delivery)
+}
+
+// @Summary Get undelivered webhook events
+// @Security BasicAuth
+// @Description Запрос на вывод недоставленных событий тенанта - доставок, все попытки которых завершились ошибкой, доступно только администраторам
+// @Produce json
+// @Success      200  {json}  json	model.WebhookDelivery
+// @Router /v1/webhooks/dead-letters [get]
+func GetWebhookDeadLettersRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "get undelivered webhook events")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Получение списка недоставленных событий из БД
+	deadLetters := requestTenant(ctx).GetWebhookDeadLetters()
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.JSON(deadLetters)
+}
+
+// @Summary Retry undelivered webhook event
+// @Security BasicAuth
+// @Description Запрос на повторную доставку недоставленного события: доставка убирается из списка недоставленных событий и снова выполняется с полным набором попыток, доступно только администраторам
+// @Param id path string true "идентификатор доставки"
+// @Success      200  {string}  string	"request completed"
+// @Failure      500  {string}  string	"no such undelivered webhook event"
+// @Router /v1/webhooks/dead-letters/{id}/retry [post]
+func RetryWebhookDeliveryRequest(ctx *fiber.Ctx) error {
+	log.Printf("\"%s\" request received", "retry undelivered webhook event")
+
+	// Проверка, является ли авторизованный пользователь администратором тенанта
+	if !isTenantAdmin(ctx) {
+		log.Println(userIsNotAdminErr.Error())
+		return userIsNotAdminErr
+	}
+
+	// Повторная доставка события
+	err := requestTenant(ctx).RetryWebhookDelivery(ctx.Params("id"))
+	if err != nil {
+		log.Printf("request error (status %d): %s", errorStatus(err), err.Error())
+		return err
+	}
+	log.Printf("request completed (status %d)", fiber.StatusOK)
+	return ctx.SendString("request completed")
+}
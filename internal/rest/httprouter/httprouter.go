@@ -21,24 +21,139 @@ func StartAPI() error {
 	// Развертывание API
 	app := fiber.New()
 
+	// Ограничение ведомого экземпляра и узла кластера, не являющегося лидером: изменяющие запросы перенаправляются ведущему экземпляру (лидеру кластера) или отклоняются
+	followerGuard, err := handlers.FollowerGuard()
+	if err != nil {
+		return err
+	}
+	app.Use(followerGuard)
+
 	// Swagger
 	app.Get("/swagger/*", swagger.HandlerDefault)
 
+	// Проверка готовности экземпляра (без авторизации)
+	app.Get("/ready", handlers.ReadinessRequest)
+
+	// OAuth 2.0 (клиенты авторизуются своими идентификатором и секретом, а не basic auth пользователей)
+	app.Post("/oauth/token", handlers.TokenRequest)           // запрос на выдачу токена доступа
+	app.Post("/oauth/introspect", handlers.IntrospectRequest) // запрос на интроспекцию токена доступа
+	app.Post("/oauth/revoke", handlers.RevokeRequest)         // запрос на отзыв токена доступа
+
+	// Определение тенанта запроса
+	tenantResolver, err := handlers.ResolveTenant()
+	if err != nil {
+		return err
+	}
+	app.Use(tenantResolver)
+
+	// Политика паролей, задаваемых пользователями
+	err = handlers.LoadPasswordPolicy()
+	if err != nil {
+		return err
+	}
+
+	// Конфигурация самостоятельной регистрации пользователей
+	err = handlers.LoadRegistrationConfig()
+	if err != nil {
+		return err
+	}
+
+	// Самостоятельная регистрация и подтверждение адреса электронной почты (пользователь еще не может авторизоваться)
+	registrationLimiter := handlers.RegistrationLimiter()
+	app.Post("/register", registrationLimiter, handlers.RegisterRequest)                 // запрос на самостоятельную регистрацию
+	app.Post("/tenants/:tenant/register", registrationLimiter, handlers.RegisterRequest) // запрос на самостоятельную регистрацию в тенанте из пути
+	app.Post("/email/verify/:token", handlers.VerifyEmailRequest)                        // запрос на подтверждение адреса электронной почты
+	app.Post("/tenants/:tenant/email/verify/:token", handlers.VerifyEmailRequest)        // запрос на подтверждение адреса электронной почты в тенанте из пути
+
+	// Принятие приглашений (приглашенный пользователь еще не имеет пароля и не авторизуется)
+	app.Post("/invitations/:token/accept", handlers.AcceptInvitationRequest)                 // запрос на принятие приглашения
+	app.Post("/tenants/:tenant/invitations/:token/accept", handlers.AcceptInvitationRequest) // запрос на принятие приглашения в тенанте из пути
+
 	// Аутентификация
 	app.Use(handlers.BasicAuth())
 
-	// Инициализация запросов
-	app.Get("/profile", handlers.GetProfileDataRequest)    // запрос на получение данных о профиле
-	app.Get("/logins", handlers.GetAllLoginsRequest)       // запрос на получение списка логинов всех пользователей
-	app.Post("/profile", handlers.AddProfileRequest)       // запрос на добавление пользователя
-	app.Patch("/profile", handlers.EditProfileRequest)     // запрос на изменение данных пользователя
-	app.Patch("/password", handlers.ChangePasswordRequest) // запрос на изменение пароля профиля
-	app.Delete("/profile", handlers.RemoveProfileRequest)  // запрос на удаление профиля
-	app.Post("/admin", handlers.AddAdminRequest)           // запрос добавление администратора
-	app.Delete("/admin", handlers.DropAdminRequest)        // запрос удаление администратора
+	// Инициализация запросов к платформе
+	app.Get("/tenants", handlers.GetAllTenantsRequest)                       // запрос на получение списка тенантов
+	app.Post("/tenant", handlers.AddTenantRequest)                           // запрос на создание тенанта
+	app.Delete("/tenant", handlers.RemoveTenantRequest)                      // запрос на удаление тенанта
+	app.Post("/platformAdmin", handlers.AddPlatformAdminRequest)             // запрос на добавление администратора платформы
+	app.Delete("/platformAdmin", handlers.DropPlatformAdminRequest)          // запрос на удаление администратора платформы
+	app.Post("/oauth/client", handlers.AddClientRequest)                     // запрос на регистрацию клиента OAuth 2.0
+	app.Delete("/oauth/client", handlers.RemoveClientRequest)                // запрос на удаление клиента OAuth 2.0
+	app.Get("/v1/admin/backup", handlers.BackupRequest)                      // запрос на получение резервной копии БД
+	app.Post("/v1/admin/restore", handlers.RestoreRequest)                   // запрос на восстановление БД из резервной копии
+	app.Get("/v1/replication/snapshot", handlers.ReplicationSnapshotRequest) // запрос на получение снимка БД для ведомого экземпляра
+	app.Get("/v1/replication/log", handlers.ReplicationLogRequest)           // запрос на получение записей журнала изменений БД для ведомого экземпляра
+	app.Get("/v1/cluster", handlers.ClusterStatusRequest)                    // запрос на получение состояния узла кластера
+
+	// Инициализация запросов к тенанту: тенант задается путем /tenants/{tenant}/..., заголовком или поддоменом
+	registerTenantRoutes(app)
+	registerTenantRoutes(app.Group("/tenants/:tenant"))
 
 	// Выыод API на порт из конфигурационного файла
 	app.Listen(port)
 
 	return nil
 }
+
+/*
+Инициализация запросов к данным тенанта
+
+:param router fiber.Router: роутер, к которому добавляются запросы
+*/
+func registerTenantRoutes(router fiber.Router) {
+	router.Get("/profile", handlers.GetProfileDataRequest)                                   // запрос на получение данных о профиле
+	router.Get("/logins", handlers.GetAllLoginsRequest)                                      // запрос на получение списка логинов всех пользователей
+	router.Post("/profile", handlers.AddProfileRequest)                                      // запрос на добавление пользователя
+	router.Patch("/profile", handlers.EditProfileRequest)                                    // запрос на изменение данных пользователя
+	router.Patch("/password", handlers.ChangePasswordRequest)                                // запрос на изменение пароля профиля
+	router.Patch("/profile/login", handlers.RenameProfileRequest)                            // запрос на изменение логина профиля
+	router.Delete("/profile", handlers.RemoveProfileRequest)                                 // запрос на удаление профиля
+	router.Get("/trash", handlers.GetDeletedProfilesRequest)                                 // запрос на получение профилей в корзине
+	router.Post("/trash/restore", handlers.RestoreProfileRequest)                            // запрос на восстановление профиля из корзины
+	router.Delete("/trash", handlers.PurgeProfileRequest)                                    // запрос на окончательное удаление профиля из корзины
+	router.Post("/admin", handlers.AddAdminRequest)                                          // запрос добавление администратора
+	router.Delete("/admin", handlers.DropAdminRequest)                                       // запрос удаление администратора
+	router.Get("/group", handlers.GetGroupDataRequest)                                       // запрос на получение данных о группе
+	router.Get("/groups", handlers.GetAllGroupsRequest)                                      // запрос на получение списка названий всех групп
+	router.Get("/membership", handlers.GetEffectiveGroupsRequest)                            // запрос на получение всех групп профиля с учетом вложенности
+	router.Post("/group", handlers.AddGroupRequest)                                          // запрос на создание группы
+	router.Patch("/group", handlers.RenameGroupRequest)                                      // запрос на переименование группы
+	router.Delete("/group", handlers.RemoveGroupRequest)                                     // запрос на удаление группы
+	router.Post("/group/member", handlers.AddGroupMemberRequest)                             // запрос на добавление профиля в группу
+	router.Delete("/group/member", handlers.RemoveGroupMemberRequest)                        // запрос на удаление профиля из группы
+	router.Post("/group/subgroup", handlers.AddSubgroupRequest)                              // запрос на вложение группы
+	router.Delete("/group/subgroup", handlers.RemoveSubgroupRequest)                         // запрос на удаление вложенной группы
+	router.Get("/schema", handlers.GetAttributesSchemaRequest)                               // запрос на получение схемы атрибутов профилей
+	router.Put("/schema/attribute", handlers.SetAttributeDefinitionRequest)                  // запрос на добавление или замену описания атрибута
+	router.Delete("/schema/attribute", handlers.RemoveAttributeDefinitionRequest)            // запрос на удаление описания атрибута
+	router.Get("/schema/visibility", handlers.GetFieldsVisibilityRequest)                    // запрос на получение настроек видимости полей профилей
+	router.Put("/schema/visibility", handlers.SetFieldVisibilityRequest)                     // запрос на изменение видимости поля профилей
+	router.Post("/admin/group", handlers.AddAdminGroupRequest)                               // запрос на добавление группы администраторов
+	router.Delete("/admin/group", handlers.DropAdminGroupRequest)                            // запрос на удаление группы администраторов
+	router.Post("/invitation", handlers.CreateInvitationRequest)                             // запрос на создание приглашения
+	router.Get("/invitations", handlers.GetInvitationsRequest)                               // запрос на получение списка приглашений
+	router.Delete("/invitation", handlers.RevokeInvitationRequest)                           // запрос на отзыв приглашения
+	router.Post("/email/verification", handlers.ResendVerificationRequest)                   // запрос на повторную отправку токена подтверждения адреса электронной почты
+	router.Get("/registrations", handlers.GetRegistrationsRequest)                           // запрос на получение очереди заявок на регистрацию
+	router.Post("/registration/approve", handlers.ApproveRegistrationRequest)                // запрос на одобрение заявки на регистрацию
+	router.Post("/registration/reject", handlers.RejectRegistrationRequest)                  // запрос на отклонение заявки на регистрацию
+	router.Get("/v1/profiles", handlers.GetAccountsRequest)                                  // запрос на получение учетных записей со статусами
+	router.Post("/v1/profiles/import", handlers.ImportProfilesRequest)                       // запрос на импорт профилей из CSV или JSON Lines
+	router.Get("/v1/profiles/export", handlers.ExportProfilesRequest)                        // запрос на экспорт профилей в CSV или JSON Lines
+	router.Post("/v1/batch", handlers.BatchRequest)                                          // запрос на выполнение пакета операций над профилями
+	router.Post("/v1/profiles/:login/activate", handlers.ActivateAccountRequest)             // запрос на активацию учетной записи
+	router.Post("/v1/profiles/:login/suspend", handlers.SuspendAccountRequest)               // запрос на приостановку учетной записи
+	router.Put("/v1/profiles/:login/expiry", handlers.SetAccountExpiryRequest)               // запрос на изменение срока действия учетной записи
+	router.Get("/v1/profiles/:login/history", handlers.GetProfileHistoryRequest)             // запрос на получение истории изменений профиля
+	router.Get("/v1/profiles/:login/history/as-of", handlers.GetProfileAsOfRequest)          // запрос на получение данных профиля на момент версии или времени
+	router.Post("/v1/profiles/:login/revert", handlers.RevertProfileRequest)                 // запрос на возврат данных профиля к предыдущей версии
+	router.Get("/v1/events", handlers.EventsRequest)                                         // запрос на получение потока событий изменений профилей
+	router.Post("/v1/webhooks", handlers.AddWebhookRequest)                                  // запрос на регистрацию вебхука
+	router.Get("/v1/webhooks", handlers.GetWebhooksRequest)                                  // запрос на получение списка вебхуков
+	router.Get("/v1/webhooks/dead-letters", handlers.GetWebhookDeadLettersRequest)           // запрос на получение списка недоставленных событий
+	router.Post("/v1/webhooks/dead-letters/:id/retry", handlers.RetryWebhookDeliveryRequest) // запрос на повторную доставку недоставленного события
+	router.Delete("/v1/webhooks/:id", handlers.RemoveWebhookRequest)                         // запрос на удаление вебхука
+	router.Get("/v1/webhooks/:id/deliveries", handlers.GetWebhookDeliveriesRequest)          // запрос на получение истории доставок вебхука
+	router.Post("/v1/webhooks/:id/test", handlers.TestWebhookRequest)                        // запрос на отправку тестового события на адрес вебхука
+}
@@ -0,0 +1,89 @@
+// Пакет testutil содержит общие вспомогательные функции тестов пакетов сервиса (импортируется только тестами)
+package testutil
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/*
+Запуск тестов пакета из временного каталога: конфиги читаются по путям "../../configs/*.json" относительно рабочего каталога, поэтому тесты выполняются
+из каталога temp/run/tests, а в temp/configs копируются конфиги репозитория с изменениями changes; логи тестируемого кода не выводятся
+
+:param m *testing.M: тесты пакета
+:param configsDir string: каталог конфигов репозитория относительно каталога пакета
+:param changes map[string]map[string]interface{}: изменения конфигов для тестов по именам файлов конфигов (nil - конфиги копируются без изменений)
+:param setup func() error: подготовка пакета после перехода во временный каталог (nil - подготовка не нужна)
+
+:return: код завершения тестов
+*/
+func RunWithConfigs(m *testing.M, configsDir string, changes map[string]map[string]interface{}, setup func() error) int {
+	dir, err := ioutil.TempDir("", "authenticationservice-test")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	err = CopyConfigs(configsDir, filepath.Join(dir, "configs"), changes)
+	if err != nil {
+		log.Fatal(err)
+	}
+	workDir := filepath.Join(dir, "run", "tests")
+	err = os.MkdirAll(workDir, 0700)
+	if err == nil {
+		err = os.Chdir(workDir)
+	}
+	if err == nil && setup != nil {
+		err = setup()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.SetOutput(ioutil.Discard)
+	return m.Run()
+}
+
+/*
+Копирование конфигов репозитория во временный каталог с изменениями для тестов
+
+:param from string: каталог конфигов репозитория
+:param to string: временный каталог конфигов
+:param changes map[string]map[string]interface{}: изменения конфигов по именам файлов конфигов (значения заменяют значения конфигов репозитория)
+
+:return: ошибка, если конфиги не удалось скопировать
+*/
+func CopyConfigs(from string, to string, changes map[string]map[string]interface{}) error {
+	err := os.MkdirAll(to, 0700)
+	if err != nil {
+		return err
+	}
+	files, err := filepath.Glob(filepath.Join(from, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if fileChanges, ok := changes[filepath.Base(file)]; ok {
+			var config map[string]interface{}
+			err = json.Unmarshal(data, &config)
+			if err != nil {
+				return err
+			}
+			for key, value := range fileChanges {
+				config[key] = value
+			}
+			data, _ = json.Marshal(config)
+		}
+		err = ioutil.WriteFile(filepath.Join(to, filepath.Base(file)), data, 0600)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package testutil
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+/*
+Запрос к API экземпляра, запросы которого проходят middleware ограничения ведомого экземпляра (узла кластера); локальные обработчики отвечают "local"
+
+:param t *testing.T: тест
+:param newGuard func() (func(*fiber.Ctx) error, error): конструктор middleware (middleware строится для каждого запроса заново)
+:param method string: метод запроса
+:param path string: путь запроса
+:param header map[string]string: заголовки запроса
+
+:return: статус и тело ответа
+*/
+func GuardedRequest(t *testing.T, newGuard func() (func(*fiber.Ctx) error, error), method string, path string, header map[string]string) (int, string) {
+	t.Helper()
+	guard, err := newGuard()
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := fiber.New()
+	app.Use(guard)
+	app.All("/*", func(ctx *fiber.Ctx) error { return ctx.SendString("local") })
+	request := httptest.NewRequest(method, path, strings.NewReader(`{"login":"user"}`))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	for key, value := range header {
+		request.Header.Set(key, value)
+	}
+	response, err := app.Test(request, 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(response.Body)
+	return response.StatusCode, string(body)
+}
@@ -0,0 +1,56 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+)
+
+// Структура конфигурации доставки событий на адреса вебхуков
+type webhooksConfig struct {
+	Timeout        int64 `json:"timeout"`        // время ожидания ответа адреса на попытку доставки в секундах
+	MaxAttempts    int   `json:"maxAttempts"`    // количество попыток доставки события, после которых доставка переносится в список недоставленных событий
+	InitialBackoff int64 `json:"initialBackoff"` // задержка перед второй попыткой доставки в секундах (каждая следующая задержка вдвое больше предыдущей)
+	MaxBackoff     int64 `json:"maxBackoff"`     // наибольшая задержка между попытками доставки в секундах
+
+	AllowPrivateTargets     bool         `json:"allowPrivateTargets"`     // true - разрешены адреса вебхуков в петлевых, частных и локальных для канала сетях (по умолчанию запрещены)
+	PrivateTargetsAllowlist []string     `json:"privateTargetsAllowlist"` // диапазоны внутренних адресов (CIDR), разрешенные при allowPrivateTargets (пустой список - все внутренние адреса)
+	privateTargetsAllowlist []*net.IPNet // разобранные диапазоны privateTargetsAllowlist
+}
+
+/*
+Чтение конфигурации доставки событий из конфига "../../configs/webhooksConfig.json"
+
+:return: конфигурация доставки событий или ошибка, если конфиг не удалось прочитать или значения в нем не положительны
+*/
+func getWebhooksConfig() (webhooksConfig, error) {
+	configFilePath := "../../configs/webhooksConfig.json"
+	configFile, err := os.Open(configFilePath)
+	if err != nil {
+		return webhooksConfig{}, errors.New("fail to read webhooks config " + configFilePath)
+	}
+	defer configFile.Close()
+	byteValue, _ := ioutil.ReadAll(configFile)
+	var config webhooksConfig
+	json.Unmarshal(byteValue, &config)
+	if config.Timeout <= 0 || config.MaxAttempts <= 0 || config.InitialBackoff <= 0 || config.MaxBackoff <= 0 {
+		return webhooksConfig{}, errors.New("timeout, attempts and backoffs must be positive in webhooks config " + configFilePath)
+	}
+
+	// Список разрешенных внутренних адресов действует только при явном разрешении внутренних адресов
+	if len(config.PrivateTargetsAllowlist) > 0 && !config.AllowPrivateTargets {
+		return webhooksConfig{}, errors.New("privateTargetsAllowlist requires allowPrivateTargets in webhooks config " + configFilePath)
+	}
+	for _, cidr := range config.PrivateTargetsAllowlist {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return webhooksConfig{}, fmt.Errorf("invalid private target network \"%s\" in webhooks config %s", cidr, configFilePath)
+		}
+		config.privateTargetsAllowlist = append(config.privateTargetsAllowlist, network)
+	}
+
+	return config, nil
+}
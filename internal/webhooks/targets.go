@@ -0,0 +1,140 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// Ошибки проверки адреса вебхука
+var invalidURLErr error = errors.New("webhook url must be an absolute http or https url")
+var forbiddenTargetErr error = errors.New("webhook target address is loopback, private or link-local")
+
+// Диапазоны адресов, не являющиеся публичными, кроме проверяемых методами net.IP (разделяемые адреса провайдеров, "эта сеть" и т.п.)
+var reservedNetworks = mustParseCIDRs("0.0.0.0/8", "100.64.0.0/10", "192.0.0.0/24", "198.18.0.0/15", "240.0.0.0/4", "64:ff9b::/96")
+
+// Время ожидания разрешения имени хоста при регистрации вебхука
+const resolveTimeout = 5 * time.Second
+
+/*
+Разбор списка диапазонов адресов
+
+:param cidrs ...string: диапазоны адресов в нотации CIDR
+
+:return: список диапазонов (паникует, если диапазон некорректен)
+*/
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+/*
+Проверка, можно ли отправлять события на IP-адрес: петлевые, частные, локальные для канала, групповые и зарезервированные адреса запрещены,
+если в конфиге не разрешены внутренние адреса (при заданном списке разрешенных диапазонов разрешаются только адреса из него)
+
+:param ip net.IP: IP-адрес
+
+:return: ошибка, если адрес запрещен
+*/
+func checkIP(ip net.IP) error {
+	internal := ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified()
+	for _, network := range reservedNetworks {
+		internal = internal || network.Contains(ip)
+	}
+	if !internal {
+		return nil
+	}
+	if config.AllowPrivateTargets {
+		if len(config.privateTargetsAllowlist) == 0 {
+			return nil
+		}
+		for _, network := range config.privateTargetsAllowlist {
+			if network.Contains(ip) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: %s", forbiddenTargetErr, ip.String())
+}
+
+/*
+Проверка адреса вебхука при регистрации: адрес должен быть абсолютным http или https адресом, а все IP-адреса его хоста - разрешенными
+
+:param rawURL string: адрес вебхука
+
+:return: ошибка, если адрес некорректен, имя хоста не удалось разрешить или один из IP-адресов хоста запрещен
+*/
+func CheckURL(rawURL string) error {
+	targetURL, err := url.ParseRequestURI(rawURL)
+	if err != nil || (targetURL.Scheme != "http" && targetURL.Scheme != "https") || targetURL.Hostname() == "" {
+		return invalidURLErr
+	}
+	if ip := net.ParseIP(targetURL.Hostname()); ip != nil {
+		return checkIP(ip)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, targetURL.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: %s", invalidURLErr, err.Error())
+	}
+	for _, addr := range addrs {
+		err = checkIP(addr.IP)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+Проверка адреса, к которому подключается HTTP-клиент доставки событий (вызывается после разрешения имени хоста, перед каждым подключением,
+поэтому имя хоста, которое после регистрации стало указывать на внутренний адрес, и перенаправления на внутренние адреса не обходят проверку)
+
+:param network string: сеть подключения
+:param address string: IP-адрес и порт подключения
+:param conn syscall.RawConn: сокет подключения
+
+:return: ошибка, если адрес запрещен
+*/
+func checkDialAddress(network string, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: %s", forbiddenTargetErr, host)
+	}
+	return checkIP(ip)
+}
+
+/*
+Создание транспорта HTTP-клиента доставки событий: подключения проверяются checkDialAddress, прокси из переменных окружения не используются
+
+:return: транспорт HTTP-клиента
+*/
+func newTransport() *http.Transport {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: checkDialAddress}
+	return &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
@@ -0,0 +1,84 @@
+package webhooks
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+/*
+Замена конфигурации доставки событий на время теста
+
+:param t *testing.T: тест
+:param testConfig webhooksConfig: конфигурация
+*/
+func setConfig(t *testing.T, testConfig webhooksConfig) {
+	previous := config
+	config = testConfig
+	t.Cleanup(func() { config = previous })
+}
+
+// Внутренние адреса запрещены по умолчанию
+func TestCheckURLRejectsInternalTargets(t *testing.T) {
+	setConfig(t, webhooksConfig{Timeout: 1, MaxAttempts: 1, InitialBackoff: 1, MaxBackoff: 1})
+	targets := []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://10.1.2.3/hook",
+		"http://172.16.0.1/hook",
+		"https://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+		"http://0.0.0.0/hook",
+		"http://100.64.0.1/hook",
+	}
+	for _, target := range targets {
+		if err := CheckURL(target); !errors.Is(err, forbiddenTargetErr) {
+			t.Errorf("%s: expected forbidden target error, got %v", target, err)
+		}
+	}
+	for _, target := range []string{"ftp://example.com/hook", "/hook", "http:///hook"} {
+		if err := CheckURL(target); !errors.Is(err, invalidURLErr) {
+			t.Errorf("%s: expected invalid url error, got %v", target, err)
+		}
+	}
+	if err := CheckURL("https://93.184.216.34/hook"); err != nil {
+		t.Errorf("public address is rejected: %v", err)
+	}
+}
+
+// Внутренние адреса разрешаются только явно, список диапазонов ограничивает разрешенные адреса
+func TestPrivateTargetsAllowlist(t *testing.T) {
+	testConfig := webhooksConfig{Timeout: 1, MaxAttempts: 1, InitialBackoff: 1, MaxBackoff: 1, AllowPrivateTargets: true}
+	setConfig(t, testConfig)
+	if err := checkIP(net.ParseIP("10.0.0.1")); err != nil {
+		t.Fatalf("private address is rejected with allowPrivateTargets: %v", err)
+	}
+	testConfig.privateTargetsAllowlist = mustParseCIDRs("127.0.0.0/8")
+	setConfig(t, testConfig)
+	if err := checkIP(net.ParseIP("127.0.0.1")); err != nil {
+		t.Fatalf("allowlisted address is rejected: %v", err)
+	}
+	if err := checkIP(net.ParseIP("169.254.169.254")); !errors.Is(err, forbiddenTargetErr) {
+		t.Fatalf("address outside of allowlist is accepted: %v", err)
+	}
+}
+
+// Подключение к внутреннему адресу отклоняется при доставке, даже если адрес не проверялся при регистрации
+func TestDeliverRejectsInternalTargetOnDial(t *testing.T) {
+	setConfig(t, webhooksConfig{Timeout: 1, MaxAttempts: 1, InitialBackoff: 1, MaxBackoff: 1})
+	received := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { received = true }))
+	defer receiver.Close()
+
+	status, err := Deliver(receiver.URL, "secret", models.WebhookPayload{DeliveryID: "d1"})
+	if err == nil || !errors.Is(err, forbiddenTargetErr) || status != 0 || received {
+		t.Fatalf("delivery to loopback address is not rejected: status %d, error %v", status, err)
+	}
+}
@@ -0,0 +1,126 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ZotovSergey/authenticationservice/internal/models"
+)
+
+// Заголовки запроса доставки события
+const (
+	HeaderDeliveryID = "X-Webhook-Id"        // идентификатор доставки
+	HeaderEvent      = "X-Webhook-Event"     // тип события
+	HeaderTimestamp  = "X-Webhook-Timestamp" // время отправки запроса (unix-время в секундах), входит в подпись
+	HeaderSignature  = "X-Webhook-Signature" // подпись "sha256=<hex>" - HMAC-SHA256 секретом вебхука от строки "<время>.<тело запроса>"
+)
+
+// Конфигурация доставки событий (заменяется конфигом функцией Configure)
+var config = webhooksConfig{Timeout: 10, MaxAttempts: 6, InitialBackoff: 30, MaxBackoff: 3600}
+
+// HTTP-клиент, которым отправляются события (время ожидания ответа задается конфигом, подключения к внутренним адресам запрещены)
+var Client = &http.Client{Timeout: time.Duration(config.Timeout) * time.Second, Transport: newTransport()}
+
+/*
+Чтение конфигурации доставки событий из конфига "../../configs/webhooksConfig.json"
+
+:return: ошибка, если конфиг не удалось прочитать
+*/
+func Configure() error {
+	var err error
+	config, err = getWebhooksConfig()
+	if err != nil {
+		return err
+	}
+	Client.Timeout = time.Duration(config.Timeout) * time.Second
+	log.Printf("webhooks configured: %d attempts, backoff from %d to %d seconds", config.MaxAttempts, config.InitialBackoff, config.MaxBackoff)
+	if config.AllowPrivateTargets {
+		log.Printf("warning: webhooks to private networks are allowed: %v", config.PrivateTargetsAllowlist)
+	}
+	return nil
+}
+
+/*
+Подпись тела запроса доставки события
+
+:param secret string: секрет вебхука
+:param timestamp int64: время отправки запроса (unix-время в секундах)
+:param body []byte: тело запроса
+
+:return: подпись в формате "sha256=<hex>"
+*/
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+/*
+Одна попытка доставки события на адрес вебхука: тело запроса подписывается секретом вебхука, событие считается доставленным, если адрес ответил статусом 2xx
+
+:param url string: адрес вебхука
+:param secret string: секрет вебхука
+:param payload models.WebhookPayload: тело запроса
+
+:return: статус ответа адреса (0 - ответ не получен) и ошибка, если событие не доставлено
+*/
+func Deliver(url string, secret string, payload models.WebhookPayload) (int, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "authenticationservice-webhooks")
+	request.Header.Set(HeaderDeliveryID, payload.DeliveryID)
+	request.Header.Set(HeaderEvent, payload.Event.Type)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	response, err := Client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(response.Body, 64*1024))
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected response status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+/*
+Задержка перед следующей попыткой доставки (экспоненциальная: каждая следующая задержка вдвое больше предыдущей, но не больше наибольшей задержки из конфига)
+
+:param attempts int: количество выполненных попыток доставки
+
+:return: задержка в секундах и true или 0 и false, если попытки доставки исчерпаны
+*/
+func RetryDelay(attempts int) (int64, bool) {
+	if attempts >= config.MaxAttempts {
+		return 0, false
+	}
+	delay := config.InitialBackoff
+	for i := 1; i < attempts && delay < config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > config.MaxBackoff {
+		delay = config.MaxBackoff
+	}
+	return delay, true
+}
@@ -0,0 +1,26 @@
+package webhooks
+
+import "testing"
+
+// Задержка удваивается после каждой неудачной попытки, ограничена наибольшей задержкой, после исчерпания попыток повторов нет
+func TestRetryDelaySchedule(t *testing.T) {
+	setConfig(t, webhooksConfig{Timeout: 10, MaxAttempts: 6, InitialBackoff: 30, MaxBackoff: 300})
+	for attempts, expected := range []int64{30, 30, 60, 120, 240, 300} {
+		delay, ok := RetryDelay(attempts)
+		if !ok || delay != expected {
+			t.Errorf("after %d attempts: expected delay %d, got %d (retry %t)", attempts, expected, delay, ok)
+		}
+	}
+	if _, ok := RetryDelay(6); ok {
+		t.Errorf("delivery is retried after all attempts")
+	}
+}
+
+// Подпись вычисляется от строки "<время>.<тело запроса>"
+func TestSign(t *testing.T) {
+	signature := Sign("secret", 1700000000, []byte(`{"deliveryId":"d1"}`))
+	if signature != Sign("secret", 1700000000, []byte(`{"deliveryId":"d1"}`)) || signature == Sign("secret", 1700000001, []byte(`{"deliveryId":"d1"}`)) ||
+		signature == Sign("other", 1700000000, []byte(`{"deliveryId":"d1"}`)) || len(signature) != len("sha256=")+64 {
+		t.Fatalf("unexpected signature %s", signature)
+	}
+}